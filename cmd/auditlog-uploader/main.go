@@ -0,0 +1,164 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/auditlog"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/version"
+	"gocloud.dev/blob"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog/v2"
+
+	// Enable FIPS when necessary
+	_ "github.com/pingcap/tidb-operator/pkg/fips"
+)
+
+// storageEnv is the env of the JSON encoded storage provider the audit log files are uploaded to
+const storageEnv = "AUDIT_LOG_STORAGE"
+
+var (
+	printVersion   bool
+	file           string
+	retention      time.Duration
+	objectLockMode string
+	interval       time.Duration
+	flushTimeout   time.Duration
+)
+
+func init() {
+	klog.InitFlags(nil)
+	flag.BoolVar(&printVersion, "V", false, "Show version and quit")
+	flag.BoolVar(&printVersion, "version", false, "Show version and quit")
+	flag.StringVar(&file, "file", "", "The path of the audit log file of TiDB")
+	flag.DurationVar(&retention, "retention", 0, "How long the uploaded files are retained, 0 means forever")
+	flag.StringVar(&objectLockMode, "object-lock-mode", "", "The S3 object lock mode of the uploaded files, GOVERNANCE or COMPLIANCE")
+	flag.DurationVar(&interval, "interval", time.Minute, "The interval to check the rotated audit log files")
+	flag.DurationVar(&flushTimeout, "flush-timeout", 20*time.Second, "The timeout to upload the active audit log file when terminated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s verify [--instance=<pod>]\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+}
+
+func main() {
+	if printVersion {
+		version.PrintVersionInfo()
+		os.Exit(0)
+	}
+	version.LogVersionInfo()
+
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	provider := v1alpha1.StorageProvider{}
+	if err := json.Unmarshal([]byte(os.Getenv(storageEnv)), &provider); err != nil {
+		klog.Fatalf("failed to parse the storage from env %s: %v", storageEnv, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := backuputil.LoadStorageCredentials(ctx, backuputil.CredentialsReloadInterval); err != nil {
+		klog.Fatal(err)
+	}
+
+	if flag.Arg(0) == "verify" {
+		runVerify(ctx, provider, flag.Args()[1:])
+		return
+	}
+	if file == "" {
+		klog.Fatal("--file is required")
+	}
+
+	uploader := &auditlog.Uploader{
+		File:           file,
+		Instance:       os.Getenv("POD_NAME"),
+		OpenBucket:     openBucketFunc(provider),
+		Retention:      retention,
+		ObjectLockMode: objectLockMode,
+	}
+
+	klog.Infof("uploading the rotated files of audit log %s to %s", file, backuputil.GetStorageType(provider))
+	uploader.Run(ctx, interval, flushTimeout)
+	klog.Infof("tidb-auditlog-uploader exited")
+}
+
+// runVerify verifies the chain of the uploaded files of the instance, it's run in the sidecar by such as
+// `kubectl exec <pod> -c auditlog-uploader -- /usr/local/bin/tidb-auditlog-uploader verify`
+func runVerify(ctx context.Context, provider v1alpha1.StorageProvider, args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	instance := fs.String("instance", os.Getenv("POD_NAME"), "The name of the TiDB instance whose uploaded files are verified")
+	fs.Parse(args)
+	if *instance == "" {
+		klog.Fatal("--instance is required")
+	}
+
+	bucket, err := openBucketFunc(provider)()
+	if err != nil {
+		klog.Fatalf("failed to open the bucket: %v", err)
+	}
+	defer bucket.Close()
+	chain, err := auditlog.Verify(ctx, bucket, *instance)
+	if err != nil {
+		klog.Fatalf("failed to verify the audit log files of %s: %v", *instance, err)
+	}
+	for _, m := range chain {
+		fmt.Printf("%s\t%s\t%s\n", m.UploadedAt.Format(time.RFC3339), m.File, m.Chain)
+	}
+	if len(chain) > 0 && chain[0].Previous != "" {
+		fmt.Printf("the chain starts at %s, its previous files are expired or not found\n", chain[0].File)
+	}
+	fmt.Printf("%d audit log files of %s are verified\n", len(chain), *instance)
+}
+
+// openBucketFunc returns the function opening the bucket of the storage
+func openBucketFunc(provider v1alpha1.StorageProvider) func() (*blob.Bucket, error) {
+	return func() (*blob.Bucket, error) {
+		if err := setGcsCredentialsFile(); err != nil {
+			return nil, err
+		}
+		backend, err := backuputil.NewStorageBackend(provider, nil)
+		if err != nil {
+			return nil, err
+		}
+		return backend.Bucket, nil
+	}
+}
+
+// setGcsCredentialsFile writes the service account key in the env GCS_SERVICE_ACCOUNT_JSON_KEY to a file for the
+// GCS client, which only reads the key from the file of GOOGLE_APPLICATION_CREDENTIALS
+func setGcsCredentialsFile() error {
+	key := os.Getenv("GCS_SERVICE_ACCOUNT_JSON_KEY")
+	if key == "" {
+		return nil
+	}
+	path := filepath.Join(os.TempDir(), "gcs-service-account.json")
+	if err := os.WriteFile(path, []byte(key), 0600); err != nil {
+		return err
+	}
+	return os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+}
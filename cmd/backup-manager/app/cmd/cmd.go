@@ -14,6 +14,9 @@
 package cmd
 
 import (
+	"context"
+
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +28,10 @@ func NewBackupMgrCommand() *cobra.Command {
 		Short: "Helper for backup manage",
 		Long:  "Dump tidb cluster data, as well as backup and restore tidb cluster data",
 		Run:   runHelp,
+		// the storage credentials from the external secret source are loaded before all the commands
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return backuputil.LoadStorageCredentials(context.Background(), backuputil.CredentialsReloadInterval)
+		},
 	}
 
 	cmds.PersistentFlags().StringVarP(&kubecfg, "kubeconfig", "k", "", "Path to kubeconfig file, omit this if run in cluster.")
@@ -35,6 +42,8 @@ func NewBackupMgrCommand() *cobra.Command {
 	cmds.AddCommand(NewImportCommand())
 	cmds.AddCommand(NewCleanCommand())
 	cmds.AddCommand(NewCompactCommand())
+	cmds.AddCommand(NewNGMExportCommand())
+	cmds.AddCommand(NewStorageCredentialsCommand())
 	return cmds
 }
 
@@ -0,0 +1,69 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/ngmexport"
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
+	bkutil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewNGMExportCommand implements the command exporting the data of tidb ng monitoring
+func NewNGMExportCommand() *cobra.Command {
+	opts := ngmexport.Options{}
+
+	cmd := &cobra.Command{
+		Use:   "ngm-export",
+		Short: "Export continuous profiling and Top SQL data of tidb ng monitoring.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(runNGMExport(opts))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Namespace, "namespace", "", "TidbNGMonitoring's namespace")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "TidbNGMonitoring's name")
+	cmd.Flags().StringVar(&opts.URL, "url", "", "URL of the ng monitoring api")
+	cmd.Flags().DurationVar(&opts.Window, "window", 0, "Time range of the exported data, ending at now")
+	cmd.Flags().StringVar(&opts.Storage, "storage", "", "Storage provider in json")
+	cmd.Flags().StringVar(&opts.CA, "ca", "", "Path of the CA cert if TLS is enabled")
+	cmd.Flags().StringVar(&opts.Cert, "cert", "", "Path of the client cert if TLS is enabled")
+	cmd.Flags().StringVar(&opts.Key, "key", "", "Path of the client key if TLS is enabled")
+	return cmd
+}
+
+func runNGMExport(opts ngmexport.Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	provider, err := opts.StorageProvider()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := util.GetContextForTerminationSignals(fmt.Sprintf("ngm-export %s", opts.String()))
+	defer cancel()
+
+	// the credentials of the storage are provided by env
+	backend, err := bkutil.NewStorageBackend(provider, &bkutil.StorageCredential{})
+	if err != nil {
+		return err
+	}
+	defer backend.Close()
+
+	return ngmexport.Run(ctx, opts, backend.Bucket)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewStorageCredentialsCommand implements the command which prints the S3 credentials for the
+// `credential_process` of the AWS shared config
+func NewStorageCredentialsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    backuputil.StorageCredentialsCommand,
+		Short:  "Print the S3 credentials loaded from the credential files.",
+		Hidden: true,
+		// the output is read by the AWS SDK, so the credentials are not loaded by the root command
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			output, err := backuputil.ReadS3Credentials(backuputil.CredentialsReloadInterval)
+			cmdutil.CheckErr(err)
+			fmt.Fprintln(cmd.OutOrStdout(), string(output))
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,346 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ngmexport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	requestTimeout = 5 * time.Minute
+	// timeLayout is the layout of the begin and end time in the object key
+	timeLayout = "20060102T150405Z"
+	// checkpointKey is the object under the prefix recording the progress of the profiles
+	checkpointKey = "checkpoint.json"
+
+	profileStateRunning = "running"
+)
+
+// Options contains the options of exporting the data of ng monitoring
+type Options struct {
+	Namespace string
+	Name      string
+	// URL is the url of the http api of ng monitoring
+	URL    string
+	Window time.Duration
+	// Storage is the storage provider in json
+	Storage string
+	// CA, Cert and Key are the paths of the client certs if TLS is enabled
+	CA   string
+	Cert string
+	Key  string
+}
+
+func (o *Options) String() string {
+	return fmt.Sprintf("%s/%s", o.Namespace, o.Name)
+}
+
+// Validate checks whether the required options are set
+func (o *Options) Validate() error {
+	if o.Namespace == "" || o.Name == "" || o.URL == "" || o.Storage == "" {
+		return fmt.Errorf("namespace, name, url and storage are required")
+	}
+	if o.Window <= 0 {
+		return fmt.Errorf("window should be positive")
+	}
+	if (o.CA != "" || o.Cert != "" || o.Key != "") && (o.CA == "" || o.Cert == "" || o.Key == "") {
+		return fmt.Errorf("ca, cert and key should be set together")
+	}
+	return nil
+}
+
+// StorageProvider parses the storage provider from the options
+func (o *Options) StorageProvider() (v1alpha1.StorageProvider, error) {
+	provider := v1alpha1.StorageProvider{}
+	if err := json.Unmarshal([]byte(o.Storage), &provider); err != nil {
+		return provider, fmt.Errorf("parse storage %q failed: %v", o.Storage, err)
+	}
+	return provider, nil
+}
+
+// HTTPClient returns the client to access ng monitoring
+func (o *Options) HTTPClient() (*http.Client, error) {
+	cli := &http.Client{Timeout: requestTimeout}
+	if o.CA == "" {
+		return cli, nil
+	}
+	ca, err := os.ReadFile(o.CA)
+	if err != nil {
+		return nil, err
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to append ca certs from %s", o.CA)
+	}
+	cert, err := tls.LoadX509KeyPair(o.Cert, o.Key)
+	if err != nil {
+		return nil, err
+	}
+	cli.Transport = &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: []tls.Certificate{cert},
+	}}
+	return cli, nil
+}
+
+// Exporter downloads the Top SQL and continuous profiling data from ng monitoring,
+// and uploads them to the bucket.
+type Exporter struct {
+	httpClient *http.Client
+	url        string
+	bucket     *blob.Bucket
+	// prefix is the prefix of all the objects, which is `<namespace>/<name>`
+	prefix string
+}
+
+// NewExporter returns an Exporter
+func NewExporter(httpClient *http.Client, url string, bucket *blob.Bucket, namespace, name string) *Exporter {
+	return &Exporter{
+		httpClient: httpClient,
+		url:        strings.TrimSuffix(url, "/"),
+		bucket:     bucket,
+		prefix:     path.Join(namespace, name),
+	}
+}
+
+type topSQLInstance struct {
+	Instance     string `json:"instance"`
+	InstanceType string `json:"instance_type"`
+}
+
+type topSQLResponse struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type groupProfile struct {
+	Ts    int64  `json:"ts"`
+	State string `json:"state"`
+}
+
+// checkpoint is the progress of the profiles. A profiling round still running is not exported,
+// so the next export lists the profiles from the oldest unfinished round instead of the begin
+// of its window, and skips the rounds after it which are already exported.
+type checkpoint struct {
+	// ProfilingBegin is the unix time from which the next export lists the profiles
+	ProfilingBegin int64 `json:"profilingBegin"`
+	// Exported is the timestamps of the rounds after ProfilingBegin which are already exported
+	Exported []int64 `json:"exported,omitempty"`
+}
+
+// Export exports the data in [begin, end) to `<prefix>/<begin>-<end>/`.
+// Top SQL summaries are stored under `topsql/` by instance, and profiles are stored under `profiling/` by
+// the timestamp of the profiling round, in the zip format downloaded from ng monitoring. The profiles are
+// listed from the checkpoint if it's before begin, and the checkpoint is updated after the export.
+func (e *Exporter) Export(ctx context.Context, begin, end time.Time) (int, error) {
+	dir := path.Join(e.prefix, fmt.Sprintf("%s-%s", begin.UTC().Format(timeLayout), end.UTC().Format(timeLayout)))
+
+	cp, err := e.readCheckpoint(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint failed: %w", err)
+	}
+	topSQL, err := e.exportTopSQL(ctx, dir, begin, end)
+	if err != nil {
+		return 0, fmt.Errorf("export top sql failed: %w", err)
+	}
+	profiles, next, err := e.exportProfiles(ctx, dir, begin, end, cp)
+	if err != nil {
+		return 0, fmt.Errorf("export profiles failed: %w", err)
+	}
+	if err := e.writeCheckpoint(ctx, next); err != nil {
+		return 0, fmt.Errorf("write checkpoint failed: %w", err)
+	}
+	return topSQL + profiles, nil
+}
+
+// readCheckpoint returns the checkpoint of the last export, it's nil for the first export
+func (e *Exporter) readCheckpoint(ctx context.Context) (*checkpoint, error) {
+	data, err := e.bucket.ReadAll(ctx, path.Join(e.prefix, checkpointKey))
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := &checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func (e *Exporter) writeCheckpoint(ctx context.Context, cp *checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return e.bucket.WriteAll(ctx, path.Join(e.prefix, checkpointKey), data, nil)
+}
+
+func (e *Exporter) exportTopSQL(ctx context.Context, dir string, begin, end time.Time) (int, error) {
+	query := url.Values{}
+	query.Set("start", strconv.FormatInt(begin.Unix(), 10))
+	query.Set("end", strconv.FormatInt(end.Unix(), 10))
+
+	data, err := e.getTopSQL("/topsql/v1/instances", query)
+	if err != nil {
+		return 0, err
+	}
+	var instances []topSQLInstance
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return 0, err
+	}
+
+	for _, inst := range instances {
+		query.Set("instance", inst.Instance)
+		query.Set("instance_type", inst.InstanceType)
+		data, err := e.getTopSQL("/topsql/v1/summary", query)
+		if err != nil {
+			return 0, err
+		}
+		key := path.Join(dir, "topsql", fmt.Sprintf("%s-%s.json", inst.InstanceType, strings.ReplaceAll(inst.Instance, ":", "_")))
+		if err := e.bucket.WriteAll(ctx, key, data, nil); err != nil {
+			return 0, err
+		}
+	}
+	return len(instances), nil
+}
+
+// getTopSQL returns the data field of the response of the Top SQL api
+func (e *Exporter) getTopSQL(api string, query url.Values) ([]byte, error) {
+	data, err := httputil.GetBodyOK(e.httpClient, e.url+api+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	res := &topSQLResponse{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	if res.Status != "ok" {
+		return nil, fmt.Errorf("%s responds with status %q: %s", api, res.Status, string(data))
+	}
+	return res.Data, nil
+}
+
+// exportProfiles exports the finished profiling rounds and returns the checkpoint of the next export
+func (e *Exporter) exportProfiles(ctx context.Context, dir string, begin, end time.Time, cp *checkpoint) (int, *checkpoint, error) {
+	exported := map[int64]bool{}
+	if cp != nil && cp.ProfilingBegin < begin.Unix() {
+		begin = time.Unix(cp.ProfilingBegin, 0)
+		for _, ts := range cp.Exported {
+			exported[ts] = true
+		}
+	}
+	query := url.Values{}
+	query.Set("begin_time", strconv.FormatInt(begin.Unix(), 10))
+	query.Set("end_time", strconv.FormatInt(end.Unix(), 10))
+	data, err := httputil.GetBodyOK(e.httpClient, e.url+"/continuous_profiling/group_profiles?"+query.Encode())
+	if err != nil {
+		return 0, nil, err
+	}
+	var groups []groupProfile
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return 0, nil, err
+	}
+
+	// the rounds after the oldest running one are exported again unless they're recorded
+	var running *int64
+	for i := range groups {
+		if groups[i].State == profileStateRunning && (running == nil || groups[i].Ts < *running) {
+			running = &groups[i].Ts
+		}
+	}
+	next := &checkpoint{ProfilingBegin: end.Unix()}
+	if running != nil {
+		next.ProfilingBegin = *running
+	}
+
+	count := 0
+	for _, g := range groups {
+		// skip the round still running, it will be exported next time
+		if g.State == profileStateRunning {
+			continue
+		}
+		if !exported[g.Ts] {
+			if err := e.downloadProfile(ctx, path.Join(dir, "profiling", fmt.Sprintf("%d.zip", g.Ts)), g.Ts); err != nil {
+				return 0, nil, err
+			}
+			count++
+		}
+		if running != nil && g.Ts > *running {
+			next.Exported = append(next.Exported, g.Ts)
+		}
+	}
+	return count, next, nil
+}
+
+// downloadProfile streams the profiles of a profiling round to the object
+func (e *Exporter) downloadProfile(ctx context.Context, key string, ts int64) (err error) {
+	res, err := e.httpClient.Get(fmt.Sprintf("%s/continuous_profiling/download?ts=%d", e.url, ts))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode != http.StatusOK {
+		return httputil.ReadErrorBody(res.Body)
+	}
+
+	w, err := e.bucket.NewWriter(ctx, key, &blob.WriterOptions{ContentType: "application/zip"})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// Run exports the data of the window ending at now
+func Run(ctx context.Context, opts Options, bucket *blob.Bucket) error {
+	cli, err := opts.HTTPClient()
+	if err != nil {
+		return err
+	}
+	end := time.Now().Truncate(time.Minute)
+	begin := end.Add(-opts.Window)
+
+	klog.Infof("start to export data of tidb ng monitoring %s from %s to %s", opts.String(), begin, end)
+	count, err := NewExporter(cli, opts.URL, bucket, opts.Namespace, opts.Name).Export(ctx, begin, end)
+	if err != nil {
+		return err
+	}
+	klog.Infof("exported %d objects of tidb ng monitoring %s", count, opts.String())
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ngmexport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"gocloud.dev/blob/fileblob"
+)
+
+func TestExport(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	begin := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	end := begin.Add(time.Hour)
+
+	mux := http.NewServeMux()
+	// the profiling round starting at 1704167940 is still running in the first export
+	finished := false
+	mux.HandleFunc("/topsql/v1/instances", func(w http.ResponseWriter, r *http.Request) {
+		if finished {
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "data": []topSQLInstance{}})
+			return
+		}
+		g.Expect(r.URL.Query().Get("start")).To(Equal("1704164400"))
+		g.Expect(r.URL.Query().Get("end")).To(Equal("1704168000"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok",
+			"data":   []topSQLInstance{{Instance: "tidb-0:10080", InstanceType: "tidb"}, {Instance: "tikv-0:20180", InstanceType: "tikv"}},
+		})
+	})
+	mux.HandleFunc("/topsql/v1/summary", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok",
+			"data":   []map[string]string{{"sql_digest": "digest", "instance": r.URL.Query().Get("instance")}},
+		})
+	})
+	mux.HandleFunc("/continuous_profiling/group_profiles", func(w http.ResponseWriter, r *http.Request) {
+		if finished {
+			// the next export lists the profiles from the unfinished round
+			g.Expect(r.URL.Query().Get("begin_time")).To(Equal("1704167940"))
+			json.NewEncoder(w).Encode([]groupProfile{{Ts: 1704167940, State: "finished"}, {Ts: 1704168060, State: "finished"}})
+			return
+		}
+		g.Expect(r.URL.Query().Get("begin_time")).To(Equal("1704164400"))
+		json.NewEncoder(w).Encode([]groupProfile{{Ts: 1704164460, State: "finished"}, {Ts: 1704167940, State: "running"}})
+	})
+	mux.HandleFunc("/continuous_profiling/download", func(w http.ResponseWriter, r *http.Request) {
+		if !finished && r.URL.Query().Get("ts") != "1704164460" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("zip"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	bucket, err := fileblob.OpenBucket(dir, nil)
+	g.Expect(err).To(Succeed())
+	defer bucket.Close()
+
+	exporter := NewExporter(server.Client(), server.URL, bucket, "ns", "ngm")
+	count, err := exporter.Export(context.Background(), begin, end)
+	g.Expect(err).To(Succeed())
+	g.Expect(count).To(Equal(3))
+
+	prefix := filepath.Join(dir, "ns", "ngm", "20240102T030000Z-20240102T040000Z")
+	data, err := os.ReadFile(filepath.Join(prefix, "topsql", "tidb-tidb-0_10080.json"))
+	g.Expect(err).To(Succeed())
+	g.Expect(string(data)).To(ContainSubstring(`"instance":"tidb-0:10080"`))
+	_, err = os.Stat(filepath.Join(prefix, "topsql", "tikv-tikv-0_20180.json"))
+	g.Expect(err).To(Succeed())
+	data, err = os.ReadFile(filepath.Join(prefix, "profiling", "1704164460.zip"))
+	g.Expect(err).To(Succeed())
+	g.Expect(string(data)).To(Equal("zip"))
+	_, err = os.Stat(filepath.Join(prefix, "profiling", "1704167940.zip"))
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+	finished = true
+	count, err = exporter.Export(context.Background(), end, end.Add(time.Hour))
+	g.Expect(err).To(Succeed())
+	g.Expect(count).To(Equal(2))
+	prefix = filepath.Join(dir, "ns", "ngm", "20240102T040000Z-20240102T050000Z")
+	_, err = os.Stat(filepath.Join(prefix, "profiling", "1704167940.zip"))
+	g.Expect(err).To(Succeed())
+	_, err = os.Stat(filepath.Join(prefix, "profiling", "1704168060.zip"))
+	g.Expect(err).To(Succeed())
+}
+
+func TestExportProfilesFromCheckpoint(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	begin := time.Unix(1704164400, 0)
+	end := begin.Add(time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/continuous_profiling/group_profiles", func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Query().Get("begin_time")).To(Equal("1704160800"))
+		json.NewEncoder(w).Encode([]groupProfile{
+			{Ts: 1704160800, State: "running"},
+			{Ts: 1704160860, State: "finished"},
+			{Ts: 1704164460, State: "finished"},
+		})
+	})
+	mux.HandleFunc("/continuous_profiling/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("zip"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	bucket, err := fileblob.OpenBucket(t.TempDir(), nil)
+	g.Expect(err).To(Succeed())
+	defer bucket.Close()
+
+	exporter := NewExporter(server.Client(), server.URL, bucket, "ns", "ngm")
+	// the round at 1704160860 is exported in the last export
+	count, next, err := exporter.exportProfiles(context.Background(), "dir", begin, end, &checkpoint{ProfilingBegin: 1704160800, Exported: []int64{1704160860}})
+	g.Expect(err).To(Succeed())
+	g.Expect(count).To(Equal(1))
+	g.Expect(next).To(Equal(&checkpoint{ProfilingBegin: 1704160800, Exported: []int64{1704160860, 1704164460}}))
+}
+
+func TestOptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	opts := Options{Namespace: "ns", Name: "ngm", URL: "http://ngm:12020", Window: time.Hour, Storage: `{"s3":{"bucket":"bucket","prefix":"ngm"}}`}
+	g.Expect(opts.Validate()).To(Succeed())
+	provider, err := opts.StorageProvider()
+	g.Expect(err).To(Succeed())
+	g.Expect(provider.S3.Bucket).To(Equal("bucket"))
+
+	opts.CA = "/ca.crt"
+	g.Expect(opts.Validate()).To(HaveOccurred())
+	opts.CA = ""
+	opts.Window = 0
+	g.Expect(opts.Validate()).To(HaveOccurred())
+	opts.Window = time.Hour
+	opts.Storage = "s3"
+	_, err = opts.StorageProvider()
+	g.Expect(err).To(HaveOccurred())
+}
@@ -41,9 +41,12 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbngmonitoring"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	"github.com/pingcap/tidb-operator/pkg/upgrader"
 	"github.com/pingcap/tidb-operator/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -84,6 +87,22 @@ func main() {
 
 	logCustomPorts()
 
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Endpoint:    cliCfg.TracingEndpoint,
+		Insecure:    cliCfg.TracingInsecure,
+		SampleRatio: cliCfg.TracingSampleRatio,
+		ServiceName: "tidb-controller-manager",
+	})
+	if err != nil {
+		klog.Fatalf("failed to init tracing: %v", err)
+	}
+
+	tlsPolicy, err := pdapi.NewTLSPolicy(cliCfg.TLSMinVersion, cliCfg.TLSCipherSuites, cliCfg.TLSServerURISAN)
+	if err != nil {
+		klog.Fatalf("failed to parse the TLS policy: %v", err)
+	}
+	pdapi.SetTLSPolicy(tlsPolicy)
+
 	hostName, err := os.Hostname()
 	if err != nil {
 		klog.Fatalf("failed to get hostname: %v", err)
@@ -150,6 +169,7 @@ func main() {
 	if err != nil {
 		klog.Fatalf("failed to create Dependencies: %s", err)
 	}
+	deps.RESTConfig = cfg
 
 	onStarted := func(ctx context.Context) {
 		// Upgrade before running any controller logic. If it fails, we wait
@@ -211,6 +231,9 @@ func main() {
 		}
 		klog.Info("cache of informer factories sync successfully")
 
+		// Export the status observed by the operator once the caches are synced
+		prometheus.MustRegister(metrics.NewStatusCollector(deps.TiDBClusterLister, deps.BackupLister, deps.BackupScheduleLister, deps.RestoreLister))
+
 		// Start syncLoop for all controllers
 		for _, controller := range controllers {
 			c := controller
@@ -268,6 +291,9 @@ func main() {
 		if err2 := srv.Shutdown(context.Background()); err2 != nil {
 			klog.Fatal("fail to shutdown the HTTP server", err2)
 		}
+		if err2 := shutdownTracing(context.Background()); err2 != nil {
+			klog.Errorf("failed to flush traces: %v", err2)
+		}
 	}()
 
 	if err = srv.ListenAndServe(); err != http.ErrServerClosed {
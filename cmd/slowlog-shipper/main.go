@@ -0,0 +1,110 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/slowlog"
+	"github.com/pingcap/tidb-operator/pkg/version"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog/v2"
+
+	// Enable FIPS when necessary
+	_ "github.com/pingcap/tidb-operator/pkg/fips"
+)
+
+var (
+	printVersion bool
+	file         string
+	sinkType     string
+	sinkPath     string
+	sinkURL      string
+	batchSize    int
+	pollInterval time.Duration
+)
+
+func init() {
+	klog.InitFlags(nil)
+	flag.BoolVar(&printVersion, "V", false, "Show version and quit")
+	flag.BoolVar(&printVersion, "version", false, "Show version and quit")
+	flag.StringVar(&file, "file", "", "The path of the slow log file of TiDB")
+	flag.StringVar(&sinkType, "sink", slowlog.SinkStdout, "Where the records are shipped to, one of stdout, file and http")
+	flag.StringVar(&sinkPath, "sink-path", "", "The path of the file the records are appended to for the file sink")
+	flag.StringVar(&sinkURL, "sink-url", "", "The URL the records are POSTed to for the http sink")
+	flag.IntVar(&batchSize, "batch-size", 100, "The max number of records in a request of the http sink")
+	flag.DurationVar(&pollInterval, "poll-interval", time.Second, "The interval to check the slow log file for new lines")
+	flag.Parse()
+}
+
+func main() {
+	if printVersion {
+		version.PrintVersionInfo()
+		os.Exit(0)
+	}
+	version.LogVersionInfo()
+
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	if file == "" {
+		klog.Fatal("--file is required")
+	}
+	sink, err := slowlog.NewSink(slowlog.SinkConfig{
+		Type:      sinkType,
+		Path:      sinkPath,
+		URL:       sinkURL,
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		klog.Fatalf("failed to create the %s sink: %v", sinkType, err)
+	}
+	defer func() {
+		if err := sink.Close(); err != nil {
+			klog.Errorf("failed to close the sink: %v", err)
+		}
+	}()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	instance := os.Getenv("POD_NAME")
+	parser := &slowlog.Parser{}
+	onLine := func(line string) {
+		r := parser.Feed(line)
+		if r == nil {
+			return
+		}
+		r.Instance = instance
+		if err := sink.Write(r); err != nil {
+			klog.Errorf("failed to ship slow log record: %v", err)
+		}
+	}
+	onIdle := func() {
+		if err := sink.Flush(); err != nil {
+			klog.Errorf("failed to flush slow log records: %v", err)
+		}
+	}
+
+	klog.Infof("shipping slow log %s to the %s sink", file, sinkType)
+	if err := slowlog.Tail(ctx, file, pollInterval, onLine, onIdle); err != nil {
+		klog.Fatalf("failed to tail slow log %s: %v", file, err)
+	}
+	klog.Infof("tidb-slowlog-shipper exited")
+}
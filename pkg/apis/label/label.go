@@ -97,6 +97,8 @@ const (
 	AnnTiKVPartition string = "tidb.pingcap.com/tikv-partition"
 	// AnnForceUpgradeKey is tc annotation key to indicate whether force upgrade should be done
 	AnnForceUpgradeKey = "tidb.pingcap.com/force-upgrade"
+	// AnnAbortVolumeReplaceKey is tc annotation key to stop replacing more volumes, e.g. to abort a storage class migration
+	AnnAbortVolumeReplaceKey = "tidb.pingcap.com/abort-volume-replace"
 	// AnnPDDeferDeleting is pd pod annotation key  in pod for defer for deleting pod
 	AnnPDDeferDeleting = "tidb.pingcap.com/pd-defer-deleting"
 	// AnnSysctlInit is pod annotation key to indicate whether configuring sysctls with init container
@@ -107,6 +109,11 @@ const (
 	AnnTiCDCGracefulShutdownBeginTime = "tidb.pingcap.com/ticdc-graceful-shutdown-begin-time"
 	// AnnStsLastSyncTimestamp is sts annotation key to indicate the last timestamp the operator sync the sts
 	AnnStsLastSyncTimestamp = "tidb.pingcap.com/sync-timestamp"
+	// AnnTLSIssuedBy is secret annotation key to indicate the certificate in the secret is issued by the operator
+	AnnTLSIssuedBy = "tidb.pingcap.com/tls-issued-by"
+	// AnnTLSCertHash is pod annotation key to record the hash of the TLS secrets only loaded at startup,
+	// so the pods are restarted to load the certificates after they are rotated
+	AnnTLSCertHash = "tidb.pingcap.com/tls-cert-hash"
 	// AnnTiflashMountCMInTiflashContainer is tiflash pod annotation key to indicate whether directly mount ConfigMap
 	// in tiflash container instead of init container for tiflash. With it annotated, the tiflash container will directly
 	// read config from files mounted by ConfigMap and that enables tiflash support hot-reload config.
@@ -126,6 +133,8 @@ const (
 	AnnForceUpgradeVal = "true"
 	// AnnSysctlInitVal is pod annotation value to indicate whether configuring sysctls with init container
 	AnnSysctlInitVal = "true"
+	// AnnTLSIssuedByInternalCAVal is secret annotation value to indicate the certificate is issued by the internal CA
+	AnnTLSIssuedByInternalCAVal = "internal-ca"
 
 	// AnnPDDeleteSlots is annotation key of pd delete slots.
 	AnnPDDeleteSlots = "pd.tidb.pingcap.com/delete-slots"
@@ -73,6 +73,14 @@ type ComponentStatus interface {
 	SetStatefulSet(sts *appsv1.StatefulSetStatus)
 	// SetVolReplaceInProgress sets the status.VolReplaceInProgress
 	SetVolReplaceInProgress(status bool)
+	// GetTLSCertRotation returns `status.tlsCertRotation`
+	//
+	// For dm-master and dm-worker, it is always nil.
+	GetTLSCertRotation() *TLSCertRotationStatus
+	// SetTLSCertRotation sets the `status.tlsCertRotation`
+	//
+	// Not supported for dm-master and dm-worker
+	SetTLSCertRotation(status *TLSCertRotationStatus)
 }
 
 func (tc *TidbCluster) AllComponentStatus() []ComponentStatus {
@@ -185,6 +193,12 @@ func (s *PDStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus) {
 func (s *PDStatus) SetVolReplaceInProgress(status bool) {
 	s.VolReplaceInProgress = status
 }
+func (s *PDStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *PDStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *PDMSStatus) MemberType() MemberType {
 	return PDMSMemberType(s.Name)
@@ -230,6 +244,12 @@ func (s *PDMSStatus) SetStatefulSet(sts *appsv1.StatefulSetStatus) {
 	s.StatefulSet = sts
 }
 func (s *PDMSStatus) SetVolReplaceInProgress(status bool) {}
+func (s *PDMSStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *PDMSStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *TiKVStatus) MemberType() MemberType {
 	return TiKVMemberType
@@ -283,6 +303,12 @@ func (s *TiKVStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus)
 func (s *TiKVStatus) SetVolReplaceInProgress(status bool) {
 	s.VolReplaceInProgress = status
 }
+func (s *TiKVStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *TiKVStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *TiDBStatus) MemberType() MemberType {
 	return TiDBMemberType
@@ -334,6 +360,12 @@ func (s *TiDBStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus)
 func (s *TiDBStatus) SetVolReplaceInProgress(status bool) {
 	s.VolReplaceInProgress = status
 }
+func (s *TiDBStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *TiDBStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *PumpStatus) MemberType() MemberType {
 	return PumpMemberType
@@ -383,6 +415,12 @@ func (s *PumpStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus)
 	s.Volumes = vols
 }
 func (s *PumpStatus) SetVolReplaceInProgress(status bool) {}
+func (s *PumpStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *PumpStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *TiFlashStatus) MemberType() MemberType {
 	return TiFlashMemberType
@@ -436,6 +474,12 @@ func (s *TiFlashStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStat
 func (s *TiFlashStatus) SetVolReplaceInProgress(status bool) {
 	s.VolReplaceInProgress = status
 }
+func (s *TiFlashStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *TiFlashStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *TiCDCStatus) MemberType() MemberType {
 	return TiCDCMemberType
@@ -487,6 +531,12 @@ func (s *TiCDCStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus
 	s.Volumes = vols
 }
 func (s *TiCDCStatus) SetVolReplaceInProgress(status bool) {}
+func (s *TiCDCStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *TiCDCStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
 
 func (s *MasterStatus) MemberType() MemberType {
 	return DMMasterMemberType
@@ -537,7 +587,9 @@ func (s *MasterStatus) SetStatefulSet(sts *appsv1.StatefulSetStatus) {
 func (s *MasterStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus) {
 	s.Volumes = vols
 }
-func (s *MasterStatus) SetVolReplaceInProgress(status bool) {}
+func (s *MasterStatus) SetVolReplaceInProgress(status bool)              {}
+func (s *MasterStatus) GetTLSCertRotation() *TLSCertRotationStatus       { return nil }
+func (s *MasterStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {}
 
 func (s *WorkerStatus) MemberType() MemberType {
 	return DMWorkerMemberType
@@ -588,7 +640,9 @@ func (s *WorkerStatus) SetStatefulSet(sts *appsv1.StatefulSetStatus) {
 func (s *WorkerStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStatus) {
 	s.Volumes = vols
 }
-func (s *WorkerStatus) SetVolReplaceInProgress(status bool) {}
+func (s *WorkerStatus) SetVolReplaceInProgress(status bool)              {}
+func (s *WorkerStatus) GetTLSCertRotation() *TLSCertRotationStatus       { return nil }
+func (s *WorkerStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {}
 
 func (s *TiProxyStatus) MemberType() MemberType {
 	return TiProxyMemberType
@@ -640,3 +694,9 @@ func (s *TiProxyStatus) SetVolumes(vols map[StorageVolumeName]*StorageVolumeStat
 	s.Volumes = vols
 }
 func (s *TiProxyStatus) SetVolReplaceInProgress(status bool) {}
+func (s *TiProxyStatus) GetTLSCertRotation() *TLSCertRotationStatus {
+	return s.TLSCertRotation
+}
+func (s *TiProxyStatus) SetTLSCertRotation(status *TLSCertRotationStatus) {
+	s.TLSCertRotation = status
+}
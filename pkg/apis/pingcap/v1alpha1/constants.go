@@ -21,6 +21,14 @@ const (
 
 	// DefaultTidbUser is the default tidb user for login tidb cluster
 	DefaultTidbUser = "root"
+
+	// DefaultTiProxyPeerPort is the port TiProxy instances communicate with each other
+	DefaultTiProxyPeerPort = int32(3081)
+
+	// DefaultDiscoveryPort is the port of the http service of discovery
+	DefaultDiscoveryPort = int32(10261)
+	// DefaultDiscoveryProxyPort is the port of the proxy service of discovery
+	DefaultDiscoveryProxyPort = int32(10262)
 )
 
 // Default component ports, can be overridden with ENV variables when building
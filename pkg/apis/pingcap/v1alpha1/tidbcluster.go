@@ -47,6 +47,18 @@ const (
 	defaultTiCDCGracefulShutdownTimeout = 10 * time.Minute
 	defaultPDStartTimeout               = 30
 	defaultPDInitWaitTime               = 0
+	// defaultInternalCACertValidity and defaultInternalCARenewBefore are the defaults of the
+	// certificates issued by the internal CA
+	defaultInternalCACertValidity = 90 * 24 * time.Hour
+	defaultInternalCARenewBefore  = 30 * 24 * time.Hour
+	// defaultTiDBUserHost is the host of the SQL users and roles managed by the operator if it's not set
+	defaultTiDBUserHost = "%"
+	// defaultConfigDriftInterval is the interval to fetch the live config to detect the config drift
+	defaultConfigDriftInterval = 5 * time.Minute
+	// defaultAuditLogFile is the path of the audit log of TiDB if it's not set
+	defaultAuditLogFile = "/var/log/tidb-audit/tidb-audit.log"
+	// defaultAuditLogUploadInterval is the interval to check the rotated audit log files if it's not set
+	defaultAuditLogUploadInterval = time.Minute
 
 	// the latest version
 	versionLatest = "latest"
@@ -1034,6 +1046,72 @@ func (tc *TidbCluster) IsTLSClusterEnabled() bool {
 	return tc.Spec.TLSCluster != nil && tc.Spec.TLSCluster.Enabled
 }
 
+// IsInternalCAEnabled returns whether the TLS certificates are issued by the operator
+func (tc *TidbCluster) IsInternalCAEnabled() bool {
+	return tc.IsTLSClusterEnabled() && tc.Spec.TLSCluster.InternalCA != nil
+}
+
+// GetInternalCASecretName returns the name of the secret storing the internal CA
+func (tc *TidbCluster) GetInternalCASecretName() string {
+	if tc.Spec.TLSCluster != nil && tc.Spec.TLSCluster.InternalCA != nil && tc.Spec.TLSCluster.InternalCA.CASecretName != "" {
+		return tc.Spec.TLSCluster.InternalCA.CASecretName
+	}
+	return fmt.Sprintf("%s-internal-ca", tc.Name)
+}
+
+// GetCertValidity returns the validity duration of the certificates issued by the internal CA
+func (ca *InternalCA) GetCertValidity() time.Duration {
+	if ca.CertValidity == nil {
+		return defaultInternalCACertValidity
+	}
+	return ca.CertValidity.Duration
+}
+
+// GetRenewBefore returns how long before the expiration the certificates are renewed
+func (ca *InternalCA) GetRenewBefore() time.Duration {
+	if ca.RenewBefore == nil {
+		return defaultInternalCARenewBefore
+	}
+	return ca.RenewBefore.Duration
+}
+
+// GetMode returns the mode of the config drift detection
+func (d *ConfigDriftDetection) GetMode() ConfigDriftMode {
+	if d.Mode == "" {
+		return ConfigDriftModeDetect
+	}
+	return d.Mode
+}
+
+// GetInterval returns the interval to fetch the live config
+func (d *ConfigDriftDetection) GetInterval() time.Duration {
+	if d.Interval == nil {
+		return defaultConfigDriftInterval
+	}
+	return d.Interval.Duration
+}
+
+// GetHost returns the host of the SQL user or role
+func (u *TiDBUser) GetHost() string {
+	if u.Host == "" {
+		return defaultTiDBUserHost
+	}
+	return u.Host
+}
+
+// Key returns the key of the SQL user or role in the status, which is `name@host`
+func (u *TiDBUser) Key() string {
+	return u.Name + "@" + u.GetHost()
+}
+
+// ParseTiDBRole parses the role in the format `name` or `name@host` granted to a SQL user
+func ParseTiDBRole(role string) (name, host string) {
+	if i := strings.LastIndex(role, "@"); i >= 0 {
+		return role[:i], role[i+1:]
+	}
+	return role, defaultTiDBUserHost
+}
+
 func (tc *TidbCluster) IsRecoveryMode() bool {
 	return tc.Spec.RecoveryMode
 }
@@ -1113,6 +1191,31 @@ func (tidb *TiDBSpec) GetSlowLogTailerSpec() TiDBSlowLogTailerSpec {
 	return *tidb.SlowLogTailer
 }
 
+// IsAuditLogEnabled returns whether the audit log is collected by the sidecar
+func (tidb *TiDBSpec) IsAuditLogEnabled() bool {
+	return tidb.AuditLog != nil
+}
+
+// GetAuditLogFile returns the path of the audit log
+func (tidb *TiDBSpec) GetAuditLogFile() string {
+	if tidb.AuditLog == nil || tidb.AuditLog.File == "" {
+		return defaultAuditLogFile
+	}
+	return tidb.AuditLog.File
+}
+
+// GetInterval returns the interval to check the rotated audit log files
+func (u *AuditLogUploadSpec) GetInterval() time.Duration {
+	if u.Interval == "" {
+		return defaultAuditLogUploadInterval
+	}
+	interval, err := time.ParseDuration(u.Interval)
+	if err != nil || interval <= 0 {
+		return defaultAuditLogUploadInterval
+	}
+	return interval
+}
+
 // GetServicePort returns the service port for tidb
 func (tidb *TiDBSpec) GetServicePort() int32 {
 	port := DefaultTiDBServerPort
@@ -1180,6 +1283,14 @@ func (tikv *TiKVSpec) GetScaleOutParallelism() int {
 	return int(*(tikv.ScalePolicy.ScaleOutParallelism))
 }
 
+// GetScaleInVictimPolicy returns the policy to pick pods to delete when scaling in
+func (p *ScalePolicy) GetScaleInVictimPolicy() ScaleInVictimPolicy {
+	if p.ScaleInVictimPolicy == "" {
+		return ScaleInVictimPolicyOrdinal
+	}
+	return p.ScaleInVictimPolicy
+}
+
 func (tiflash *TiFlashSpec) GetRecoverByUID() types.UID {
 	if tiflash.Failover == nil {
 		return ""
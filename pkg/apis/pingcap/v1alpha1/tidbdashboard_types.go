@@ -118,6 +118,46 @@ type TidbDashboardSpec struct {
 	// Optional: Defaults to false
 	// +optional
 	DisableKeyVisualizer *bool `json:"disableKeyVisualizer,omitempty" default:"false"`
+
+	// SSO configures the single sign-on of tidb dashboard through OIDC.
+	// It's applied through the API of tidb dashboard after the pod is ready, and the changes
+	// made in the UI are reverted.
+	// Optional: Defaults to nil, which means the SSO is managed in the UI
+	// +optional
+	SSO *TidbDashboardSSO `json:"sso,omitempty"`
+}
+
+// TidbDashboardSSO is the single sign-on config of tidb dashboard.
+//
+// +k8s:openapi-gen=true
+type TidbDashboardSSO struct {
+	// Issuer is the URL of the OIDC issuer, which provides the discovery document
+	// at `<issuer>/.well-known/openid-configuration`.
+	Issuer string `json:"issuer"`
+
+	// SecretName is the name of the secret storing the OIDC client in the key `clientID`.
+	// tidb dashboard uses the authorization code flow with PKCE, so the client secret is not needed.
+	SecretName string `json:"secretName"`
+
+	// ReadOnly is whether the users logged in by SSO can only view the data.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// Impersonations are the SQL users which the users logged in by SSO can act as.
+	// The first one is also used by the operator to call the API of tidb dashboard.
+	// +kubebuilder:validation:MinItems=1
+	Impersonations []TidbDashboardSQLUser `json:"impersonations"`
+}
+
+// TidbDashboardSQLUser is a SQL user of the TiDB cluster.
+//
+// +k8s:openapi-gen=true
+type TidbDashboardSQLUser struct {
+	// User is the name of the SQL user.
+	User string `json:"user"`
+
+	// SecretName is the name of the secret storing the password of the user in the key `password`.
+	SecretName string `json:"secretName"`
 }
 
 // TidbDashboardStatus is status of tidb dashboard.
@@ -126,4 +166,23 @@ type TidbDashboardStatus struct {
 	Phase  MemberPhase `json:"phase,omitempty"`
 
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
+
+	// SSO is the status of the single sign-on config.
+	SSO *TidbDashboardSSOStatus `json:"sso,omitempty"`
+}
+
+// TidbDashboardSSOStatus is the status of the single sign-on config of tidb dashboard.
+type TidbDashboardSSOStatus struct {
+	// Synced is whether the SSO config is applied to tidb dashboard.
+	Synced bool `json:"synced"`
+
+	// Message is the reason why the SSO config fails to be applied.
+	Message string `json:"message,omitempty"`
+
+	// LastSyncTime is the last time the SSO config is applied.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// ImpersonationSecretVersions is the uid and resource version of the secret of the password of
+	// each impersonated SQL user, it's used to detect the change of the password.
+	ImpersonationSecretVersions map[string]string `json:"impersonationSecretVersions,omitempty"`
 }
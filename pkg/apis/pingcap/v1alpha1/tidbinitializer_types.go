@@ -88,6 +88,11 @@ type TidbInitializerSpec struct {
 	// +optional
 	PasswordSecret *string `json:"passwordSecret,omitempty"`
 
+	// PasswordSecretSource is the external source of the passwords of the users,
+	// it takes precedence over PasswordSecret if set.
+	// +optional
+	PasswordSecretSource *CredentialSource `json:"passwordSecretSource,omitempty"`
+
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
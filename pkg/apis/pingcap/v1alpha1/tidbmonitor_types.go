@@ -188,6 +188,92 @@ type TidbMonitorSpec struct {
 
 	// PreferIPv6 indicates whether to prefer IPv6 addresses for all components.
 	PreferIPv6 bool `json:"preferIPv6,omitempty"`
+
+	// PrometheusOperator makes TidbMonitor generate PodMonitor, PrometheusRule and Grafana dashboard
+	// objects for an existing Prometheus Operator deployment, e.g. kube-prometheus-stack.
+	// The bundled Prometheus and Grafana are not deployed if it is set, and the ones deployed before are
+	// removed. Their PVCs are retained unless `deleteBundledPVCs` is set.
+	// +optional
+	PrometheusOperator *PrometheusOperatorSpec `json:"prometheusOperator,omitempty"`
+
+	// GeneratedAlertRules makes the operator generate alert rules from the spec and status of
+	// the monitored clusters and their backups, in addition to the static alert rules.
+	// The backup alerts are evaluated with the metrics of tidb-controller-manager, so it must be
+	// scraped by the Prometheus.
+	// +optional
+	GeneratedAlertRules *GeneratedAlertRulesSpec `json:"generatedAlertRules,omitempty"`
+}
+
+// GeneratedAlertRulesSpec describes the alert rules generated by the operator
+// +k8s:openapi-gen=true
+type GeneratedAlertRulesSpec struct {
+	// DisabledAlerts are the names of generated alerts which should not be generated,
+	// e.g. TiKVStoresBelowDesired
+	// +optional
+	DisabledAlerts []string `json:"disabledAlerts,omitempty"`
+
+	// For is how long the condition of component alerts must hold before they fire
+	// Optional: Defaults to 5m
+	// +optional
+	For string `json:"for,omitempty"`
+
+	// Labels are added to all generated alerts
+	// Optional: Defaults to {"level": "critical"}
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// BackupScheduleDelay is how long a scheduled backup may be later than the schedule
+	// before BackupScheduleMissed fires
+	// Optional: Defaults to 1h
+	// +optional
+	BackupScheduleDelay string `json:"backupScheduleDelay,omitempty"`
+
+	// LogBackupCheckpointLag is the max lag of the checkpoint of a running log backup
+	// before LogBackupCheckpointLagging fires
+	// Optional: Defaults to 10m
+	// +optional
+	LogBackupCheckpointLag string `json:"logBackupCheckpointLag,omitempty"`
+}
+
+// PrometheusOperatorSpec describes the objects generated for Prometheus Operator
+// +k8s:openapi-gen=true
+type PrometheusOperatorSpec struct {
+	// Labels are added to the generated PodMonitor and PrometheusRule objects,
+	// they are usually required by the `podMonitorSelector` and `ruleSelector` of Prometheus.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ScrapeInterval of the generated PodMonitor objects
+	// Optional: Defaults to 15s
+	// +optional
+	ScrapeInterval string `json:"scrapeInterval,omitempty"`
+
+	// GrafanaDashboards configures the dashboard ConfigMaps loaded by the Grafana sidecar
+	// +optional
+	GrafanaDashboards *GrafanaDashboardsSpec `json:"grafanaDashboards,omitempty"`
+
+	// DeleteBundledPVCs makes the operator delete the PVCs of the bundled Prometheus deployed before,
+	// the data in them is lost. They are retained by default and can be deleted manually.
+	// +optional
+	DeleteBundledPVCs bool `json:"deleteBundledPVCs,omitempty"`
+}
+
+// GrafanaDashboardsSpec describes the dashboard ConfigMaps for the Grafana sidecar
+// +k8s:openapi-gen=true
+type GrafanaDashboardsSpec struct {
+	// ConfigMapRefs refer to the ConfigMaps containing the dashboards, e.g. the dashboards in the
+	// monitor initializer image. Only keys with suffix `.json` are used, every dashboard is written
+	// into a separate ConfigMap because of the size limit of ConfigMap.
+	ConfigMapRefs []ConfigMapRef `json:"configMapRefs,omitempty"`
+
+	// Labels of the generated ConfigMaps which are watched by the Grafana sidecar
+	// Optional: Defaults to {"grafana_dashboard": "1"}
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations of the generated ConfigMaps, e.g. the folder annotation of the Grafana sidecar
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // PrometheusReloaderSpec is the desired state of prometheus configuration reloader
@@ -230,6 +316,13 @@ type PrometheusSpec struct {
 	// If specified, the remote_write spec. This is an experimental feature, it may change in any upcoming release in a breaking way.
 	RemoteWrite []*RemoteWriteSpec `json:"remoteWrite,omitempty"`
 
+	// AgentMode runs Prometheus in agent mode (`--enable-feature=agent`).
+	// Only the WAL is kept locally and all the metrics are shipped by `remoteWrite`,
+	// which is required in agent mode. Local query, alerting, Grafana and Thanos
+	// are not available in agent mode.
+	// +optional
+	AgentMode bool `json:"agentMode,omitempty"`
+
 	// Additional volume mounts of prometheus pod.
 	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
 }
@@ -295,8 +388,54 @@ type GrafanaSpec struct {
 
 	// Additional volume mounts of grafana pod.
 	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+
+	// Tenancy provisions a Grafana folder with the dashboards and a datasource for each monitored TidbCluster,
+	// only the team owning the cluster is granted access to them. The shared dashboards with the cluster
+	// dropdown are moved to a folder only visible to Grafana admins.
+	// +optional
+	Tenancy *GrafanaTenancySpec `json:"tenancy,omitempty"`
 }
 
+// GrafanaTenancySpec describes how the monitored TidbClusters are mapped to Grafana teams
+type GrafanaTenancySpec struct {
+	// TeamLabelKey is the label of TidbCluster whose value is the name of the Grafana team owning the cluster.
+	// The team is created if it doesn't exist, its members are managed in Grafana.
+	// Clusters without the label are only visible to Grafana admins.
+	// Optional: Defaults to `tidb.pingcap.com/grafana-team`
+	// +optional
+	TeamLabelKey string `json:"teamLabelKey,omitempty"`
+
+	// Permission granted to the team on the folder and the datasource of its clusters.
+	// Permissions on datasources are only supported by Grafana Enterprise. For other editions, all users
+	// can query the datasources of all clusters, so the `GrafanaTenancyEnforced` condition of the TidbMonitor
+	// is set to False and a warning event is recorded.
+	// Optional: Defaults to View
+	// +kubebuilder:validation:Enum=View;Edit
+	// +optional
+	Permission GrafanaPermission `json:"permission,omitempty"`
+
+	// LabelProxy is the prom-label-proxy container added to the pod of Grafana. The datasource of each
+	// cluster queries Prometheus through it, which enforces the tidb_cluster label of the cluster on
+	// the queries, so the datasource can't be used to query the metrics of other clusters.
+	// Optional: Defaults to quay.io/prometheuscommunity/prom-label-proxy:v0.11.0
+	// +optional
+	LabelProxy *MonitorContainer `json:"labelProxy,omitempty"`
+}
+
+// GrafanaPermission is the permission granted to a Grafana team
+type GrafanaPermission string
+
+const (
+	// GrafanaPermissionView allows the team to view the dashboards
+	GrafanaPermissionView GrafanaPermission = "View"
+	// GrafanaPermissionEdit allows the team to edit the dashboards, the changes are overwritten
+	// when the Grafana is synced again, e.g. the dashboards in the initializer image are updated
+	GrafanaPermissionEdit GrafanaPermission = "Edit"
+
+	// DefaultGrafanaTeamLabelKey is the default label of TidbCluster to find the Grafana team
+	DefaultGrafanaTeamLabelKey = "tidb.pingcap.com/grafana-team"
+)
+
 // ReloaderSpec is the desired state of reloader
 type ReloaderSpec struct {
 	MonitorContainer `json:",inline"`
@@ -380,8 +519,25 @@ type TidbMonitorStatus struct {
 	DeploymentStorageStatus *DeploymentStorageStatus `json:"deploymentStorageStatus,omitempty"`
 
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
+
+	// Conditions of TidbMonitor, e.g. whether the Grafana tenancy is enforced
+	// +optional
+	// +nullable
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// GrafanaTenancyHashes are the hashes of the tenants synced to the Grafana of each shard by the name of
+	// the Grafana. The Grafana is synced again only if the tenants or the pod of the Grafana are changed.
+	// +optional
+	GrafanaTenancyHashes map[string]string `json:"grafanaTenancyHashes,omitempty"`
 }
 
+// The `Type` of the TidbMonitor condition
+const (
+	// TidbMonitorGrafanaTenancyEnforced indicates whether the users of Grafana can only query the clusters of
+	// their teams. It's False if the Grafana doesn't support the permissions of datasources.
+	TidbMonitorGrafanaTenancyEnforced string = "GrafanaTenancyEnforced"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // +k8s:openapi-gen=true
@@ -558,6 +714,32 @@ func (tm *TidbMonitor) GetShards() int32 {
 	return shards
 }
 
+// IsAgentMode returns whether Prometheus runs in agent mode
+func (tm *TidbMonitor) IsAgentMode() bool {
+	return tm.Spec.Prometheus.AgentMode
+}
+
+// IsGrafanaTenancyEnabled returns whether a Grafana folder is provisioned for each monitored TidbCluster
+func (tm *TidbMonitor) IsGrafanaTenancyEnabled() bool {
+	return tm.Spec.Grafana != nil && tm.Spec.Grafana.Tenancy != nil
+}
+
+// GetTeamLabelKey returns the label of TidbCluster to find the Grafana team
+func (t *GrafanaTenancySpec) GetTeamLabelKey() string {
+	if t.TeamLabelKey == "" {
+		return DefaultGrafanaTeamLabelKey
+	}
+	return t.TeamLabelKey
+}
+
+// GetPermission returns the permission granted to the Grafana team
+func (t *GrafanaTenancySpec) GetPermission() GrafanaPermission {
+	if t.Permission == "" {
+		return GrafanaPermissionView
+	}
+	return t.Permission
+}
+
 func (tm *TidbMonitor) Timezone() string {
 	tz := tm.Spec.Timezone
 	if len(tz) <= 0 {
@@ -13,7 +13,15 @@
 
 package v1alpha1
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultNGMonitoringExportSchedule = "0 * * * *"
+	defaultNGMonitoringExportWindow   = time.Hour
+)
 
 func (tngm *TidbNGMonitoring) GetInstanceName() string {
 	return tngm.Name
@@ -37,3 +45,24 @@ func (tngm *TidbNGMonitoring) NGMonitoringImage() string {
 	}
 	return image
 }
+
+// IsExportEnabled returns whether the periodic export of ng monitoring data is enabled
+func (tngm *TidbNGMonitoring) IsExportEnabled() bool {
+	return tngm.Spec.NGMonitoring.Export != nil
+}
+
+// GetSchedule returns the cron schedule of the export
+func (e *NGMonitoringExport) GetSchedule() string {
+	if e.Schedule == "" {
+		return defaultNGMonitoringExportSchedule
+	}
+	return e.Schedule
+}
+
+// GetWindow returns the time range of the data exported each time
+func (e *NGMonitoringExport) GetWindow() time.Duration {
+	if e.Window == nil {
+		return defaultNGMonitoringExportWindow
+	}
+	return e.Window.Duration
+}
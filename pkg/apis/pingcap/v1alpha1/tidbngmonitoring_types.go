@@ -117,6 +117,73 @@ type NGMonitoringSpec struct {
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:validation:XPreserveUnknownFields
 	Config *config.GenericConfig `json:"config,omitempty"`
+
+	// Retention configures how long the data of ng monitoring is kept
+	//
+	// +optional
+	Retention *NGMonitoringRetention `json:"retention,omitempty"`
+
+	// StorageAutoscaling expands the data volume of ng monitoring automatically
+	// when its disk usage crosses the threshold. The disk usage is reported by a sidecar
+	// running the helper image of the TidbCluster, which requires df of busybox. The disk usage is read
+	// by exec into the sidecar, so tidb-controller-manager requires the create permission of pods/exec.
+	//
+	// +optional
+	StorageAutoscaling *StorageAutoscaling `json:"storageAutoscaling,omitempty"`
+
+	// Export uploads the continuous profiling data and Top SQL snapshots to
+	// the external storage periodically.
+	//
+	// +optional
+	Export *NGMonitoringExport `json:"export,omitempty"`
+}
+
+// NGMonitoringRetention is the data retention of ng monitoring
+//
+// +k8s:openapi-gen=true
+type NGMonitoringRetention struct {
+	// TopSQL is the retention of Top SQL data, it's rounded down to hours.
+	// Defaults to the retention of ng monitoring.
+	//
+	// +optional
+	TopSQL *metav1.Duration `json:"topSQL,omitempty"`
+
+	// Profiling is the retention of continuous profiling data, it's rounded down to seconds.
+	// Defaults to the retention of ng monitoring.
+	//
+	// +optional
+	Profiling *metav1.Duration `json:"profiling,omitempty"`
+}
+
+// NGMonitoringExport configures the periodic export of ng monitoring data
+//
+// +k8s:openapi-gen=true
+type NGMonitoringExport struct {
+	// StorageProvider configures where the data is exported to.
+	// The data of each export is stored under `<namespace>/<name>/<begin>-<end>/`.
+	StorageProvider `json:",inline"`
+
+	// Schedule is the cron schedule of the export.
+	// Defaults to "0 * * * *".
+	//
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Window is the time range of the data exported each time, ending at the export time.
+	// It should not be shorter than the interval of the schedule. Defaults to 1h.
+	//
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// ServiceAccount of the export job
+	//
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// Suspend stops scheduling the export
+	//
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
 }
 
 // NGMonitoringStatus is latest status of ng monitoring
@@ -58,6 +58,8 @@ func (c ContainerName) String() string {
 
 const (
 	ContainerSlowLogTailer    ContainerName = "slowlog"
+	ContainerAuditLogTailer   ContainerName = "auditlog"
+	ContainerAuditLogUploader ContainerName = "auditlog-uploader"
 	ContainerRocksDBLogTailer ContainerName = "rocksdblog"
 	ContainerRaftLogTailer    ContainerName = "raftlog"
 )
@@ -145,6 +147,12 @@ const (
 	// ConfigUpdateStrategyRollingUpdate generate different configmap on configuration update and
 	// try to rolling-update the pod controller (e.g. statefulset) to apply updates.
 	ConfigUpdateStrategyRollingUpdate ConfigUpdateStrategy = "RollingUpdate"
+	// ConfigUpdateStrategyDynamic update the configmap in place and apply the changed configuration items
+	// online through the config API of the component if all of them can be changed online, otherwise
+	// generate different configmap like RollingUpdate. The items rejected by the config API are applied
+	// like RollingUpdate as well. It's only supported by PD and TiKV, and the other components fall back
+	// to RollingUpdate.
+	ConfigUpdateStrategyDynamic ConfigUpdateStrategy = "Dynamic"
 )
 
 type StartScriptVersion string
@@ -296,6 +304,8 @@ type TidbClusterSpec struct {
 	// cluster component is needed to reload the configuration change.
 	// UpdateStrategyRollingUpdate will create a new ConfigMap with the new configuration and rolling-update the
 	// related components to use the new ConfigMap, that is, the new configuration will be applied automatically.
+	// UpdateStrategyDynamic will apply the configuration items changeable online through the config API of PD
+	// and TiKV, and rolling-update the component only if some changed items need a restart.
 	ConfigUpdateStrategy ConfigUpdateStrategy `json:"configUpdateStrategy,omitempty"`
 
 	// Whether enable PVC reclaim for orphan PVC left by statefulset scale-in
@@ -427,6 +437,69 @@ type TidbClusterSpec struct {
 	// - WaitForDnsNameIpMatch indicates whether PD and TiKV has to wait until local IP address matches the one published to external DNS
 	// - PreferPDAddressesOverDiscovery advises start script to use TidbClusterSpec.PDAddresses (if supplied) as argument for pd-server, tikv-server and tidb-server commands
 	StartScriptV2FeatureFlags []StartScriptV2FeatureFlag `json:"startScriptV2FeatureFlags,omitempty"`
+
+	// ConfigDriftDetection makes the operator compare the live config of PD, TiKV and TiDB against the config in spec
+	// periodically, and report the drifted items in the ConfigDrift condition.
+	// +optional
+	ConfigDriftDetection *ConfigDriftDetection `json:"configDriftDetection,omitempty"`
+
+	// NetworkPolicy makes the operator generate a NetworkPolicy for each component of the cluster, which only
+	// allows the ingress traffic required by the cluster on the ports of the component.
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+}
+
+// ConfigDriftMode is the mode of the config drift detection
+type ConfigDriftMode string
+
+const (
+	// ConfigDriftModeDetect only reports the drifted items
+	ConfigDriftModeDetect ConfigDriftMode = "Detect"
+	// ConfigDriftModeReapply re-applies the values in spec to the drifted items that can be changed online
+	// through the config API of PD and TiKV, the other drifted items are only reported
+	ConfigDriftModeReapply ConfigDriftMode = "Reapply"
+)
+
+// +k8s:openapi-gen=true
+// ConfigDriftDetection is the config of the detection of the drift between spec and live config
+type ConfigDriftDetection struct {
+	// Mode is the mode of the detection, one of Detect and Reapply.
+	// Optional: Defaults to Detect
+	// +kubebuilder:validation:Enum:="";"Detect";"Reapply"
+	// +optional
+	Mode ConfigDriftMode `json:"mode,omitempty"`
+
+	// Interval is the interval to fetch the live config.
+	// Optional: Defaults to 5m
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// NetworkPolicySpec is the config of the NetworkPolicies generated for the components. The components of the
+// cluster are always allowed to access each other on the ports they require.
+type NetworkPolicySpec struct {
+	// Clients are the peers allowed to access the MySQL port of TiDB and TiProxy.
+	// Optional: Defaults to all sources
+	// +optional
+	Clients []networkingv1.NetworkPolicyPeer `json:"clients,omitempty"`
+
+	// Operator are the peers of TiDB Operator, which are allowed to access all ports of the components.
+	// Optional: Defaults to the pods of TiDB Operator in all namespaces
+	// +optional
+	Operator []networkingv1.NetworkPolicyPeer `json:"operator,omitempty"`
+
+	// Monitor are the peers allowed to scrape the metrics of the components.
+	// Optional: Defaults to the pods of TidbMonitor, TidbNGMonitoring and the Prometheus deployed by the Prometheus
+	// Operator in all namespaces
+	// +optional
+	Monitor []networkingv1.NetworkPolicyPeer `json:"monitor,omitempty"`
+
+	// Tools are the peers allowed to access the client ports of PD, TiKV, TiDB, TiFlash, TiCDC and Pump, such as
+	// the pods of backup, restore, the initializer and TidbDashboard.
+	// Optional: Defaults to the pods of Backup, Restore, TidbInitializer and TidbDashboard in all namespaces
+	// +optional
+	Tools []networkingv1.NetworkPolicyPeer `json:"tools,omitempty"`
 }
 
 // TidbClusterStatus represents the current status of a tidb cluster.
@@ -441,6 +514,12 @@ type TidbClusterStatus struct {
 	TiProxy    TiProxyStatus             `json:"tiproxy,omitempty"`
 	TiCDC      TiCDCStatus               `json:"ticdc,omitempty"`
 	AutoScaler *TidbClusterAutoScalerRef `json:"auto-scaler,omitempty"`
+	// InternalCA is the status of the certificates issued by the internal CA.
+	// +optional
+	InternalCA *InternalCAStatus `json:"internalCA,omitempty"`
+	// ConfigDrift is the status of the last config drift detection.
+	// +optional
+	ConfigDrift *ConfigDriftStatus `json:"configDrift,omitempty"`
 	// Represents the latest available observations of a tidb cluster's state.
 	// +optional
 	// +nullable
@@ -480,8 +559,39 @@ const (
 	// - All TiKV stores are up.
 	// - All TiFlash stores are up.
 	TidbClusterReady TidbClusterConditionType = "Ready"
+	// TidbClusterConfigDrift indicates that the live config of some instances drifts away from the config in spec.
+	TidbClusterConfigDrift TidbClusterConditionType = "ConfigDrift"
 )
 
+// ConfigDriftStatus is the status of the config drift detection
+type ConfigDriftStatus struct {
+	// LastCheckTime is the last time the live config is fetched.
+	// +optional
+	// +nullable
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Items are the drifted items found in the last check.
+	// +optional
+	Items []ConfigDriftItem `json:"items,omitempty"`
+}
+
+// ConfigDriftItem is a config item whose live value differs from the value in spec
+type ConfigDriftItem struct {
+	// Component is the component of the item.
+	Component MemberType `json:"component"`
+	// Instance is the instance with the drifted value, it's empty for the config shared by the cluster.
+	// +optional
+	Instance string `json:"instance,omitempty"`
+	// Key is the full name of the item, such as "raftstore.raft-log-gc-threshold".
+	Key string `json:"key"`
+	// Expected is the value in spec.
+	Expected string `json:"expected"`
+	// Live is the value of the running instance.
+	Live string `json:"live"`
+	// Reapplied is whether the value in spec is re-applied.
+	// +optional
+	Reapplied bool `json:"reapplied,omitempty"`
+}
+
 // The `Type` of the component condition
 const (
 	// ComponentVolumeResizing indicates that any volume of this component is resizing.
@@ -536,6 +646,12 @@ type PDSpec struct {
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
 
+	// The volumeAttributesClassName of the persistent volume for PD data storage.
+	// Changing it modifies the volumes in place through the CSI driver.
+	// It requires the VolumeAttributesClass feature of Kubernetes.
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
+
 	// StorageVolumes configure additional storage for PD pods.
 	// +optional
 	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
@@ -723,6 +839,12 @@ type TiKVSpec struct {
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
 
+	// The volumeAttributesClassName of the persistent volume for TiKV data storage.
+	// Changing it modifies the volumes in place through the CSI driver.
+	// It requires the VolumeAttributesClass feature of Kubernetes.
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
+
 	// Subdirectory within the volume to store TiKV Data. By default, the data
 	// is stored in the root directory of volume which is mounted at
 	// /var/lib/tikv.
@@ -787,6 +909,11 @@ type TiKVSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	// +optional
 	SpareVolReplaceReplicas *int32 `json:"spareVolReplaceReplicas,omitempty"`
+
+	// StorageAutoscaling expands the data volume of a TiKV store automatically
+	// when its disk usage crosses the threshold.
+	// +optional
+	StorageAutoscaling *StorageAutoscaling `json:"storageAutoscaling,omitempty"`
 }
 
 // TiFlashSpec contains details of TiFlash members
@@ -847,6 +974,27 @@ type TiFlashSpec struct {
 	// ScalePolicy is the scale configuration for TiFlash
 	// +optional
 	ScalePolicy ScalePolicy `json:"scalePolicy,omitempty"`
+
+	// StorageAutoscaling expands the first storage claim of a TiFlash store automatically
+	// when its disk usage crosses the threshold.
+	// +optional
+	StorageAutoscaling *StorageAutoscaling `json:"storageAutoscaling,omitempty"`
+}
+
+// StorageAutoscaling configures the automatic expansion of data volumes.
+// The storage class of volumes must allow volume expansion.
+// +k8s:openapi-gen=true
+type StorageAutoscaling struct {
+	// ThresholdPercent is the disk usage percent of a store to expand its data volume.
+	// Optional: Defaults to 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	ThresholdPercent *int32 `json:"thresholdPercent,omitempty"`
+	// Step is the size added to the data volume in one expansion.
+	Step resource.Quantity `json:"step"`
+	// MaxSize is the max size of the data volume, it will not be expanded beyond it.
+	MaxSize resource.Quantity `json:"maxSize"`
 }
 
 // TiCDCSpec contains details of TiCDC members
@@ -887,6 +1035,12 @@ type TiCDCSpec struct {
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
 
+	// The volumeAttributesClassName of the persistent volume for TiCDC data storage.
+	// Changing it modifies the volumes in place through the CSI driver.
+	// It requires the VolumeAttributesClass feature of Kubernetes.
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
+
 	// GracefulShutdownTimeout is the timeout of gracefully shutdown a TiCDC pod.
 	// Encoded in the format of Go Duration.
 	// Defaults to 10m
@@ -964,6 +1118,12 @@ type TiProxySpec struct {
 	// Defaults to Kubernetes default storage class.
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// The volumeAttributesClassName of the persistent volume for TiProxy data storage.
+	// Changing it modifies the volumes in place through the CSI driver.
+	// It requires the VolumeAttributesClass feature of Kubernetes.
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
 }
 
 // LogTailerSpec represents an optional log tailer sidecar container
@@ -990,6 +1150,10 @@ type StorageClaim struct {
 	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes#class-1
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
+	// Name of the VolumeAttributesClass used by the claim.
+	// More info: https://kubernetes.io/docs/concepts/storage/volume-attributes-classes
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
 }
 
 // TiDBSpec contains details of TiDB members
@@ -1039,6 +1203,10 @@ type TiDBSpec struct {
 	// +optional
 	SlowLogTailer *TiDBSlowLogTailerSpec `json:"slowLogTailer,omitempty"`
 
+	// AuditLog configures the collection of the audit log written by the audit plugin loaded by `plugins`
+	// +optional
+	AuditLog *TiDBAuditLogSpec `json:"auditLog,omitempty"`
+
 	// Whether enable the TLS connection between the SQL client and TiDB server
 	// Optional: Defaults to nil
 	// +optional
@@ -1076,11 +1244,22 @@ type TiDBSpec struct {
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
 
+	// The volumeAttributesClassName of the persistent volume for TiDB data storage.
+	// Changing it modifies the volumes in place through the CSI driver.
+	// It requires the VolumeAttributesClass feature of Kubernetes.
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
+
 	// Initializer is the init configurations of TiDB
 	//
 	// +optional
 	Initializer *TiDBInitializer `json:"initializer,omitempty"`
 
+	// Users is the SQL users and roles managed by the operator, they are reconciled continuously
+	// against TiDB and the changes made by SQL are reverted.
+	// +optional
+	Users *TiDBUsers `json:"users,omitempty"`
+
 	// BootstrapSQLConfigMapName is the name of the ConfigMap which contains the bootstrap SQL file with the key `bootstrap-sql`,
 	// which will only be executed when a TiDB cluster bootstrap on the first time.
 	// The field should be set ONLY when create a TC, since it only take effect on the first time bootstrap.
@@ -1139,6 +1318,65 @@ type TiDBInitializer struct {
 	CreatePassword bool `json:"createPassword,omitempty"`
 }
 
+// TiDBUsers is the SQL users and roles managed by the operator
+type TiDBUsers struct {
+	// AdminSecretName is the name of the secret with the keys `user` and `password`, which is the account
+	// used to manage the users. It requires the CREATE USER and CREATE ROLE privileges, and all the privileges
+	// granted to the users WITH GRANT OPTION.
+	// Defaults to the `root` user with the password in the secret `<cluster>-init` created by `initializer.createPassword`.
+	// The admin account can not be in the users.
+	// +optional
+	AdminSecretName string `json:"adminSecretName,omitempty"`
+
+	// Users is the list of the users and roles.
+	// The users and roles created by the operator are dropped after they are removed from the list,
+	// the ones existing before they are added to the list are left in TiDB.
+	// +optional
+	Users []TiDBUser `json:"users,omitempty"`
+}
+
+// TiDBUser is a SQL user or role
+type TiDBUser struct {
+	// Name is the name of the user or role, it can not be `root`
+	Name string `json:"name"`
+
+	// Host is the host of the user or role
+	// Defaults to `%`
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Role means it's a role, which has no password
+	// +optional
+	Role bool `json:"role,omitempty"`
+
+	// PasswordSecret is the key of the secret containing the password, it's required by a user.
+	// The password is changed after the secret is changed.
+	// +optional
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+
+	// Grants is the privileges granted to the user or role
+	// +optional
+	Grants []TiDBGrant `json:"grants,omitempty"`
+
+	// Roles is the roles granted to the user, they are activated by default.
+	// The format is `name` or `name@host`, the host defaults to `%`.
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+}
+
+// TiDBGrant is the privileges granted on a level
+type TiDBGrant struct {
+	// Privileges is the privileges, such as `SELECT`, `INSERT` and `ALL PRIVILEGES`
+	Privileges []string `json:"privileges"`
+
+	// On is the level of the privileges, such as `*.*`, `db.*` and `db.table`
+	On string `json:"on"`
+
+	// WithGrantOption allows the user to grant the privileges to others
+	// +optional
+	WithGrantOption bool `json:"withGrantOption,omitempty"`
+}
+
 const (
 	// TCPProbeType represents the readiness prob method with TCP
 	TCPProbeType string = "tcp"
@@ -1193,6 +1431,12 @@ type PumpSpec struct {
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
 
+	// The volumeAttributesClassName of the persistent volume for Pump data storage.
+	// Changing it modifies the volumes in place through the CSI driver.
+	// It requires the VolumeAttributesClass feature of Kubernetes.
+	// +optional
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
+
 	// The configuration of Pump cluster.
 	// +optional
 	// +kubebuilder:validation:Schemaless
@@ -1232,6 +1476,114 @@ type TiDBSlowLogTailerSpec struct {
 	// Use `spec.helper.imagePullPolicy` instead
 	// +k8s:openapi-gen=false
 	ImagePullPolicy *corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Format of the slow log shipped by the sidecar.
+	// `raw` prints the slow log as it is, `json` parses the multi-line entries and ships one JSON
+	// record per entry with the query time, digest, user, database and plan digest.
+	// The `json` format runs the sidecar with the image set by `--tidb-slowlog-shipper-image` of
+	// tidb-controller-manager instead of `spec.helper.image`.
+	// Optional: Defaults to raw
+	// +kubebuilder:validation:Enum=raw;json
+	// +optional
+	Format SlowLogFormat `json:"format,omitempty"`
+
+	// Sink is where the JSON records are shipped to, it only takes effect for the `json` format.
+	// Optional: Defaults to the stdout of the sidecar
+	// +optional
+	Sink *SlowLogSinkSpec `json:"sink,omitempty"`
+}
+
+// SlowLogFormat is the format of the slow log shipped by the slow log tailer
+type SlowLogFormat string
+
+const (
+	// SlowLogFormatRaw prints the slow log as it is
+	SlowLogFormatRaw SlowLogFormat = "raw"
+	// SlowLogFormatJSON prints a JSON record per slow log entry
+	SlowLogFormatJSON SlowLogFormat = "json"
+)
+
+// SlowLogSinkType is the type of the destination of the structured slow log
+type SlowLogSinkType string
+
+const (
+	// SlowLogSinkStdout prints the records to the stdout of the sidecar
+	SlowLogSinkStdout SlowLogSinkType = "stdout"
+	// SlowLogSinkFile appends the records to a file
+	SlowLogSinkFile SlowLogSinkType = "file"
+	// SlowLogSinkHTTP POSTs the records in batches as newline delimited JSON
+	SlowLogSinkHTTP SlowLogSinkType = "http"
+)
+
+// SlowLogSinkSpec describes the destination of the structured slow log
+// +k8s:openapi-gen=true
+type SlowLogSinkSpec struct {
+	// Type of the sink
+	// Optional: Defaults to stdout
+	// +kubebuilder:validation:Enum=stdout;file;http
+	// +optional
+	Type SlowLogSinkType `json:"type,omitempty"`
+
+	// Path of the file the records are appended to, required by the file sink.
+	// The file should be on a volume mounted to the TiDB pod by `spec.tidb.additionalVolumes`
+	// and `spec.tidb.additionalVolumeMounts`.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// URL of the HTTP endpoint the records are POSTed to, required by the http sink
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// TiDBAuditLogSpec describes the collection of the audit log of TiDB. The audit log is written to a dedicated
+// volume and printed to STDOUT by a tailer sidecar, the rotated files can be uploaded to the remote storage.
+// +k8s:openapi-gen=true
+type TiDBAuditLogSpec struct {
+	// ResourceRequirements of the audit log tailer sidecar
+	corev1.ResourceRequirements `json:",inline"`
+
+	// File is the path of the audit log, the audit plugin should be configured to write the audit log to it,
+	// such as by the `tidb_audit_log` system variable. The rotated files are expected to be in the same directory
+	// and named with the name of the file as the prefix, such as `tidb-audit-2024-01-01T00-00-00.000.log`.
+	// Optional: Defaults to /var/log/tidb-audit/tidb-audit.log
+	// +optional
+	File string `json:"file,omitempty"`
+
+	// VolumeName is the name of the volume in `storageVolumes` or `additionalVolumes` holding the audit log,
+	// the file should be under the mount path of the volume.
+	// Optional: Defaults to an emptyDir volume mounted to the directory of the file
+	// +optional
+	VolumeName string `json:"volumeName,omitempty"`
+
+	// Upload configures uploading the rotated audit log files to the remote storage
+	// +optional
+	Upload *AuditLogUploadSpec `json:"upload,omitempty"`
+}
+
+// AuditLogUploadSpec describes how the rotated audit log files are uploaded and retained. Each file is uploaded
+// with a manifest recording its SHA-256 digest chained with the digest of the previous file of the same instance,
+// so that the modification or the removal of the uploaded files can be detected by `tidb-auditlog-uploader verify`
+// in the sidecar. The active file is also uploaded when the pod is terminated.
+// +k8s:openapi-gen=true
+type AuditLogUploadSpec struct {
+	StorageProvider `json:",inline"`
+
+	// Retention is how long the uploaded files are retained, such as "2160h". The files older than the retention
+	// are deleted by the uploader.
+	// Optional: Defaults to retaining the files forever
+	// +optional
+	Retention string `json:"retention,omitempty"`
+
+	// ObjectLockMode is the S3 object lock mode of the uploaded files, which are locked until the end of the
+	// retention. The bucket must have the object lock enabled.
+	// +kubebuilder:validation:Enum=GOVERNANCE;COMPLIANCE
+	// +optional
+	ObjectLockMode string `json:"objectLockMode,omitempty"`
+
+	// Interval to check the rotated files, such as "1m"
+	// Optional: Defaults to 1m
+	// +optional
+	Interval string `json:"interval,omitempty"`
 }
 
 // ComponentSpec is the base spec of each component, the fields should always accessed by the Basic<Component>Spec() method to respect the cluster-level properties
@@ -1520,6 +1872,12 @@ type PDStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// Indicates that a Volume replace using VolumeReplacing feature is in progress.
 	VolReplaceInProgress bool `json:"volReplaceInProgress,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
+	// DynamicConfig is the status of the last configuration update with the Dynamic strategy.
+	// +optional
+	DynamicConfig *DynamicConfigStatus `json:"dynamicConfig,omitempty"`
 }
 
 // PDMSStatus is PD microservice status
@@ -1538,6 +1896,9 @@ type PDMSStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
 }
 
 // PDMember is PD member
@@ -1596,6 +1957,46 @@ type TiDBStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// Indicates that a Volume replace using VolumeReplacing feature is in progress.
 	VolReplaceInProgress bool `json:"volReplaceInProgress,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
+	// Users is the status of the SQL users and roles managed by the operator, the key is `name@host`.
+	// +optional
+	Users map[string]TiDBUserStatus `json:"users,omitempty"`
+}
+
+// TiDBUserStatus is the status of a SQL user or role managed by the operator
+type TiDBUserStatus struct {
+	// Synced means the user is consistent with the spec
+	Synced bool `json:"synced"`
+	// Message is the reason why the user is not synced
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Role means it's a role
+	// +optional
+	Role bool `json:"role,omitempty"`
+	// Owned means the user or role is created by the operator, which is dropped after it's removed from the spec
+	// +optional
+	Owned bool `json:"owned,omitempty"`
+	// PasswordSecretVersion is the uid and resource version of the secret and the key of the password last set,
+	// it's used to detect the change of the password
+	// +optional
+	PasswordSecretVersion string `json:"passwordSecretVersion,omitempty"`
+	// GrantsHash is the hash of the grants and roles in the spec last applied
+	// +optional
+	GrantsHash string `json:"grantsHash,omitempty"`
+	// ObservedGrantsHash is the hash of the grants shown by TiDB after the last apply,
+	// which is used to detect the changes made by SQL
+	// +optional
+	ObservedGrantsHash string `json:"observedGrantsHash,omitempty"`
+	// LastSyncTime is the last time the changes are applied
+	// +optional
+	// +nullable
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// LastDriftTime is the last time the changes made by SQL are detected
+	// +optional
+	// +nullable
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
 }
 
 // TiDBMember is TiDB member
@@ -1704,6 +2105,12 @@ type TiKVStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// Indicates that a Volume replace using VolumeReplacing feature is in progress.
 	VolReplaceInProgress bool `json:"volReplaceInProgress,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
+	// DynamicConfig is the status of the last configuration update with the Dynamic strategy.
+	// +optional
+	DynamicConfig *DynamicConfigStatus `json:"dynamicConfig,omitempty"`
 }
 
 // TiFlashStatus is TiFlash status
@@ -1725,6 +2132,9 @@ type TiFlashStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// Indicates that a Volume replace using VolumeReplacing feature is in progress.
 	VolReplaceInProgress bool `json:"volReplaceInProgress,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
 }
 
 // TiProxyMember is TiProxy member
@@ -1751,6 +2161,9 @@ type TiProxyStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
 }
 
 // TiCDCStatus is TiCDC status
@@ -1765,6 +2178,9 @@ type TiCDCStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
 }
 
 // TiCDCCapture is TiCDC Capture status
@@ -1830,6 +2246,9 @@ type PumpStatus struct {
 	// +optional
 	// +nullable
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// TLSCertRotation is the status of the rotation of the TLS certificates mounted by the component.
+	// +optional
+	TLSCertRotation *TLSCertRotationStatus `json:"tlsCertRotation,omitempty"`
 }
 
 // TiDBTLSClient can enable TLS connection between TiDB server and MySQL client
@@ -1884,6 +2303,124 @@ type TLSCluster struct {
 	//        Same for other components.
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+
+	// InternalCA makes the operator issue the certificates above from a CA managed by itself
+	// instead of requiring them to be created by hand. The certificates are renewed before they
+	// expire. The components reload the renewed certificates from the mounted secrets, except that
+	// the pods of TiDB and Pump, which only load them at startup, are restarted in the order of the upgrade.
+	// If the TiDB server TLS is enabled, `<clusterName>-tidb-server-secret` and `<clusterName>-tidb-client-secret`
+	// are issued as well.
+	// The certificates of the servers contain the URI SAN `spiffe://{clusterDomain}/ns/{namespace}/tc/{cluster}/{component}`,
+	// whose trust domain is `spec.clusterDomain`, or `cluster.local` if it's empty,
+	// or the one pinned by the `--tls-server-uri-san` flag of the controller manager.
+	// The CA generated by the operator is rotated before it can't cover a renewal of the certificates:
+	// a new CA is trusted first, and it signs the certificates after the components load it. The replaced CA
+	// is trusted until it expires. The CA created by the users is not rotated, and a warning event is recorded instead.
+	// The secrets which are not created by the operator are left untouched.
+	// It's only supported by TidbCluster.
+	// +optional
+	InternalCA *InternalCA `json:"internalCA,omitempty"`
+}
+
+// InternalCA configures the certificates issued by the operator.
+type InternalCA struct {
+	// CASecretName is the name of the secret storing the CA in the keys `tls.crt` and `tls.key`, and the trusted
+	// CAs in the key `ca.crt` which defaults to the CA.
+	// The CA is generated if the secret doesn't exist. The clusters deployed across Kubernetes
+	// should share the same CA by creating the secret in each of them without the annotation
+	// `tidb.pingcap.com/tls-issued-by`, such a CA is not rotated by the operator.
+	// Optional: Defaults to `<clusterName>-internal-ca`
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+
+	// CertValidity is the validity duration of the issued certificates.
+	// Optional: Defaults to 2160h (90 days)
+	// +optional
+	CertValidity *metav1.Duration `json:"certValidity,omitempty"`
+
+	// RenewBefore is how long before the expiration the issued certificates are renewed.
+	// Optional: Defaults to 720h (30 days)
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// TLSReloadStrategy is how a component loads the rotated TLS certificates
+type TLSReloadStrategy string
+
+const (
+	// TLSReloadStrategyOnline means the component reloads the certificates from the mounted secrets,
+	// which are updated in place by kubelet, without restart. The operator verifies that the pods
+	// serve the rotated certificates.
+	TLSReloadStrategyOnline TLSReloadStrategy = "Online"
+	// TLSReloadStrategyAlterInstance means the operator makes each TiDB reload the certificate of the MySQL
+	// protocol by `ALTER INSTANCE RELOAD TLS`.
+	TLSReloadStrategyAlterInstance TLSReloadStrategy = "AlterInstance"
+	// TLSReloadStrategyRollingRestart means the pods are restarted in order by the upgrader,
+	// with the leaders evicted before the restart if the component has leaders.
+	TLSReloadStrategyRollingRestart TLSReloadStrategy = "RollingRestart"
+)
+
+// TLSCertRotationStatus is the status of the rotation of the TLS certificates mounted by a component
+type TLSCertRotationStatus struct {
+	// OnlineHash is the hash of the contents of the TLS secrets reloaded by the component online,
+	// which are served by all the pods.
+	// +optional
+	OnlineHash string `json:"onlineHash,omitempty"`
+	// ReloadHash is the hash of the contents of the TLS secrets reloaded by `ALTER INSTANCE RELOAD TLS`
+	// on all the pods.
+	// +optional
+	ReloadHash string `json:"reloadHash,omitempty"`
+	// RestartHash is the hash of the contents of the TLS secrets only loaded by the component at startup.
+	// +optional
+	RestartHash string `json:"restartHash,omitempty"`
+	// RollingRestartHash is set to the pod template to restart the pods in order. It's changed after the
+	// secrets only loaded at startup are changed, or the pods fail to reload the rotated certificates
+	// in 10 minutes.
+	// +optional
+	RollingRestartHash string `json:"rollingRestartHash,omitempty"`
+	// Reloading is true if the rotated certificates are not loaded by all the pods yet.
+	// +optional
+	Reloading bool `json:"reloading,omitempty"`
+	// LastReloadStrategy is how the last rotation is loaded by the component.
+	// +optional
+	LastReloadStrategy TLSReloadStrategy `json:"lastReloadStrategy,omitempty"`
+	// LastRotationTime is the last time the change of the TLS secrets is detected.
+	// +optional
+	// +nullable
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// DynamicConfigStatus is the status of the configuration update with the Dynamic strategy
+type DynamicConfigStatus struct {
+	// AppliedOnline are the configuration items applied online through the config API in the last update.
+	// +optional
+	AppliedOnline []string `json:"appliedOnline,omitempty"`
+	// PendingRestart are the configuration items that take effect after the rolling restart triggered
+	// by the last update, it's cleared after the rolling restart is done.
+	// +optional
+	PendingRestart []string `json:"pendingRestart,omitempty"`
+	// LastUpdateTime is the last time the configuration is updated.
+	// +optional
+	// +nullable
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// InternalCAStatus is the status of the certificates issued by the internal CA.
+type InternalCAStatus struct {
+	// CANotAfter is the time the CA expires.
+	CANotAfter *metav1.Time `json:"caNotAfter,omitempty"`
+
+	// Certificates are the issued certificates, keyed by the name of the secret.
+	Certificates map[string]IssuedCertificate `json:"certificates,omitempty"`
+}
+
+// IssuedCertificate is a certificate issued by the internal CA.
+type IssuedCertificate struct {
+	// SerialNumber is the serial number of the certificate in hex.
+	SerialNumber string `json:"serialNumber"`
+
+	// NotAfter is the time the certificate expires.
+	NotAfter metav1.Time `json:"notAfter"`
 }
 
 // +genclient
@@ -1992,6 +2529,10 @@ type S3StorageProvider struct {
 	// SecretName is the name of secret which stores
 	// S3 compliant storage access key and secret key.
 	SecretName string `json:"secretName,omitempty"`
+	// SecretSource is the external source of the access key and secret key,
+	// it takes precedence over SecretName if set.
+	// +optional
+	SecretSource *CredentialSource `json:"secretSource,omitempty"`
 	// Prefix of the data path.
 	Prefix string `json:"prefix,omitempty"`
 	// SSE Sever-Side Encryption.
@@ -2021,6 +2562,10 @@ type GcsStorageProvider struct {
 	// SecretName is the name of secret which stores the
 	// gcs service account credentials JSON.
 	SecretName string `json:"secretName,omitempty"`
+	// SecretSource is the external source of the service account credentials JSON,
+	// it takes precedence over SecretName if set.
+	// +optional
+	SecretSource *CredentialSource `json:"secretSource,omitempty"`
 	// Prefix of the data path.
 	Prefix string `json:"prefix,omitempty"`
 }
@@ -2038,6 +2583,10 @@ type AzblobStorageProvider struct {
 	// SecretName is the name of secret which stores the
 	// azblob service account credentials.
 	SecretName string `json:"secretName,omitempty"`
+	// SecretSource is the external source of the azblob service account credentials,
+	// it takes precedence over SecretName if set.
+	// +optional
+	SecretSource *CredentialSource `json:"secretSource,omitempty"`
 	// StorageAccount is the storage account of the azure blob storage
 	// If this field is set, then use this to set backup-manager env
 	// Otherwise retrieve the storage account from secret
@@ -2048,6 +2597,22 @@ type AzblobStorageProvider struct {
 	Prefix string `json:"prefix,omitempty"`
 }
 
+// CredentialSource represents the credentials that are mounted as files instead of read from a Secret,
+// such as the ones provided by the secrets store CSI driver. Each file is named by the key of the Secret
+// it replaces, and the files are read again when they are rotated.
+// Only one of CSI and Path can be set.
+// +k8s:openapi-gen=true
+type CredentialSource struct {
+	// CSI is the CSI volume that provides the credential files, such as the secrets store CSI driver
+	// with a SecretProviderClass of Vault, AWS Secrets Manager or Azure Key Vault.
+	// +optional
+	CSI *corev1.CSIVolumeSource `json:"csi,omitempty"`
+	// Path is the directory of the credential files that are already mounted to the Pod,
+	// such as by the additional volumes or an injector.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
 // BackupType represents the backup type.
 // +k8s:openapi-gen=true
 type BackupType string
@@ -3307,11 +3872,13 @@ type WorkerFailureMember struct {
 // If `MountPath` is not set, volumeMount will not be generated. (You may not want to set this field when you inject volumeMount
 // in somewhere else such as Mutating Admission Webhook)
 // If `StorageClassName` is not set, default to the `spec.${component}.storageClassName`
+// If `VolumeAttributesClassName` is not set, default to the `spec.${component}.volumeAttributesClassName`
 type StorageVolume struct {
-	Name             string  `json:"name"`
-	StorageClassName *string `json:"storageClassName,omitempty"`
-	StorageSize      string  `json:"storageSize"`
-	MountPath        string  `json:"mountPath,omitempty"`
+	Name                      string  `json:"name"`
+	StorageClassName          *string `json:"storageClassName,omitempty"`
+	VolumeAttributesClassName *string `json:"volumeAttributesClassName,omitempty"`
+	StorageSize               string  `json:"storageSize"`
+	MountPath                 string  `json:"mountPath,omitempty"`
 }
 
 type ObservedStorageVolumeStatus struct {
@@ -3355,6 +3922,63 @@ type StorageVolumeStatus struct {
 	ObservedStorageVolumeStatus `json:",inline"`
 	// Name is the volume name which is same as `volumes.name` in Pod spec.
 	Name StorageVolumeName `json:"name"`
+	// StorageClassMigration is the progress of migrating volumes to a new storage class by replacing them.
+	// It is only set when the VolumeReplacing feature or `spec.enablePVCReplace` is enabled.
+	// +optional
+	StorageClassMigration *StorageClassMigrationStatus `json:"storageClassMigration,omitempty"`
+	// StorageAutoscaling is the status of the automatic expansion of volumes.
+	// It is only set when `storageAutoscaling` of the component is configured.
+	// +optional
+	StorageAutoscaling *StorageAutoscalingStatus `json:"storageAutoscaling,omitempty"`
+}
+
+// StorageAutoscalingStatus is the status of the automatic expansion of volumes
+type StorageAutoscalingStatus struct {
+	// ExpandedCount is the count of volumes which have been expanded automatically.
+	ExpandedCount int `json:"expandedCount"`
+	// MaxSizeReachedCount is the count of volumes which are still above the threshold
+	// but can't be expanded because they have reached the max size.
+	MaxSizeReachedCount int `json:"maxSizeReachedCount"`
+	// LastExpansionTime is the time of the last expansion.
+	// +optional
+	LastExpansionTime *metav1.Time `json:"lastExpansionTime,omitempty"`
+}
+
+// StorageClassMigrationPhase is the phase of a storage class migration
+type StorageClassMigrationPhase string
+
+const (
+	// StorageClassMigrationPhaseMigrating means volumes are being replaced pod by pod
+	StorageClassMigrationPhaseMigrating StorageClassMigrationPhase = "Migrating"
+	// StorageClassMigrationPhaseAborted means the migration is stopped by the annotation
+	// `tidb.pingcap.com/abort-volume-replace` and no more volumes will be replaced until it is removed
+	StorageClassMigrationPhaseAborted StorageClassMigrationPhase = "Aborted"
+	// StorageClassMigrationPhaseCompleted means all volumes use the desired storage class
+	StorageClassMigrationPhaseCompleted StorageClassMigrationPhase = "Completed"
+)
+
+// StorageClassMigrationStatus is the status of migrating volumes from one storage class to another
+type StorageClassMigrationStatus struct {
+	// Phase is the current phase of the migration.
+	Phase StorageClassMigrationPhase `json:"phase"`
+	// FromStorageClass is the storage class volumes are migrated from.
+	// +optional
+	FromStorageClass string `json:"fromStorageClass,omitempty"`
+	// ToStorageClass is the storage class volumes are migrated to.
+	ToStorageClass string `json:"toStorageClass"`
+	// TotalCount is the count of volumes to migrate.
+	TotalCount int `json:"totalCount"`
+	// MigratedCount is the count of volumes which already use the desired storage class.
+	MigratedCount int `json:"migratedCount"`
+	// StartTime is the time when the migration is started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EstimatedCompletionTime is estimated by the average time used to migrate one volume.
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+	// CompletionTime is the time when all volumes are migrated.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // TopologySpreadConstraint specifies how to spread matching pods among the given topology.
@@ -3456,8 +4080,32 @@ type ScalePolicy struct {
 	// +kubebuilder:default=1
 	// +optional
 	ScaleOutParallelism *int32 `json:"scaleOutParallelism,omitempty"`
+
+	// ScaleInVictimPolicy configures how to pick the pods to delete when scaling in.
+	// It only takes effect for TiKV and TiDB when the AdvancedStatefulSet feature is enabled,
+	// the picked ordinals are written into the delete-slots annotation of the TidbCluster.
+	// Optional: Defaults to Ordinal
+	// +kubebuilder:validation:Enum=Ordinal;ZoneBalance;StoreLoad;NodePressure
+	// +optional
+	ScaleInVictimPolicy ScaleInVictimPolicy `json:"scaleInVictimPolicy,omitempty"`
 }
 
+// ScaleInVictimPolicy is the policy to pick the pods to delete when scaling in
+type ScaleInVictimPolicy string
+
+const (
+	// ScaleInVictimPolicyOrdinal deletes the pods with the highest ordinals
+	ScaleInVictimPolicyOrdinal ScaleInVictimPolicy = "Ordinal"
+	// ScaleInVictimPolicyZoneBalance deletes pods from the zones with the most pods,
+	// so that pods are still spread evenly across zones after scaling in
+	ScaleInVictimPolicyZoneBalance ScaleInVictimPolicy = "ZoneBalance"
+	// ScaleInVictimPolicyStoreLoad deletes the TiKV stores with the fewest regions and leaders,
+	// so that the least data is migrated. It is the same as Ordinal for TiDB.
+	ScaleInVictimPolicyStoreLoad ScaleInVictimPolicy = "StoreLoad"
+	// ScaleInVictimPolicyNodePressure deletes pods on the nodes with memory, disk or pid pressure first
+	ScaleInVictimPolicyNodePressure ScaleInVictimPolicy = "NodePressure"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
@@ -21,6 +21,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -29,8 +30,10 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilnet "k8s.io/utils/net"
@@ -85,6 +88,43 @@ func ValidateTiDBDashboard(td *v1alpha1.TidbDashboard) field.ErrorList {
 		allErrs = append(allErrs, validateStorageVolumes(td.Spec.StorageVolumes, field.NewPath("spec").Child("storageVolumes"))...)
 	}
 
+	if td.Spec.SSO != nil {
+		allErrs = append(allErrs, validateTidbDashboardSSO(td, field.NewPath("spec").Child("sso"))...)
+	}
+
+	return allErrs
+}
+
+func validateTidbDashboardSSO(td *v1alpha1.TidbDashboard, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	sso := td.Spec.SSO
+	if td.Spec.ListenOnLocalhostOnly != nil && *td.Spec.ListenOnLocalhostOnly {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the SSO can not be applied when listenOnLocalhostOnly is true"))
+	}
+	if sso.Issuer == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("issuer"), "issuer must not be empty"))
+	} else if u, err := url.Parse(sso.Issuer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("issuer"), sso.Issuer, "issuer must be a http or https URL"))
+	}
+	if sso.SecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("secretName"), "secretName must not be empty"))
+	}
+	if len(sso.Impersonations) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("impersonations"), "at least one SQL user must be impersonated"))
+	}
+	users := map[string]bool{}
+	for i, user := range sso.Impersonations {
+		idxPath := fldPath.Child("impersonations").Index(i)
+		if user.User == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("user"), "user must not be empty"))
+		} else if users[user.User] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("user"), user.User))
+		}
+		users[user.User] = true
+		if user.SecretName == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("secretName"), "secretName must not be empty"))
+		}
+	}
 	return allErrs
 }
 
@@ -101,6 +141,81 @@ func ValidateTidbMonitor(monitor *v1alpha1.TidbMonitor) field.ErrorList {
 	if monitor.Spec.Persistent {
 		allErrs = append(allErrs, validateStorageInfo(monitor.Spec.Storage, field.NewPath("spec"))...)
 	}
+	if po := monitor.Spec.PrometheusOperator; po != nil && po.ScrapeInterval != "" {
+		allErrs = append(allErrs, validatePromDurationStr(&po.ScrapeInterval, field.NewPath("spec", "prometheusOperator", "scrapeInterval"))...)
+	}
+	if rules := monitor.Spec.GeneratedAlertRules; rules != nil {
+		allErrs = append(allErrs, validateGeneratedAlertRules(monitor, rules, field.NewPath("spec", "generatedAlertRules"))...)
+	}
+	if monitor.IsAgentMode() {
+		allErrs = append(allErrs, validatePrometheusAgentMode(monitor)...)
+	}
+	if monitor.IsGrafanaTenancyEnabled() {
+		allErrs = append(allErrs, validateGrafanaTenancy(monitor, monitor.Spec.Grafana.Tenancy, field.NewPath("spec", "grafana", "tenancy"))...)
+	}
+	return allErrs
+}
+
+func validateGrafanaTenancy(monitor *v1alpha1.TidbMonitor, tenancy *v1alpha1.GrafanaTenancySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if monitor.Spec.PrometheusOperator != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the bundled Grafana is not deployed with Prometheus Operator"))
+	}
+	if tenancy.TeamLabelKey != "" {
+		for _, msg := range validation.IsQualifiedName(tenancy.TeamLabelKey) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("teamLabelKey"), tenancy.TeamLabelKey, msg))
+		}
+	}
+	switch tenancy.Permission {
+	case "", v1alpha1.GrafanaPermissionView, v1alpha1.GrafanaPermissionEdit:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("permission"), tenancy.Permission,
+			[]string{string(v1alpha1.GrafanaPermissionView), string(v1alpha1.GrafanaPermissionEdit)}))
+	}
+	return allErrs
+}
+
+// validatePrometheusAgentMode checks the features which need the local TSDB or query
+// are not enabled with the agent mode.
+func validatePrometheusAgentMode(monitor *v1alpha1.TidbMonitor) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if len(monitor.Spec.Prometheus.RemoteWrite) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("prometheus", "remoteWrite"), "remote write is required in agent mode"))
+	}
+	if monitor.Spec.PrometheusOperator != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("prometheusOperator"), "agent mode is not supported with Prometheus Operator"))
+	}
+	if monitor.Spec.Grafana != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("grafana"), "Grafana can't query Prometheus in agent mode"))
+	}
+	if monitor.Spec.Thanos != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("thanos"), "Thanos sidecar needs the TSDB blocks which are not kept in agent mode"))
+	}
+	if monitor.Spec.AlertmanagerURL != nil || monitor.Spec.EnableAlertRules || monitor.Spec.GeneratedAlertRules != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath, "alert rules are not evaluated in agent mode"))
+	}
+	if monitor.Spec.Prometheus.Config != nil && monitor.Spec.Prometheus.Config.RuleConfigRef != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("prometheus", "config", "ruleConfigRef"), "alert rules are not evaluated in agent mode"))
+	}
+	return allErrs
+}
+
+func validateGeneratedAlertRules(monitor *v1alpha1.TidbMonitor, rules *v1alpha1.GeneratedAlertRulesSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	// the generated rules count the targets of components, they are wrong if targets are split into shards
+	if monitor.GetShards() > 1 && monitor.Spec.PrometheusOperator == nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "generated alert rules are not supported with more than one shard"))
+	}
+	for name, d := range map[string]string{
+		"for":                    rules.For,
+		"backupScheduleDelay":    rules.BackupScheduleDelay,
+		"logBackupCheckpointLag": rules.LogBackupCheckpointLag,
+	} {
+		if d != "" {
+			allErrs = append(allErrs, validatePromDurationStr(&d, fldPath.Child(name))...)
+		}
+	}
 	return allErrs
 }
 
@@ -156,6 +271,78 @@ func validateTiDBClusterSpec(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path
 	if spec.StartScriptV2FeatureFlags != nil {
 		allErrs = append(allErrs, validateStartScriptFeatureFlags(spec.StartScriptV2FeatureFlags, fldPath.Child("startScriptV2FeatureFlags"))...)
 	}
+	if spec.TLSCluster != nil && spec.TLSCluster.InternalCA != nil {
+		allErrs = append(allErrs, validateInternalCA(spec.TLSCluster.InternalCA, fldPath.Child("tlsCluster", "internalCA"))...)
+	}
+	if spec.ConfigDriftDetection != nil {
+		allErrs = append(allErrs, validateConfigDriftDetection(spec.ConfigDriftDetection, fldPath.Child("configDriftDetection"))...)
+	}
+	if spec.NetworkPolicy != nil {
+		allErrs = append(allErrs, validateNetworkPolicy(spec, fldPath.Child("networkPolicy"))...)
+	}
+	return allErrs
+}
+
+func validateNetworkPolicy(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.AcrossK8s {
+		// the pods in the other Kubernetes clusters can not be selected by the NetworkPolicies
+		allErrs = append(allErrs, field.Forbidden(fldPath, "networkPolicy is not supported for the cluster across Kubernetes"))
+	}
+	validatePeers := func(peers []networkingv1.NetworkPolicyPeer, fldPath *field.Path) {
+		for i, peer := range peers {
+			idxPath := fldPath.Index(i)
+			if peer.IPBlock == nil && peer.PodSelector == nil && peer.NamespaceSelector == nil {
+				allErrs = append(allErrs, field.Required(idxPath, "one of ipBlock, podSelector and namespaceSelector must be set"))
+				continue
+			}
+			if peer.IPBlock == nil {
+				continue
+			}
+			if peer.PodSelector != nil || peer.NamespaceSelector != nil {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("ipBlock"), "ipBlock can not be set with podSelector or namespaceSelector"))
+			}
+			if _, _, err := utilnet.ParseCIDRSloppy(peer.IPBlock.CIDR); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("ipBlock", "cidr"), peer.IPBlock.CIDR, err.Error()))
+			}
+		}
+	}
+	np := spec.NetworkPolicy
+	validatePeers(np.Clients, fldPath.Child("clients"))
+	validatePeers(np.Operator, fldPath.Child("operator"))
+	validatePeers(np.Monitor, fldPath.Child("monitor"))
+	validatePeers(np.Tools, fldPath.Child("tools"))
+	return allErrs
+}
+
+func validateConfigDriftDetection(d *v1alpha1.ConfigDriftDetection, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch d.GetMode() {
+	case v1alpha1.ConfigDriftModeDetect, v1alpha1.ConfigDriftModeReapply:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), d.Mode,
+			[]string{string(v1alpha1.ConfigDriftModeDetect), string(v1alpha1.ConfigDriftModeReapply)}))
+	}
+	if interval := d.GetInterval(); interval < time.Minute {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("interval"), interval.String(), "interval should be at least 1m"))
+	}
+	return allErrs
+}
+
+func validateInternalCA(ca *v1alpha1.InternalCA, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ca.CASecretName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(ca.CASecretName, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("caSecretName"), ca.CASecretName, msg))
+		}
+	}
+	validity, renewBefore := ca.GetCertValidity(), ca.GetRenewBefore()
+	if validity < time.Hour {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("certValidity"), validity.String(), "certValidity should be at least 1h"))
+	}
+	if renewBefore <= 0 || renewBefore >= validity {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("renewBefore"), renewBefore.String(), "renewBefore should be positive and less than certValidity"))
+	}
 	return allErrs
 }
 
@@ -234,6 +421,7 @@ func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorL
 		allErrs = append(allErrs, validateVolumeName(spec.RocksDBLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
 	}
 	allErrs = append(allErrs, validateTimeDurationStr(spec.EvictLeaderTimeout, fldPath.Child("evictLeaderTimeout"))...)
+	allErrs = append(allErrs, validateStorageAutoscaling(spec.StorageAutoscaling, fldPath.Child("storageAutoscaling"))...)
 	return allErrs
 }
 
@@ -246,6 +434,7 @@ func validateTiFlashSpec(spec *v1alpha1.TiFlashSpec, fldPath *field.Path) field.
 			spec.StorageClaims, "storageClaims should be configured at least one item."))
 	}
 	allErrs = append(allErrs, validateScalePolicy(&spec.ScalePolicy, fldPath.Child("scalePolicy"))...)
+	allErrs = append(allErrs, validateStorageAutoscaling(spec.StorageAutoscaling, fldPath.Child("storageAutoscaling"))...)
 
 	// fix storageClaim
 	for _, storageClaim := range spec.StorageClaims {
@@ -330,6 +519,176 @@ func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorL
 	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName != "" {
 		allErrs = append(allErrs, validateVolumeName(spec.SlowLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
 	}
+	if spec.SlowLogTailer != nil {
+		allErrs = append(allErrs, validateSlowLogTailer(spec.SlowLogTailer, fldPath.Child("slowLogTailer"))...)
+	}
+	if spec.AuditLog != nil {
+		allErrs = append(allErrs, validateAuditLog(spec, fldPath.Child("auditLog"))...)
+	}
+	if spec.Users != nil {
+		allErrs = append(allErrs, validateTiDBUsers(spec.Users, fldPath.Child("users"))...)
+	}
+	return allErrs
+}
+
+var (
+	// tidbPrivilegeRegexp matches the privileges, such as `SELECT`, `ALL PRIVILEGES` and `BACKUP_ADMIN`
+	tidbPrivilegeRegexp = regexp.MustCompile(`^[A-Za-z_]+( [A-Za-z_]+)*$`)
+	// tidbGrantLevelRegexp matches the levels of the privileges, such as `*.*`, `db.*` and `db.table`
+	tidbGrantLevelRegexp = regexp.MustCompile(`^(\*|[A-Za-z0-9_$-]+)\.(\*|[A-Za-z0-9_$-]+)$`)
+)
+
+const (
+	// tidbRootUser is the admin account used to manage the users by default
+	tidbRootUser = "root"
+	// tidbUserNameMaxLength is the max length of the name of a SQL user in TiDB
+	tidbUserNameMaxLength = 32
+	// tidbUserHostMaxLength is the max length of the host of a SQL user in TiDB
+	tidbUserHostMaxLength = 255
+)
+
+func validateTiDBUsers(users *v1alpha1.TiDBUsers, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if users.AdminSecretName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(users.AdminSecretName, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("adminSecretName"), users.AdminSecretName, msg))
+		}
+	}
+	keys := sets.NewString()
+	for i := range users.Users {
+		user := &users.Users[i]
+		idxPath := fldPath.Child("users").Index(i)
+		allErrs = append(allErrs, validateTiDBUserName(user.Name, user.GetHost(), idxPath)...)
+		// root is the default admin account, the one in `adminSecretName` is rejected by the operator
+		// when the secret is read
+		if user.Name == tidbRootUser {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("name"), "root can not be managed"))
+		}
+		if keys.Has(user.Key()) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, user.Key()))
+		}
+		keys.Insert(user.Key())
+
+		if user.Role {
+			if user.PasswordSecret != nil {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("passwordSecret"), "role has no password"))
+			}
+		} else if user.PasswordSecret == nil || user.PasswordSecret.Name == "" || user.PasswordSecret.Key == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("passwordSecret"), "name and key of the password secret are required by a user"))
+		}
+
+		for j, grant := range user.Grants {
+			grantPath := idxPath.Child("grants").Index(j)
+			if len(grant.Privileges) == 0 {
+				allErrs = append(allErrs, field.Required(grantPath.Child("privileges"), "at least one privilege is required"))
+			}
+			for k, privilege := range grant.Privileges {
+				if !tidbPrivilegeRegexp.MatchString(privilege) {
+					allErrs = append(allErrs, field.Invalid(grantPath.Child("privileges").Index(k), privilege, "privilege should only contain letters, underscores and spaces"))
+				}
+			}
+			if !tidbGrantLevelRegexp.MatchString(grant.On) || (strings.HasPrefix(grant.On, "*.") && grant.On != "*.*") {
+				allErrs = append(allErrs, field.Invalid(grantPath.Child("on"), grant.On, "on should be one of `*.*`, `db.*` and `db.table`"))
+			}
+		}
+		for j, role := range user.Roles {
+			name, host := v1alpha1.ParseTiDBRole(role)
+			allErrs = append(allErrs, validateTiDBUserName(name, host, idxPath.Child("roles").Index(j))...)
+		}
+	}
+	return allErrs
+}
+
+func validateTiDBUserName(name, host string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if name == "" || len(name) > tidbUserNameMaxLength {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), name, fmt.Sprintf("name should be 1 to %d characters", tidbUserNameMaxLength)))
+	}
+	if len(host) > tidbUserHostMaxLength {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("host"), host, fmt.Sprintf("host should be at most %d characters", tidbUserHostMaxLength)))
+	}
+	return allErrs
+}
+
+func validateSlowLogTailer(spec *v1alpha1.TiDBSlowLogTailerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch spec.Format {
+	case "", v1alpha1.SlowLogFormatRaw:
+		if spec.Sink != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("sink"), "sink is only supported by the json format"))
+		}
+		return allErrs
+	case v1alpha1.SlowLogFormatJSON:
+	default:
+		return append(allErrs, field.NotSupported(fldPath.Child("format"), spec.Format,
+			[]string{string(v1alpha1.SlowLogFormatRaw), string(v1alpha1.SlowLogFormatJSON)}))
+	}
+	if spec.Sink == nil {
+		return allErrs
+	}
+	sinkPath := fldPath.Child("sink")
+	switch spec.Sink.Type {
+	case "", v1alpha1.SlowLogSinkStdout:
+	case v1alpha1.SlowLogSinkFile:
+		if spec.Sink.Path == "" {
+			allErrs = append(allErrs, field.Required(sinkPath.Child("path"), "path is required by the file sink"))
+		}
+	case v1alpha1.SlowLogSinkHTTP:
+		if spec.Sink.URL == "" {
+			allErrs = append(allErrs, field.Required(sinkPath.Child("url"), "url is required by the http sink"))
+		} else if u, err := url.Parse(spec.Sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(sinkPath.Child("url"), spec.Sink.URL, "must be an absolute http or https URL"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(sinkPath.Child("type"), spec.Sink.Type,
+			[]string{string(v1alpha1.SlowLogSinkStdout), string(v1alpha1.SlowLogSinkFile), string(v1alpha1.SlowLogSinkHTTP)}))
+	}
+	return allErrs
+}
+
+func validateAuditLog(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	auditLog := spec.AuditLog
+	if auditLog.File != "" && (!path.IsAbs(auditLog.File) || strings.HasSuffix(auditLog.File, "/")) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("file"), auditLog.File, "must be an absolute path of a file"))
+	}
+	if auditLog.VolumeName != "" {
+		allErrs = append(allErrs, validateVolumeName(auditLog.VolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
+	}
+	if auditLog.Upload == nil {
+		return allErrs
+	}
+
+	upload := auditLog.Upload
+	uploadPath := fldPath.Child("upload")
+	if upload.S3 == nil && upload.Gcs == nil && upload.Azblob == nil {
+		allErrs = append(allErrs, field.Required(uploadPath, "one of s3, gcs and azblob is required"))
+	}
+	if upload.Local != nil {
+		allErrs = append(allErrs, field.Forbidden(uploadPath.Child("local"), "the audit log should be retained outside the cluster"))
+	}
+	if upload.Retention != "" {
+		if d, err := time.ParseDuration(upload.Retention); err != nil || d <= 0 {
+			allErrs = append(allErrs, field.Invalid(uploadPath.Child("retention"), upload.Retention, "must be a positive duration, such as 2160h"))
+		}
+	}
+	if upload.Interval != "" {
+		if d, err := time.ParseDuration(upload.Interval); err != nil || d <= 0 {
+			allErrs = append(allErrs, field.Invalid(uploadPath.Child("interval"), upload.Interval, "must be a positive duration, such as 1m"))
+		}
+	}
+	switch upload.ObjectLockMode {
+	case "":
+	case "GOVERNANCE", "COMPLIANCE":
+		if upload.S3 == nil {
+			allErrs = append(allErrs, field.Forbidden(uploadPath.Child("objectLockMode"), "object lock is only supported by s3"))
+		}
+		if upload.Retention == "" {
+			allErrs = append(allErrs, field.Required(uploadPath.Child("retention"), "retention is required by the object lock"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(uploadPath.Child("objectLockMode"), upload.ObjectLockMode, []string{"GOVERNANCE", "COMPLIANCE"}))
+	}
 	return allErrs
 }
 
@@ -352,6 +711,9 @@ func validateDMClusterSpec(spec *v1alpha1.DMClusterSpec, fldPath *field.Path) fi
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), spec.Version, "dm cluster version can't set to v1.x.y"))
 		}
 	}
+	if spec.TLSCluster != nil && spec.TLSCluster.InternalCA != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("tlsCluster", "internalCA"), "the internal CA is not supported by DMCluster"))
+	}
 	allErrs = append(allErrs, validateDMDiscoverySpec(spec.Discovery, fldPath.Child("discovery"))...)
 	allErrs = append(allErrs, validateMasterSpec(&spec.Master, fldPath.Child("master"))...)
 	if spec.Worker != nil {
@@ -403,7 +765,45 @@ func validateNGMonitoringSpec(spec *v1alpha1.NGMonitoringSpec, fldPath *field.Pa
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	allErrs = append(allErrs, validateNGMonitoringRetention(spec.Retention, fldPath.Child("retention"))...)
+	allErrs = append(allErrs, validateStorageAutoscaling(spec.StorageAutoscaling, fldPath.Child("storageAutoscaling"))...)
+	allErrs = append(allErrs, validateNGMonitoringExport(spec.Export, fldPath.Child("export"))...)
+
+	return allErrs
+}
+
+func validateNGMonitoringRetention(retention *v1alpha1.NGMonitoringRetention, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if retention == nil {
+		return allErrs
+	}
+	// the retention of Top SQL is configured in hours
+	if retention.TopSQL != nil && retention.TopSQL.Duration < time.Hour {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("topSQL"), retention.TopSQL.Duration.String(), "topSQL should not be less than 1h"))
+	}
+	if retention.Profiling != nil && retention.Profiling.Duration < time.Second {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("profiling"), retention.Profiling.Duration.String(), "profiling should not be less than 1s"))
+	}
+	return allErrs
+}
 
+func validateNGMonitoringExport(export *v1alpha1.NGMonitoringExport, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if export == nil {
+		return allErrs
+	}
+	providers := 0
+	for _, set := range []bool{export.S3 != nil, export.Gcs != nil, export.Azblob != nil, export.Local != nil} {
+		if set {
+			providers++
+		}
+	}
+	if providers != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath, providers, "exactly one of s3, gcs, azblob and local should be set"))
+	}
+	if export.Window != nil && export.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("window"), export.Window.Duration.String(), "window should be positive"))
+	}
 	return allErrs
 }
 
@@ -822,6 +1222,26 @@ func validateStorageInfo(storage string, fldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+func validateStorageAutoscaling(autoscaling *v1alpha1.StorageAutoscaling, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if autoscaling == nil {
+		return allErrs
+	}
+	if autoscaling.ThresholdPercent != nil && (*autoscaling.ThresholdPercent <= 0 || *autoscaling.ThresholdPercent >= 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("thresholdPercent"),
+			*autoscaling.ThresholdPercent, "thresholdPercent should be in (0, 100)"))
+	}
+	if autoscaling.Step.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("step"),
+			autoscaling.Step.String(), "step should be positive"))
+	}
+	if autoscaling.MaxSize.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxSize"),
+			autoscaling.MaxSize.String(), "maxSize should be positive"))
+	}
+	return allErrs
+}
+
 func validateScalePolicy(scalePolicy *v1alpha1.ScalePolicy, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if scalePolicy.ScaleInParallelism != nil && *scalePolicy.ScaleInParallelism <= 0 {
@@ -16,11 +16,13 @@ package validation
 import (
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -492,6 +494,44 @@ func TestValidateTidbMonitor(t *testing.T) {
 	}
 }
 
+func TestValidateTidbMonitorAgentMode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	monitor := newTidbMonitor()
+	monitor.Spec.Prometheus.AgentMode = true
+	monitor.Spec.EnableAlertRules = true
+	errs := ValidateTidbMonitor(monitor)
+	g.Expect(errs).To(HaveLen(3))
+	g.Expect(errs[0].Field).To(Equal("spec.prometheus.remoteWrite"))
+	g.Expect(errs[1].Field).To(Equal("spec.grafana"))
+	g.Expect(errs[2].Detail).To(ContainSubstring("alert rules are not evaluated in agent mode"))
+
+	monitor.Spec.EnableAlertRules = false
+	monitor.Spec.Grafana = nil
+	monitor.Spec.Prometheus.RemoteWrite = []*v1alpha1.RemoteWriteSpec{{URL: "http://mimir/api/v1/push"}}
+	g.Expect(ValidateTidbMonitor(monitor)).To(BeEmpty())
+}
+
+func TestValidateTidbMonitorGrafanaTenancy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	monitor := newTidbMonitor()
+	monitor.Spec.Grafana.Tenancy = &v1alpha1.GrafanaTenancySpec{}
+	g.Expect(ValidateTidbMonitor(monitor)).To(BeEmpty())
+
+	monitor.Spec.Grafana.Tenancy = &v1alpha1.GrafanaTenancySpec{TeamLabelKey: "team/a/b", Permission: "Admin"}
+	errs := ValidateTidbMonitor(monitor)
+	g.Expect(errs).To(HaveLen(2))
+	g.Expect(errs[0].Field).To(Equal("spec.grafana.tenancy.teamLabelKey"))
+	g.Expect(errs[1].Field).To(Equal("spec.grafana.tenancy.permission"))
+
+	monitor.Spec.Grafana.Tenancy = &v1alpha1.GrafanaTenancySpec{TeamLabelKey: "example.com/team", Permission: v1alpha1.GrafanaPermissionEdit}
+	monitor.Spec.PrometheusOperator = &v1alpha1.PrometheusOperatorSpec{}
+	errs = ValidateTidbMonitor(monitor)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0].Field).To(Equal("spec.grafana.tenancy"))
+}
+
 func TestValidateDMCluster(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tests := []struct {
@@ -648,6 +688,293 @@ func TestValidateEvictLeaderTimeout(t *testing.T) {
 	}
 }
 
+func TestValidateStorageAutoscaling(t *testing.T) {
+	successCases := []*v1alpha1.StorageAutoscaling{
+		nil,
+		{
+			Step:    resource.MustParse("100Gi"),
+			MaxSize: resource.MustParse("2Ti"),
+		},
+		{
+			ThresholdPercent: pointer.Int32Ptr(90),
+			Step:             resource.MustParse("100Gi"),
+			MaxSize:          resource.MustParse("2Ti"),
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateStorageAutoscaling(c, field.NewPath("storageAutoscaling"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []*v1alpha1.StorageAutoscaling{
+		{
+			ThresholdPercent: pointer.Int32Ptr(100),
+			Step:             resource.MustParse("100Gi"),
+			MaxSize:          resource.MustParse("2Ti"),
+		},
+		{
+			MaxSize: resource.MustParse("2Ti"),
+		},
+		{
+			Step: resource.MustParse("100Gi"),
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validateStorageAutoscaling(c, field.NewPath("storageAutoscaling"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateNGMonitoringSpec(t *testing.T) {
+	s3 := v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "bucket"}}
+	successCases := []v1alpha1.NGMonitoringSpec{
+		{},
+		{
+			Retention: &v1alpha1.NGMonitoringRetention{
+				TopSQL:    &metav1.Duration{Duration: 30 * 24 * time.Hour},
+				Profiling: &metav1.Duration{Duration: 7 * 24 * time.Hour},
+			},
+		},
+		{
+			Export: &v1alpha1.NGMonitoringExport{StorageProvider: s3},
+		},
+		{
+			Export: &v1alpha1.NGMonitoringExport{StorageProvider: s3, Schedule: "*/30 * * * *", Window: &metav1.Duration{Duration: 30 * time.Minute}},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateNGMonitoringSpec(&c, field.NewPath("ngMonitoring"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.NGMonitoringSpec{
+		{
+			Retention: &v1alpha1.NGMonitoringRetention{TopSQL: &metav1.Duration{Duration: 30 * time.Minute}},
+		},
+		{
+			Retention: &v1alpha1.NGMonitoringRetention{Profiling: &metav1.Duration{}},
+		},
+		{
+			StorageAutoscaling: &v1alpha1.StorageAutoscaling{Step: resource.MustParse("10Gi")},
+		},
+		{
+			Export: &v1alpha1.NGMonitoringExport{},
+		},
+		{
+			Export: &v1alpha1.NGMonitoringExport{StorageProvider: v1alpha1.StorageProvider{
+				S3:  &v1alpha1.S3StorageProvider{Bucket: "bucket"},
+				Gcs: &v1alpha1.GcsStorageProvider{Bucket: "bucket"},
+			}},
+		},
+		{
+			Export: &v1alpha1.NGMonitoringExport{StorageProvider: s3, Window: &metav1.Duration{}},
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validateNGMonitoringSpec(&c, field.NewPath("ngMonitoring"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateInternalCA(t *testing.T) {
+	successCases := []v1alpha1.InternalCA{
+		{},
+		{CASecretName: "shared-ca"},
+		{CertValidity: &metav1.Duration{Duration: 24 * time.Hour}, RenewBefore: &metav1.Duration{Duration: 8 * time.Hour}},
+	}
+
+	for _, c := range successCases {
+		errs := validateInternalCA(&c, field.NewPath("internalCA"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.InternalCA{
+		{CASecretName: "Shared_CA"},
+		{CertValidity: &metav1.Duration{Duration: time.Minute}},
+		{CertValidity: &metav1.Duration{Duration: 24 * time.Hour}},
+		{RenewBefore: &metav1.Duration{}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateInternalCA(&c, field.NewPath("internalCA"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateConfigDriftDetection(t *testing.T) {
+	successCases := []v1alpha1.ConfigDriftDetection{
+		{},
+		{Mode: v1alpha1.ConfigDriftModeReapply},
+		{Mode: v1alpha1.ConfigDriftModeDetect, Interval: &metav1.Duration{Duration: time.Hour}},
+	}
+
+	for _, c := range successCases {
+		errs := validateConfigDriftDetection(&c, field.NewPath("configDriftDetection"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.ConfigDriftDetection{
+		{Mode: "Fix"},
+		{Interval: &metav1.Duration{Duration: time.Second}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateConfigDriftDetection(&c, field.NewPath("configDriftDetection"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	appSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	successCases := []v1alpha1.TidbClusterSpec{
+		{NetworkPolicy: &v1alpha1.NetworkPolicySpec{}},
+		{NetworkPolicy: &v1alpha1.NetworkPolicySpec{
+			Clients: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: appSelector, NamespaceSelector: &metav1.LabelSelector{}},
+				{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.0.1.0/24"}}},
+			},
+			Operator: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: appSelector}},
+		}},
+	}
+
+	for _, c := range successCases {
+		errs := validateNetworkPolicy(&c, field.NewPath("networkPolicy"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TidbClusterSpec{
+		{AcrossK8s: true, NetworkPolicy: &v1alpha1.NetworkPolicySpec{}},
+		{NetworkPolicy: &v1alpha1.NetworkPolicySpec{Monitor: []networkingv1.NetworkPolicyPeer{{}}}},
+		{NetworkPolicy: &v1alpha1.NetworkPolicySpec{Tools: []networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}, PodSelector: appSelector},
+		}}},
+		{NetworkPolicy: &v1alpha1.NetworkPolicySpec{Clients: []networkingv1.NetworkPolicyPeer{
+			{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0"}},
+		}}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateNetworkPolicy(&c, field.NewPath("networkPolicy"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c.NetworkPolicy)
+		}
+	}
+}
+
+func TestValidateTiDBUsers(t *testing.T) {
+	password := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}, Key: "password"}
+	successCases := []v1alpha1.TiDBUsers{
+		{},
+		{
+			AdminSecretName: "tidb-admin",
+			Users: []v1alpha1.TiDBUser{
+				{Name: "reader", Role: true, Grants: []v1alpha1.TiDBGrant{{Privileges: []string{"SELECT"}, On: "app.*"}}},
+				{Name: "app", Host: "10.0.0.%", PasswordSecret: password, Roles: []string{"reader"}},
+				{Name: "app", PasswordSecret: password, Grants: []v1alpha1.TiDBGrant{
+					{Privileges: []string{"ALL PRIVILEGES"}, On: "app.orders", WithGrantOption: true},
+					{Privileges: []string{"BACKUP_ADMIN", "PROCESS"}, On: "*.*"},
+				}},
+			},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateTiDBUsers(&c, field.NewPath("users"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TiDBUsers{
+		{AdminSecretName: "TiDB_Admin"},
+		{Users: []v1alpha1.TiDBUser{{PasswordSecret: password}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app"}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "reader", Role: true, PasswordSecret: password}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app", PasswordSecret: password}, {Name: "app", Host: "%", PasswordSecret: password}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app", PasswordSecret: password, Grants: []v1alpha1.TiDBGrant{{On: "app.*"}}}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app", PasswordSecret: password, Grants: []v1alpha1.TiDBGrant{{Privileges: []string{"SELECT; DROP"}, On: "app.*"}}}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app", PasswordSecret: password, Grants: []v1alpha1.TiDBGrant{{Privileges: []string{"SELECT"}, On: "app"}}}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app", PasswordSecret: password, Grants: []v1alpha1.TiDBGrant{{Privileges: []string{"SELECT"}, On: "*.orders"}}}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "app", PasswordSecret: password, Roles: []string{"@%"}}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "root", Host: "localhost", PasswordSecret: password}}},
+		{Users: []v1alpha1.TiDBUser{{Name: "root", Role: true}}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTiDBUsers(&c, field.NewPath("users"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTidbDashboardSSO(t *testing.T) {
+	impersonations := []v1alpha1.TidbDashboardSQLUser{{User: "dashboard", SecretName: "dashboard-user"}}
+	successCases := []v1alpha1.TidbDashboardSSO{
+		{Issuer: "https://example.okta.com", SecretName: "oidc", Impersonations: impersonations},
+		{Issuer: "http://dex.auth.svc:5556/dex", SecretName: "oidc", ReadOnly: true, Impersonations: impersonations},
+		{Issuer: "https://example.okta.com", SecretName: "oidc", Impersonations: []v1alpha1.TidbDashboardSQLUser{
+			{User: "dashboard", SecretName: "dashboard-user"},
+			{User: "viewer", SecretName: "viewer-user"},
+		}},
+	}
+
+	for _, c := range successCases {
+		td := &v1alpha1.TidbDashboard{Spec: v1alpha1.TidbDashboardSpec{SSO: &c}}
+		errs := validateTidbDashboardSSO(td, field.NewPath("sso"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TidbDashboardSpec{
+		{SSO: &v1alpha1.TidbDashboardSSO{SecretName: "oidc", Impersonations: impersonations}},
+		{SSO: &v1alpha1.TidbDashboardSSO{Issuer: "example.okta.com", SecretName: "oidc", Impersonations: impersonations}},
+		{SSO: &v1alpha1.TidbDashboardSSO{Issuer: "https://example.okta.com", Impersonations: impersonations}},
+		{SSO: &v1alpha1.TidbDashboardSSO{Issuer: "https://example.okta.com", SecretName: "oidc"}},
+		{SSO: &v1alpha1.TidbDashboardSSO{Issuer: "https://example.okta.com", SecretName: "oidc", Impersonations: []v1alpha1.TidbDashboardSQLUser{{User: "dashboard"}}}},
+		{SSO: &v1alpha1.TidbDashboardSSO{Issuer: "https://example.okta.com", SecretName: "oidc", Impersonations: []v1alpha1.TidbDashboardSQLUser{{SecretName: "dashboard-user"}}}},
+		{SSO: &v1alpha1.TidbDashboardSSO{Issuer: "https://example.okta.com", SecretName: "oidc", Impersonations: []v1alpha1.TidbDashboardSQLUser{
+			{User: "dashboard", SecretName: "dashboard-user"},
+			{User: "dashboard", SecretName: "other-user"},
+		}}},
+		{
+			ListenOnLocalhostOnly: pointer.BoolPtr(true),
+			SSO:                   &v1alpha1.TidbDashboardSSO{Issuer: "https://example.okta.com", SecretName: "oidc", Impersonations: impersonations},
+		},
+	}
+
+	for _, c := range errorCases {
+		td := &v1alpha1.TidbDashboard{Spec: c}
+		errs := validateTidbDashboardSSO(td, field.NewPath("sso"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c.SSO)
+		}
+	}
+}
+
 func TestValidatePromDurationStr(t *testing.T) {
 	successCases := []*string{
 		nil,
@@ -852,6 +1179,151 @@ func TestValidateTiFlashSpec(t *testing.T) {
 	}
 }
 
+func TestValidateSlowLogTailer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tests := []struct {
+		name          string
+		spec          v1alpha1.TiDBSlowLogTailerSpec
+		expectedField string
+	}{
+		{
+			name: "raw format",
+			spec: v1alpha1.TiDBSlowLogTailerSpec{},
+		},
+		{
+			name:          "sink with raw format",
+			spec:          v1alpha1.TiDBSlowLogTailerSpec{Sink: &v1alpha1.SlowLogSinkSpec{}},
+			expectedField: "spec.tidb.slowLogTailer.sink",
+		},
+		{
+			name:          "unknown format",
+			spec:          v1alpha1.TiDBSlowLogTailerSpec{Format: "xml"},
+			expectedField: "spec.tidb.slowLogTailer.format",
+		},
+		{
+			name: "json format to stdout",
+			spec: v1alpha1.TiDBSlowLogTailerSpec{Format: v1alpha1.SlowLogFormatJSON},
+		},
+		{
+			name: "file sink without path",
+			spec: v1alpha1.TiDBSlowLogTailerSpec{
+				Format: v1alpha1.SlowLogFormatJSON,
+				Sink:   &v1alpha1.SlowLogSinkSpec{Type: v1alpha1.SlowLogSinkFile},
+			},
+			expectedField: "spec.tidb.slowLogTailer.sink.path",
+		},
+		{
+			name: "http sink with relative url",
+			spec: v1alpha1.TiDBSlowLogTailerSpec{
+				Format: v1alpha1.SlowLogFormatJSON,
+				Sink:   &v1alpha1.SlowLogSinkSpec{Type: v1alpha1.SlowLogSinkHTTP, URL: "/slowlog"},
+			},
+			expectedField: "spec.tidb.slowLogTailer.sink.url",
+		},
+		{
+			name: "http sink",
+			spec: v1alpha1.TiDBSlowLogTailerSpec{
+				Format: v1alpha1.SlowLogFormatJSON,
+				Sink:   &v1alpha1.SlowLogSinkSpec{Type: v1alpha1.SlowLogSinkHTTP, URL: "http://vector:8080/slowlog"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateSlowLogTailer(&tt.spec, field.NewPath("spec", "tidb", "slowLogTailer"))
+			if tt.expectedField == "" {
+				g.Expect(errs).To(BeEmpty())
+				return
+			}
+			g.Expect(errs).To(HaveLen(1))
+			g.Expect(errs[0].Field).To(Equal(tt.expectedField))
+		})
+	}
+}
+
+func TestValidateAuditLog(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s3 := &v1alpha1.S3StorageProvider{Bucket: "audit"}
+	tests := []struct {
+		name          string
+		spec          v1alpha1.TiDBAuditLogSpec
+		expectedField string
+	}{
+		{
+			name: "default",
+			spec: v1alpha1.TiDBAuditLogSpec{},
+		},
+		{
+			name:          "relative file",
+			spec:          v1alpha1.TiDBAuditLogSpec{File: "tidb-audit.log"},
+			expectedField: "spec.tidb.auditLog.file",
+		},
+		{
+			name:          "unknown volume",
+			spec:          v1alpha1.TiDBAuditLogSpec{VolumeName: "audit"},
+			expectedField: "spec.tidb.auditLog.volumeName",
+		},
+		{
+			name:          "upload without storage",
+			spec:          v1alpha1.TiDBAuditLogSpec{Upload: &v1alpha1.AuditLogUploadSpec{}},
+			expectedField: "spec.tidb.auditLog.upload",
+		},
+		{
+			name: "upload to local storage",
+			spec: v1alpha1.TiDBAuditLogSpec{Upload: &v1alpha1.AuditLogUploadSpec{
+				StorageProvider: v1alpha1.StorageProvider{S3: s3, Local: &v1alpha1.LocalStorageProvider{}},
+			}},
+			expectedField: "spec.tidb.auditLog.upload.local",
+		},
+		{
+			name: "invalid retention",
+			spec: v1alpha1.TiDBAuditLogSpec{Upload: &v1alpha1.AuditLogUploadSpec{
+				StorageProvider: v1alpha1.StorageProvider{S3: s3},
+				Retention:       "90d",
+			}},
+			expectedField: "spec.tidb.auditLog.upload.retention",
+		},
+		{
+			name: "object lock without retention",
+			spec: v1alpha1.TiDBAuditLogSpec{Upload: &v1alpha1.AuditLogUploadSpec{
+				StorageProvider: v1alpha1.StorageProvider{S3: s3},
+				ObjectLockMode:  "COMPLIANCE",
+			}},
+			expectedField: "spec.tidb.auditLog.upload.retention",
+		},
+		{
+			name: "object lock on gcs",
+			spec: v1alpha1.TiDBAuditLogSpec{Upload: &v1alpha1.AuditLogUploadSpec{
+				StorageProvider: v1alpha1.StorageProvider{Gcs: &v1alpha1.GcsStorageProvider{Bucket: "audit"}},
+				Retention:       "2160h",
+				ObjectLockMode:  "GOVERNANCE",
+			}},
+			expectedField: "spec.tidb.auditLog.upload.objectLockMode",
+		},
+		{
+			name: "upload with object lock",
+			spec: v1alpha1.TiDBAuditLogSpec{Upload: &v1alpha1.AuditLogUploadSpec{
+				StorageProvider: v1alpha1.StorageProvider{S3: s3},
+				Retention:       "2160h",
+				ObjectLockMode:  "COMPLIANCE",
+				Interval:        "30s",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1alpha1.TiDBSpec{AuditLog: &tt.spec}
+			errs := validateAuditLog(spec, field.NewPath("spec", "tidb", "auditLog"))
+			if tt.expectedField == "" {
+				g.Expect(errs).To(BeEmpty())
+				return
+			}
+			g.Expect(errs).To(HaveLen(1))
+			g.Expect(errs[0].Field).To(Equal(tt.expectedField))
+		})
+	}
+}
+
 func Test_disallowMutateBootstrapSQLConfigMapName(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tests := []struct {
@@ -32,6 +32,23 @@ import (
 	types "k8s.io/apimachinery/pkg/types"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogUploadSpec) DeepCopyInto(out *AuditLogUploadSpec) {
+	*out = *in
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogUploadSpec.
+func (in *AuditLogUploadSpec) DeepCopy() *AuditLogUploadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogUploadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoResource) DeepCopyInto(out *AutoResource) {
 	*out = *in
@@ -85,6 +102,11 @@ func (in *AutoRule) DeepCopy() *AutoRule {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzblobStorageProvider) DeepCopyInto(out *AzblobStorageProvider) {
 	*out = *in
+	if in.SecretSource != nil {
+		in, out := &in.SecretSource, &out.SecretSource
+		*out = new(CredentialSource)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1190,6 +1212,68 @@ func (in *ComponentSpec) DeepCopy() *ComponentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigDriftDetection) DeepCopyInto(out *ConfigDriftDetection) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDriftDetection.
+func (in *ConfigDriftDetection) DeepCopy() *ConfigDriftDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDriftDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigDriftItem) DeepCopyInto(out *ConfigDriftItem) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDriftItem.
+func (in *ConfigDriftItem) DeepCopy() *ConfigDriftItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDriftItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigDriftStatus) DeepCopyInto(out *ConfigDriftStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ConfigDriftItem, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDriftStatus.
+func (in *ConfigDriftStatus) DeepCopy() *ConfigDriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
 	*out = *in
@@ -1298,6 +1382,27 @@ func (in *CrdKinds) DeepCopy() *CrdKinds {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialSource) DeepCopyInto(out *CredentialSource) {
+	*out = *in
+	if in.CSI != nil {
+		in, out := &in.CSI, &out.CSI
+		*out = new(v1.CSIVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialSource.
+func (in *CredentialSource) DeepCopy() *CredentialSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomizedProbe) DeepCopyInto(out *CustomizedProbe) {
 	*out = *in
@@ -1451,7 +1556,7 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 	if in.TLSCluster != nil {
 		in, out := &in.TLSCluster, &out.TLSCluster
 		*out = new(TLSCluster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.TLSClientSecretNames != nil {
 		in, out := &in.TLSClientSecretNames, &out.TLSClientSecretNames
@@ -1851,6 +1956,36 @@ func (in *DumplingConfig) DeepCopy() *DumplingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicConfigStatus) DeepCopyInto(out *DynamicConfigStatus) {
+	*out = *in
+	if in.AppliedOnline != nil {
+		in, out := &in.AppliedOnline, &out.AppliedOnline
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingRestart != nil {
+		in, out := &in.PendingRestart, &out.PendingRestart
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigStatus.
+func (in *DynamicConfigStatus) DeepCopy() *DynamicConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmptyStruct) DeepCopyInto(out *EmptyStruct) {
 	*out = *in
@@ -2392,6 +2527,11 @@ func (in *FlashUser) DeepCopy() *FlashUser {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GcsStorageProvider) DeepCopyInto(out *GcsStorageProvider) {
 	*out = *in
+	if in.SecretSource != nil {
+		in, out := &in.SecretSource, &out.SecretSource
+		*out = new(CredentialSource)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2405,6 +2545,71 @@ func (in *GcsStorageProvider) DeepCopy() *GcsStorageProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedAlertRulesSpec) DeepCopyInto(out *GeneratedAlertRulesSpec) {
+	*out = *in
+	if in.DisabledAlerts != nil {
+		in, out := &in.DisabledAlerts, &out.DisabledAlerts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedAlertRulesSpec.
+func (in *GeneratedAlertRulesSpec) DeepCopy() *GeneratedAlertRulesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedAlertRulesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardsSpec) DeepCopyInto(out *GrafanaDashboardsSpec) {
+	*out = *in
+	if in.ConfigMapRefs != nil {
+		in, out := &in.ConfigMapRefs, &out.ConfigMapRefs
+		*out = make([]ConfigMapRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardsSpec.
+func (in *GrafanaDashboardsSpec) DeepCopy() *GrafanaDashboardsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 	*out = *in
@@ -2439,6 +2644,11 @@ func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Tenancy != nil {
+		in, out := &in.Tenancy, &out.Tenancy
+		*out = new(GrafanaTenancySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2452,6 +2662,27 @@ func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaTenancySpec) DeepCopyInto(out *GrafanaTenancySpec) {
+	*out = *in
+	if in.LabelProxy != nil {
+		in, out := &in.LabelProxy, &out.LabelProxy
+		*out = new(MonitorContainer)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaTenancySpec.
+func (in *GrafanaTenancySpec) DeepCopy() *GrafanaTenancySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaTenancySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelperSpec) DeepCopyInto(out *HelperSpec) {
 	*out = *in
@@ -2554,6 +2785,59 @@ func (in *InitializerSpec) DeepCopy() *InitializerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalCA) DeepCopyInto(out *InternalCA) {
+	*out = *in
+	if in.CertValidity != nil {
+		in, out := &in.CertValidity, &out.CertValidity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalCA.
+func (in *InternalCA) DeepCopy() *InternalCA {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalCA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalCAStatus) DeepCopyInto(out *InternalCAStatus) {
+	*out = *in
+	if in.CANotAfter != nil {
+		in, out := &in.CANotAfter, &out.CANotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make(map[string]IssuedCertificate, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalCAStatus.
+func (in *InternalCAStatus) DeepCopy() *InternalCAStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalCAStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Interval) DeepCopyInto(out *Interval) {
 	*out = *in
@@ -2621,6 +2905,23 @@ func (in *IsolationRead) DeepCopy() *IsolationRead {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuedCertificate) DeepCopyInto(out *IssuedCertificate) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuedCertificate.
+func (in *IssuedCertificate) DeepCopy() *IssuedCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuedCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalStorageProvider) DeepCopyInto(out *LocalStorageProvider) {
 	*out = *in
@@ -3098,6 +3399,54 @@ func (in *MonitorContainer) DeepCopy() *MonitorContainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NGMonitoringExport) DeepCopyInto(out *NGMonitoringExport) {
+	*out = *in
+	in.StorageProvider.DeepCopyInto(&out.StorageProvider)
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NGMonitoringExport.
+func (in *NGMonitoringExport) DeepCopy() *NGMonitoringExport {
+	if in == nil {
+		return nil
+	}
+	out := new(NGMonitoringExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NGMonitoringRetention) DeepCopyInto(out *NGMonitoringRetention) {
+	*out = *in
+	if in.TopSQL != nil {
+		in, out := &in.TopSQL, &out.TopSQL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Profiling != nil {
+		in, out := &in.Profiling, &out.Profiling
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NGMonitoringRetention.
+func (in *NGMonitoringRetention) DeepCopy() *NGMonitoringRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(NGMonitoringRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NGMonitoringSpec) DeepCopyInto(out *NGMonitoringSpec) {
 	*out = *in
@@ -3119,6 +3468,21 @@ func (in *NGMonitoringSpec) DeepCopyInto(out *NGMonitoringSpec) {
 		in, out := &in.Config, &out.Config
 		*out = (*in).DeepCopy()
 	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(NGMonitoringRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageAutoscaling != nil {
+		in, out := &in.StorageAutoscaling, &out.StorageAutoscaling
+		*out = new(StorageAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(NGMonitoringExport)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3153,6 +3517,50 @@ func (in *NGMonitoringStatus) DeepCopy() *NGMonitoringStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicySpec) DeepCopyInto(out *NetworkPolicySpec) {
+	*out = *in
+	if in.Clients != nil {
+		in, out := &in.Clients, &out.Clients
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Operator != nil {
+		in, out := &in.Operator, &out.Operator
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Monitor != nil {
+		in, out := &in.Monitor, &out.Monitor
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicySpec.
+func (in *NetworkPolicySpec) DeepCopy() *NetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Networks) DeepCopyInto(out *Networks) {
 	*out = *in
@@ -3683,6 +4091,11 @@ func (in *PDMSStatus) DeepCopyInto(out *PDMSStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4116,6 +4529,11 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.StorageVolumes != nil {
 		in, out := &in.StorageVolumes, &out.StorageVolumes
 		*out = make([]StorageVolume, len(*in))
@@ -4220,6 +4638,16 @@ func (in *PDStatus) DeepCopyInto(out *PDStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DynamicConfig != nil {
+		in, out := &in.DynamicConfig, &out.DynamicConfig
+		*out = new(DynamicConfigStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4611,6 +5039,34 @@ func (in *PrometheusConfiguration) DeepCopy() *PrometheusConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusOperatorSpec) DeepCopyInto(out *PrometheusOperatorSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GrafanaDashboards != nil {
+		in, out := &in.GrafanaDashboards, &out.GrafanaDashboards
+		*out = new(GrafanaDashboardsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusOperatorSpec.
+func (in *PrometheusOperatorSpec) DeepCopy() *PrometheusOperatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusOperatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusReloaderSpec) DeepCopyInto(out *PrometheusReloaderSpec) {
 	*out = *in
@@ -4822,6 +5278,11 @@ func (in *PumpSpec) DeepCopyInto(out *PumpSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = (*in).DeepCopy()
@@ -4885,6 +5346,11 @@ func (in *PumpStatus) DeepCopyInto(out *PumpStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5244,6 +5710,11 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StorageProvider) DeepCopyInto(out *S3StorageProvider) {
 	*out = *in
+	if in.SecretSource != nil {
+		in, out := &in.SecretSource, &out.SecretSource
+		*out = new(CredentialSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]string, len(*in))
@@ -5485,6 +5956,22 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlowLogSinkSpec) DeepCopyInto(out *SlowLogSinkSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowLogSinkSpec.
+func (in *SlowLogSinkSpec) DeepCopy() *SlowLogSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlowLogSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
@@ -5557,12 +6044,55 @@ func (in *StmtSummary) DeepCopyInto(out *StmtSummary) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StmtSummary.
-func (in *StmtSummary) DeepCopy() *StmtSummary {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StmtSummary.
+func (in *StmtSummary) DeepCopy() *StmtSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(StmtSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutoscaling) DeepCopyInto(out *StorageAutoscaling) {
+	*out = *in
+	if in.ThresholdPercent != nil {
+		in, out := &in.ThresholdPercent, &out.ThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	out.Step = in.Step.DeepCopy()
+	out.MaxSize = in.MaxSize.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutoscaling.
+func (in *StorageAutoscaling) DeepCopy() *StorageAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutoscalingStatus) DeepCopyInto(out *StorageAutoscalingStatus) {
+	*out = *in
+	if in.LastExpansionTime != nil {
+		in, out := &in.LastExpansionTime, &out.LastExpansionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutoscalingStatus.
+func (in *StorageAutoscalingStatus) DeepCopy() *StorageAutoscalingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(StmtSummary)
+	out := new(StorageAutoscalingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -5576,6 +6106,11 @@ func (in *StorageClaim) DeepCopyInto(out *StorageClaim) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -5589,6 +6124,34 @@ func (in *StorageClaim) DeepCopy() *StorageClaim {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassMigrationStatus) DeepCopyInto(out *StorageClassMigrationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMigrationStatus.
+func (in *StorageClassMigrationStatus) DeepCopy() *StorageClassMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageProvider) DeepCopyInto(out *StorageProvider) {
 	*out = *in
@@ -5600,12 +6163,12 @@ func (in *StorageProvider) DeepCopyInto(out *StorageProvider) {
 	if in.Gcs != nil {
 		in, out := &in.Gcs, &out.Gcs
 		*out = new(GcsStorageProvider)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Azblob != nil {
 		in, out := &in.Azblob, &out.Azblob
 		*out = new(AzblobStorageProvider)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Local != nil {
 		in, out := &in.Local, &out.Local
@@ -5633,6 +6196,11 @@ func (in *StorageVolume) DeepCopyInto(out *StorageVolume) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -5650,6 +6218,16 @@ func (in *StorageVolume) DeepCopy() *StorageVolume {
 func (in *StorageVolumeStatus) DeepCopyInto(out *StorageVolumeStatus) {
 	*out = *in
 	in.ObservedStorageVolumeStatus.DeepCopyInto(&out.ObservedStorageVolumeStatus)
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(StorageClassMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageAutoscaling != nil {
+		in, out := &in.StorageAutoscaling, &out.StorageAutoscaling
+		*out = new(StorageAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5679,9 +6257,34 @@ func (in *SuspendAction) DeepCopy() *SuspendAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSCertRotationStatus) DeepCopyInto(out *TLSCertRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCertRotationStatus.
+func (in *TLSCertRotationStatus) DeepCopy() *TLSCertRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSCertRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSCluster) DeepCopyInto(out *TLSCluster) {
 	*out = *in
+	if in.InternalCA != nil {
+		in, out := &in.InternalCA, &out.InternalCA
+		*out = new(InternalCA)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5840,6 +6443,11 @@ func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.GracefulShutdownTimeout != nil {
 		in, out := &in.GracefulShutdownTimeout, &out.GracefulShutdownTimeout
 		*out = new(metav1.Duration)
@@ -5895,6 +6503,11 @@ func (in *TiCDCStatus) DeepCopyInto(out *TiCDCStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5929,6 +6542,28 @@ func (in *TiDBAccessConfig) DeepCopy() *TiDBAccessConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBAuditLogSpec) DeepCopyInto(out *TiDBAuditLogSpec) {
+	*out = *in
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.Upload != nil {
+		in, out := &in.Upload, &out.Upload
+		*out = new(AuditLogUploadSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBAuditLogSpec.
+func (in *TiDBAuditLogSpec) DeepCopy() *TiDBAuditLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBAuditLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiDBConfig) DeepCopyInto(out *TiDBConfig) {
 	*out = *in
@@ -6209,6 +6844,27 @@ func (in *TiDBFailureMember) DeepCopy() *TiDBFailureMember {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBGrant) DeepCopyInto(out *TiDBGrant) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBGrant.
+func (in *TiDBGrant) DeepCopy() *TiDBGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiDBInitializer) DeepCopyInto(out *TiDBInitializer) {
 	*out = *in
@@ -6300,6 +6956,11 @@ func (in *TiDBSlowLogTailerSpec) DeepCopyInto(out *TiDBSlowLogTailerSpec) {
 		*out = new(v1.PullPolicy)
 		**out = **in
 	}
+	if in.Sink != nil {
+		in, out := &in.Sink, &out.Sink
+		*out = new(SlowLogSinkSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -6343,6 +7004,11 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBSlowLogTailerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(TiDBAuditLogSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TLSClient != nil {
 		in, out := &in.TLSClient, &out.TLSClient
 		*out = new(TiDBTLSClient)
@@ -6380,11 +7046,21 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.Initializer != nil {
 		in, out := &in.Initializer, &out.Initializer
 		*out = new(TiDBInitializer)
 		**out = **in
 	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = new(TiDBUsers)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BootstrapSQLConfigMapName != nil {
 		in, out := &in.BootstrapSQLConfigMapName, &out.BootstrapSQLConfigMapName
 		*out = new(string)
@@ -6463,6 +7139,18 @@ func (in *TiDBStatus) DeepCopyInto(out *TiDBStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make(map[string]TiDBUserStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -6492,6 +7180,86 @@ func (in *TiDBTLSClient) DeepCopy() *TiDBTLSClient {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBUser) DeepCopyInto(out *TiDBUser) {
+	*out = *in
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]TiDBGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBUser.
+func (in *TiDBUser) DeepCopy() *TiDBUser {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBUserStatus) DeepCopyInto(out *TiDBUserStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDriftTime != nil {
+		in, out := &in.LastDriftTime, &out.LastDriftTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBUserStatus.
+func (in *TiDBUserStatus) DeepCopy() *TiDBUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBUsers) DeepCopyInto(out *TiDBUsers) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]TiDBUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBUsers.
+func (in *TiDBUsers) DeepCopy() *TiDBUsers {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBUsers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiFlashCommonConfigWraper) DeepCopyInto(out *TiFlashCommonConfigWraper) {
 	*out = *in
@@ -6627,6 +7395,11 @@ func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 		**out = **in
 	}
 	in.ScalePolicy.DeepCopyInto(&out.ScalePolicy)
+	if in.StorageAutoscaling != nil {
+		in, out := &in.StorageAutoscaling, &out.StorageAutoscaling
+		*out = new(StorageAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -6698,6 +7471,11 @@ func (in *TiFlashStatus) DeepCopyInto(out *TiFlashStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -8480,6 +9258,11 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(TiKVConfigWraper)
@@ -8523,6 +9306,11 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.StorageAutoscaling != nil {
+		in, out := &in.StorageAutoscaling, &out.StorageAutoscaling
+		*out = new(StorageAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -8609,6 +9397,16 @@ func (in *TiKVStatus) DeepCopyInto(out *TiKVStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DynamicConfig != nil {
+		in, out := &in.DynamicConfig, &out.DynamicConfig
+		*out = new(DynamicConfigStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -8963,6 +9761,11 @@ func (in *TiProxySpec) DeepCopyInto(out *TiProxySpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeAttributesClassName != nil {
+		in, out := &in.VolumeAttributesClassName, &out.VolumeAttributesClassName
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -9013,6 +9816,11 @@ func (in *TiProxyStatus) DeepCopyInto(out *TiProxyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLSCertRotation != nil {
+		in, out := &in.TLSCertRotation, &out.TLSCertRotation
+		*out = new(TLSCertRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9367,7 +10175,7 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 	if in.TLSCluster != nil {
 		in, out := &in.TLSCluster, &out.TLSCluster
 		*out = new(TLSCluster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.HostNetwork != nil {
 		in, out := &in.HostNetwork, &out.HostNetwork
@@ -9459,6 +10267,16 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = make([]StartScriptV2FeatureFlag, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConfigDriftDetection != nil {
+		in, out := &in.ConfigDriftDetection, &out.ConfigDriftDetection
+		*out = new(ConfigDriftDetection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9502,6 +10320,16 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 		*out = new(TidbClusterAutoScalerRef)
 		**out = **in
 	}
+	if in.InternalCA != nil {
+		in, out := &in.InternalCA, &out.InternalCA
+		*out = new(InternalCAStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigDrift != nil {
+		in, out := &in.ConfigDrift, &out.ConfigDrift
+		*out = new(ConfigDriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]TidbClusterCondition, len(*in))
@@ -9583,6 +10411,70 @@ func (in *TidbDashboardList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbDashboardSQLUser) DeepCopyInto(out *TidbDashboardSQLUser) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbDashboardSQLUser.
+func (in *TidbDashboardSQLUser) DeepCopy() *TidbDashboardSQLUser {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbDashboardSQLUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbDashboardSSO) DeepCopyInto(out *TidbDashboardSSO) {
+	*out = *in
+	if in.Impersonations != nil {
+		in, out := &in.Impersonations, &out.Impersonations
+		*out = make([]TidbDashboardSQLUser, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbDashboardSSO.
+func (in *TidbDashboardSSO) DeepCopy() *TidbDashboardSSO {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbDashboardSSO)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbDashboardSSOStatus) DeepCopyInto(out *TidbDashboardSSOStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ImpersonationSecretVersions != nil {
+		in, out := &in.ImpersonationSecretVersions, &out.ImpersonationSecretVersions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbDashboardSSOStatus.
+func (in *TidbDashboardSSOStatus) DeepCopy() *TidbDashboardSSOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbDashboardSSOStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TidbDashboardSpec) DeepCopyInto(out *TidbDashboardSpec) {
 	*out = *in
@@ -9636,6 +10528,11 @@ func (in *TidbDashboardSpec) DeepCopyInto(out *TidbDashboardSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SSO != nil {
+		in, out := &in.SSO, &out.SSO
+		*out = new(TidbDashboardSSO)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9657,6 +10554,11 @@ func (in *TidbDashboardStatus) DeepCopyInto(out *TidbDashboardStatus) {
 		*out = new(appsv1.StatefulSetStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SSO != nil {
+		in, out := &in.SSO, &out.SSO
+		*out = new(TidbDashboardSSOStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -9770,6 +10672,11 @@ func (in *TidbInitializerSpec) DeepCopyInto(out *TidbInitializerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PasswordSecretSource != nil {
+		in, out := &in.PasswordSecretSource, &out.PasswordSecretSource
+		*out = new(CredentialSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(v1.ResourceRequirements)
@@ -10031,6 +10938,16 @@ func (in *TidbMonitorSpec) DeepCopyInto(out *TidbMonitorSpec) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PrometheusOperator != nil {
+		in, out := &in.PrometheusOperator, &out.PrometheusOperator
+		*out = new(PrometheusOperatorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GeneratedAlertRules != nil {
+		in, out := &in.GeneratedAlertRules, &out.GeneratedAlertRules
+		*out = new(GeneratedAlertRulesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -10057,6 +10974,20 @@ func (in *TidbMonitorStatus) DeepCopyInto(out *TidbMonitorStatus) {
 		*out = new(appsv1.StatefulSetStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GrafanaTenancyHashes != nil {
+		in, out := &in.GrafanaTenancyHashes, &out.GrafanaTenancyHashes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -0,0 +1,377 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"crypto/md5" // nolint: gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ManifestSuffix is the suffix of the manifest uploaded with each audit log file
+	ManifestSuffix = ".manifest.json"
+	// HeadFile is the file under the directory of the instance recording the manifest of the last uploaded file
+	HeadFile = "HEAD.json"
+	// chainFile is the file in the directory of the audit log recording the chain digest of the last uploaded file
+	chainFile = ".auditlog-uploader-chain"
+)
+
+// Manifest records the digest of an uploaded audit log file. The chain digest covers the digest of the file and the
+// chain digest of the previous file of the same instance, so that any modified, removed or reordered file breaks
+// the chain.
+type Manifest struct {
+	File       string    `json:"file"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	Previous   string    `json:"previous"`
+	Chain      string    `json:"chain"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// ChainDigest returns the chain digest of the file following the file with the previous chain digest
+func ChainDigest(previous, file, sha256Digest string) string {
+	sum := sha256.Sum256([]byte(previous + "\n" + file + "\n" + sha256Digest))
+	return hex.EncodeToString(sum[:])
+}
+
+// Uploader uploads the rotated audit log files to the bucket under the directory of the instance and removes them
+// locally once they are uploaded. The uploaded files older than the retention are deleted from the bucket.
+type Uploader struct {
+	// File is the path of the audit log
+	File string
+	// Instance is the name of the TiDB instance, the files are uploaded under the directory of it
+	Instance string
+	// OpenBucket opens the bucket, it's called for every round so that the rotated credentials are used
+	OpenBucket func() (*blob.Bucket, error)
+	// Retention is how long the uploaded files are retained, 0 means forever
+	Retention time.Duration
+	// ObjectLockMode is the S3 object lock mode of the uploaded files, which are locked until the end of the retention
+	ObjectLockMode string
+
+	now func() time.Time
+}
+
+// Run uploads the rotated files and deletes the expired ones every interval until ctx is done, and then flushes
+// the active file within the flush timeout
+func (u *Uploader) Run(ctx context.Context, interval, flushTimeout time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		bucket, err := u.OpenBucket()
+		if err != nil {
+			klog.Errorf("failed to open the bucket: %v", err)
+			return
+		}
+		defer bucket.Close()
+		if err := u.UploadRotated(ctx, bucket); err != nil {
+			klog.Errorf("failed to upload the rotated audit log files: %v", err)
+		}
+		if err := u.DeleteExpired(ctx, bucket); err != nil {
+			klog.Errorf("failed to delete the expired audit log files: %v", err)
+		}
+	}, interval)
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	bucket, err := u.OpenBucket()
+	if err != nil {
+		klog.Errorf("failed to open the bucket: %v", err)
+		return
+	}
+	defer bucket.Close()
+	if err := u.Flush(flushCtx, bucket); err != nil {
+		klog.Errorf("failed to flush the active audit log file: %v", err)
+	}
+}
+
+// Flush uploads the content of the active file written so far as a rotated file, it's called when the uploader is
+// terminated, such as the pod is deleted. The active file is snapshotted to a rotated file first, so the snapshot
+// is uploaded by the next run if it fails. The flushed content may be uploaded again in the next rotated file if
+// only the container is restarted and the active file is kept.
+func (u *Uploader) Flush(ctx context.Context, bucket *blob.Bucket) error {
+	dir, name := filepath.Split(u.File)
+	ext := filepath.Ext(name)
+	snapshot := filepath.Join(dir, fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), u.getNow().UTC().Format(flushTimeFormat), ext))
+	if err := copyFile(u.File, snapshot); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return u.UploadRotated(ctx, bucket)
+}
+
+// flushTimeFormat is the time format of the name of the snapshot of the active file, which is the same as the
+// rotated files of TiDB
+const flushTimeFormat = "2006-01-02T15-04-05.000"
+
+// copyFile copies the content of the file written so far to the destination
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	// the lines appended by TiDB during the copy are left to the next snapshot or rotated file
+	if _, err := io.CopyN(out, in, info.Size()); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// RotatedFiles returns the rotated files of the audit log from the oldest to the newest. The rotated files are
+// named with the name of the audit log without the extension and a dash as the prefix, such as
+// `tidb-audit-2024-01-01T00-00-00.000.log`, or with the name of the audit log and a dot, such as `tidb-audit.log.1`.
+func (u *Uploader) RotatedFiles() ([]string, error) {
+	dir, name := filepath.Split(u.File)
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type rotated struct {
+		name    string
+		modTime time.Time
+	}
+	var files []rotated
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == name {
+			continue
+		}
+		if !strings.HasPrefix(entry.Name(), stem+"-") && !strings.HasPrefix(entry.Name(), name+".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		files = append(files, rotated{name: entry.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].modTime.Equal(files[j].modTime) {
+			return files[i].name < files[j].name
+		}
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, filepath.Join(dir, f.name))
+	}
+	return paths, nil
+}
+
+// UploadRotated uploads the rotated files with their manifests in order and removes them once uploaded
+func (u *Uploader) UploadRotated(ctx context.Context, bucket *blob.Bucket) error {
+	files, err := u.RotatedFiles()
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	previous, err := u.lastChain(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		m, err := u.upload(ctx, bucket, file, previous)
+		if err != nil {
+			return fmt.Errorf("upload %s failed: %v", file, err)
+		}
+		if err := u.writeHead(ctx, bucket, m); err != nil {
+			return fmt.Errorf("update the head to %s failed: %v", file, err)
+		}
+		if err := os.WriteFile(filepath.Join(filepath.Dir(u.File), chainFile), []byte(m.Chain), 0600); err != nil {
+			return err
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+		previous = m.Chain
+		klog.Infof("audit log %s is uploaded, sha256: %s, chain: %s", file, m.SHA256, m.Chain)
+	}
+	return nil
+}
+
+// lastChain returns the chain digest of the last uploaded file. It's recovered from the head in the bucket if the
+// local record is lost, such as the emptyDir volume of the audit log is recreated.
+func (u *Uploader) lastChain(ctx context.Context, bucket *blob.Bucket) (string, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(u.File), chainFile))
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	head, err := readHead(ctx, bucket, u.Instance)
+	if err != nil || head == nil {
+		return "", err
+	}
+	return head.Chain, nil
+}
+
+// readHead returns the manifest of the last uploaded file of the instance, it returns nil if nothing is uploaded
+func readHead(ctx context.Context, bucket *blob.Bucket, instance string) (*Manifest, error) {
+	data, err := bucket.ReadAll(ctx, instance+"/"+HeadFile)
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid head of %s: %v", instance, err)
+	}
+	return m, nil
+}
+
+// writeHead points the head to the manifest of the last uploaded file. The head is overwritten, so it's not locked,
+// the previous versions are still retained if the object lock is enabled with the versioning of the bucket.
+func (u *Uploader) writeHead(ctx context.Context, bucket *blob.Bucket, m *Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	headMD5 := md5.Sum(data) // nolint: gosec
+	return bucket.WriteAll(ctx, u.Instance+"/"+HeadFile, data, &blob.WriterOptions{ContentMD5: headMD5[:]})
+}
+
+func (u *Uploader) upload(ctx context.Context, bucket *blob.Bucket, file, previous string) (*Manifest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sha256Hash, md5Hash := sha256.New(), md5.New() // nolint: gosec
+	size, err := io.Copy(io.MultiWriter(sha256Hash, md5Hash), f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(file)
+	digest := hex.EncodeToString(sha256Hash.Sum(nil))
+	m := &Manifest{
+		File:       name,
+		Size:       size,
+		SHA256:     digest,
+		Previous:   previous,
+		Chain:      ChainDigest(previous, name, digest),
+		UploadedAt: u.getNow().UTC(),
+	}
+
+	key := u.Instance + "/" + name
+	w, err := bucket.NewWriter(ctx, key, u.writerOptions(md5Hash.Sum(nil), m.UploadedAt))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	// the manifest is uploaded after the file, so a file without the manifest is uploaded again in the next round
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	manifestMD5 := md5.Sum(data) // nolint: gosec
+	if err := bucket.WriteAll(ctx, key+ManifestSuffix, data, u.writerOptions(manifestMD5[:], m.UploadedAt)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (u *Uploader) writerOptions(contentMD5 []byte, uploadedAt time.Time) *blob.WriterOptions {
+	opts := &blob.WriterOptions{ContentMD5: contentMD5}
+	if u.ObjectLockMode == "" || u.Retention == 0 {
+		return opts
+	}
+	opts.BeforeWrite = func(as func(interface{}) bool) error {
+		var req *s3manager.UploadInput
+		if !as(&req) {
+			return fmt.Errorf("object lock is only supported by s3")
+		}
+		req.ObjectLockMode = aws.String(u.ObjectLockMode)
+		req.ObjectLockRetainUntilDate = aws.Time(uploadedAt.Add(u.Retention))
+		return nil
+	}
+	return opts
+}
+
+// DeleteExpired deletes the uploaded files of the instance older than the retention, the head is kept
+func (u *Uploader) DeleteExpired(ctx context.Context, bucket *blob.Bucket) error {
+	if u.Retention == 0 {
+		return nil
+	}
+	expired := u.getNow().Add(-u.Retention)
+	iter := bucket.List(&blob.ListOptions{Prefix: u.Instance + "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if obj.IsDir || obj.Key == u.Instance+"/"+HeadFile || !obj.ModTime.Before(expired) {
+			continue
+		}
+		if err := bucket.Delete(ctx, obj.Key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return fmt.Errorf("delete %s failed: %v", obj.Key, err)
+		}
+		klog.Infof("expired audit log %s is deleted", obj.Key)
+	}
+}
+
+func (u *Uploader) getNow() time.Time {
+	if u.now != nil {
+		return u.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+)
+
+func TestUploader(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	logDir, bucketDir := t.TempDir(), t.TempDir()
+	writeFile := func(name, content string, modTime time.Time) {
+		file := filepath.Join(logDir, name)
+		g.Expect(os.WriteFile(file, []byte(content), 0600)).To(Succeed())
+		g.Expect(os.Chtimes(file, modTime, modTime)).To(Succeed())
+	}
+	now := time.Now()
+	writeFile("tidb-audit.log", "current", now)
+	writeFile("tidb-audit-2024-01-02T00-00-00.000.log", "second", now.Add(-time.Hour))
+	writeFile("tidb-audit-2024-01-01T00-00-00.000.log", "first", now.Add(-2*time.Hour))
+	writeFile("tidb-audit.log.1", "third", now.Add(-time.Minute))
+	writeFile("tidb-slow.log", "slow", now.Add(-time.Hour))
+
+	bucket, err := fileblob.OpenBucket(bucketDir, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer bucket.Close()
+	u := &Uploader{File: filepath.Join(logDir, "tidb-audit.log"), Instance: "basic-tidb-0"}
+
+	files, err := u.RotatedFiles()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(Equal([]string{
+		filepath.Join(logDir, "tidb-audit-2024-01-01T00-00-00.000.log"),
+		filepath.Join(logDir, "tidb-audit-2024-01-02T00-00-00.000.log"),
+		filepath.Join(logDir, "tidb-audit.log.1"),
+	}))
+
+	g.Expect(u.UploadRotated(ctx, bucket)).To(Succeed())
+	readManifest := func(name string) *Manifest {
+		data, err := bucket.ReadAll(ctx, "basic-tidb-0/"+name+ManifestSuffix)
+		g.Expect(err).NotTo(HaveOccurred())
+		m := &Manifest{}
+		g.Expect(json.Unmarshal(data, m)).To(Succeed())
+		return m
+	}
+	// the manifests are chained in the order of rotation
+	previous := ""
+	for _, f := range []struct{ name, content string }{
+		{"tidb-audit-2024-01-01T00-00-00.000.log", "first"},
+		{"tidb-audit-2024-01-02T00-00-00.000.log", "second"},
+		{"tidb-audit.log.1", "third"},
+	} {
+		data, err := bucket.ReadAll(ctx, "basic-tidb-0/"+f.name)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(data)).To(Equal(f.content))
+
+		sum := sha256.Sum256([]byte(f.content))
+		m := readManifest(f.name)
+		g.Expect(m.SHA256).To(Equal(hex.EncodeToString(sum[:])))
+		g.Expect(m.Size).To(Equal(int64(len(f.content))))
+		g.Expect(m.Previous).To(Equal(previous))
+		g.Expect(m.Chain).To(Equal(ChainDigest(previous, f.name, m.SHA256)))
+		previous = m.Chain
+
+		_, err = os.Stat(filepath.Join(logDir, f.name))
+		g.Expect(os.IsNotExist(err)).To(BeTrue())
+	}
+	_, err = os.Stat(filepath.Join(logDir, "tidb-audit.log"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	head, err := readHead(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(head.File).To(Equal("tidb-audit.log.1"))
+	g.Expect(head.Chain).To(Equal(previous))
+
+	// the chain is recovered from the head in the bucket if the local record is lost
+	g.Expect(os.Remove(filepath.Join(logDir, chainFile))).To(Succeed())
+	writeFile("tidb-audit.log.2", "fourth", now)
+	g.Expect(u.UploadRotated(ctx, bucket)).To(Succeed())
+	g.Expect(readManifest("tidb-audit.log.2").Previous).To(Equal(previous))
+	chain, err := Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(chain).To(HaveLen(4))
+
+	// nothing is deleted without the retention
+	g.Expect(u.DeleteExpired(ctx, bucket)).To(Succeed())
+	exists, err := bucket.Exists(ctx, "basic-tidb-0/tidb-audit.log.1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exists).To(BeTrue())
+
+	u.Retention = time.Hour
+	u.now = func() time.Time { return now.Add(2 * time.Hour) }
+	g.Expect(u.DeleteExpired(ctx, bucket)).To(Succeed())
+	iter := bucket.List(&blob.ListOptions{Prefix: "basic-tidb-0/"})
+	obj, err := iter.Next(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(obj.Key).To(Equal("basic-tidb-0/" + HeadFile))
+	_, err = iter.Next(ctx)
+	g.Expect(err).To(Equal(io.EOF))
+}
+
+func TestUploaderFlush(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	logDir, bucketDir := t.TempDir(), t.TempDir()
+	bucket, err := fileblob.OpenBucket(bucketDir, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer bucket.Close()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := &Uploader{File: filepath.Join(logDir, "tidb-audit.log"), Instance: "basic-tidb-0", now: func() time.Time { return now }}
+
+	// nothing is uploaded if the active file is empty
+	g.Expect(os.WriteFile(u.File, nil, 0600)).To(Succeed())
+	g.Expect(u.Flush(ctx, bucket)).To(Succeed())
+	chain, err := Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(chain).To(BeEmpty())
+
+	// the content of the active file is uploaded as a rotated file and the active file is kept
+	g.Expect(os.WriteFile(u.File, []byte("active"), 0600)).To(Succeed())
+	g.Expect(u.Flush(ctx, bucket)).To(Succeed())
+	data, err := bucket.ReadAll(ctx, "basic-tidb-0/tidb-audit-2024-01-01T00-00-00.000.log")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal("active"))
+	data, err = os.ReadFile(u.File)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal("active"))
+	files, err := u.RotatedFiles()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(BeEmpty())
+	chain, err = Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(chain).To(HaveLen(1))
+}
+
+func TestVerify(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	upload := func() (*Uploader, *blob.Bucket) {
+		logDir := t.TempDir()
+		bucket, err := fileblob.OpenBucket(t.TempDir(), nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		t.Cleanup(func() { bucket.Close() })
+		u := &Uploader{File: filepath.Join(logDir, "tidb-audit.log"), Instance: "basic-tidb-0"}
+		now := time.Now()
+		for i, content := range []string{"first", "second", "third"} {
+			file := filepath.Join(logDir, fmt.Sprintf("tidb-audit.log.%d", i+1))
+			g.Expect(os.WriteFile(file, []byte(content), 0600)).To(Succeed())
+			modTime := now.Add(time.Duration(i-3) * time.Minute)
+			g.Expect(os.Chtimes(file, modTime, modTime)).To(Succeed())
+		}
+		g.Expect(u.UploadRotated(ctx, bucket)).To(Succeed())
+		return u, bucket
+	}
+
+	_, bucket := upload()
+	chain, err := Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(chain).To(HaveLen(3))
+	g.Expect(chain[0].File).To(Equal("tidb-audit.log.1"))
+	g.Expect(chain[2].File).To(Equal("tidb-audit.log.3"))
+
+	// the oldest files may be deleted by the retention
+	g.Expect(bucket.Delete(ctx, "basic-tidb-0/tidb-audit.log.1")).To(Succeed())
+	g.Expect(bucket.Delete(ctx, "basic-tidb-0/tidb-audit.log.1"+ManifestSuffix)).To(Succeed())
+	chain, err = Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(chain).To(HaveLen(2))
+
+	// the modified file is detected
+	_, bucket = upload()
+	g.Expect(bucket.WriteAll(ctx, "basic-tidb-0/tidb-audit.log.2", []byte("modified"), nil)).To(Succeed())
+	_, err = Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).To(MatchError(ContainSubstring("the content of tidb-audit.log.2 is modified")))
+
+	// the removed file in the middle of the chain is detected
+	_, bucket = upload()
+	g.Expect(bucket.Delete(ctx, "basic-tidb-0/tidb-audit.log.2")).To(Succeed())
+	g.Expect(bucket.Delete(ctx, "basic-tidb-0/tidb-audit.log.2"+ManifestSuffix)).To(Succeed())
+	_, err = Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).To(MatchError(ContainSubstring("the files [tidb-audit.log.1] of basic-tidb-0 are not on the chain")))
+
+	// the removed last file is detected
+	_, bucket = upload()
+	g.Expect(bucket.Delete(ctx, "basic-tidb-0/tidb-audit.log.3"+ManifestSuffix)).To(Succeed())
+	_, err = Verify(ctx, bucket, "basic-tidb-0")
+	g.Expect(err).To(MatchError(ContainSubstring("the manifest of the head tidb-audit.log.3 of basic-tidb-0 is missing")))
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gocloud.dev/blob"
+)
+
+// Verify verifies the chain of the uploaded files of the instance from the head to the oldest one, and returns
+// the manifests on the chain from the oldest to the newest. It fails if any file on the chain is modified or
+// removed, or any uploaded file is not on the chain. The previous files of the oldest one may have been deleted
+// by the retention, so the chain is allowed to start with a previous chain digest.
+func Verify(ctx context.Context, bucket *blob.Bucket, instance string) ([]*Manifest, error) {
+	manifests, err := listManifests(ctx, bucket, instance)
+	if err != nil {
+		return nil, err
+	}
+	head, err := readHead(ctx, bucket, instance)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		if len(manifests) > 0 {
+			return nil, fmt.Errorf("the head of %s is missing, but %d files are uploaded", instance, len(manifests))
+		}
+		return nil, nil
+	}
+
+	var chain []*Manifest
+	for digest := head.Chain; digest != ""; {
+		m, ok := manifests[digest]
+		if !ok {
+			if digest == head.Chain {
+				return nil, fmt.Errorf("the manifest of the head %s of %s is missing", head.File, instance)
+			}
+			break
+		}
+		if err := verifyFile(ctx, bucket, instance, m); err != nil {
+			return nil, err
+		}
+		chain = append(chain, m)
+		delete(manifests, digest)
+		digest = m.Previous
+	}
+	if len(manifests) > 0 {
+		files := make([]string, 0, len(manifests))
+		for _, m := range manifests {
+			files = append(files, m.File)
+		}
+		sort.Strings(files)
+		return nil, fmt.Errorf("the files %v of %s are not on the chain", files, instance)
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// listManifests returns the manifests of the uploaded files of the instance by their chain digests
+func listManifests(ctx context.Context, bucket *blob.Bucket, instance string) (map[string]*Manifest, error) {
+	manifests := map[string]*Manifest{}
+	iter := bucket.List(&blob.ListOptions{Prefix: instance + "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(obj.Key, ManifestSuffix) {
+			continue
+		}
+		data, err := bucket.ReadAll(ctx, obj.Key)
+		if err != nil {
+			return nil, err
+		}
+		m := &Manifest{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %v", obj.Key, err)
+		}
+		manifests[m.Chain] = m
+	}
+}
+
+// verifyFile verifies the chain digest of the manifest and the digest of the uploaded file
+func verifyFile(ctx context.Context, bucket *blob.Bucket, instance string, m *Manifest) error {
+	if m.Chain != ChainDigest(m.Previous, m.File, m.SHA256) {
+		return fmt.Errorf("the chain digest of %s is modified", m.File)
+	}
+	r, err := bucket.NewReader(ctx, instance+"/"+m.File, nil)
+	if err != nil {
+		return fmt.Errorf("read %s failed: %v", m.File, err)
+	}
+	defer r.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return fmt.Errorf("read %s failed: %v", m.File, err)
+	}
+	if size != m.Size || hex.EncodeToString(h.Sum(nil)) != m.SHA256 {
+		return fmt.Errorf("the content of %s is modified", m.File)
+	}
+	return nil
+}
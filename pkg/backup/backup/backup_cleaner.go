@@ -240,6 +240,9 @@ func (bc *backupCleaner) makeCleanJob(backup *v1alpha1.Backup) (*batchv1.Job, st
 	if len(backup.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, backup.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(backup.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	serviceAccount := constants.DefaultServiceAccountName
 	if backup.Spec.ServiceAccount != "" {
@@ -416,6 +419,9 @@ func (bc *backupCleaner) makeStopLogBackupJob(backup *v1alpha1.Backup) (*batchv1
 	if len(backup.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, backup.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(backup.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	// mount volumes if specified
 	if backup.Spec.Local != nil {
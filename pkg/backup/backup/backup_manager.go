@@ -355,7 +355,10 @@ func (bm *backupManager) checkVolumeBackupSnapshotsCreated(b *v1alpha1.Backup) e
 
 	ns := b.GetNamespace()
 	name := b.GetName()
-	cred := backuputil.GetStorageCredential(b.Namespace, b.Spec.StorageProvider, bm.deps.SecretLister)
+	cred, err := backuputil.GetStorageCredential(b.Namespace, b.Spec.StorageProvider, bm.deps.SecretLister)
+	if err != nil {
+		return fmt.Errorf("get storage credential error: %s", err.Error())
+	}
 	externalStorage, err := backuputil.NewStorageBackend(b.Spec.StorageProvider, cred)
 	if err != nil {
 		return fmt.Errorf("create storage backend error: %s", err.Error())
@@ -534,6 +537,9 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	if len(backup.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, backup.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(backup.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	if backup.Spec.From.TLSClientSecretName != nil {
 		args = append(args, "--client-tls=true")
@@ -794,6 +800,9 @@ func (bm *backupManager) makeBRBackupJob(backup *v1alpha1.Backup) (*batchv1.Job,
 	if len(backup.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, backup.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(backup.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	// mount volumes if specified
 	if backup.Spec.Local != nil {
@@ -908,7 +917,10 @@ func (bm *backupManager) saveClusterMetaToExternalStorage(b *v1alpha1.Backup, cs
 
 	// write a file into external storage
 	klog.Infof("save the cluster meta to external storage")
-	cred := backuputil.GetStorageCredential(b.Namespace, b.Spec.StorageProvider, bm.deps.SecretLister)
+	cred, err := backuputil.GetStorageCredential(b.Namespace, b.Spec.StorageProvider, bm.deps.SecretLister)
+	if err != nil {
+		return "GetStorageCredentialFailed", err
+	}
 	externalStorage, err := backuputil.NewStorageBackend(b.Spec.StorageProvider, cred)
 	if err != nil {
 		return "NewStorageBackendFailed", err
@@ -952,7 +964,10 @@ func (bm *backupManager) volumeSnapshotBackup(b *v1alpha1.Backup, tc *v1alpha1.T
 }
 
 func (bm *backupManager) backupManifests(b *v1alpha1.Backup, tc *v1alpha1.TidbCluster) error {
-	cred := backuputil.GetStorageCredential(b.Namespace, b.Spec.StorageProvider, bm.deps.SecretLister)
+	cred, err := backuputil.GetStorageCredential(b.Namespace, b.Spec.StorageProvider, bm.deps.SecretLister)
+	if err != nil {
+		return err
+	}
 	externalStorage, err := backuputil.NewStorageBackend(b.Spec.StorageProvider, cred)
 	if err != nil {
 		return err
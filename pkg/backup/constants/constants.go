@@ -59,6 +59,16 @@ const (
 	// BR certificate storage path
 	BRCertPath = "/var/lib/br-tls"
 
+	// StorageCredentialsVolumeName is the name of the volume of the storage credentials from the CSI driver
+	StorageCredentialsVolumeName = "storage-credentials"
+
+	// StorageCredentialsPath is where the storage credentials from the CSI driver are mounted
+	StorageCredentialsPath = "/var/lib/storage-credentials"
+
+	// StorageCredentialsDirEnv is the environment variable of the directory of the storage credential files,
+	// which are loaded into the environment variables by tidb-backup-manager
+	StorageCredentialsDirEnv = "STORAGE_CREDENTIALS_DIR"
+
 	// ServiceAccountCAPath is where is CABundle of serviceaccount locates
 	ServiceAccountCAPath = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
 
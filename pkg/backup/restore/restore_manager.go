@@ -270,7 +270,10 @@ func (rm *restoreManager) readRestoreMetaFromExternalStorage(r *v1alpha1.Restore
 
 	// read restore meta from output of BR 1st restore
 	klog.Infof("read the restore meta from external storage")
-	cred := backuputil.GetStorageCredential(r.Namespace, r.Spec.StorageProvider, rm.deps.SecretLister)
+	cred, err := backuputil.GetStorageCredential(r.Namespace, r.Spec.StorageProvider, rm.deps.SecretLister)
+	if err != nil {
+		return nil, "GetStorageCredentialFailed", err
+	}
 	externalStorage, err := backuputil.NewStorageBackend(r.Spec.StorageProvider, cred)
 	if err != nil {
 		return nil, "NewStorageBackendFailed", err
@@ -606,6 +609,9 @@ func (rm *restoreManager) makeImportJob(restore *v1alpha1.Restore) (*batchv1.Job
 	if len(restore.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, restore.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(restore.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	if restore.Spec.To.TLSClientSecretName != nil {
 		args = append(args, "--client-tls=true")
@@ -849,6 +855,9 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 	if len(restore.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, restore.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(restore.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	// mount volumes if specified
 	if restore.Spec.Local != nil {
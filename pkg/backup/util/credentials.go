@@ -0,0 +1,188 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// CredentialsReloadInterval is the interval to reload the storage credential files
+	CredentialsReloadInterval = time.Minute
+	// StorageCredentialsCommand is the command of backup manager which prints the S3 credentials in the
+	// credential files, it is set as the `credential_process` of the AWS shared config
+	StorageCredentialsCommand = "storage-credentials"
+	// s3SessionTokenFile is the optional credential file of the S3 session token
+	s3SessionTokenFile = "session_token"
+)
+
+// credentialEnvs maps the storage credential files, which are named by the keys of the storage secret,
+// to the env that the backup tools read. The azblob files are named by the env, so they are loaded as is.
+// The S3 credentials are not loaded into the env, they are read by the `credential_process` of the AWS shared
+// config instead, so that the running commands also refresh them.
+var credentialEnvs = map[string]string{
+	constants.GcsCredentialsKey: "GCS_SERVICE_ACCOUNT_JSON_KEY",
+}
+
+// credentialEnvName returns the env of the credential file, it returns empty if the file is not a credential
+func credentialEnvName(file string) string {
+	if env, ok := credentialEnvs[file]; ok {
+		return env
+	}
+	if strings.HasPrefix(file, "AZURE_") {
+		return file
+	}
+	return ""
+}
+
+// processCredentials is the output of the `credential_process` of the AWS shared config
+type processCredentials struct {
+	Version         int
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string
+	SessionToken    string `json:",omitempty"`
+	Expiration      string
+}
+
+// LoadStorageCredentials loads the storage credential files in the directory set by the env
+// `STORAGE_CREDENTIALS_DIR`. The S3 credentials are provided by the `credential_process` of the AWS shared
+// config, which expires them after the reload interval, so that br and rclone refresh the rotated credentials
+// even if they are running. The other credentials are loaded into the env and reloaded periodically until
+// the context is done, so only the commands started later use the rotated ones.
+func LoadStorageCredentials(ctx context.Context, interval time.Duration) error {
+	dir := os.Getenv(constants.StorageCredentialsDirEnv)
+	if dir == "" {
+		return nil
+	}
+	if err := setAWSCredentialProcess(dir); err != nil {
+		return fmt.Errorf("set credential process of %s failed, err: %v", dir, err)
+	}
+	if _, err := loadCredentialFiles(dir); err != nil {
+		return fmt.Errorf("load storage credentials from %s failed, err: %v", dir, err)
+	}
+	klog.Infof("Storage credentials are loaded from %s", dir)
+
+	go wait.Until(func() {
+		changed, err := loadCredentialFiles(dir)
+		if err != nil {
+			klog.Warningf("Reload storage credentials from %s failed, err: %v", dir, err)
+			return
+		}
+		if len(changed) > 0 {
+			klog.Infof("Storage credentials %v are rotated", changed)
+		}
+	}, interval, ctx.Done())
+	return nil
+}
+
+// setAWSCredentialProcess writes an AWS shared config whose `credential_process` is the storage credentials
+// command of the current executable if the S3 credential files exist in the directory
+func setAWSCredentialProcess(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, constants.S3AccessKey)); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	configDir, err := os.MkdirTemp("", "aws")
+	if err != nil {
+		return err
+	}
+	config := filepath.Join(configDir, "config")
+	content := fmt.Sprintf("[default]\ncredential_process = %s %s\n", executable, StorageCredentialsCommand)
+	if err := os.WriteFile(config, []byte(content), 0600); err != nil {
+		return err
+	}
+	if err := os.Setenv("AWS_CONFIG_FILE", config); err != nil {
+		return err
+	}
+	return os.Setenv("AWS_SDK_LOAD_CONFIG", "1")
+}
+
+// ReadS3Credentials reads the S3 credential files in the directory set by the env `STORAGE_CREDENTIALS_DIR`,
+// and returns them in the output format of the `credential_process`, they expire after the reload interval
+func ReadS3Credentials(interval time.Duration) ([]byte, error) {
+	dir := os.Getenv(constants.StorageCredentialsDirEnv)
+	if dir == "" {
+		return nil, fmt.Errorf("env %s is not set", constants.StorageCredentialsDirEnv)
+	}
+	read := func(file string, optional bool) (string, error) {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			if optional && os.IsNotExist(err) {
+				return "", nil
+			}
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	cred := processCredentials{
+		Version:    1,
+		Expiration: time.Now().Add(interval).UTC().Format(time.RFC3339),
+	}
+	var err error
+	if cred.AccessKeyID, err = read(constants.S3AccessKey, false); err != nil {
+		return nil, err
+	}
+	if cred.SecretAccessKey, err = read(constants.S3SecretKey, false); err != nil {
+		return nil, err
+	}
+	if cred.SessionToken, err = read(s3SessionTokenFile, true); err != nil {
+		return nil, err
+	}
+	return json.Marshal(cred)
+}
+
+// loadCredentialFiles sets the env of the credential files in the directory, it returns the changed env
+func loadCredentialFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, entry := range entries {
+		// the files of the secret volumes are symlinks, so the mode of the entry is not checked
+		env := credentialEnvName(entry.Name())
+		if env == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		value := strings.TrimSpace(string(data))
+		if old, ok := os.LookupEnv(env); ok && old == value {
+			continue
+		}
+		if err := os.Setenv(env, value); err != nil {
+			return nil, err
+		}
+		changed = append(changed, env)
+	}
+	return changed, nil
+}
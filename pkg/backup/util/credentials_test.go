@@ -0,0 +1,125 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+)
+
+func TestLoadStorageCredentials(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		g.Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)).To(Succeed())
+	}
+	writeFile(constants.S3AccessKey, "ak\n")
+	writeFile(constants.S3SecretKey, "sk")
+	writeFile(constants.AzblobAccountName, "account")
+	writeFile("unknown", "value")
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_CONFIG_FILE", "AWS_SDK_LOAD_CONFIG", "AZURE_STORAGE_ACCOUNT", "unknown"} {
+		t.Setenv(env, "")
+	}
+
+	// nothing is loaded if the directory is not set
+	t.Setenv(constants.StorageCredentialsDirEnv, "")
+	g.Expect(LoadStorageCredentials(context.Background(), time.Hour)).To(Succeed())
+	g.Expect(os.Getenv("AWS_CONFIG_FILE")).To(BeEmpty())
+
+	t.Setenv(constants.StorageCredentialsDirEnv, filepath.Join(dir, "not-exist"))
+	g.Expect(LoadStorageCredentials(context.Background(), time.Hour)).NotTo(Succeed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	t.Setenv(constants.StorageCredentialsDirEnv, dir)
+	g.Expect(LoadStorageCredentials(ctx, 10*time.Millisecond)).To(Succeed())
+	g.Expect(os.Getenv("AZURE_STORAGE_ACCOUNT")).To(Equal("account"))
+	g.Expect(os.Getenv("unknown")).To(BeEmpty())
+
+	// the S3 credentials are provided by the credential process instead of the env
+	g.Expect(os.Getenv("AWS_ACCESS_KEY_ID")).To(BeEmpty())
+	g.Expect(os.Getenv("AWS_SDK_LOAD_CONFIG")).To(Equal("1"))
+	config, err := os.ReadFile(os.Getenv("AWS_CONFIG_FILE"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(config)).To(HavePrefix("[default]\ncredential_process = "))
+	g.Expect(string(config)).To(HaveSuffix(" " + StorageCredentialsCommand + "\n"))
+
+	// the rotated credentials are reloaded
+	writeFile(constants.AzblobAccountName, "account2")
+	g.Eventually(func() string { return os.Getenv("AZURE_STORAGE_ACCOUNT") }, time.Second).Should(Equal("account2"))
+}
+
+func TestReadS3Credentials(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		g.Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)).To(Succeed())
+	}
+	t.Setenv(constants.StorageCredentialsDirEnv, dir)
+
+	_, err := ReadS3Credentials(time.Minute)
+	g.Expect(err).To(HaveOccurred())
+
+	writeFile(constants.S3AccessKey, "ak\n")
+	writeFile(constants.S3SecretKey, "sk")
+	read := func() processCredentials {
+		output, err := ReadS3Credentials(time.Minute)
+		g.Expect(err).NotTo(HaveOccurred())
+		cred := processCredentials{}
+		g.Expect(json.Unmarshal(output, &cred)).To(Succeed())
+		return cred
+	}
+	cred := read()
+	g.Expect(cred.Version).To(Equal(1))
+	g.Expect(cred.AccessKeyID).To(Equal("ak"))
+	g.Expect(cred.SecretAccessKey).To(Equal("sk"))
+	g.Expect(cred.SessionToken).To(BeEmpty())
+	expiration, err := time.Parse(time.RFC3339, cred.Expiration)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiration).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+
+	// the rotated credentials are read after the expiration
+	writeFile(constants.S3SecretKey, "sk2")
+	writeFile(s3SessionTokenFile, "token")
+	cred = read()
+	g.Expect(cred.SecretAccessKey).To(Equal("sk2"))
+	g.Expect(cred.SessionToken).To(Equal("token"))
+}
+
+func TestLoadCredentialFiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, constants.GcsCredentialsKey), []byte(`{"type": "service_account"}`), 0600)).To(Succeed())
+	t.Setenv("GCS_SERVICE_ACCOUNT_JSON_KEY", "")
+
+	changed, err := loadCredentialFiles(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(ConsistOf("GCS_SERVICE_ACCOUNT_JSON_KEY"))
+	g.Expect(os.Getenv("GCS_SERVICE_ACCOUNT_JSON_KEY")).To(Equal(`{"type": "service_account"}`))
+
+	// the unchanged credentials are not reported
+	changed, err = loadCredentialFiles(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeEmpty())
+}
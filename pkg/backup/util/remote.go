@@ -328,7 +328,13 @@ func BatchDeleteObjectsConcurrently(ctx context.Context, bucket *blob.Bucket, ob
 	return result
 }
 
-func GetStorageCredential(ns string, provider v1alpha1.StorageProvider, secretLister corelisterv1.SecretLister) *StorageCredential {
+// GetStorageCredential returns the credential of the storage used by the operator, it returns an error if the
+// credentials are provided by the external secret source, which are only mounted to the backup pods
+func GetStorageCredential(ns string, provider v1alpha1.StorageProvider, secretLister corelisterv1.SecretLister) (*StorageCredential, error) {
+	if GetStorageCredentialSource(provider) != nil {
+		return nil, fmt.Errorf("the storage credentials from the secret source are not accessible by the operator")
+	}
+
 	var err error
 	var secret *corev1.Secret
 	storageType := GetStorageType(provider)
@@ -340,7 +346,7 @@ func GetStorageCredential(ns string, provider v1alpha1.StorageProvider, secretLi
 			secret, err = secretLister.Secrets(ns).Get(s3SecretName)
 			if err != nil {
 				klog.Errorf("Get the secret key failed.")
-				return &StorageCredential{}
+				return &StorageCredential{}, nil
 			}
 
 			s3AccessKey := string(secret.Data[constants.S3AccessKey])
@@ -350,18 +356,18 @@ func GetStorageCredential(ns string, provider v1alpha1.StorageProvider, secretLi
 				cred := credentials.NewStaticCredentials(s3AccessKey, s3SecretKey, "")
 				return &StorageCredential{
 					cred,
-				}
+				}, nil
 			}
 
 		}
 	//TODO: will support gcs
 	case v1alpha1.BackupStorageTypeGcs:
-		return &StorageCredential{}
+		return &StorageCredential{}, nil
 	default:
-		return &StorageCredential{}
+		return &StorageCredential{}, nil
 	}
 
-	return &StorageCredential{}
+	return &StorageCredential{}, nil
 }
 
 // genStorageArgs returns the arg for --flag option and the remote/local path for br, default flag is storage.
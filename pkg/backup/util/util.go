@@ -116,7 +116,9 @@ func generateS3CertEnvVar(s3 *v1alpha1.S3StorageProvider, useKMS bool) ([]corev1
 		}...)
 	}
 
-	if s3.SecretName != "" {
+	if s3.SecretSource != nil {
+		envVars = append(envVars, generateStorageCredentialsDirEnv(s3.SecretSource))
+	} else if s3.SecretName != "" {
 		envVars = append(envVars, []corev1.EnvVar{
 			{
 				Name: "AWS_ACCESS_KEY_ID",
@@ -169,7 +171,9 @@ func generateGcsCertEnvVar(gcs *v1alpha1.GcsStorageProvider) ([]corev1.EnvVar, s
 			Value: gcs.StorageClass,
 		},
 	}
-	if gcs.SecretName != "" {
+	if gcs.SecretSource != nil {
+		envVars = append(envVars, generateStorageCredentialsDirEnv(gcs.SecretSource))
+	} else if gcs.SecretName != "" {
 		envVars = append(envVars, corev1.EnvVar{
 			Name: "GCS_SERVICE_ACCOUNT_JSON_KEY",
 			ValueFrom: &corev1.EnvVarSource{
@@ -201,6 +205,11 @@ func generateAzblobCertEnvVar(azblob *v1alpha1.AzblobStorageProvider, secret *co
 	if useSasToken {
 		return envVars, "", nil
 	}
+	if azblob.SecretSource != nil {
+		// the credential files are named by the keys of the secret, so they are loaded as the same env
+		envVars = append(envVars, generateStorageCredentialsDirEnv(azblob.SecretSource))
+		return envVars, "", nil
+	}
 	_, exist := CheckAllKeysExistInSecret(secret, constants.AzblobClientID, constants.AzblobClientScrt, constants.AzblobTenantID)
 	if exist { // using AAD auth
 		envVars = append(envVars, []corev1.EnvVar{
@@ -252,6 +261,57 @@ func generateAzblobCertEnvVar(azblob *v1alpha1.AzblobStorageProvider, secret *co
 	return nil, "azblobKeyOrAADMissing", fmt.Errorf("secret %s/%s missing some keys", secret.Namespace, secret.Name)
 }
 
+// generateStorageCredentialsDirEnv generate the env of the directory of the storage credential files
+func generateStorageCredentialsDirEnv(source *v1alpha1.CredentialSource) corev1.EnvVar {
+	dir := source.Path
+	if source.CSI != nil {
+		dir = constants.StorageCredentialsPath
+	}
+	return corev1.EnvVar{
+		Name:  constants.StorageCredentialsDirEnv,
+		Value: dir,
+	}
+}
+
+// GetStorageCredentialSource returns the external source of the storage credentials,
+// it returns nil if the credentials are read from the secret or the default credential chain
+func GetStorageCredentialSource(provider v1alpha1.StorageProvider) *v1alpha1.CredentialSource {
+	switch {
+	case provider.S3 != nil:
+		return provider.S3.SecretSource
+	case provider.Gcs != nil:
+		return provider.Gcs.SecretSource
+	case provider.Azblob != nil:
+		return provider.Azblob.SecretSource
+	}
+	return nil
+}
+
+// GenerateStorageCredentialVolumes generate the volumes and the volume mounts of the storage credentials
+// provided by the CSI driver, such as the secrets store CSI driver
+func GenerateStorageCredentialVolumes(provider v1alpha1.StorageProvider) ([]corev1.Volume, []corev1.VolumeMount) {
+	source := GetStorageCredentialSource(provider)
+	if source == nil || source.CSI == nil {
+		return nil, nil
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: constants.StorageCredentialsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				CSI: source.CSI.DeepCopy(),
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      constants.StorageCredentialsVolumeName,
+			ReadOnly:  true,
+			MountPath: constants.StorageCredentialsPath,
+		},
+	}
+	return volumes, volumeMounts
+}
+
 // GenerateStorageCertEnv generate the env info in order to access backend backup storage
 func GenerateStorageCertEnv(ns string, useKMS bool, provider v1alpha1.StorageProvider, secretLister corelisterv1.SecretLister) ([]corev1.EnvVar, string, error) {
 	var certEnv []corev1.EnvVar
@@ -262,7 +322,7 @@ func GenerateStorageCertEnv(ns string, useKMS bool, provider v1alpha1.StoragePro
 	switch storageType {
 	case v1alpha1.BackupStorageTypeS3:
 		s3SecretName := provider.S3.SecretName
-		if s3SecretName != "" {
+		if provider.S3.SecretSource == nil && s3SecretName != "" {
 			secret, err := secretLister.Secrets(ns).Get(s3SecretName)
 			if err != nil {
 				err := fmt.Errorf("get s3 secret %s/%s failed, err: %v", ns, s3SecretName, err)
@@ -282,7 +342,7 @@ func GenerateStorageCertEnv(ns string, useKMS bool, provider v1alpha1.StoragePro
 		}
 	case v1alpha1.BackupStorageTypeGcs:
 		gcsSecretName := provider.Gcs.SecretName
-		if gcsSecretName != "" {
+		if provider.Gcs.SecretSource == nil && gcsSecretName != "" {
 			secret, err := secretLister.Secrets(ns).Get(gcsSecretName)
 			if err != nil {
 				err := fmt.Errorf("get gcs secret %s/%s failed, err: %v", ns, gcsSecretName, err)
@@ -304,6 +364,11 @@ func GenerateStorageCertEnv(ns string, useKMS bool, provider v1alpha1.StoragePro
 	case v1alpha1.BackupStorageTypeAzblob:
 		azblobSecretName := provider.Azblob.SecretName
 		var secret *corev1.Secret
+		if provider.Azblob.SecretSource != nil {
+			// the storage account may be in the credential files, which are not accessible here
+			certEnv, reason, err = generateAzblobCertEnvVar(provider.Azblob, nil, provider.Azblob.SasToken != "")
+			return certEnv, reason, err
+		}
 		if azblobSecretName != "" {
 			secret, err = secretLister.Secrets(ns).Get(azblobSecretName)
 			if err != nil {
@@ -515,6 +580,10 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string, tc *v1alpha1.Tidb
 				return err
 			}
 		}
+		if err := ValidateCredentialSource(GetStorageCredentialSource(backup.Spec.StorageProvider), "secretSource",
+			fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)); err != nil {
+			return err
+		}
 
 		// validate log backup
 		if backup.Spec.Mode == v1alpha1.BackupModeLog {
@@ -627,6 +696,10 @@ func ValidateRestore(restore *v1alpha1.Restore, tikvImage string, acrossK8s bool
 				return err
 			}
 		}
+		if err := ValidateCredentialSource(GetStorageCredentialSource(restore.Spec.StorageProvider), "secretSource",
+			fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)); err != nil {
+			return err
+		}
 
 		if restore.Spec.Mode == v1alpha1.RestoreModeVolumeSnapshot {
 			// only support across k8s now. TODO compatible for single k8s
@@ -670,6 +743,21 @@ func validateGcs(ns, name string, gcs *v1alpha1.GcsStorageProvider) error {
 	return nil
 }
 
+// ValidateCredentialSource validates the external source of the credentials in the field, configuredFor
+// describes where the field is configured
+func ValidateCredentialSource(source *v1alpha1.CredentialSource, field, configuredFor string) error {
+	if source == nil {
+		return nil
+	}
+	if (source.CSI == nil) == (source.Path == "") {
+		return fmt.Errorf("only one of csi and path of %s should be %s", field, configuredFor)
+	}
+	if source.Path != "" && !path.IsAbs(source.Path) {
+		return fmt.Errorf("path %s of %s should be an absolute path %s", source.Path, field, configuredFor)
+	}
+	return nil
+}
+
 func validateLocal(ns, name string, local *v1alpha1.LocalStorageProvider) error {
 	configuredForBR := fmt.Sprintf("configured for BR in spec of %s/%s", ns, name)
 	if local.VolumeMount.Name != local.Volume.Name {
@@ -786,7 +874,10 @@ func GetVolSnapBackupMetaData(r *v1alpha1.Restore, secretLister corelisterv1.Sec
 	defer cancel()
 
 	klog.Infof("read the backup meta from external storage")
-	cred := GetStorageCredential(r.Namespace, r.Spec.StorageProvider, secretLister)
+	cred, err := GetStorageCredential(r.Namespace, r.Spec.StorageProvider, secretLister)
+	if err != nil {
+		return nil, err
+	}
 	s, err := NewStorageBackend(r.Spec.StorageProvider, cred)
 	if err != nil {
 		return nil, err
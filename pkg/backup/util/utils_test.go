@@ -344,6 +344,79 @@ func TestGenerateStorageCertEnv(t *testing.T) {
 	}
 }
 
+func TestGenerateStorageCertEnvWithSecretSource(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ns := "ns"
+	csi := &corev1.CSIVolumeSource{
+		Driver:           "secrets-store.csi.k8s.io",
+		VolumeAttributes: map[string]string{"secretProviderClass": "backup-credentials"},
+	}
+
+	tests := []struct {
+		name     string
+		provider v1alpha1.StorageProvider
+		dir      string
+	}{
+		{
+			name: "s3 with csi",
+			provider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{
+					SecretName:   "not-exist",
+					SecretSource: &v1alpha1.CredentialSource{CSI: csi},
+				},
+			},
+			dir: constants.StorageCredentialsPath,
+		},
+		{
+			name: "gcs with path",
+			provider: v1alpha1.StorageProvider{
+				Gcs: &v1alpha1.GcsStorageProvider{
+					ProjectId:    "id",
+					SecretSource: &v1alpha1.CredentialSource{Path: "/vault/secrets"},
+				},
+			},
+			dir: "/vault/secrets",
+		},
+		{
+			name: "azblob with csi",
+			provider: v1alpha1.StorageProvider{
+				Azblob: &v1alpha1.AzblobStorageProvider{
+					SecretSource: &v1alpha1.CredentialSource{CSI: csi},
+				},
+			},
+			dir: constants.StorageCredentialsPath,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			informer := kubeinformers.NewSharedInformerFactory(client, 0)
+
+			// the secret is not read when the secret source is set
+			envs, _, err := GenerateStorageCertEnv(ns, false, test.provider, informer.Core().V1().Secrets().Lister())
+			g.Expect(err).Should(BeNil())
+			g.Expect(envs).Should(ContainElement(corev1.EnvVar{Name: constants.StorageCredentialsDirEnv, Value: test.dir}))
+			for _, env := range envs {
+				g.Expect(env.ValueFrom).Should(BeNil())
+			}
+
+			volumes, volumeMounts := GenerateStorageCredentialVolumes(test.provider)
+			if test.dir != constants.StorageCredentialsPath {
+				g.Expect(volumes).Should(BeEmpty())
+				g.Expect(volumeMounts).Should(BeEmpty())
+				return
+			}
+			g.Expect(volumes).Should(HaveLen(1))
+			g.Expect(volumes[0].CSI).Should(Equal(csi))
+			g.Expect(volumeMounts).Should(ConsistOf(corev1.VolumeMount{
+				Name:      constants.StorageCredentialsVolumeName,
+				ReadOnly:  true,
+				MountPath: constants.StorageCredentialsPath,
+			}))
+		})
+	}
+}
+
 func TestGenerateTidbPasswordEnv(t *testing.T) {
 	g := NewGomegaWithT(t)
 	ns := "ns"
@@ -550,6 +623,15 @@ func TestValidateBackup(t *testing.T) {
 
 	backup.Spec.S3.Endpoint = "s3://localhost:80"
 	match("")
+
+	backup.Spec.S3.SecretSource = &v1alpha1.CredentialSource{}
+	match("only one of csi and path of secretSource should be configured")
+
+	backup.Spec.S3.SecretSource.Path = "secrets"
+	match("should be an absolute path")
+
+	backup.Spec.S3.SecretSource.Path = "/vault/secrets"
+	match("")
 }
 
 func TestValidateRestore(t *testing.T) {
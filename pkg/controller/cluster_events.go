@@ -0,0 +1,74 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"k8s.io/client-go/tools/record"
+)
+
+// ClusterEventTarget is the member affected by a decision of the operator
+type ClusterEventTarget struct {
+	MemberType v1alpha1.MemberType
+	PodName    string
+	StoreID    string
+}
+
+// annotations returns the annotations of the event to find the affected member without parsing the message
+func (t ClusterEventTarget) annotations() map[string]string {
+	anns := map[string]string{}
+	if t.MemberType != "" {
+		anns[label.ComponentLabelKey] = t.MemberType.String()
+	}
+	if t.PodName != "" {
+		anns[label.AnnPodNameKey] = t.PodName
+	}
+	if t.StoreID != "" {
+		anns[label.StoreIDLabelKey] = t.StoreID
+	}
+	return anns
+}
+
+// String returns the prefix of the event message, e.g. "[tikv pod=basic-tikv-0 store=1]"
+func (t ClusterEventTarget) String() string {
+	var fields []string
+	if t.MemberType != "" {
+		fields = append(fields, t.MemberType.String())
+	}
+	if t.PodName != "" {
+		fields = append(fields, "pod="+t.PodName)
+	}
+	if t.StoreID != "" {
+		fields = append(fields, "store="+t.StoreID)
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(fields, " ") + "]"
+}
+
+// RecordClusterEvent records an event on the TidbCluster to explain a decision of the operator,
+// so that users can see why the operator is waiting by `kubectl describe tc`. The affected member
+// is put at the beginning of the message and in the annotations of the event.
+func RecordClusterEvent(recorder record.EventRecorder, tc *v1alpha1.TidbCluster, eventType, reason string, target ClusterEventTarget, messageFmt string, args ...interface{}) {
+	msg := fmt.Sprintf(messageFmt, args...)
+	if prefix := target.String(); prefix != "" {
+		msg = prefix + " " + msg
+	}
+	recorder.AnnotatedEventf(tc, target.annotations(), eventType, reason, "%s", msg)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordClusterEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	recorder := record.NewFakeRecorder(10)
+
+	target := ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType, PodName: "demo-tikv-0", StoreID: "1"}
+	RecordClusterEvent(recorder, tc, corev1.EventTypeWarning, "EvictLeaderTimeout", target, "leaders are not evicted in %v", "5m")
+	RecordClusterEvent(recorder, tc, corev1.EventTypeNormal, "UpgradeBlocked", ClusterEventTarget{}, "pd is upgrading")
+
+	events := collectEvents(recorder.Events)
+	g.Expect(events).To(Equal([]string{
+		// the fake recorder appends the annotations
+		"Warning EvictLeaderTimeout [tikv pod=demo-tikv-0 store=1] leaders are not evicted in 5m " +
+			"map[app.kubernetes.io/component:tikv tidb.pingcap.com/pod-name:demo-tikv-0 tidb.pingcap.com/store-id:1]",
+		"Normal UpgradeBlocked pd is upgrading",
+	}))
+}
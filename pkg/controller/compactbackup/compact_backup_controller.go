@@ -400,6 +400,9 @@ func (c *Controller) makeCompactJob(compact *v1alpha1.CompactBackup) (*batchv1.J
 	if len(compact.Spec.AdditionalVolumeMounts) > 0 {
 		volumeMounts = append(volumeMounts, compact.Spec.AdditionalVolumeMounts...)
 	}
+	credentialVolumes, credentialVolumeMounts := backuputil.GenerateStorageCredentialVolumes(compact.Spec.StorageProvider)
+	volumes = append(volumes, credentialVolumes...)
+	volumeMounts = append(volumeMounts, credentialVolumeMounts...)
 
 	// mount volumes if specified
 	if compact.Spec.Local != nil {
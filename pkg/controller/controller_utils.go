@@ -24,7 +24,10 @@ import (
 	fedv1alpha1 "github.com/pingcap/tidb-operator/pkg/apis/federation/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -710,3 +713,14 @@ func GuaranteedUpdate(cli client.Client, obj client.Object, updateFunc func() er
 		return cli.Update(context.TODO(), obj)
 	})
 }
+
+// startWriteSpan starts a child span of the current span of the TidbCluster
+// for a write to the Kubernetes API. Writes for other controllers are not traced.
+func startWriteSpan(controller runtime.Object, verb, kind, name string) (context.Context, trace.Span) {
+	tc, ok := controller.(*v1alpha1.TidbCluster)
+	if !ok {
+		return context.TODO(), trace.SpanFromContext(context.TODO())
+	}
+	return tracing.StartClusterSpan(tc.GetNamespace(), tc.GetName(), fmt.Sprintf("%s %s", verb, kind),
+		attribute.String("k8s.object.name", name))
+}
@@ -33,6 +33,7 @@ import (
 	extensionslister "k8s.io/client-go/listers/extensions/v1beta1"
 	networklister "k8s.io/client-go/listers/networking/v1"
 	storagelister "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -90,6 +91,9 @@ type CLIConfig struct {
 	TestMode               bool
 	TiDBBackupManagerImage string
 	TiDBDiscoveryImage     string
+	// SlowLogShipperImage is the image of the sidecar shipping the slow log of TiDB in the json format,
+	// it's not the same flag as TiDBDiscoveryImage to keep TiDB pods from being rolling updated with the operator
+	SlowLogShipperImage string
 	// Selector is used to filter CR labels to decide
 	// what resources should be watched and synced by controller
 	Selector string
@@ -97,6 +101,22 @@ type CLIConfig struct {
 	// KubeClientQPS indicates the maximum QPS to the kubenetes API server from client.
 	KubeClientQPS   float64
 	KubeClientBurst int
+
+	// TracingEndpoint is the OTLP gRPC endpoint which traces of reconciles are
+	// exported to, tracing is disabled if it's empty.
+	TracingEndpoint string
+	// TracingInsecure disables TLS of the connection to TracingEndpoint
+	TracingInsecure bool
+	// TracingSampleRatio is the ratio of reconciles to be traced
+	TracingSampleRatio float64
+
+	// TLSMinVersion is the minimum TLS version of the connections to the components, such as "1.2"
+	TLSMinVersion string
+	// TLSCipherSuites is the comma separated cipher suites of the connections to the components
+	TLSCipherSuites string
+	// TLSServerURISAN is the template of the URI SAN that the certificates of the components must contain,
+	// only the CA is verified if it's empty.
+	TLSServerURISAN string
 }
 
 // DefaultCLIConfig returns the default command line configuration
@@ -121,7 +141,9 @@ func DefaultCLIConfig() *CLIConfig {
 		DetectNodeFailure:      false,
 		TiDBBackupManagerImage: "pingcap/tidb-backup-manager:latest",
 		TiDBDiscoveryImage:     "pingcap/tidb-operator:latest",
+		SlowLogShipperImage:    "pingcap/tidb-operator:latest",
 		Selector:               "",
+		TracingSampleRatio:     1,
 	}
 }
 
@@ -148,6 +170,7 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.StringVar(&c.TiDBBackupManagerImage, "tidb-backup-manager-image", c.TiDBBackupManagerImage, "The image of backup manager tool")
 	// TODO: actually we just want to use the same image with tidb-controller-manager, but DownwardAPI cannot get image ID, see if there is any better solution
 	flag.StringVar(&c.TiDBDiscoveryImage, "tidb-discovery-image", c.TiDBDiscoveryImage, "The image of the tidb discovery service")
+	flag.StringVar(&c.SlowLogShipperImage, "tidb-slowlog-shipper-image", c.SlowLogShipperImage, "The image of the sidecar shipping the slow log of TiDB in the json format")
 	flag.StringVar(&c.Selector, "selector", c.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='")
 
 	// see https://pkg.go.dev/k8s.io/client-go/tools/leaderelection#LeaderElectionConfig for the config
@@ -157,6 +180,12 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.StringVar(&c.ResourceLock, "leader-resource-lock", c.ResourceLock, "The type of resource object that is used for locking during leader election")
 	flag.Float64Var(&c.KubeClientQPS, "kube-client-qps", c.KubeClientQPS, "The maximum QPS to the kubenetes API server from client")
 	flag.IntVar(&c.KubeClientBurst, "kube-client-burst", c.KubeClientBurst, "The maximum burst for throttle to the kubenetes API server from client")
+	flag.StringVar(&c.TracingEndpoint, "tracing-endpoint", c.TracingEndpoint, "The OTLP gRPC endpoint to export traces of reconciles to, tracing is disabled if it's empty")
+	flag.BoolVar(&c.TracingInsecure, "tracing-insecure", c.TracingInsecure, "Whether to disable TLS of the connection to the tracing endpoint")
+	flag.Float64Var(&c.TracingSampleRatio, "tracing-sample-ratio", c.TracingSampleRatio, "The ratio of reconciles to be traced, in range [0, 1]")
+	flag.StringVar(&c.TLSMinVersion, "tls-min-version", c.TLSMinVersion, "The minimum TLS version of the connections to the components, one of 1.0, 1.1, 1.2 and 1.3, the default of Go is used if it's empty")
+	flag.StringVar(&c.TLSCipherSuites, "tls-cipher-suites", c.TLSCipherSuites, "The comma separated cipher suites of the TLS 1.2 connections to the components, the defaults of Go are used if it's empty")
+	flag.StringVar(&c.TLSServerURISAN, "tls-server-uri-san", c.TLSServerURISAN, "The template of the URI SAN that the certificates of the components must contain, such as spiffe://{clusterDomain}/ns/{namespace}/tc/{cluster}/{component}, only the CA is verified if it's empty")
 }
 
 // HasNodePermission returns whether the user has permission for node operations.
@@ -214,6 +243,8 @@ type Dependencies struct {
 	KubeInformerFactory            kubeinformers.SharedInformerFactory
 	LabelFilterKubeInformerFactory kubeinformers.SharedInformerFactory
 	Recorder                       record.EventRecorder
+	// RESTConfig is the config of KubeClientset, it's used to exec into pods
+	RESTConfig *rest.Config
 
 	// Listers
 	ServiceLister               corelisterv1.ServiceLister
@@ -229,6 +260,7 @@ type Dependencies struct {
 	JobLister                   batchlisters.JobLister
 	IngressLister               networklister.IngressLister
 	IngressV1Beta1Lister        extensionslister.IngressLister // TODO: in order to be compatibility with kubernetes which less than v1.19, remove it if v1.19- is not supported
+	NetworkPolicyLister         networklister.NetworkPolicyLister
 	StorageClassLister          storagelister.StorageClassLister
 	TiDBClusterLister           listers.TidbClusterLister
 	TiDBClusterAutoScalerLister listers.TidbClusterAutoScalerLister
@@ -376,6 +408,7 @@ func newDependencies(
 		JobLister:                   kubeInformerFactory.Batch().V1().Jobs().Lister(),
 		IngressLister:               ingLister,
 		IngressV1Beta1Lister:        ingv1beta1Lister,
+		NetworkPolicyLister:         labelFilterKubeInformerFactory.Networking().V1().NetworkPolicies().Lister(),
 		TiDBClusterLister:           informerFactory.Pingcap().V1alpha1().TidbClusters().Lister(),
 		TiDBClusterAutoScalerLister: informerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers().Lister(),
 		DMClusterLister:             informerFactory.Pingcap().V1alpha1().DMClusters().Lister(),
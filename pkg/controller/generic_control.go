@@ -20,7 +20,10 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/scheme"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -62,6 +65,10 @@ type TypedControlInterface interface {
 	CreateOrUpdateIngress(controller client.Object, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error)
 	// CreateOrUpdateIngressV1beta1 create the desired v1beta1 ingress or update the current one to desired state if already existed
 	CreateOrUpdateIngressV1beta1(controller client.Object, ingress *extensionsv1beta1.Ingress) (*extensionsv1beta1.Ingress, error)
+	// CreateOrUpdateNetworkPolicy create the desired network policy or update the current one to desired state if already existed
+	CreateOrUpdateNetworkPolicy(controller client.Object, policy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error)
+	// CreateOrUpdateCronJob create the desired cronjob or update the current one to desired state if already existed
+	CreateOrUpdateCronJob(controller client.Object, cj *batchv1.CronJob) (*batchv1.CronJob, error)
 	// UpdateStatus update the /status subresource of the object
 	UpdateStatus(newStatus client.Object) error
 	// Delete delete the given object from the cluster
@@ -200,6 +207,36 @@ func (w *typedWrapper) CreateOrUpdateDeployment(controller client.Object, deploy
 	return result.(*appsv1.Deployment), err
 }
 
+func (w *typedWrapper) CreateOrUpdateCronJob(controller client.Object, cj *batchv1.CronJob) (*batchv1.CronJob, error) {
+	result, err := w.GenericControlInterface.CreateOrUpdate(controller, cj, func(existing, desired client.Object) error {
+		existingCJ := existing.(*batchv1.CronJob)
+		desiredCJ := desired.(*batchv1.CronJob)
+
+		existingCJ.Labels = desiredCJ.Labels
+		existingCJ.Spec.Schedule = desiredCJ.Spec.Schedule
+		existingCJ.Spec.Suspend = desiredCJ.Spec.Suspend
+		existingCJ.Spec.ConcurrencyPolicy = desiredCJ.Spec.ConcurrencyPolicy
+
+		if existingCJ.Annotations == nil {
+			existingCJ.Annotations = map[string]string{}
+		}
+		// job template of cronjob is hard to merge, use an annotation to assist
+		b, err := json.Marshal(desiredCJ.Spec.JobTemplate)
+		if err != nil {
+			return err
+		}
+		if existingCJ.Annotations[LastAppliedConfigAnnotation] != string(b) {
+			existingCJ.Annotations[LastAppliedConfigAnnotation] = string(b)
+			existingCJ.Spec.JobTemplate = desiredCJ.Spec.JobTemplate
+		}
+		return nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*batchv1.CronJob), err
+}
+
 func (w *typedWrapper) CreateOrUpdateRole(controller client.Object, role *rbacv1.Role) (*rbacv1.Role, error) {
 	result, err := w.GenericControlInterface.CreateOrUpdate(controller, role, func(existing, desired client.Object) error {
 		existingRole := existing.(*rbacv1.Role)
@@ -252,6 +289,9 @@ func (w *typedWrapper) CreateOrUpdateConfigMap(controller client.Object, cm *cor
 
 		existingCm.Data = desiredCm.Data
 		existingCm.Labels = desiredCm.Labels
+		if existingCm.Annotations == nil && len(desiredCm.Annotations) > 0 {
+			existingCm.Annotations = map[string]string{}
+		}
 		for k, v := range desiredCm.Annotations {
 			existingCm.Annotations[k] = v
 		}
@@ -383,6 +423,39 @@ func (w *typedWrapper) CreateOrUpdateIngress(controller client.Object, ingress *
 	return result.(*networkingv1.Ingress), nil
 }
 
+func (w *typedWrapper) CreateOrUpdateNetworkPolicy(controller client.Object, policy *networkingv1.NetworkPolicy) (*networkingv1.NetworkPolicy, error) {
+	result, err := w.GenericControlInterface.CreateOrUpdate(controller, policy, func(existing, desired client.Object) error {
+		existingPolicy := existing.(*networkingv1.NetworkPolicy)
+		desiredPolicy := desired.(*networkingv1.NetworkPolicy)
+
+		if existingPolicy.Annotations == nil {
+			existingPolicy.Annotations = map[string]string{}
+		}
+		for k, v := range desiredPolicy.Annotations {
+			existingPolicy.Annotations[k] = v
+		}
+		existingPolicy.Labels = desiredPolicy.Labels
+		equal, err := NetworkPolicyEqual(desiredPolicy, existingPolicy)
+		if err != nil {
+			return err
+		}
+		if !equal {
+			// record desiredPolicy Spec in annotations in favor of future equality checks
+			b, err := json.Marshal(desiredPolicy.Spec)
+			if err != nil {
+				return err
+			}
+			existingPolicy.Annotations[LastAppliedConfigAnnotation] = string(b)
+			existingPolicy.Spec = desiredPolicy.Spec
+		}
+		return nil
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*networkingv1.NetworkPolicy), nil
+}
+
 func (w *typedWrapper) Create(controller, obj client.Object) error {
 	return w.GenericControlInterface.Create(controller, obj, true)
 }
@@ -461,7 +534,9 @@ func (c *realGenericControlInterface) CreateOrUpdate(controller, obj client.Obje
 	}
 
 	// 1. try to create and see if there is any conflicts
-	err := c.client.Create(context.TODO(), desired)
+	ctx, span := startObjectWriteSpan(controller, "Create", desired)
+	err := c.client.Create(ctx, desired)
+	tracing.End(span, err)
 	if errors.IsAlreadyExists(err) {
 
 		// 2. object has already existed, merge our desired changes to it
@@ -490,7 +565,9 @@ func (c *realGenericControlInterface) CreateOrUpdate(controller, obj client.Obje
 
 		// 5. check if the copy is actually mutated
 		if !apiequality.Semantic.DeepEqual(existing, mutated) {
-			err := c.client.Update(context.TODO(), mutated)
+			ctx, span := startObjectWriteSpan(controller, "Update", mutated)
+			err := c.client.Update(ctx, mutated)
+			tracing.End(span, err)
 			return mutated, err
 		}
 
@@ -516,17 +593,30 @@ func (c *realGenericControlInterface) Create(controller, obj client.Object, setO
 		}
 	}
 
-	err := c.client.Create(context.TODO(), desired)
+	ctx, span := startObjectWriteSpan(controller, "Create", desired)
+	err := c.client.Create(ctx, desired)
+	tracing.End(span, err)
 	c.RecordControllerEvent("create", controller, desired, err)
 	return err
 }
 
 func (c *realGenericControlInterface) Delete(controller, obj client.Object) error {
-	err := c.client.Delete(context.TODO(), obj)
+	ctx, span := startObjectWriteSpan(controller, "Delete", obj)
+	err := c.client.Delete(ctx, obj)
+	tracing.End(span, err)
 	c.RecordControllerEvent("delete", controller, obj, err)
 	return err
 }
 
+// startObjectWriteSpan is like startWriteSpan but infers the kind from the object.
+func startObjectWriteSpan(controller runtime.Object, verb string, obj client.Object) (context.Context, trace.Span) {
+	var kind string
+	if gvk, err := InferObjectKind(obj); err == nil {
+		kind = gvk.Kind
+	}
+	return startWriteSpan(controller, verb, kind, obj.GetName())
+}
+
 // RecordControllerEvent is a generic method to record event for controller
 func (c *realGenericControlInterface) RecordControllerEvent(verb string, controller runtime.Object, obj runtime.Object, err error) {
 	var controllerName string
@@ -14,14 +14,10 @@
 package controller
 
 import (
-	"crypto/tls"
-	"crypto/x509"
-	"fmt"
 	"net/http"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
-	"github.com/pingcap/tidb-operator/pkg/util"
-	v1 "k8s.io/api/core/v1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 )
 
@@ -29,37 +25,17 @@ type httpClient struct {
 	secretLister corelisterv1.SecretLister
 }
 
-func (c *httpClient) getHTTPClient(tc *v1alpha1.TidbCluster) (*http.Client, error) {
+// getHTTPClient returns the http client to the component of the tidb cluster
+func (c *httpClient) getHTTPClient(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType) (*http.Client, error) {
 	httpClient := &http.Client{Timeout: timeout}
 	if !tc.IsTLSClusterEnabled() {
 		return httpClient, nil
 	}
 
-	tcName := tc.Name
-	ns := tc.Namespace
-	secretName := util.ClusterClientTLSSecretName(tcName)
-	secret, err := c.secretLister.Secrets(ns).Get(secretName)
+	config, err := pdapi.GetComponentTLSConfig(c.secretLister, pdapi.Namespace(tc.Namespace), tc.Name, tc.Spec.ClusterDomain, component)
 	if err != nil {
 		return nil, err
 	}
-
-	clientCert, certExists := secret.Data[v1.TLSCertKey]
-	clientKey, keyExists := secret.Data[v1.TLSPrivateKeyKey]
-	if !certExists || !keyExists {
-		return nil, fmt.Errorf("cert or key does not exist in secret %s/%s", ns, secretName)
-	}
-
-	tlsCert, err := tls.X509KeyPair(clientCert, clientKey)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load certificates from secret %s/%s: %v", ns, secretName, err)
-	}
-
-	rootCAs := x509.NewCertPool()
-	rootCAs.AppendCertsFromPEM(secret.Data[v1.ServiceAccountRootCAKey])
-	config := &tls.Config{
-		RootCAs:      rootCAs,
-		Certificates: []tls.Certificate{tlsCert},
-	}
 	httpClient.Transport = &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}
 
 	return httpClient, nil
@@ -14,7 +14,6 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -24,6 +23,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -82,10 +82,11 @@ func (c *realPodControl) UpdatePod(controller runtime.Object, pod *corev1.Pod) (
 	ann := pod.GetAnnotations()
 
 	var updatePod *corev1.Pod
+	ctx, span := startWriteSpan(controller, "Update", "Pod", podName)
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
-		updatePod, updateErr = c.kubeCli.CoreV1().Pods(namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
+		updatePod, updateErr = c.kubeCli.CoreV1().Pods(namespace).Update(ctx, pod, metav1.UpdateOptions{})
 		if updateErr == nil {
 			klog.Infof("Pod: [%s/%s] updated successfully, %s: [%s/%s]", namespace, podName, kind, namespace, name)
 			return nil
@@ -103,6 +104,7 @@ func (c *realPodControl) UpdatePod(controller runtime.Object, pod *corev1.Pod) (
 
 		return updateErr
 	})
+	tracing.End(span, err)
 	return updatePod, err
 }
 
@@ -205,9 +207,10 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 	// annotations is already a pointer and was updated so pod.Annotations is updated.
 
 	var updatePod *corev1.Pod
+	ctx, span := startWriteSpan(tc, "Update", "Pod", podName)
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
-		updatePod, updateErr = c.kubeCli.CoreV1().Pods(ns).Update(context.TODO(), pod, metav1.UpdateOptions{})
+		updatePod, updateErr = c.kubeCli.CoreV1().Pods(ns).Update(ctx, pod, metav1.UpdateOptions{})
 		if updateErr == nil {
 			klog.V(4).Infof("update pod %s/%s with cluster labels %v successfully, TidbCluster: %s", ns, podName, labels, tcName)
 			return nil
@@ -224,6 +227,7 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 		}
 		return updateErr
 	})
+	tracing.End(span, err)
 
 	return updatePod, err
 }
@@ -257,7 +261,9 @@ func (c *realPodControl) deletePod(controller runtime.Object, pod *corev1.Pod, n
 		deleteOptions.GracePeriodSeconds = &gracePeriod
 		deleteOptions.PropagationPolicy = &propagationPolicy
 	}
-	err := c.kubeCli.CoreV1().Pods(namespace).Delete(context.TODO(), podName, deleteOptions)
+	ctx, span := startWriteSpan(controller, "Delete", "Pod", podName)
+	err := c.kubeCli.CoreV1().Pods(namespace).Delete(ctx, podName, deleteOptions)
+	tracing.End(span, err)
 	if err != nil {
 		klog.Errorf("failed to delete Pod: [%s/%s], %s: %s, %v", namespace, podName, kind, namespace, err)
 	} else {
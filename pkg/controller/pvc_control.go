@@ -14,11 +14,11 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -75,7 +75,9 @@ func (c *realPVCControl) DeletePVC(controller runtime.Object, pvc *corev1.Persis
 	namespace := controllerMo.GetNamespace()
 
 	pvcName := pvc.GetName()
-	err := c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), pvcName, metav1.DeleteOptions{})
+	ctx, span := startWriteSpan(controller, "Delete", "PersistentVolumeClaim", pvcName)
+	err := c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvcName, metav1.DeleteOptions{})
+	tracing.End(span, err)
 	if err != nil {
 		klog.Errorf("failed to delete PVC: [%s/%s], %s: %s, %v", namespace, pvcName, kind, name, err)
 	}
@@ -95,7 +97,9 @@ func (c *realPVCControl) CreatePVC(controller runtime.Object, pvc *corev1.Persis
 	namespace := controllerMo.GetNamespace()
 
 	pvcName := pvc.GetName()
-	_, err := c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+	ctx, span := startWriteSpan(controller, "Create", "PersistentVolumeClaim", pvcName)
+	_, err := c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	tracing.End(span, err)
 	if err != nil {
 		klog.Errorf("failed to create PVC: [%s/%s], %s: %s, %v", namespace, pvcName, kind, name, err)
 	}
@@ -118,9 +122,10 @@ func (c *realPVCControl) UpdatePVC(controller runtime.Object, pvc *corev1.Persis
 	labels := pvc.GetLabels()
 	ann := pvc.GetAnnotations()
 	var updatePVC *corev1.PersistentVolumeClaim
+	ctx, span := startWriteSpan(controller, "Update", "PersistentVolumeClaim", pvcName)
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
-		updatePVC, updateErr = c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
+		updatePVC, updateErr = c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{})
 		if updateErr == nil {
 			klog.Infof("update PVC: [%s/%s] successfully, %s: %s", namespace, pvcName, kind, name)
 			return nil
@@ -138,6 +143,7 @@ func (c *realPVCControl) UpdatePVC(controller runtime.Object, pvc *corev1.Persis
 
 		return updateErr
 	})
+	tracing.End(span, err)
 	return updatePVC, err
 }
 
@@ -183,9 +189,10 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 	labels := pvc.GetLabels()
 	ann := pvc.GetAnnotations()
 	var updatePVC *corev1.PersistentVolumeClaim
+	ctx, span := startWriteSpan(controller, "Update", "PersistentVolumeClaim", pvcName)
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
-		updatePVC, updateErr = c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
+		updatePVC, updateErr = c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{})
 		if updateErr == nil {
 			klog.V(4).Infof("update PVC: [%s/%s] successfully, %s: %s", namespace, pvcName, kind, name)
 			return nil
@@ -203,6 +210,7 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 
 		return updateErr
 	})
+	tracing.End(span, err)
 	return updatePVC, err
 }
 
@@ -14,10 +14,10 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -67,7 +67,9 @@ func (c *realServiceControl) CreateService(controller runtime.Object, svc *corev
 	kind := controller.GetObjectKind().GroupVersionKind().Kind
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
-	_, err := c.kubeCli.CoreV1().Services(namespace).Create(context.TODO(), svc, metav1.CreateOptions{})
+	ctx, span := startWriteSpan(controller, "Create", "Service", svc.GetName())
+	_, err := c.kubeCli.CoreV1().Services(namespace).Create(ctx, svc, metav1.CreateOptions{})
+	tracing.End(span, err)
 	c.recordServiceEvent("create", name, kind, controller, svc, err)
 	return err
 }
@@ -84,9 +86,10 @@ func (c *realServiceControl) UpdateService(controller runtime.Object, svc *corev
 	svcSpec := svc.Spec.DeepCopy()
 
 	var updateSvc *corev1.Service
+	ctx, span := startWriteSpan(controller, "Update", "Service", svcName)
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
-		updateSvc, updateErr = c.kubeCli.CoreV1().Services(namespace).Update(context.TODO(), svc, metav1.UpdateOptions{})
+		updateSvc, updateErr = c.kubeCli.CoreV1().Services(namespace).Update(ctx, svc, metav1.UpdateOptions{})
 		if updateErr == nil {
 			klog.Infof("update Service: [%s/%s] successfully, kind: %s, name: %s", namespace, svcName, kind, name)
 			return nil
@@ -101,6 +104,7 @@ func (c *realServiceControl) UpdateService(controller runtime.Object, svc *corev
 
 		return updateErr
 	})
+	tracing.End(span, err)
 	return updateSvc, err
 }
 
@@ -113,7 +117,9 @@ func (c *realServiceControl) DeleteService(controller runtime.Object, svc *corev
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
-	err := c.kubeCli.CoreV1().Services(namespace).Delete(context.TODO(), svc.Name, metav1.DeleteOptions{})
+	ctx, span := startWriteSpan(controller, "Delete", "Service", svc.Name)
+	err := c.kubeCli.CoreV1().Services(namespace).Delete(ctx, svc.Name, metav1.DeleteOptions{})
+	tracing.End(span, err)
 	c.recordServiceEvent("delete", name, kind, controller, svc, err)
 	return err
 }
@@ -14,10 +14,10 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -61,7 +61,9 @@ func (c *realStatefulSetControl) CreateStatefulSet(controller runtime.Object, se
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
-	_, err := c.kubeCli.AppsV1().StatefulSets(namespace).Create(context.TODO(), set, metav1.CreateOptions{})
+	ctx, span := startWriteSpan(controller, "Create", "StatefulSet", set.GetName())
+	_, err := c.kubeCli.AppsV1().StatefulSets(namespace).Create(ctx, set, metav1.CreateOptions{})
+	tracing.End(span, err)
 	// sink already exists errors
 	if apierrors.IsAlreadyExists(err) {
 		return err
@@ -86,10 +88,11 @@ func (c *realStatefulSetControl) UpdateStatefulSet(controller runtime.Object, se
 	setAnnotations := set.Annotations
 	var updatedSS *apps.StatefulSet
 
+	ctx, span := startWriteSpan(controller, "Update", "StatefulSet", setName)
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// TODO: verify if StatefulSet identity(name, namespace, labels) matches TidbCluster
 		var updateErr error
-		updatedSS, updateErr = c.kubeCli.AppsV1().StatefulSets(namespace).Update(context.TODO(), set, metav1.UpdateOptions{})
+		updatedSS, updateErr = c.kubeCli.AppsV1().StatefulSets(namespace).Update(ctx, set, metav1.UpdateOptions{})
 		if updateErr == nil {
 			klog.Infof("%s: [%s/%s]'s StatefulSet: [%s/%s] updated successfully", kind, namespace, name, namespace, setName)
 			return nil
@@ -107,6 +110,7 @@ func (c *realStatefulSetControl) UpdateStatefulSet(controller runtime.Object, se
 		}
 		return updateErr
 	})
+	tracing.End(span, err)
 
 	return updatedSS, err
 }
@@ -121,7 +125,9 @@ func (c *realStatefulSetControl) DeleteStatefulSet(controller runtime.Object, se
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
-	err := c.kubeCli.AppsV1().StatefulSets(namespace).Delete(context.TODO(), set.Name, opts)
+	ctx, span := startWriteSpan(controller, "Delete", "StatefulSet", set.GetName())
+	err := c.kubeCli.AppsV1().StatefulSets(namespace).Delete(ctx, set.Name, opts)
+	tracing.End(span, err)
 	c.recordStatefulSetEvent("delete", kind, name, controller, set, err)
 	return err
 }
@@ -80,7 +80,7 @@ func NewDefaultTiCDCControl(secretLister corelisterv1.SecretLister) *defaultTiCD
 }
 
 func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error) {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiCDCMemberType)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32)
 }
 
 func (c *defaultTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int32) (int, bool, error) {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiCDCMemberType)
 	if err != nil {
 		klog.Warningf("ticdc control: drain capture is failed, error: %v", err)
 		return 0, false, err
@@ -171,7 +171,7 @@ func (c *defaultTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ordinal int
 }
 
 func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiCDCMemberType)
 	if err != nil {
 		klog.Warningf("ticdc control: resign owner failed, error: %v", err)
 		return false, err
@@ -224,7 +224,7 @@ func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int3
 }
 
 func (c *defaultTiCDCControl) IsHealthy(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiCDCMemberType)
 	if err != nil {
 		klog.Warningf("ticdc control: get http client failed, error: %v", err)
 		return false, err
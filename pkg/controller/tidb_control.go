@@ -15,14 +15,23 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 )
 
@@ -45,6 +54,11 @@ type TiDBControlInterface interface {
 	GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error)
 	// SetServerLabels update TiDB's labels config
 	SetServerLabels(tc *v1alpha1.TidbCluster, ordinal int32, labels map[string]string) error
+	// GetConfig returns the live config of tidb
+	GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error)
+	// ReloadTLS makes tidb reload the certificate of the MySQL protocol by `ALTER INSTANCE RELOAD TLS`,
+	// and returns the certificate served by tidb after reloading
+	ReloadTLS(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (*x509.Certificate, error)
 }
 
 // defaultTiDBControl is default implementation of TiDBControlInterface.
@@ -60,7 +74,7 @@ func NewDefaultTiDBControl(secretLister corelisterv1.SecretLister) *defaultTiDBC
 }
 
 func (c *defaultTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiDBMemberType)
 	if err != nil {
 		return false, err
 	}
@@ -72,7 +86,7 @@ func (c *defaultTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32)
 }
 
 func (c *defaultTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error) {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiDBMemberType)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +120,7 @@ func (c *defaultTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*
 
 // SetServerLabels update TiDB's labels config
 func (c *defaultTiDBControl) SetServerLabels(tc *v1alpha1.TidbCluster, ordinal int32, labels map[string]string) error {
-	httpClient, err := c.getHTTPClient(tc)
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiDBMemberType)
 	if err != nil {
 		return err
 	}
@@ -121,6 +135,98 @@ func (c *defaultTiDBControl) SetServerLabels(tc *v1alpha1.TidbCluster, ordinal i
 	return err
 }
 
+// GetConfig returns the live config of tidb
+func (c *defaultTiDBControl) GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error) {
+	httpClient, err := c.getHTTPClient(tc, v1alpha1.TiDBMemberType)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/config", c.getBaseURL(tc, ordinal))
+	body, err := getBodyOK(httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ReloadTLS makes tidb reload the certificate of the MySQL protocol by `ALTER INSTANCE RELOAD TLS`,
+// and returns the certificate served by tidb after reloading
+func (c *defaultTiDBControl) ReloadTLS(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (*x509.Certificate, error) {
+	tlsConfig, err := c.getMySQLTLSConfig(tc)
+	if err != nil {
+		return nil, err
+	}
+	var served *x509.Certificate
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) > 0 {
+			served = cs.PeerCertificates[0]
+		}
+		return nil
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", c.getHost(tc, ordinal), v1alpha1.DefaultTiDBServerPort)
+	cfg.Timeout = timeout
+	cfg.TLS = tlsConfig
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	// the connections are not reused, so the certificate is served again by the new connection after reloading
+	db.SetMaxIdleConns(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := db.ExecContext(ctx, "ALTER INSTANCE RELOAD TLS"); err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	if served == nil {
+		return nil, fmt.Errorf("no certificate is served by %s", cfg.Addr)
+	}
+	return served, nil
+}
+
+// getMySQLTLSConfig returns the TLS config to connect to the MySQL protocol of tidb, the certificate in the
+// TiDB client secret is presented if it exists, otherwise only the CA in the TiDB server secret is trusted
+func (c *defaultTiDBControl) getMySQLTLSConfig(tc *v1alpha1.TidbCluster) (*tls.Config, error) {
+	ns := tc.GetNamespace()
+	clientSecretName := util.TiDBClientTLSSecretName(tc.GetName(), nil)
+	if _, err := c.secretLister.Secrets(ns).Get(clientSecretName); err == nil {
+		return pdapi.GetTLSConfig(c.secretLister, pdapi.Namespace(ns), clientSecretName, pdapi.TLSServer{
+			Namespace:     pdapi.Namespace(ns),
+			ClusterName:   tc.GetName(),
+			ClusterDomain: tc.Spec.ClusterDomain,
+			Component:     v1alpha1.TiDBMemberType,
+		})
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to load certificates from secret %s/%s: %v", ns, clientSecretName, err)
+	}
+
+	serverSecretName := util.TiDBServerTLSSecretName(tc.GetName())
+	secret, err := c.secretLister.Secrets(ns).Get(serverSecretName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load certificates from secret %s/%s: %v", ns, serverSecretName, err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(secret.Data[corev1.ServiceAccountRootCAKey]) {
+		return nil, fmt.Errorf("no CA is found in secret %s/%s", ns, serverSecretName)
+	}
+	return &tls.Config{RootCAs: rootCAs}, nil
+}
+
 func getBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
 	res, err := httpClient.Get(apiURL)
 	if err != nil {
@@ -144,16 +250,18 @@ func (c *defaultTiDBControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32)
 		return c.testURL
 	}
 
+	return fmt.Sprintf("%s://%s:%d", tc.Scheme(), c.getHost(tc, ordinal), v1alpha1.DefaultTiDBStatusPort)
+}
+
+// getHost returns the host name of the tidb pod
+func (c *defaultTiDBControl) getHost(tc *v1alpha1.TidbCluster, ordinal int32) string {
 	tcName := tc.GetName()
 	ns := tc.GetNamespace()
-	scheme := tc.Scheme()
 	hostName := fmt.Sprintf("%s-%d", TiDBMemberName(tcName), ordinal)
-
-	baseURL := fmt.Sprintf("%s://%s.%s.%s:%d", scheme, hostName, TiDBPeerMemberName(tcName), ns, v1alpha1.DefaultTiDBStatusPort)
 	if tc.Spec.ClusterDomain != "" {
-		baseURL = fmt.Sprintf("%s://%s.%s.%s.svc.%s:%d", scheme, hostName, TiDBPeerMemberName(tcName), ns, tc.Spec.ClusterDomain, v1alpha1.DefaultTiDBStatusPort)
+		return fmt.Sprintf("%s.%s.%s.svc.%s", hostName, TiDBPeerMemberName(tcName), ns, tc.Spec.ClusterDomain)
 	}
-	return baseURL
+	return fmt.Sprintf("%s.%s.%s", hostName, TiDBPeerMemberName(tcName), ns)
 }
 
 // FakeTiDBControl is a fake implementation of TiDBControlInterface.
@@ -162,6 +270,12 @@ type FakeTiDBControl struct {
 	tiDBInfo       *DBInfo
 	getInfoError   error
 	setLabelsError error
+	config         map[string]interface{}
+	getConfigError error
+	servedCert     *x509.Certificate
+	reloadTLSError error
+	// ReloadedTLS are the ordinals of the tidb on which ReloadTLS is called
+	ReloadedTLS []int32
 }
 
 // NewFakeTiDBControl returns a FakeTiDBControl instance
@@ -178,6 +292,18 @@ func (c *FakeTiDBControl) SetLabelsErr(err error) {
 	c.setLabelsError = err
 }
 
+// SetConfig sets the live config returned by FakeTiDBControl
+func (c *FakeTiDBControl) SetConfig(config map[string]interface{}, err error) {
+	c.config = config
+	c.getConfigError = err
+}
+
+// SetReloadTLS sets the certificate served after ReloadTLS of FakeTiDBControl
+func (c *FakeTiDBControl) SetReloadTLS(served *x509.Certificate, err error) {
+	c.servedCert = served
+	c.reloadTLSError = err
+}
+
 func (c *FakeTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
 	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
 	if c.healthInfo == nil {
@@ -196,3 +322,12 @@ func (c *FakeTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBI
 func (c *FakeTiDBControl) SetServerLabels(tc *v1alpha1.TidbCluster, ordinal int32, labels map[string]string) error {
 	return c.setLabelsError
 }
+
+func (c *FakeTiDBControl) GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error) {
+	return c.config, c.getConfigError
+}
+
+func (c *FakeTiDBControl) ReloadTLS(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (*x509.Certificate, error) {
+	c.ReloadedTLS = append(c.ReloadedTLS, ordinal)
+	return c.servedCert, c.reloadTLSError
+}
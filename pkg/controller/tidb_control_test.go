@@ -259,7 +259,7 @@ func TestGetHTTPClient(t *testing.T) {
 		control := NewDefaultTiDBControl(informer.Core().V1().Secrets().Lister())
 		tc := getTidbCluster()
 		c.updateTC(tc)
-		httpClient, err := control.getHTTPClient(tc)
+		httpClient, err := control.getHTTPClient(tc, v1alpha1.TiDBMemberType)
 		g.Expect(err).NotTo(HaveOccurred())
 		c.expected(httpClient)
 	}
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/volumes"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
@@ -58,6 +59,11 @@ func NewDefaultTidbClusterControl(
 	tiflashMemberManager manager.Manager,
 	ticdcMemberManager manager.Manager,
 	discoveryManager member.TidbDiscoveryManager,
+	tlsCertManager manager.Manager,
+	tlsReloadManager manager.Manager,
+	tidbUserManager manager.Manager,
+	configDriftManager manager.Manager,
+	networkPolicyManager manager.Manager,
 	tidbClusterStatusManager manager.Manager,
 	conditionUpdater TidbClusterConditionUpdater,
 	recorder record.EventRecorder) ControlInterface {
@@ -78,6 +84,11 @@ func NewDefaultTidbClusterControl(
 		tiflashMemberManager:     tiflashMemberManager,
 		ticdcMemberManager:       ticdcMemberManager,
 		discoveryManager:         discoveryManager,
+		tlsCertManager:           tlsCertManager,
+		tlsReloadManager:         tlsReloadManager,
+		tidbUserManager:          tidbUserManager,
+		configDriftManager:       configDriftManager,
+		networkPolicyManager:     networkPolicyManager,
 		tidbClusterStatusManager: tidbClusterStatusManager,
 		conditionUpdater:         conditionUpdater,
 		recorder:                 recorder,
@@ -101,18 +112,26 @@ type defaultTidbClusterControl struct {
 	tiflashMemberManager     manager.Manager
 	ticdcMemberManager       manager.Manager
 	discoveryManager         member.TidbDiscoveryManager
+	tlsCertManager           manager.Manager
+	tlsReloadManager         manager.Manager
+	tidbUserManager          manager.Manager
+	configDriftManager       manager.Manager
+	networkPolicyManager     manager.Manager
 	tidbClusterStatusManager manager.Manager
 	conditionUpdater         TidbClusterConditionUpdater
 	recorder                 record.EventRecorder
 }
 
 // UpdateTidbCluster executes the core logic loop for a tidbcluster.
-func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster) error {
+func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster) (err error) {
 	c.defaulting(tc)
 	if !c.validate(tc) {
 		return nil // fatal error, no need to retry on invalid object
 	}
 
+	endSpan := tracing.StartClusterReconcile(tc.GetNamespace(), tc.GetName(), "UpdateTidbCluster")
+	defer func() { endSpan(err) }()
+
 	var errs []error
 	oldStatus := tc.Status.DeepCopy()
 
@@ -156,7 +175,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	tcName := tc.GetName()
 
 	// syncing all PVs managed by operator's reclaim policy to Retain
-	if err := c.reclaimPolicyManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "ReclaimPolicyManager.Sync", c.reclaimPolicyManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "pv_reclaim_policy").Inc()
 		return err
 	}
@@ -174,6 +193,30 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		}
 	}
 
+	// issuing the TLS certificates of the components from the internal CA, and renewing them before
+	// they expire
+	if err := syncWithSpan(tc, "TLSCertManager.Sync", c.tlsCertManager.Sync); err != nil {
+		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tls_cert").Inc()
+		return err
+	}
+
+	// detecting the rotation of the TLS secrets mounted by the components:
+	// - verifying the certificates served by the components which reload them online
+	// - reloading the TiDB server certificate by `ALTER INSTANCE RELOAD TLS`
+	// - restarting the components in order by the upgraders if they can not reload the certificates
+	if err := syncWithSpan(tc, "TLSReloadManager.Sync", c.tlsReloadManager.Sync); err != nil {
+		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tls_reload").Inc()
+		return err
+	}
+
+	// works that should be done to make the network policies match the components if `spec.networkPolicy` is set:
+	//   - create or update the network policy of each component
+	//   - delete the network policies of the removed components, or all of them if it is unset
+	if err := syncWithSpan(tc, "NetworkPolicyManager.Sync", c.networkPolicyManager.Sync); err != nil {
+		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "network_policy").Inc()
+		return err
+	}
+
 	// reconcile TiDB discovery service
 	if err := c.discoveryManager.Reconcile(tc); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "discovery").Inc()
@@ -194,7 +237,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - sync pdms cluster status from pdms to TidbCluster object
 	//   - upgrade the pdms cluster
 	//   - scale out/in the pdms cluster
-	if err := c.pdMSMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "PDMSMemberManager.Sync", c.pdMSMemberManager.Sync); err != nil {
 		return err
 	}
 
@@ -209,7 +252,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the pd cluster
 	//   - scale out/in the pd cluster
 	//   - failover the pd cluster
-	if err := c.pdMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "PDMemberManager.Sync", c.pdMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "pd").Inc()
 		return err
 	}
@@ -222,7 +265,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tiproxy cluster
 	//   - scale out/in the tiproxy cluster
 	//   - failover the tiproxy cluster
-	if err := c.tiproxyMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "TiProxyMemberManager.Sync", c.tiproxyMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tiproxy").Inc()
 		return err
 	}
@@ -236,7 +279,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tiflash cluster
 	//   - scale out/in the tiflash cluster
 	//   - failover the tiflash cluster
-	if err := c.tiflashMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "TiFlashMemberManager.Sync", c.tiflashMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tiflash").Inc()
 		return err
 	}
@@ -250,13 +293,13 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tikv cluster
 	//   - scale out/in the tikv cluster
 	//   - failover the tikv cluster
-	if err := c.tikvMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "TiKVMemberManager.Sync", c.tikvMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tikv").Inc()
 		return err
 	}
 
 	// syncing the pump cluster
-	if err := c.pumpMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "PumpMemberManager.Sync", c.pumpMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "pump").Inc()
 		return err
 	}
@@ -269,26 +312,45 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tidb cluster
 	//   - scale out/in the tidb cluster
 	//   - failover the tidb cluster
-	if err := c.tidbMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "TiDBMemberManager.Sync", c.tidbMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tidb").Inc()
 		return err
 	}
 
+	// works that should be done to make the SQL users and roles match `spec.tidb.users`:
+	//   - waiting for the tidb cluster available(at least one tidb is ready)
+	//   - create the users and roles, change the passwords
+	//   - grant the privileges and roles, revert the grants changed by SQL
+	//   - drop the users and roles removed from the spec
+	if err := syncWithSpan(tc, "TiDBUserManager.Sync", c.tidbUserManager.Sync); err != nil {
+		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "tidb_users").Inc()
+		return err
+	}
+
 	// works that should be done to make the ticdc cluster current state match the desired state:
 	//   - waiting for the pd cluster available(pd cluster is in quorum)
 	//   - waiting for the tikv cluster available(at least one peer works)
 	//   - create or update ticdc deployment
 	//   - sync ticdc cluster status from pd to TidbCluster object
-	if err := c.ticdcMemberManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "TiCDCMemberManager.Sync", c.ticdcMemberManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "ticdc").Inc()
 		return err
 	}
 
+	// works that should be done to detect the config drift of the components periodically:
+	//   - compare the live config of pd, tikv and tidb with the config in spec
+	//   - reapply the drifted items that can be changed online if the mode is Reapply
+	//   - update the ConfigDrift condition
+	if err := syncWithSpan(tc, "ConfigDriftManager.Sync", c.configDriftManager.Sync); err != nil {
+		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "config_drift").Inc()
+		return err
+	}
+
 	// syncing the labels from Pod to PVC and PV, these labels include:
 	//   - label.StoreIDLabelKey
 	//   - label.MemberIDLabelKey
 	//   - label.NamespaceLabelKey
-	if err := c.metaManager.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "MetaManager.Sync", c.metaManager.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "meta").Inc()
 		return err
 	}
@@ -307,14 +369,14 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 
 	// Replace volumes if necessary. Note: if enabled, takes precedence over pvcModifier.
 	if features.DefaultFeatureGate.Enabled(features.VolumeReplacing) || tc.IsPVCReplaceEnabled() {
-		if err := c.pvcReplacer.Sync(tc); err != nil {
+		if err := syncWithSpan(tc, "PVCReplacer.Sync", c.pvcReplacer.Sync); err != nil {
 			metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "pvc_replacer_sync").Inc()
 			return err
 		}
 	}
 
 	// modify volumes if necessary
-	if err := c.pvcModifier.Sync(tc); err != nil {
+	if err := syncWithSpan(tc, "PVCModifier.Sync", c.pvcModifier.Sync); err != nil {
 		metrics.ClusterUpdateErrors.WithLabelValues(ns, tcName, "pvc_modifier").Inc()
 		return err
 	}
@@ -328,6 +390,15 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	return err
 }
 
+// syncWithSpan runs the sync function in a child span of the reconcile span of tc,
+// the spans started by the sync function are children of this span.
+func syncWithSpan(tc *v1alpha1.TidbCluster, spanName string, sync func(*v1alpha1.TidbCluster) error) error {
+	endSpan := tracing.StartClusterSyncSpan(tc.GetNamespace(), tc.GetName(), spanName)
+	err := sync(tc)
+	endSpan(err)
+	return err
+}
+
 func (c *defaultTidbClusterControl) recordMetrics(tc *v1alpha1.TidbCluster) {
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
@@ -343,6 +343,11 @@ func newFakeTidbClusterControl() (
 		tiflashMemberManager,
 		ticdcMemberManager,
 		discoveryManager,
+		&mm.FakeTLSCertManager{},
+		&mm.FakeTLSReloadManager{},
+		&mm.FakeTiDBUserManager{},
+		&mm.FakeConfigDriftManager{},
+		&mm.FakeNetworkPolicyManager{},
 		statusManager,
 		&tidbClusterConditionUpdater{},
 		recorder,
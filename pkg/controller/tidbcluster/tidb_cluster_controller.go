@@ -18,8 +18,11 @@ import (
 	"time"
 
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -70,6 +73,11 @@ func NewController(deps *controller.Dependencies) *Controller {
 			mm.NewTiFlashMemberManager(deps, mm.NewTiFlashFailover(deps), mm.NewTiFlashScaler(deps), mm.NewTiFlashUpgrader(deps), suspender, podVolumeModifier),
 			mm.NewTiCDCMemberManager(deps, mm.NewTiCDCScaler(deps), mm.NewTiCDCUpgrader(deps), suspender, podVolumeModifier),
 			mm.NewTidbDiscoveryManager(deps),
+			mm.NewTLSCertManager(deps),
+			mm.NewTLSReloadManager(deps),
+			mm.NewTiDBUserManager(deps),
+			mm.NewConfigDriftManager(deps),
+			mm.NewNetworkPolicyManager(deps),
 			mm.NewTidbClusterStatusManager(deps),
 			&tidbClusterConditionUpdater{},
 			deps.Recorder,
@@ -82,6 +90,7 @@ func NewController(deps *controller.Dependencies) *Controller {
 
 	tidbClusterInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters()
 	statefulsetInformer := deps.KubeInformerFactory.Apps().V1().StatefulSets()
+	secretInformer := deps.KubeInformerFactory.Core().V1().Secrets()
 	tidbClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.enqueueTidbCluster,
 		UpdateFunc: func(old, cur interface{}) {
@@ -96,6 +105,9 @@ func NewController(deps *controller.Dependencies) *Controller {
 		},
 		DeleteFunc: c.deleteStatefulSet,
 	})
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.updateSecret,
+	})
 
 	return c
 }
@@ -274,6 +286,30 @@ func (c *Controller) deleteStatefulSet(obj interface{}) {
 	c.enqueueTidbCluster(tc)
 }
 
+// updateSecret enqueues the tidbclusters using the secret as a TLS secret after its content is changed,
+// so the rotation of the certificates is handled in time.
+func (c *Controller) updateSecret(old, cur interface{}) {
+	curSecret := cur.(*corev1.Secret)
+	oldSecret := old.(*corev1.Secret)
+	if curSecret.ResourceVersion == oldSecret.ResourceVersion || equality.Semantic.DeepEqual(curSecret.Data, oldSecret.Data) {
+		return
+	}
+
+	ns := curSecret.GetNamespace()
+	tcs, err := c.deps.TiDBClusterLister.TidbClusters(ns).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list tidbclusters in namespace %s: %v", ns, err))
+		return
+	}
+	for _, tc := range tcs {
+		if !mm.IsTLSSecretUsed(tc, curSecret.GetName()) {
+			continue
+		}
+		klog.V(4).Infof("Secret %s/%s updated, TidbCluster: %s/%s", ns, curSecret.GetName(), ns, tc.Name)
+		c.enqueueTidbCluster(tc)
+	}
+}
+
 // resolveTidbClusterFromSet returns the TidbCluster by a StatefulSet,
 // or nil if the StatefulSet could not be resolved to a matching TidbCluster
 // of the correct Kind.
@@ -194,6 +194,87 @@ func TestTidbClusterControllerUpdateStatefulSet(t *testing.T) {
 	}
 }
 
+func TestTidbClusterControllerUpdateSecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+	type testcase struct {
+		name         string
+		secretName   string
+		updateSecret func(*corev1.Secret) *corev1.Secret
+		tlsCluster   bool
+		expectedLen  int
+	}
+
+	testFn := func(test *testcase, t *testing.T) {
+		t.Log("test: ", test.name)
+
+		tc := newTidbCluster()
+		if test.tlsCluster {
+			tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+		}
+		secret1 := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            test.secretName,
+				Namespace:       corev1.NamespaceDefault,
+				ResourceVersion: "1",
+			},
+			Data: map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+		}
+		secret2 := test.updateSecret(secret1.DeepCopy())
+
+		fakeDeps := controller.NewFakeDependencies()
+		tcc := NewController(fakeDeps)
+		tcc.control = NewFakeTidbClusterControlInterface()
+		tcIndexer := fakeDeps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+		g.Expect(tcIndexer.Add(tc)).To(Succeed())
+		tcc.updateSecret(secret1, secret2)
+		g.Expect(tcc.queue.Len()).To(Equal(test.expectedLen))
+	}
+
+	rotate := func(secret *corev1.Secret) *corev1.Secret {
+		secret.ResourceVersion = "2"
+		secret.Data[corev1.TLSCertKey] = []byte("new-cert")
+		return secret
+	}
+	tests := []testcase{
+		{
+			name:         "normal",
+			secretName:   "test-pd-tikv-cluster-secret",
+			updateSecret: rotate,
+			tlsCluster:   true,
+			expectedLen:  1,
+		},
+		{
+			name:       "content is not changed",
+			secretName: "test-pd-tikv-cluster-secret",
+			updateSecret: func(secret *corev1.Secret) *corev1.Secret {
+				secret.ResourceVersion = "2"
+				secret.Labels = map[string]string{"foo": "bar"}
+				return secret
+			},
+			tlsCluster:  true,
+			expectedLen: 0,
+		},
+		{
+			name:         "TLS is not enabled",
+			secretName:   "test-pd-tikv-cluster-secret",
+			updateSecret: rotate,
+			tlsCluster:   false,
+			expectedLen:  0,
+		},
+		{
+			name:         "secret is not used",
+			secretName:   "other-secret",
+			updateSecret: rotate,
+			tlsCluster:   true,
+			expectedLen:  0,
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i], t)
+	}
+}
+
 func TestTidbClusterControllerSync(t *testing.T) {
 	g := NewGomegaWithT(t)
 	type testcase struct {
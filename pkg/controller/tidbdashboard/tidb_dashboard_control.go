@@ -102,13 +102,11 @@ func (c *defaultTiDBDashboardControl) Reconcile(td *v1alpha1.TidbDashboard) erro
 		return err
 	}
 
-	err = c.dashboardManager.Sync(td, tc)
-	if err != nil {
-		return err
-	}
+	// the status is updated even if the sync fails, it records the failure of the SSO
+	syncErr := c.dashboardManager.Sync(td, tc)
 
 	if apiequality.Semantic.DeepEqual(&td.Status, oldStatus) {
-		return nil
+		return syncErr
 	}
 
 	_, err = c.updateStatus(td.DeepCopy())
@@ -116,7 +114,7 @@ func (c *defaultTiDBDashboardControl) Reconcile(td *v1alpha1.TidbDashboard) erro
 		return err
 	}
 
-	return nil
+	return syncErr
 }
 
 func (c *defaultTiDBDashboardControl) updateStatus(td *v1alpha1.TidbDashboard) (*v1alpha1.TidbDashboard, error) {
@@ -20,3 +20,6 @@ import (
 	// Restricts all TLS configuration to FIPS-approved settings
 	_ "crypto/tls/fipsonly"
 )
+
+// Enabled indicates whether the binary is built with the FIPS-approved crypto
+const Enabled = true
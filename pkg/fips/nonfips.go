@@ -11,4 +11,10 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build !boringcrypto
+// +build !boringcrypto
+
 package fips
+
+// Enabled indicates whether the binary is built with the FIPS-approved crypto
+const Enabled = false
@@ -133,6 +133,9 @@ func (sf *commonStoreFailover) tryMarkAStoreAsFailure(tc *v1alpha1.TidbCluster)
 					sf.storeAccess.CreateFailureStoresIfAbsent(tc)
 					if len(sf.storeAccess.GetFailureStores(tc)) >= int(*maxFailoverCount) {
 						klog.Warningf("%s/%s %s failure stores count reached the limit: %d", ns, tcName, sf.storeAccess.GetMemberType(), maxFailoverCount)
+						controller.RecordClusterEvent(sf.deps.Recorder, tc, corev1.EventTypeWarning, eventReasonFailoverLimitReached,
+							controller.ClusterEventTarget{MemberType: sf.storeAccess.GetMemberType(), PodName: podName, StoreID: store.ID},
+							"store is Down but failure stores count reached the limit %d, no more failover", *maxFailoverCount)
 						return nil
 					}
 					pvcs, err := sf.failureRecovery.getPodPvcs(tc, podName)
@@ -150,8 +153,9 @@ func (sf *commonStoreFailover) tryMarkAStoreAsFailure(tc *v1alpha1.TidbCluster)
 						PVCUIDSet: pvcUIDSet,
 						CreatedAt: metav1.Now(),
 					})
-					msg := fmt.Sprintf("store[%s] is Down", store.ID)
-					sf.deps.Recorder.Event(tc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, sf.storeAccess.GetMemberType(), podName, msg))
+					controller.RecordClusterEvent(sf.deps.Recorder, tc, corev1.EventTypeWarning, eventReasonFailoverTriggered,
+						controller.ClusterEventTarget{MemberType: sf.storeAccess.GetMemberType(), PodName: podName, StoreID: store.ID},
+						"store is Down for more than %v, it's marked as failure store", sf.storeAccess.GetFailoverPeriod(sf.deps.CLIConfig))
 				}
 			}
 		}
@@ -0,0 +1,450 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	eventReasonConfigDrifted   = "ConfigDrifted"
+	eventReasonConfigReapplied = "ConfigReapplied"
+
+	conditionReasonConfigDrifted = "ConfigDrifted"
+	conditionReasonNoConfigDrift = "NoConfigDrift"
+
+	// maxConfigDriftInMessage is the max number of the drifted items listed in the message of the condition
+	maxConfigDriftInMessage = 10
+)
+
+// readableSizeRegexp matches the readable sizes used by the config of TiKV and PD, such as "1GB" and "512MiB"
+var readableSizeRegexp = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([KMGTP]?)(?:i?B)?$`)
+
+// liveConfig is the live config of an instance
+type liveConfig struct {
+	instance string
+	items    map[string]interface{}
+	// reapply changes the items online, it's nil if the config can not be changed online
+	reapply func(config map[string]interface{}) error
+}
+
+// ConfigDriftManager compares the live config of PD, TiKV and TiDB against the config in spec periodically,
+// and reports the drifted items in the ConfigDrift condition. The items only in spec are compared, since
+// the others are defaulted by the components.
+type ConfigDriftManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewConfigDriftManager returns a *ConfigDriftManager
+func NewConfigDriftManager(deps *controller.Dependencies) *ConfigDriftManager {
+	return &ConfigDriftManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *ConfigDriftManager) Sync(tc *v1alpha1.TidbCluster) error {
+	detection := tc.Spec.ConfigDriftDetection
+	if detection == nil {
+		tc.Status.ConfigDrift = nil
+		removeTidbClusterCondition(&tc.Status, v1alpha1.TidbClusterConfigDrift)
+		return nil
+	}
+	now := m.now()
+	if status := tc.Status.ConfigDrift; status != nil && status.LastCheckTime != nil &&
+		now.Sub(status.LastCheckTime.Time) < detection.GetInterval() {
+		return nil
+	}
+	reapply := detection.GetMode() == v1alpha1.ConfigDriftModeReapply
+
+	items := []v1alpha1.ConfigDriftItem{}
+	if tc.Spec.PD != nil && tc.Spec.PD.Config != nil {
+		items = append(items, m.checkComponent(tc, v1alpha1.PDMemberType, tc.Spec.PD.Config, m.livePDConfig, isPDOnlineConfig, reapply)...)
+	}
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Config != nil {
+		items = append(items, m.checkComponent(tc, v1alpha1.TiKVMemberType, tc.Spec.TiKV.Config, m.liveTiKVConfig, isTiKVOnlineConfig, reapply)...)
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.Config != nil {
+		// tidb has no API to change the config online, the drift is only reported
+		items = append(items, m.checkComponent(tc, v1alpha1.TiDBMemberType, tc.Spec.TiDB.Config, m.liveTiDBConfig, nil, false)...)
+	}
+
+	tc.Status.ConfigDrift = &v1alpha1.ConfigDriftStatus{
+		LastCheckTime: &metav1.Time{Time: now},
+		Items:         items,
+	}
+	m.setCondition(tc, items)
+	return nil
+}
+
+// configMarshaler is the config wraper of a component
+type configMarshaler interface {
+	MarshalTOML() ([]byte, error)
+}
+
+// checkComponent compares the live config of the instances of the component against the config in spec.
+// The instances whose live config can not be fetched are skipped, they are checked in the next round.
+func (m *ConfigDriftManager) checkComponent(
+	tc *v1alpha1.TidbCluster,
+	memberType v1alpha1.MemberType,
+	config configMarshaler,
+	getLive func(tc *v1alpha1.TidbCluster) ([]liveConfig, error),
+	isOnline func(key string) bool,
+	reapply bool,
+) []v1alpha1.ConfigDriftItem {
+	data, err := config.MarshalTOML()
+	if err != nil {
+		klog.Errorf("tidb cluster %s/%s: failed to marshal config of %s, error: %v", tc.Namespace, tc.Name, memberType, err)
+		return nil
+	}
+	expected := map[string]interface{}{}
+	if err := mngerutils.ParseConfigItems(string(data), expected); err != nil {
+		klog.Errorf("tidb cluster %s/%s: failed to parse config of %s, error: %v", tc.Namespace, tc.Name, memberType, err)
+		return nil
+	}
+	if len(expected) == 0 {
+		return nil
+	}
+	lives, err := getLive(tc)
+	if err != nil {
+		klog.Warningf("tidb cluster %s/%s: failed to get live config of %s, error: %v", tc.Namespace, tc.Name, memberType, err)
+	}
+
+	var items []v1alpha1.ConfigDriftItem
+	for _, live := range lives {
+		var drifted []v1alpha1.ConfigDriftItem
+		toReapply := map[string]interface{}{}
+		for key, value := range expected {
+			liveValue, ok := live.items[key]
+			// the item may be unknown or renamed by the version of the component
+			if !ok || configValueEqual(value, liveValue) {
+				continue
+			}
+			drifted = append(drifted, v1alpha1.ConfigDriftItem{
+				Component: memberType,
+				Instance:  live.instance,
+				Key:       key,
+				Expected:  configValueString(value),
+				Live:      configValueString(liveValue),
+			})
+			if reapply && live.reapply != nil && isOnline != nil && isOnline(key) {
+				toReapply[key] = value
+			}
+		}
+
+		if len(toReapply) > 0 {
+			rejected, err := applyConfigOnline(toReapply, live.reapply)
+			if err != nil {
+				klog.Errorf("tidb cluster %s/%s: failed to reapply config of %s %s, error: %v", tc.Namespace, tc.Name, memberType, live.instance, err)
+			} else {
+				if rejected.Len() > 0 {
+					klog.Warningf("tidb cluster %s/%s: config %v of %s %s is rejected by the online API, it's not reapplied",
+						tc.Namespace, tc.Name, rejected.List(), memberType, live.instance)
+				}
+				for key := range rejected {
+					delete(toReapply, key)
+				}
+				for i := range drifted {
+					_, drifted[i].Reapplied = toReapply[drifted[i].Key]
+				}
+				if len(toReapply) > 0 {
+					m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, eventReasonConfigReapplied,
+						"config %v of %s %s is reapplied with the values in spec", sortedKeys(toReapply), memberType, live.instance)
+				}
+			}
+		}
+		sort.Slice(drifted, func(i, j int) bool { return drifted[i].Key < drifted[j].Key })
+		items = append(items, drifted...)
+	}
+	return items
+}
+
+// livePDConfig returns the config persisted by PD, which is shared by all the members
+func (m *ConfigDriftManager) livePDConfig(tc *v1alpha1.TidbCluster) ([]liveConfig, error) {
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	config, err := pdClient.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	items, err := flattenJSONConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return []liveConfig{{items: items, reapply: pdClient.UpdateConfig}}, nil
+}
+
+// liveTiKVConfig returns the config of the stores that are up
+func (m *ConfigDriftManager) liveTiKVConfig(tc *v1alpha1.TidbCluster) ([]liveConfig, error) {
+	var lives []liveConfig
+	var errs []string
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		client := m.deps.TiKVControl.GetTiKVPodClient(tc.Namespace, tc.Name, store.PodName, tc.Spec.ClusterDomain, tc.IsTLSClusterEnabled())
+		config, err := client.GetConfig()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", store.PodName, err))
+			continue
+		}
+		items := map[string]interface{}{}
+		mngerutils.FlattenConfigItems("", config, items)
+		lives = append(lives, liveConfig{instance: store.PodName, items: items, reapply: client.UpdateConfig})
+	}
+	return lives, joinErrors(errs)
+}
+
+// liveTiDBConfig returns the config of the healthy tidb members
+func (m *ConfigDriftManager) liveTiDBConfig(tc *v1alpha1.TidbCluster) ([]liveConfig, error) {
+	var lives []liveConfig
+	var errs []string
+	for _, member := range tc.Status.TiDB.Members {
+		if !member.Health {
+			continue
+		}
+		ordinal, err := util.GetOrdinalFromPodName(member.Name)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", member.Name, err))
+			continue
+		}
+		config, err := m.deps.TiDBControl.GetConfig(tc, ordinal)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", member.Name, err))
+			continue
+		}
+		items := map[string]interface{}{}
+		mngerutils.FlattenConfigItems("", config, items)
+		lives = append(lives, liveConfig{instance: member.Name, items: items})
+	}
+	return lives, joinErrors(errs)
+}
+
+func (m *ConfigDriftManager) setCondition(tc *v1alpha1.TidbCluster, items []v1alpha1.ConfigDriftItem) {
+	status, reason, message := corev1.ConditionFalse, conditionReasonNoConfigDrift, "the live config matches the config in spec"
+	if len(items) > 0 {
+		status, reason = corev1.ConditionTrue, conditionReasonConfigDrifted
+		var drifts []string
+		for i, item := range items {
+			if i == maxConfigDriftInMessage {
+				drifts = append(drifts, fmt.Sprintf("and %d more", len(items)-i))
+				break
+			}
+			drift := fmt.Sprintf("%s %s: %s=%s (spec: %s)", item.Component, item.Instance, item.Key, item.Live, item.Expected)
+			if item.Instance == "" {
+				drift = fmt.Sprintf("%s: %s=%s (spec: %s)", item.Component, item.Key, item.Live, item.Expected)
+			}
+			drifts = append(drifts, drift)
+		}
+		message = fmt.Sprintf("%d config items drift from spec: %s", len(items), strings.Join(drifts, "; "))
+	}
+
+	now := metav1.NewTime(m.now())
+	for i := range tc.Status.Conditions {
+		cond := &tc.Status.Conditions[i]
+		if cond.Type != v1alpha1.TidbClusterConfigDrift {
+			continue
+		}
+		if cond.Status == status && cond.Reason == reason && cond.Message == message {
+			return
+		}
+		if cond.Status != status {
+			cond.LastTransitionTime = now
+		}
+		cond.Status, cond.Reason, cond.Message, cond.LastUpdateTime = status, reason, message, now
+		m.recordDrift(tc, items, message)
+		return
+	}
+	tc.Status.Conditions = append(tc.Status.Conditions, v1alpha1.TidbClusterCondition{
+		Type:               v1alpha1.TidbClusterConfigDrift,
+		Status:             status,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	})
+	m.recordDrift(tc, items, message)
+}
+
+func (m *ConfigDriftManager) recordDrift(tc *v1alpha1.TidbCluster, items []v1alpha1.ConfigDriftItem, message string) {
+	if len(items) == 0 {
+		return
+	}
+	klog.Warningf("tidb cluster %s/%s: %s", tc.Namespace, tc.Name, message)
+	m.deps.Recorder.Event(tc, corev1.EventTypeWarning, eventReasonConfigDrifted, message)
+}
+
+func removeTidbClusterCondition(status *v1alpha1.TidbClusterStatus, condType v1alpha1.TidbClusterConditionType) {
+	var conditions []v1alpha1.TidbClusterCondition
+	for _, c := range status.Conditions {
+		if c.Type != condType {
+			conditions = append(conditions, c)
+		}
+	}
+	status.Conditions = conditions
+}
+
+// flattenJSONConfig flattens the config struct by its JSON form
+func flattenJSONConfig(config interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	items := map[string]interface{}{}
+	mngerutils.FlattenConfigItems("", m, items)
+	return items, nil
+}
+
+// configValueEqual returns whether the value in spec equals the live value. The numbers are compared by value,
+// and the durations and sizes are compared by what they represent, since the components may format them
+// differently, such as "1h" and "1h0m0s", "1GB" and "1GiB".
+func configValueEqual(expected, live interface{}) bool {
+	if e, ok := toFloat(expected); ok {
+		if l, ok := toFloat(live); ok {
+			return e == l
+		}
+		if l, ok := parseReadableSize(live); ok {
+			return e == l
+		}
+	}
+	if e, ok := expected.(string); ok {
+		l, ok := live.(string)
+		if ok && e == l {
+			return true
+		}
+		if ed, err := time.ParseDuration(e); err == nil {
+			if ok {
+				if ld, err := time.ParseDuration(l); err == nil {
+					return ed == ld
+				}
+			}
+			return false
+		}
+		if es, ok := parseReadableSize(e); ok {
+			if ls, ok := parseReadableSize(live); ok {
+				return math.Abs(es-ls) < 1
+			}
+		}
+		return false
+	}
+	if e, ok := expected.([]interface{}); ok {
+		l, ok := live.([]interface{})
+		if !ok || len(e) != len(l) {
+			return false
+		}
+		for i := range e {
+			if !configValueEqual(e[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return configValueString(expected) == configValueString(live)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// parseReadableSize parses the size in bytes, the units are based on 1024 as the components do
+func parseReadableSize(v interface{}) (float64, bool) {
+	if n, ok := toFloat(v); ok {
+		return n, true
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0, false
+	}
+	match := readableSizeRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	if match[2] == "" {
+		return n, true
+	}
+	return n * math.Pow(1024, float64(strings.Index("KMGTP", match[2])+1)), true
+}
+
+func configValueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinErrors(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+type FakeConfigDriftManager struct {
+}
+
+func (f *FakeConfigDriftManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
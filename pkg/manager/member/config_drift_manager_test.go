@@ -0,0 +1,233 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestConfigValueEqual(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(configValueEqual(int64(3), float64(3))).To(BeTrue())
+	g.Expect(configValueEqual(int64(3), float64(4))).To(BeFalse())
+	g.Expect(configValueEqual("30m", "30m0s")).To(BeTrue())
+	g.Expect(configValueEqual("30m", "1h0m0s")).To(BeFalse())
+	g.Expect(configValueEqual("1GB", "1GiB")).To(BeTrue())
+	g.Expect(configValueEqual("512MB", "1GiB")).To(BeFalse())
+	g.Expect(configValueEqual(int64(1024), "1KiB")).To(BeTrue())
+	g.Expect(configValueEqual("info", "info")).To(BeTrue())
+	g.Expect(configValueEqual("info", "warn")).To(BeFalse())
+	g.Expect(configValueEqual(true, true)).To(BeTrue())
+	g.Expect(configValueEqual(true, false)).To(BeFalse())
+	g.Expect(configValueEqual([]interface{}{"zone", int64(1)}, []interface{}{"zone", float64(1)})).To(BeTrue())
+	g.Expect(configValueEqual([]interface{}{"zone"}, []interface{}{"zone", "host"})).To(BeFalse())
+}
+
+func TestParseReadableSize(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		value    interface{}
+		expected float64
+		ok       bool
+	}{
+		{value: int64(512), expected: 512, ok: true},
+		{value: "512", expected: 512, ok: true},
+		{value: "512B", expected: 512, ok: true},
+		{value: "1KB", expected: 1024, ok: true},
+		{value: "1.5 MiB", expected: 1.5 * 1024 * 1024, ok: true},
+		{value: "2GB", expected: 2 * 1024 * 1024 * 1024, ok: true},
+		{value: "1PiB", expected: 1024 * 1024 * 1024 * 1024 * 1024, ok: true},
+		{value: "1XB", ok: false},
+		{value: "info", ok: false},
+	}
+	for _, tt := range tests {
+		n, ok := parseReadableSize(tt.value)
+		g.Expect(ok).To(Equal(tt.ok), "value: %v", tt.value)
+		g.Expect(n).To(Equal(tt.expected), "value: %v", tt.value)
+	}
+}
+
+func TestConfigDriftManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewConfigDriftManager(deps)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	events := deps.Recorder.(*record.FakeRecorder).Events
+
+	tc := newTidbClusterForPD()
+	tc.Spec.PD.Config = v1alpha1.NewPDConfig()
+	tc.Spec.PD.Config.Set("schedule.max-snapshot-count", 3)
+	tc.Spec.PD.Config.Set("schedule.max-store-down-time", "30m")
+	tc.Spec.PD.Config.Set("log.level", "info")
+	tc.Spec.TiKV.Config = v1alpha1.NewTiKVConfig()
+	tc.Spec.TiKV.Config.Set("raftstore.raft-log-gc-threshold", 50)
+	tc.Spec.TiKV.Config.Set("storage.reserve-space", "1GB")
+	tc.Spec.TiDB.Config = v1alpha1.NewTiDBConfig()
+	tc.Spec.TiDB.Config.Set("log.slow-threshold", 300)
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateDown},
+	}
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+		"test-tidb-0": {Name: "test-tidb-0", Health: true},
+	}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	livePD := &pdapi.PDConfigFromAPI{
+		Log:      &pdapi.PDLogConfig{Level: "info"},
+		Schedule: &pdapi.PDScheduleConfig{MaxSnapshotCount: pointer.Uint64Ptr(3), MaxStoreDownTime: "30m0s"},
+	}
+	pdClient.AddReaction(pdapi.GetConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		return livePD, nil
+	})
+	var pdReapplied map[string]interface{}
+	pdClient.AddReaction(pdapi.UpdateConfigActionType, func(action *pdapi.Action) (interface{}, error) {
+		pdReapplied = action.Config
+		return nil, nil
+	})
+	tikvClient := controller.NewFakeTiKVClient(deps.TiKVControl.(*tikvapi.FakeTiKVControl), tc, "test-tikv-0")
+	liveTiKV := map[string]interface{}{
+		"raftstore": map[string]interface{}{"raft-log-gc-threshold": float64(50)},
+		"storage":   map[string]interface{}{"reserve-space": "1GiB"},
+	}
+	tikvClient.AddReaction(tikvapi.GetConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+		return liveTiKV, nil
+	})
+	var tikvReapplied map[string]interface{}
+	tikvClient.AddReaction(tikvapi.UpdateConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+		tikvReapplied = action.Config
+		return nil, nil
+	})
+	fakeTiDBControl := deps.TiDBControl.(*controller.FakeTiDBControl)
+	fakeTiDBControl.SetConfig(map[string]interface{}{
+		"log": map[string]interface{}{"slow-threshold": float64(300)},
+	}, nil)
+	driftCondition := func() *v1alpha1.TidbClusterCondition {
+		for i := range tc.Status.Conditions {
+			if tc.Status.Conditions[i].Type == v1alpha1.TidbClusterConfigDrift {
+				return &tc.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	// nothing is done if the detection is disabled
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ConfigDrift).To(BeNil())
+	g.Expect(driftCondition()).To(BeNil())
+
+	// no drift is reported if the live config matches the spec in different formats
+	tc.Spec.ConfigDriftDetection = &v1alpha1.ConfigDriftDetection{}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ConfigDrift.LastCheckTime.Time).To(Equal(now))
+	g.Expect(tc.Status.ConfigDrift.Items).To(BeEmpty())
+	g.Expect(driftCondition().Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(driftCondition().Reason).To(Equal(conditionReasonNoConfigDrift))
+	g.Expect(events).To(BeEmpty())
+
+	// the config is not checked again within the interval
+	livePD.Schedule.MaxSnapshotCount = pointer.Uint64Ptr(64)
+	liveTiKV["storage"] = map[string]interface{}{"reserve-space": "2GiB"}
+	fakeTiDBControl.SetConfig(map[string]interface{}{
+		"log": map[string]interface{}{"slow-threshold": float64(100)},
+	}, nil)
+	now = now.Add(time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ConfigDrift.Items).To(BeEmpty())
+
+	// the drifted items are reported in Detect mode
+	now = now.Add(5 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ConfigDrift.Items).To(Equal([]v1alpha1.ConfigDriftItem{
+		{Component: v1alpha1.PDMemberType, Key: "schedule.max-snapshot-count", Expected: "3", Live: "64"},
+		{Component: v1alpha1.TiKVMemberType, Instance: "test-tikv-0", Key: "storage.reserve-space", Expected: "1GB", Live: "2GiB"},
+		{Component: v1alpha1.TiDBMemberType, Instance: "test-tidb-0", Key: "log.slow-threshold", Expected: "300", Live: "100"},
+	}))
+	g.Expect(driftCondition().Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(driftCondition().Reason).To(Equal(conditionReasonConfigDrifted))
+	g.Expect(driftCondition().Message).To(ContainSubstring("3 config items"))
+	g.Expect(driftCondition().Message).To(ContainSubstring("tikv test-tikv-0: storage.reserve-space=2GiB (spec: 1GB)"))
+	g.Expect(<-events).To(ContainSubstring(eventReasonConfigDrifted))
+	g.Expect(pdReapplied).To(BeNil())
+	g.Expect(tikvReapplied).To(BeNil())
+
+	// the drifted items that can be changed online are reapplied in Reapply mode
+	tc.Spec.ConfigDriftDetection.Mode = v1alpha1.ConfigDriftModeReapply
+	liveTiKV["raftstore"] = map[string]interface{}{"raft-log-gc-threshold": float64(100)}
+	now = now.Add(5 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(pdReapplied).To(Equal(map[string]interface{}{"schedule.max-snapshot-count": int64(3)}))
+	g.Expect(tikvReapplied).To(Equal(map[string]interface{}{"raftstore.raft-log-gc-threshold": int64(50)}))
+	items := tc.Status.ConfigDrift.Items
+	g.Expect(items).To(HaveLen(4))
+	g.Expect(items[0].Reapplied).To(BeTrue())
+	g.Expect(items[1].Key).To(Equal("raftstore.raft-log-gc-threshold"))
+	g.Expect(items[1].Reapplied).To(BeTrue())
+	g.Expect(items[2].Key).To(Equal("storage.reserve-space"))
+	g.Expect(items[2].Reapplied).To(BeFalse())
+	g.Expect(items[3].Reapplied).To(BeFalse())
+	g.Expect(driftCondition().Message).To(ContainSubstring("4 config items"))
+	for len(events) > 0 {
+		<-events
+	}
+
+	// the instances whose config can not be fetched are skipped
+	tikvClient.AddReaction(tikvapi.GetConfigActionType, func(action *tikvapi.Action) (interface{}, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	livePD.Schedule.MaxSnapshotCount = pointer.Uint64Ptr(3)
+	fakeTiDBControl.SetConfig(map[string]interface{}{
+		"log": map[string]interface{}{"slow-threshold": float64(300)},
+	}, nil)
+	lastTransitionTime := driftCondition().LastTransitionTime
+	now = now.Add(5 * time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ConfigDrift.Items).To(BeEmpty())
+	g.Expect(driftCondition().Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(driftCondition().LastTransitionTime).NotTo(Equal(lastTransitionTime))
+	g.Expect(events).To(BeEmpty())
+
+	// the status and condition are cleared after the detection is disabled
+	tc.Spec.ConfigDriftDetection = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.ConfigDrift).To(BeNil())
+	g.Expect(driftCondition()).To(BeNil())
+}
+
+func TestConfigDriftInterval(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	detection := &v1alpha1.ConfigDriftDetection{}
+	g.Expect(detection.GetMode()).To(Equal(v1alpha1.ConfigDriftModeDetect))
+	g.Expect(detection.GetInterval()).To(Equal(5 * time.Minute))
+	detection.Interval = &metav1.Duration{Duration: time.Hour}
+	g.Expect(detection.GetInterval()).To(Equal(time.Hour))
+}
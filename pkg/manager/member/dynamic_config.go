@@ -0,0 +1,311 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	eventReasonDynamicConfigApplied = "DynamicConfigApplied"
+)
+
+var (
+	// tikvOnlineConfigItems are the items of TiKV that can be changed online through the `/config` API,
+	// refer to https://docs.pingcap.com/tidb/stable/dynamic-config
+	tikvOnlineConfigItems = sets.NewString(
+		"raftstore.raft-max-inflight-msgs",
+		"raftstore.raft-log-gc-tick-interval",
+		"raftstore.raft-log-gc-threshold",
+		"raftstore.raft-log-gc-count-limit",
+		"raftstore.raft-log-gc-size-limit",
+		"raftstore.raft-max-size-per-msg",
+		"raftstore.raft-entry-max-size",
+		"raftstore.raft-entry-cache-life-time",
+		"raftstore.max-apply-unpersisted-log-limit",
+		"raftstore.split-region-check-tick-interval",
+		"raftstore.region-split-check-diff",
+		"raftstore.region-compact-check-interval",
+		"raftstore.region-compact-check-step",
+		"raftstore.region-compact-min-tombstones",
+		"raftstore.region-compact-tombstones-percent",
+		"raftstore.region-compact-min-redundant-rows",
+		"raftstore.region-compact-redundant-rows-percent",
+		"raftstore.pd-heartbeat-tick-interval",
+		"raftstore.pd-store-heartbeat-tick-interval",
+		"raftstore.snap-mgr-gc-tick-interval",
+		"raftstore.snap-gc-timeout",
+		"raftstore.lock-cf-compact-interval",
+		"raftstore.lock-cf-compact-bytes-threshold",
+		"raftstore.messages-per-tick",
+		"raftstore.max-peer-down-duration",
+		"raftstore.max-leader-missing-duration",
+		"raftstore.abnormal-leader-missing-duration",
+		"raftstore.peer-stale-state-check-interval",
+		"raftstore.consistency-check-interval",
+		"raftstore.raft-store-max-leader-lease",
+		"raftstore.merge-check-tick-interval",
+		"raftstore.cleanup-import-sst-interval",
+		"raftstore.local-read-batch-size",
+		"raftstore.apply-yield-write-size",
+		"raftstore.hibernate-timeout",
+		"raftstore.apply-pool-size",
+		"raftstore.store-pool-size",
+		"raftstore.apply-max-batch-size",
+		"raftstore.store-max-batch-size",
+		"raftstore.store-io-pool-size",
+		"raftstore.periodic-full-compact-start-times",
+		"raftstore.periodic-full-compact-start-max-cpu",
+		"readpool.unified.max-thread-count",
+		"readpool.unified.auto-adjust-pool-size",
+		"coprocessor.split-region-on-table",
+		"coprocessor.batch-split-limit",
+		"coprocessor.region-max-size",
+		"coprocessor.region-split-size",
+		"coprocessor.region-max-keys",
+		"coprocessor.region-split-keys",
+		"pessimistic-txn.wait-for-lock-timeout",
+		"pessimistic-txn.wake-up-delay-duration",
+		"pessimistic-txn.pipelined",
+		"pessimistic-txn.in-memory",
+		"quota.foreground-cpu-time",
+		"quota.foreground-write-bandwidth",
+		"quota.foreground-read-bandwidth",
+		"quota.background-cpu-time",
+		"quota.background-write-bandwidth",
+		"quota.background-read-bandwidth",
+		"quota.enable-auto-tune",
+		"gc.ratio-threshold",
+		"gc.batch-keys",
+		"gc.max-write-bytes-per-sec",
+		"gc.enable-compaction-filter",
+		"gc.compaction-filter-skip-version-check",
+		"server.grpc-memory-pool-quota",
+		"server.max-grpc-send-msg-len",
+		"server.raft-msg-max-batch-size",
+		"server.simplify-metrics",
+		"server.snap-io-max-bytes-per-sec",
+		"server.concurrent-send-snap-limit",
+		"server.concurrent-recv-snap-limit",
+		"storage.block-cache.capacity",
+		"storage.scheduler-worker-pool-size",
+		"storage.io-rate-limit.max-bytes-per-sec",
+		"storage.flow-control.enable",
+		"storage.flow-control.soft-pending-compaction-bytes-limit",
+		"storage.flow-control.hard-pending-compaction-bytes-limit",
+		"storage.flow-control.memtables-threshold",
+		"storage.flow-control.l0-files-threshold",
+		"backup.num-threads",
+		"split.qps-threshold",
+		"split.byte-threshold",
+		"split.region-cpu-overload-threshold-ratio",
+		"split.split-balance-score",
+		"split.split-contained-score",
+		"cdc.min-ts-interval",
+		"cdc.old-value-cache-memory-quota",
+		"cdc.sink-memory-quota",
+		"cdc.incremental-scan-speed-limit",
+		"cdc.incremental-scan-concurrency-limit",
+		"rocksdb.defaultcf.titan.blob-run-mode",
+	).Union(tikvRocksDBOnlineConfigItems())
+	// pdOnlineConfigPrefixes are the sections of the PD configuration that can be changed online through
+	// the `/pd/api/v1/config` API. They are persisted by PD after bootstrap, so the changes in the config
+	// file don't take effect even after restart.
+	pdOnlineConfigPrefixes = []string{
+		"schedule.",
+		"replication.",
+		"pd-server.",
+		"replication-mode.",
+	}
+)
+
+// tikvRocksDBOnlineConfigItems returns the items of the RocksDB instances of TiKV that can be changed online
+func tikvRocksDBOnlineConfigItems() sets.String {
+	items := sets.NewString()
+	dbItems := []string{
+		"max-total-wal-size",
+		"max-background-jobs",
+		"max-background-flushes",
+		"max-open-files",
+		"compaction-readahead-size",
+		"bytes-per-sync",
+		"wal-bytes-per-sync",
+		"writable-file-max-buffer-size",
+		"rate-bytes-per-sec",
+		"rate-limiter-auto-tuned",
+	}
+	cfItems := []string{
+		"block-cache-size",
+		"write-buffer-size",
+		"max-write-buffer-number",
+		"max-bytes-for-level-base",
+		"target-file-size-base",
+		"level0-file-num-compaction-trigger",
+		"level0-slowdown-writes-trigger",
+		"level0-stop-writes-trigger",
+		"max-compaction-bytes",
+		"max-bytes-for-level-multiplier",
+		"disable-auto-compactions",
+		"soft-pending-compaction-bytes-limit",
+		"hard-pending-compaction-bytes-limit",
+	}
+	cfs := map[string][]string{
+		"rocksdb": {"defaultcf", "writecf", "lockcf"},
+		"raftdb":  {"defaultcf"},
+	}
+	for db, dbCFs := range cfs {
+		for _, item := range dbItems {
+			items.Insert(db + "." + item)
+		}
+		for _, cf := range dbCFs {
+			for _, item := range cfItems {
+				items.Insert(db + "." + cf + "." + item)
+			}
+		}
+	}
+	return items
+}
+
+// isTiKVOnlineConfig returns whether the item of TiKV can be changed online
+func isTiKVOnlineConfig(key string) bool {
+	return tikvOnlineConfigItems.Has(key)
+}
+
+// isPDOnlineConfig returns whether the item of PD can be changed online
+func isPDOnlineConfig(key string) bool {
+	for _, prefix := range pdOnlineConfigPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigRejected returns whether the config is rejected by the online API of the component, such as the
+// items can not be changed online by the version, instead of failing to connect to the component
+func isConfigRejected(err error) bool {
+	var statusErr *httputil.StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusBadRequest || statusErr.StatusCode == http.StatusInternalServerError)
+}
+
+// applyConfigOnline changes the config online, and returns the items rejected by the component. The items
+// are applied one by one to find out the rejected ones if the config is rejected.
+func applyConfigOnline(config map[string]interface{}, applyOnline func(config map[string]interface{}) error) (sets.String, error) {
+	rejected := sets.NewString()
+	err := applyOnline(config)
+	if err == nil || !isConfigRejected(err) {
+		return rejected, err
+	}
+	for _, key := range sortedKeys(config) {
+		if err := applyOnline(map[string]interface{}{key: config[key]}); err != nil {
+			if !isConfigRejected(err) {
+				return nil, err
+			}
+			rejected.Insert(key)
+		}
+	}
+	return rejected, nil
+}
+
+// syncDynamicConfigMap syncs the ConfigMap with the Dynamic strategy. The changed items that can be changed
+// online are applied before the ConfigMap is updated, so they are applied again in the next round if it fails.
+// The items rejected by the online API are applied by a rolling restart like the others.
+func syncDynamicConfigMap(
+	deps *controller.Dependencies,
+	tc *v1alpha1.TidbCluster,
+	memberType v1alpha1.MemberType,
+	status **v1alpha1.DynamicConfigStatus,
+	set *apps.StatefulSet,
+	inUseName string,
+	newCm *corev1.ConfigMap,
+	isOnline func(key string) bool,
+	applyOnline func(config map[string]interface{}) error,
+) (*corev1.ConfigMap, error) {
+	// the items pending restart are applied by the rolling restart in progress
+	rejected := sets.NewString()
+	if *status != nil {
+		rejected.Insert((*status).PendingRestart...)
+	}
+	isApplicable := func(key string) bool {
+		return isOnline(key) && !rejected.Has(key)
+	}
+	name := newCm.Name
+	diff, err := mngerutils.UpdateConfigMapDynamically(deps.ConfigMapLister, inUseName, newCm, isApplicable)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff.Online) > 0 {
+		if rejected, err = applyConfigOnline(diff.Online, applyOnline); err != nil {
+			return nil, fmt.Errorf("apply config %v of %s online failed: %v", diff.OnlineKeys(), memberType, err)
+		}
+		if rejected.Len() > 0 {
+			klog.Warningf("tidb cluster %s/%s: config %v of %s is rejected by the online API, it's applied by a rolling restart",
+				tc.Namespace, tc.Name, rejected.List(), memberType)
+			// the name of the ConfigMap is decided again to restart the pods
+			newCm.Name = name
+			if diff, err = mngerutils.UpdateConfigMapDynamically(deps.ConfigMapLister, inUseName, newCm, isApplicable); err != nil {
+				return nil, err
+			}
+		}
+		if len(diff.Online) > 0 {
+			klog.Infof("tidb cluster %s/%s: config %v of %s is applied online", tc.Namespace, tc.Name, diff.OnlineKeys(), memberType)
+		}
+	}
+	cm, err := deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diff.Online) > 0 || len(diff.PendingRestart) > 0 {
+		pending := sets.NewString(diff.PendingRestart...)
+		if *status != nil {
+			// the items of the rolling restart in progress are still pending
+			pending.Insert((*status).PendingRestart...)
+		}
+		*status = &v1alpha1.DynamicConfigStatus{
+			AppliedOnline:  diff.OnlineKeys(),
+			PendingRestart: pending.List(),
+			LastUpdateTime: &metav1.Time{Time: time.Now()},
+		}
+		if len(diff.PendingRestart) > 0 {
+			klog.Infof("tidb cluster %s/%s: config %v of %s needs a rolling restart", tc.Namespace, tc.Name, diff.PendingRestart, memberType)
+		}
+		deps.Recorder.Eventf(tc, corev1.EventTypeNormal, eventReasonDynamicConfigApplied,
+			"config of %s is updated, applied online: %v, pending restart: %v", memberType, diff.OnlineKeys(), diff.PendingRestart)
+		return cm, nil
+	}
+
+	// the rolling restart is done after all the pods use the current ConfigMap
+	if *status != nil && len((*status).PendingRestart) > 0 &&
+		set != nil && inUseName == newCm.Name && !mngerutils.StatefulSetIsUpgrading(set) {
+		(*status).PendingRestart = nil
+	}
+	return cm, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+
+	. "github.com/onsi/gomega"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestIsOnlineConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(isTiKVOnlineConfig("raftstore.raft-log-gc-threshold")).To(BeTrue())
+	g.Expect(isTiKVOnlineConfig("rocksdb.defaultcf.block-cache-size")).To(BeTrue())
+	g.Expect(isTiKVOnlineConfig("storage.block-cache.capacity")).To(BeTrue())
+	g.Expect(isTiKVOnlineConfig("rocksdb.wal-dir")).To(BeFalse())
+	g.Expect(isTiKVOnlineConfig("storage.reserve-space")).To(BeFalse())
+	g.Expect(isTiKVOnlineConfig("server.grpc-concurrency")).To(BeFalse())
+	g.Expect(isTiKVOnlineConfig("raftstore.raftdb-path")).To(BeFalse())
+	g.Expect(isTiKVOnlineConfig("rocksdb.info-log-dir")).To(BeFalse())
+	g.Expect(isTiKVOnlineConfig("rocksdb.defaultcf.compression-per-level")).To(BeFalse())
+
+	g.Expect(isPDOnlineConfig("schedule.max-snapshot-count")).To(BeTrue())
+	g.Expect(isPDOnlineConfig("replication.max-replicas")).To(BeTrue())
+	g.Expect(isPDOnlineConfig("log.file.max-days")).To(BeFalse())
+}
+
+func TestSyncDynamicConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	cmIndexer := deps.LabelFilterKubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer()
+	events := deps.Recorder.(*record.FakeRecorder).Events
+	tc := newTidbClusterForPD()
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: "test-tikv-1234"},
+		Data: map[string]string{
+			"config-file":    "[raftstore]\nraft-log-gc-threshold = 50\n[storage]\nreserve-space = \"1GB\"\n",
+			"startup-script": "start",
+		},
+	}
+	g.Expect(cmIndexer.Add(existing)).To(Succeed())
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(3)},
+		Status:     apps.StatefulSetStatus{ObservedGeneration: 1, Replicas: 3, CurrentRevision: "1", UpdateRevision: "1"},
+	}
+	newCm := func(config string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: "test-tikv"},
+			Data:       map[string]string{"config-file": config, "startup-script": "start"},
+		}
+	}
+	var applied map[string]interface{}
+	var applyErr error
+	rejected := sets.NewString()
+	sync := func(inUseName string, cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+		applied = nil
+		return syncDynamicConfigMap(deps, tc, v1alpha1.TiKVMemberType, &tc.Status.TiKV.DynamicConfig, set, inUseName, cm,
+			isTiKVOnlineConfig, func(config map[string]interface{}) error {
+				for key := range config {
+					if rejected.Has(key) {
+						return &httputil.StatusError{StatusCode: http.StatusInternalServerError, Message: key + " can not be changed"}
+					}
+				}
+				applied = config
+				return applyErr
+			})
+	}
+
+	// the ConfigMap is not updated if the config fails to be applied online
+	applyErr = fmt.Errorf("connection refused")
+	_, err := sync(existing.Name, newCm("[raftstore]\nraft-log-gc-threshold = 100\n[storage]\nreserve-space = \"1GB\"\n"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(tc.Status.TiKV.DynamicConfig).To(BeNil())
+
+	// the ConfigMap is updated in place if all the items are applied online
+	applyErr = nil
+	cm, err := sync(existing.Name, newCm("[raftstore]\nraft-log-gc-threshold = 100\n[storage]\nreserve-space = \"1GB\"\n"))
+	g.Expect(err).To(Succeed())
+	g.Expect(cm.Name).To(Equal(existing.Name))
+	g.Expect(applied).To(Equal(map[string]interface{}{"raftstore.raft-log-gc-threshold": int64(100)}))
+	g.Expect(tc.Status.TiKV.DynamicConfig.AppliedOnline).To(Equal([]string{"raftstore.raft-log-gc-threshold"}))
+	g.Expect(tc.Status.TiKV.DynamicConfig.PendingRestart).To(BeEmpty())
+	g.Expect(<-events).To(ContainSubstring(eventReasonDynamicConfigApplied))
+	g.Expect(cmIndexer.Update(cm)).To(Succeed())
+
+	// the items rejected by the online API are applied by a rolling restart
+	rejected.Insert("gc.ratio-threshold")
+	cm, err = sync(existing.Name, newCm("[raftstore]\nraft-log-gc-threshold = 150\n[gc]\nratio-threshold = 1.2\n[storage]\nreserve-space = \"1GB\"\n"))
+	g.Expect(err).To(Succeed())
+	g.Expect(cm.Name).NotTo(Equal(existing.Name))
+	g.Expect(applied).To(Equal(map[string]interface{}{"raftstore.raft-log-gc-threshold": int64(150)}))
+	g.Expect(tc.Status.TiKV.DynamicConfig.AppliedOnline).To(Equal([]string{"raftstore.raft-log-gc-threshold"}))
+	g.Expect(tc.Status.TiKV.DynamicConfig.PendingRestart).To(Equal([]string{"gc.ratio-threshold"}))
+	<-events
+	rejected = sets.NewString()
+	tc.Status.TiKV.DynamicConfig = nil
+
+	// a new ConfigMap is generated for the items that need a restart
+	cm, err = sync(existing.Name, newCm("[raftstore]\nraft-log-gc-threshold = 200\n[storage]\nreserve-space = \"2GB\"\n"))
+	g.Expect(err).To(Succeed())
+	g.Expect(cm.Name).NotTo(Equal(existing.Name))
+	g.Expect(applied).To(Equal(map[string]interface{}{"raftstore.raft-log-gc-threshold": int64(200)}))
+	g.Expect(tc.Status.TiKV.DynamicConfig.AppliedOnline).To(Equal([]string{"raftstore.raft-log-gc-threshold"}))
+	g.Expect(tc.Status.TiKV.DynamicConfig.PendingRestart).To(Equal([]string{"storage.reserve-space"}))
+	<-events
+	g.Expect(cmIndexer.Add(cm)).To(Succeed())
+
+	// the pending items are kept during the rolling restart
+	set.Status.UpdateRevision = "2"
+	_, err = sync(cm.Name, newCm(cm.Data["config-file"]))
+	g.Expect(err).To(Succeed())
+	g.Expect(applied).To(BeNil())
+	g.Expect(tc.Status.TiKV.DynamicConfig.PendingRestart).To(Equal([]string{"storage.reserve-space"}))
+
+	// the pending items are cleared after the rolling restart is done
+	set.Status.CurrentRevision = "2"
+	_, err = sync(cm.Name, newCm(cm.Data["config-file"]))
+	g.Expect(err).To(Succeed())
+	g.Expect(tc.Status.TiKV.DynamicConfig.PendingRestart).To(BeEmpty())
+	g.Expect(tc.Status.TiKV.DynamicConfig.AppliedOnline).To(Equal([]string{"raftstore.raft-log-gc-threshold"}))
+	g.Expect(events).To(BeEmpty())
+}
@@ -27,6 +27,26 @@ const (
 	recoveryEventReason     = "Recovery"
 )
 
+// Reasons of the events recorded on TidbCluster for the decisions made by the operator.
+// Users and tools may filter events by them, so they must not be changed once released.
+const (
+	// eventReasonFailoverTriggered means a member is marked as failure and a new one will be created for it
+	eventReasonFailoverTriggered = "FailoverTriggered"
+	// eventReasonFailoverLimitReached means a member should be failed over but the max failover count is reached
+	eventReasonFailoverLimitReached = "FailoverLimitReached"
+	// eventReasonScaleInBlocked means a member can't be scaled in for the safety of the cluster
+	eventReasonScaleInBlocked = "ScaleInBlocked"
+	// eventReasonUpgradeBlocked means a component can't be upgraded until other components are ready
+	eventReasonUpgradeBlocked = "UpgradeBlocked"
+	// eventReasonUpgradeWaitingForStability means the upgrade of the next pod waits for the cluster to be stable
+	eventReasonUpgradeWaitingForStability = "UpgradeWaitingForStability"
+	// eventReasonEvictLeaderTimeout means the leaders of a store are not evicted in time, and the pod is restarted anyway
+	eventReasonEvictLeaderTimeout = "EvictLeaderTimeout"
+	// eventReasonTransferLeaderBackTimeout means the leaders are not transferred back to an upgraded store in time,
+	// and the upgrade continues anyway
+	eventReasonTransferLeaderBackTimeout = "TransferLeaderBackTimeout"
+)
+
 // Failover implements the logic for pd/tikv/tidb's failover and recovery.
 type Failover interface {
 	Failover(*v1alpha1.TidbCluster) error
@@ -0,0 +1,359 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// operatorNameLabelVal is the name label of the pods deployed by the chart of TiDB Operator
+	operatorNameLabelVal = "tidb-operator"
+	// drainerLabelVal is the component label of the pods deployed by the chart of Drainer
+	drainerLabelVal = "drainer"
+	// prometheusNameLabelVal is the name label of the Prometheus pods deployed by the Prometheus Operator
+	prometheusNameLabelVal = "prometheus"
+)
+
+// networkPolicyPeers are the peers the ingress rules of the components are built from
+type networkPolicyPeers struct {
+	// clusters are the pods of the cluster and the heterogeneous clusters sharing the same PD, the components
+	// are allowed to access each other on the ports they require
+	clusters []networkingv1.NetworkPolicyPeer
+	// clusterSelectors are the selectors of the pods of the clusters, which are narrowed to the components
+	clusterSelectors []clusterPodSelector
+	// drainer are the pods of the Drainer deployed for the cluster
+	drainer  []networkingv1.NetworkPolicyPeer
+	clients  []networkingv1.NetworkPolicyPeer
+	operator []networkingv1.NetworkPolicyPeer
+	monitor  []networkingv1.NetworkPolicyPeer
+	tools    []networkingv1.NetworkPolicyPeer
+}
+
+type clusterPodSelector struct {
+	namespace string
+	instance  string
+}
+
+// components returns the peers of the given components of the clusters
+func (p *networkPolicyPeers) components(components ...string) []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(p.clusterSelectors))
+	for _, s := range p.clusterSelectors {
+		selector := label.New().Instance(s.instance).LabelSelector()
+		selector.MatchExpressions = []metav1.LabelSelectorRequirement{{
+			Key:      label.ComponentLabelKey,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   components,
+		}}
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector:       selector,
+			NamespaceSelector: namespaceSelector(s.namespace),
+		})
+	}
+	return peers
+}
+
+// NetworkPolicyManager generates a NetworkPolicy for each component of the TidbCluster when `spec.networkPolicy`
+// is set, which only allows the ingress traffic required by the cluster on the ports of the component. The ports
+// are the same as the ones exposed by the member managers.
+type NetworkPolicyManager struct {
+	deps *controller.Dependencies
+}
+
+// NewNetworkPolicyManager returns a *NetworkPolicyManager
+func NewNetworkPolicyManager(deps *controller.Dependencies) *NetworkPolicyManager {
+	return &NetworkPolicyManager{
+		deps: deps,
+	}
+}
+
+func (m *NetworkPolicyManager) Sync(tc *v1alpha1.TidbCluster) error {
+	var desired []*networkingv1.NetworkPolicy
+	if tc.Spec.NetworkPolicy != nil {
+		var err error
+		if desired, err = m.getNetworkPolicies(tc); err != nil {
+			return err
+		}
+	}
+
+	names := sets.NewString()
+	for _, policy := range desired {
+		names.Insert(policy.Name)
+		if _, err := m.deps.TypedControl.CreateOrUpdateNetworkPolicy(tc, policy); err != nil {
+			return fmt.Errorf("sync network policy %s/%s for tc %s/%s failed: %v", policy.Namespace, policy.Name, tc.Namespace, tc.Name, err)
+		}
+	}
+
+	// delete the network policies of the removed components, or all of them if the network policy is disabled
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return err
+	}
+	policies, err := m.deps.NetworkPolicyLister.NetworkPolicies(tc.Namespace).List(selector)
+	if err != nil {
+		return fmt.Errorf("list network policies for tc %s/%s failed: %v", tc.Namespace, tc.Name, err)
+	}
+	for _, policy := range policies {
+		if names.Has(policy.Name) || !metav1.IsControlledBy(policy, tc) {
+			continue
+		}
+		if err := m.deps.TypedControl.Delete(tc, policy); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete network policy %s/%s for tc %s/%s failed: %v", policy.Namespace, policy.Name, tc.Namespace, tc.Name, err)
+		}
+		klog.Infof("network policy %s/%s of tc %s/%s is deleted", policy.Namespace, policy.Name, tc.Namespace, tc.Name)
+	}
+	return nil
+}
+
+// getNetworkPolicies returns the network policies of the components in the spec
+func (m *NetworkPolicyManager) getNetworkPolicies(tc *v1alpha1.TidbCluster) ([]*networkingv1.NetworkPolicy, error) {
+	p, err := m.getPeers(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*networkingv1.NetworkPolicy
+	add := func(component string, rules ...networkingv1.NetworkPolicyIngressRule) {
+		policies = append(policies, newNetworkPolicy(tc, component, rules))
+	}
+
+	if tc.Spec.PD != nil || len(tc.Spec.PDMS) > 0 {
+		// discovery is deployed for PD and PD microservices
+		add(label.DiscoveryLabelVal,
+			ingressRule([]int32{v1alpha1.DefaultDiscoveryPort}, p.clusters, p.operator),
+			ingressRule([]int32{v1alpha1.DefaultDiscoveryProxyPort}, p.clusters, p.operator, p.tools),
+		)
+	}
+	if tc.Spec.PD != nil {
+		add(label.PDLabelVal,
+			ingressRule([]int32{v1alpha1.DefaultPDClientPort}, p.clusters, p.drainer, p.operator, p.monitor, p.tools),
+			ingressRule([]int32{v1alpha1.DefaultPDPeerPort}, p.components(label.PDLabelVal)),
+		)
+	}
+	for _, pdms := range tc.Spec.PDMS {
+		if pdms == nil {
+			continue
+		}
+		add(label.New().PDMS(pdms.Name)[label.ComponentLabelKey],
+			ingressRule([]int32{v1alpha1.DefaultPDClientPort}, p.clusters, p.operator, p.monitor),
+		)
+	}
+	if tc.Spec.TiKV != nil {
+		add(label.TiKVLabelVal,
+			ingressRule([]int32{v1alpha1.DefaultTiKVServerPort},
+				p.components(label.PDLabelVal, label.TiDBLabelVal, label.TiKVLabelVal, label.TiFlashLabelVal, label.TiCDCLabelVal), p.tools),
+			ingressRule([]int32{v1alpha1.DefaultTiKVStatusPort}, p.clusters, p.operator, p.monitor, p.tools),
+		)
+	}
+	if tc.Spec.TiDB != nil {
+		add(label.TiDBLabelVal,
+			clientsIngressRule([]int32{v1alpha1.DefaultTiDBServerPort}, p.clients),
+			ingressRule([]int32{v1alpha1.DefaultTiDBServerPort}, p.components(label.TiProxyLabelVal), p.operator, p.tools),
+			ingressRule([]int32{v1alpha1.DefaultTiDBStatusPort}, p.clusters, p.operator, p.monitor, p.tools),
+		)
+	}
+	if tc.Spec.TiFlash != nil {
+		add(label.TiFlashLabelVal,
+			ingressRule([]int32{v1alpha1.DefaultTiFlashFlashPort}, p.components(label.TiDBLabelVal, label.TiFlashLabelVal), p.tools),
+			ingressRule([]int32{v1alpha1.DefaultTiFlashProxyPort}, p.components(label.TiKVLabelVal, label.TiFlashLabelVal)),
+			ingressRule([]int32{v1alpha1.DefaultTiFlashTcpPort, v1alpha1.DefaultTiFlashHttpPort, v1alpha1.DefaultTiFlashInternalPort},
+				p.components(label.TiFlashLabelVal)),
+			ingressRule([]int32{v1alpha1.DefaultTiFlashMetricsPort, v1alpha1.DefaultTiFlashProxyStatusPort}, p.clusters, p.operator, p.monitor),
+		)
+	}
+	if tc.Spec.TiCDC != nil {
+		add(label.TiCDCLabelVal,
+			ingressRule([]int32{v1alpha1.DefaultTiCDCPort}, p.clusters, p.operator, p.monitor, p.tools),
+		)
+	}
+	if tc.Spec.Pump != nil {
+		add(label.PumpLabelVal,
+			ingressRule([]int32{v1alpha1.DefaultPumpPort}, p.clusters, p.drainer, p.operator, p.monitor, p.tools),
+		)
+	}
+	if tc.Spec.TiProxy != nil {
+		add(label.TiProxyLabelVal,
+			clientsIngressRule([]int32{v1alpha1.DefaultTiProxyServerPort}, p.clients),
+			ingressRule([]int32{v1alpha1.DefaultTiProxyServerPort}, p.operator, p.tools),
+			ingressRule([]int32{v1alpha1.DefaultTiProxyStatusPort}, p.clusters, p.operator, p.monitor),
+			ingressRule([]int32{v1alpha1.DefaultTiProxyPeerPort}, p.components(label.TiProxyLabelVal)),
+		)
+	}
+	return policies, nil
+}
+
+// getPeers returns the peers of the cluster. The heterogeneous clusters sharing the same PD with the cluster are
+// regarded as the same cluster, since their components access each other.
+func (m *NetworkPolicyManager) getPeers(tc *v1alpha1.TidbCluster) (*networkPolicyPeers, error) {
+	spec := tc.Spec.NetworkPolicy
+
+	baseNamespace, baseName := tc.Namespace, tc.Name
+	if tc.Heterogeneous() {
+		baseName = tc.Spec.Cluster.Name
+		if tc.Spec.Cluster.Namespace != "" {
+			baseNamespace = tc.Spec.Cluster.Namespace
+		}
+	}
+	selectors := map[clusterPodSelector]struct{}{
+		{namespace: tc.Namespace, instance: tc.GetInstanceName()}: {},
+	}
+	// the peers can not be generated from part of the clusters, which would deny the traffic from the others
+	tcs, err := m.deps.TiDBClusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list tidb clusters for the network policies of tc %s/%s failed: %v", tc.Namespace, tc.Name, err)
+	}
+	for _, other := range tcs {
+		isBase := other.Namespace == baseNamespace && other.Name == baseName
+		refsBase := false
+		if other.Heterogeneous() {
+			ns := other.Spec.Cluster.Namespace
+			if ns == "" {
+				ns = other.Namespace
+			}
+			refsBase = ns == baseNamespace && other.Spec.Cluster.Name == baseName
+		}
+		if isBase || refsBase {
+			selectors[clusterPodSelector{namespace: other.Namespace, instance: other.GetInstanceName()}] = struct{}{}
+		}
+	}
+
+	p := &networkPolicyPeers{}
+	for s := range selectors {
+		p.clusterSelectors = append(p.clusterSelectors, s)
+	}
+	sort.Slice(p.clusterSelectors, func(i, j int) bool {
+		if p.clusterSelectors[i].namespace != p.clusterSelectors[j].namespace {
+			return p.clusterSelectors[i].namespace < p.clusterSelectors[j].namespace
+		}
+		return p.clusterSelectors[i].instance < p.clusterSelectors[j].instance
+	})
+	for _, s := range p.clusterSelectors {
+		p.clusters = append(p.clusters, networkingv1.NetworkPolicyPeer{
+			PodSelector:       label.New().Instance(s.instance).LabelSelector(),
+			NamespaceSelector: namespaceSelector(s.namespace),
+		})
+		p.drainer = append(p.drainer, networkingv1.NetworkPolicyPeer{
+			PodSelector: label.Label{
+				label.InstanceLabelKey:  s.instance,
+				label.ComponentLabelKey: drainerLabelVal,
+			}.LabelSelector(),
+			NamespaceSelector: namespaceSelector(s.namespace),
+		})
+	}
+
+	p.clients = spec.Clients
+	p.operator = spec.Operator
+	if len(p.operator) == 0 {
+		p.operator = []networkingv1.NetworkPolicyPeer{
+			allNamespacesPeer(label.Label{label.NameLabelKey: operatorNameLabelVal}),
+		}
+	}
+	p.monitor = spec.Monitor
+	if len(p.monitor) == 0 {
+		p.monitor = []networkingv1.NetworkPolicyPeer{
+			allNamespacesPeer(label.NewMonitor().Monitor()),
+			allNamespacesPeer(label.NewTiDBNGMonitoring()),
+			allNamespacesPeer(label.Label{label.NameLabelKey: prometheusNameLabelVal}),
+		}
+	}
+	p.tools = spec.Tools
+	if len(p.tools) == 0 {
+		p.tools = []networkingv1.NetworkPolicyPeer{
+			allNamespacesPeer(label.NewBackup()),
+			allNamespacesPeer(label.NewRestore()),
+			allNamespacesPeer(label.NewInitializer()),
+			allNamespacesPeer(label.NewTiDBDashboard()),
+		}
+	}
+	return p, nil
+}
+
+func newNetworkPolicy(tc *v1alpha1.TidbCluster, component string, rules []networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+	podLabels := label.New().Instance(tc.GetInstanceName()).Component(component)
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", tc.Name, component),
+			Namespace: tc.Namespace,
+			Labels:    podLabels.Copy(),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *podLabels.LabelSelector(),
+			Ingress:     rules,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// ingressRule returns the rule allowing the peers to access the ports, at least the pods of the cluster or the
+// operator are always in the peers, since the rule without peers allows all sources
+func ingressRule(ports []int32, peers ...[]networkingv1.NetworkPolicyPeer) networkingv1.NetworkPolicyIngressRule {
+	rule := networkingv1.NetworkPolicyIngressRule{
+		Ports: networkPolicyPorts(ports),
+	}
+	for _, p := range peers {
+		rule.From = append(rule.From, p...)
+	}
+	return rule
+}
+
+// clientsIngressRule returns the rule allowing the clients to access the ports, all sources are allowed if the
+// clients are not specified
+func clientsIngressRule(ports []int32, clients []networkingv1.NetworkPolicyPeer) networkingv1.NetworkPolicyIngressRule {
+	return networkingv1.NetworkPolicyIngressRule{
+		Ports: networkPolicyPorts(ports),
+		From:  clients,
+	}
+}
+
+func networkPolicyPorts(ports []int32) []networkingv1.NetworkPolicyPort {
+	tcp := corev1.ProtocolTCP
+	policyPorts := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		p := intstr.FromInt(int(port))
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &p})
+	}
+	return policyPorts
+}
+
+func namespaceSelector(ns string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: ns}}
+}
+
+func allNamespacesPeer(l label.Label) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector:       l.LabelSelector(),
+		NamespaceSelector: &metav1.LabelSelector{},
+	}
+}
+
+type FakeNetworkPolicyManager struct {
+}
+
+func (f *FakeNetworkPolicyManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
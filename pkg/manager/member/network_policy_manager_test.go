@@ -0,0 +1,198 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNetworkPolicyManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewNetworkPolicyManager(deps)
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+
+	tc := newTidbClusterForPD()
+	clients := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}}
+	tc.Spec.NetworkPolicy = &v1alpha1.NetworkPolicySpec{Clients: clients}
+	tc.Spec.TiProxy = &v1alpha1.TiProxySpec{}
+
+	// the heterogeneous cluster is regarded as the same cluster
+	hetero := newTidbClusterForPD()
+	hetero.Name, hetero.Namespace = "hetero", "other"
+	hetero.Spec.Cluster = &v1alpha1.TidbClusterRef{Name: tc.Name, Namespace: tc.Namespace}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(hetero)).To(Succeed())
+
+	g.Expect(m.Sync(tc)).To(Succeed())
+
+	getPolicy := func(component string) *networkingv1.NetworkPolicy {
+		policy := &networkingv1.NetworkPolicy{}
+		err := cli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: tc.Name + "-" + component}, policy)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(metav1.IsControlledBy(policy, tc)).To(BeTrue())
+		g.Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string(label.New().Instance(tc.Name).Component(component))))
+		g.Expect(policy.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress}))
+		return policy
+	}
+	port := func(rule networkingv1.NetworkPolicyIngressRule) []int {
+		var ports []int
+		for _, p := range rule.Ports {
+			ports = append(ports, p.Port.IntValue())
+		}
+		return ports
+	}
+	components := func(peer networkingv1.NetworkPolicyPeer) []string {
+		g.Expect(peer.PodSelector.MatchExpressions).To(HaveLen(1))
+		return peer.PodSelector.MatchExpressions[0].Values
+	}
+
+	getPolicy(label.DiscoveryLabelVal)
+
+	pd := getPolicy(label.PDLabelVal)
+	g.Expect(pd.Spec.Ingress).To(HaveLen(2))
+	g.Expect(port(pd.Spec.Ingress[0])).To(Equal([]int{int(v1alpha1.DefaultPDClientPort)}))
+	g.Expect(pd.Spec.Ingress[0].From[0].PodSelector.MatchLabels).To(Equal(map[string]string(label.New().Instance("test"))))
+	g.Expect(pd.Spec.Ingress[0].From[1].PodSelector.MatchLabels).To(Equal(map[string]string(label.New().Instance("hetero"))))
+	g.Expect(pd.Spec.Ingress[0].From[1].NamespaceSelector.MatchLabels).To(Equal(map[string]string{"kubernetes.io/metadata.name": "other"}))
+	g.Expect(port(pd.Spec.Ingress[1])).To(Equal([]int{int(v1alpha1.DefaultPDPeerPort)}))
+	g.Expect(pd.Spec.Ingress[1].From).To(HaveLen(2))
+	g.Expect(components(pd.Spec.Ingress[1].From[0])).To(Equal([]string{label.PDLabelVal}))
+
+	tikv := getPolicy(label.TiKVLabelVal)
+	g.Expect(port(tikv.Spec.Ingress[0])).To(Equal([]int{int(v1alpha1.DefaultTiKVServerPort)}))
+	g.Expect(components(tikv.Spec.Ingress[0].From[0])).To(ConsistOf(
+		label.PDLabelVal, label.TiDBLabelVal, label.TiKVLabelVal, label.TiFlashLabelVal, label.TiCDCLabelVal))
+	// the default peers of the tools
+	g.Expect(tikv.Spec.Ingress[0].From).To(ContainElement(networkingv1.NetworkPolicyPeer{
+		PodSelector:       label.NewBackup().LabelSelector(),
+		NamespaceSelector: &metav1.LabelSelector{},
+	}))
+
+	tidb := getPolicy(label.TiDBLabelVal)
+	g.Expect(port(tidb.Spec.Ingress[0])).To(Equal([]int{int(v1alpha1.DefaultTiDBServerPort)}))
+	g.Expect(tidb.Spec.Ingress[0].From).To(Equal(clients))
+	g.Expect(components(tidb.Spec.Ingress[1].From[0])).To(Equal([]string{label.TiProxyLabelVal}))
+	g.Expect(port(tidb.Spec.Ingress[2])).To(Equal([]int{int(v1alpha1.DefaultTiDBStatusPort)}))
+	// the default peers of the monitor
+	g.Expect(tidb.Spec.Ingress[2].From).To(ContainElement(networkingv1.NetworkPolicyPeer{
+		PodSelector:       label.NewMonitor().Monitor().LabelSelector(),
+		NamespaceSelector: &metav1.LabelSelector{},
+	}))
+	g.Expect(tidb.Spec.Ingress[2].From).To(ContainElement(networkingv1.NetworkPolicyPeer{
+		PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{label.NameLabelKey: "prometheus"}},
+		NamespaceSelector: &metav1.LabelSelector{},
+	}))
+
+	tiproxy := getPolicy(label.TiProxyLabelVal)
+	g.Expect(port(tiproxy.Spec.Ingress[0])).To(Equal([]int{int(v1alpha1.DefaultTiProxyServerPort)}))
+	g.Expect(tiproxy.Spec.Ingress[0].From).To(Equal(clients))
+	g.Expect(port(tiproxy.Spec.Ingress[3])).To(Equal([]int{int(v1alpha1.DefaultTiProxyPeerPort)}))
+
+	// the network policies of the removed components are deleted
+	indexer := deps.LabelFilterKubeInformerFactory.Networking().V1().NetworkPolicies().Informer().GetIndexer()
+	for _, component := range []string{label.DiscoveryLabelVal, label.PDLabelVal, label.TiKVLabelVal, label.TiDBLabelVal, label.TiProxyLabelVal} {
+		g.Expect(indexer.Add(getPolicy(component))).To(Succeed())
+	}
+	tc.Spec.TiProxy = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	exist, err := deps.TypedControl.Exist(client.ObjectKey{Namespace: tc.Namespace, Name: "test-tiproxy"}, &networkingv1.NetworkPolicy{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+	getPolicy(label.TiDBLabelVal)
+}
+
+func TestNetworkPolicyManagerUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewNetworkPolicyManager(deps)
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	tc := newTidbClusterForPD()
+	tc.Spec.NetworkPolicy = &v1alpha1.NetworkPolicySpec{}
+	g.Expect(m.Sync(tc)).To(Succeed())
+	// the last applied config is recorded by the update
+	g.Expect(m.Sync(tc)).To(Succeed())
+
+	key := client.ObjectKey{Namespace: tc.Namespace, Name: "test-tidb"}
+	policy := &networkingv1.NetworkPolicy{}
+	g.Expect(cli.Get(context.TODO(), key, policy)).To(Succeed())
+	// all sources are allowed to access the MySQL port without the clients
+	g.Expect(policy.Spec.Ingress[0].From).To(BeEmpty())
+	equal, err := controller.NetworkPolicyEqual(newNetworkPolicy(tc, label.TiDBLabelVal, policy.Spec.Ingress), policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(equal).To(BeTrue())
+
+	// the policy is updated with the clients
+	clients := []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}}}
+	tc.Spec.NetworkPolicy.Clients = clients
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(cli.Get(context.TODO(), key, policy)).To(Succeed())
+	g.Expect(policy.Spec.Ingress[0].From).To(Equal(clients))
+
+	// all network policies are deleted if it's disabled
+	indexer := deps.LabelFilterKubeInformerFactory.Networking().V1().NetworkPolicies().Informer().GetIndexer()
+	for _, component := range []string{label.DiscoveryLabelVal, label.PDLabelVal, label.TiKVLabelVal, label.TiDBLabelVal} {
+		policy := &networkingv1.NetworkPolicy{}
+		g.Expect(cli.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: "test-" + component}, policy)).To(Succeed())
+		g.Expect(indexer.Add(policy)).To(Succeed())
+	}
+	tc.Spec.NetworkPolicy = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	for _, component := range []string{label.DiscoveryLabelVal, label.PDLabelVal, label.TiKVLabelVal, label.TiDBLabelVal} {
+		exist, err := deps.TypedControl.Exist(client.ObjectKey{Namespace: tc.Namespace, Name: "test-" + component}, &networkingv1.NetworkPolicy{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(exist).To(BeFalse())
+	}
+}
+
+// failedTidbClusterLister fails to list the tidb clusters
+type failedTidbClusterLister struct {
+	listers.TidbClusterLister
+}
+
+func (l *failedTidbClusterLister) List(labels.Selector) ([]*v1alpha1.TidbCluster, error) {
+	return nil, fmt.Errorf("list failed")
+}
+
+func TestNetworkPolicyManagerListClustersFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	deps.TiDBClusterLister = &failedTidbClusterLister{TidbClusterLister: deps.TiDBClusterLister}
+	m := NewNetworkPolicyManager(deps)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.NetworkPolicy = &v1alpha1.NetworkPolicySpec{}
+
+	// the network policies are not synced without the peers of the other clusters
+	err := m.Sync(tc)
+	g.Expect(err).To(MatchError(ContainSubstring("list failed")))
+	exist, err := deps.TypedControl.Exist(client.ObjectKey{Namespace: tc.Namespace, Name: tc.Name + "-" + label.PDLabelVal}, &networkingv1.NetworkPolicy{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(exist).To(BeFalse())
+}
@@ -132,8 +132,6 @@ func (f *pdFailover) tryToMarkAPeerAsFailure(tc *v1alpha1.TidbCluster) error {
 			return fmt.Errorf("tryToMarkAPeerAsFailure: failed to get pvcs for pod %s/%s, error: %s", ns, pod.Name, err)
 		}
 
-		f.deps.Recorder.Eventf(tc, apiv1.EventTypeWarning, "PDMemberUnhealthy", "%s/%s(%s) is unhealthy", ns, podName, pdMember.ID)
-
 		// mark a peer member failed and return an error to skip reconciliation
 		// note that status of tidb cluster will be updated always
 		pvcUIDSet := make(map[types.UID]v1alpha1.EmptyStruct)
@@ -147,6 +145,9 @@ func (f *pdFailover) tryToMarkAPeerAsFailure(tc *v1alpha1.TidbCluster) error {
 			MemberDeleted: false,
 			CreatedAt:     metav1.Now(),
 		}
+		controller.RecordClusterEvent(f.deps.Recorder, tc, apiv1.EventTypeWarning, eventReasonFailoverTriggered,
+			controller.ClusterEventTarget{MemberType: v1alpha1.PDMemberType, PodName: podName},
+			"member %s is unhealthy for more than %v, it's marked as failure member", pdMember.ID, f.deps.CLIConfig.PDFailoverPeriod)
 		return controller.RequeueErrorf("marking Pod: %s/%s pd member: %s as failure", ns, podName, pdMember.Name)
 	}
 
@@ -458,7 +458,17 @@ func (m *pdMemberManager) syncPDConfigMap(tc *v1alpha1.TidbCluster, set *apps.St
 		}
 	}
 
-	err = mngerutils.UpdateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BasePDSpec().ConfigUpdateStrategy(), inUseName, newCm)
+	strategy := tc.BasePDSpec().ConfigUpdateStrategy()
+	if strategy == v1alpha1.ConfigUpdateStrategyDynamic {
+		// the config changed online is persisted by PD and shared by all the members
+		return syncDynamicConfigMap(m.deps, tc, v1alpha1.PDMemberType, &tc.Status.PD.DynamicConfig,
+			set, inUseName, newCm, isPDOnlineConfig, func(config map[string]interface{}) error {
+				return controller.GetPDClient(m.deps.PDControl, tc).UpdateConfig(config)
+			})
+	}
+	tc.Status.PD.DynamicConfig = nil
+
+	err = mngerutils.UpdateConfigMapIfNeed(m.deps.ConfigMapLister, strategy, inUseName, newCm)
 	if err != nil {
 		return nil, err
 	}
@@ -733,6 +743,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	stsLabels := label.New().Instance(instanceName).PD()
 	podLabels := util.CombineStringMap(stsLabels, basePDSpec.Labels())
 	podAnnotations := util.CombineStringMap(basePDSpec.Annotations(), controller.AnnProm(v1alpha1.DefaultPDClientPort, "/metrics"))
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsRollingRestartAnnotations(&tc.Status.PD))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.PDLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -604,6 +604,9 @@ func (m *pdMSMemberManager) getNewPDMSStatefulSet(tc *v1alpha1.TidbCluster, cm *
 	stsLabels := label.New().Instance(instanceName).PDMS(curService)
 	podLabels := util.CombineStringMap(stsLabels, basePDMSSpec.Labels())
 	podAnnotations := util.CombineStringMap(basePDMSSpec.Annotations(), controller.AnnProm(v1alpha1.DefaultPDClientPort, "/metrics"))
+	if status := tc.Status.PDMS[curService]; status != nil {
+		podAnnotations = util.CombineStringMap(podAnnotations, tlsRollingRestartAnnotations(status))
+	}
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.PDMSLabel(curService))
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -180,6 +180,9 @@ func (s *pdScaler) preCheckUpMembers(tc *v1alpha1.TidbCluster, podName string) b
 		errMsg := fmt.Sprintf("The PD is in use by TidbCluster [%s/%s], can't scale in PD, podname %s, upComponents %d", tc.GetNamespace(), tc.GetName(), podName, upComponents)
 		klog.Error(errMsg)
 		s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
+		controller.RecordClusterEvent(s.deps.Recorder, tc, v1.EventTypeWarning, eventReasonScaleInBlocked,
+			controller.ClusterEventTarget{MemberType: v1alpha1.PDMemberType, PodName: podName},
+			"PD is in use by %d members of other components", upComponents)
 		return false
 	}
 
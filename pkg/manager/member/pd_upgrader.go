@@ -63,6 +63,8 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 	if tc.PDScaling() {
 		klog.Infof("TidbCluster: [%s/%s]'s pd status is %v, can not upgrade pd",
 			ns, tcName, tc.Status.PD.Phase)
+		controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeNormal, eventReasonUpgradeBlocked,
+			controller.ClusterEventTarget{MemberType: v1alpha1.PDMemberType}, "pd status is %s, can not upgrade pd", tc.Status.PD.Phase)
 		_, podSpec, err := GetLastAppliedConfig(oldSet)
 		if err != nil {
 			return err
@@ -370,6 +370,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 	storageClass := tc.Spec.Pump.StorageClassName
 	podLabels := util.CombineStringMap(stsLabels.Labels(), spec.Labels())
 	podAnnos := util.CombineStringMap(spec.Annotations(), controller.AnnProm(v1alpha1.DefaultPumpPort, "/metrics"))
+	podAnnos = util.CombineStringMap(podAnnos, tlsRollingRestartAnnotations(&tc.Status.Pump))
 	storageRequest, err := controller.ParseStorageRequest(tc.Spec.Pump.Requests)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse storage request for pump, tidbcluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
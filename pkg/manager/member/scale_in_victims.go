@@ -0,0 +1,256 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	eventReasonScaleInVictimsPicked = "ScaleInVictimsPicked"
+)
+
+// scaleInCandidate is a pod which may be deleted when scaling in
+type scaleInCandidate struct {
+	ordinal int32
+	// zone of the node which the pod is scheduled to
+	zone string
+	// whether the node which the pod is scheduled to is under pressure
+	pressure bool
+	// region and leader count of the store, only for TiKV
+	regionCount int
+	leaderCount int
+}
+
+// ensureScaleInVictims picks the pods to delete by the scale-in victim policy and writes them into
+// the delete-slots annotation of tc.
+// It returns true if the annotation is updated, and then the scaling should be retried with the new statefulset.
+func (s *generalScaler) ensureScaleInVictims(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType,
+	policy v1alpha1.ScaleInVictimPolicy, oldSet, newSet *apps.StatefulSet) (bool, error) {
+	if policy == v1alpha1.ScaleInVictimPolicyOrdinal || !features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
+		return false, nil
+	}
+
+	actualOrdinals := helper.GetPodOrdinals(*oldSet.Spec.Replicas, oldSet)
+	desiredDeleteSlots := helper.GetDeleteSlots(newSet)
+	if desiredDeleteSlots.Intersection(actualOrdinals).Len() > 0 {
+		// victims have been specified by users or picked before
+		return false, nil
+	}
+	count := actualOrdinals.Len() - int(*newSet.Spec.Replicas)
+	if count <= 0 {
+		return false, nil
+	}
+
+	candidates, err := s.getScaleInCandidates(tc, memberType, policy, actualOrdinals)
+	if err != nil {
+		return false, err
+	}
+	victims := pickScaleInVictims(policy, candidates, count)
+
+	var annKey string
+	switch memberType {
+	case v1alpha1.TiKVMemberType:
+		annKey = label.AnnTiKVDeleteSlots
+	case v1alpha1.TiDBMemberType:
+		annKey = label.AnnTiDBDeleteSlots
+	default:
+		return false, fmt.Errorf("scale-in victim policy is unsupported for %s", memberType)
+	}
+	value, err := json.Marshal(desiredDeleteSlots.Union(victims).List())
+	if err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				annKey: string(value),
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.deps.TiDBClusterControl.Patch(tc, data); err != nil {
+		return false, fmt.Errorf("set annotation %s of tc %s/%s failed: %v", annKey, tc.Namespace, tc.Name, err)
+	}
+	if tc.Annotations == nil {
+		tc.Annotations = map[string]string{}
+	}
+	tc.Annotations[annKey] = string(value)
+
+	klog.Infof("TidbCluster: [%s/%s], pick %s pods %v to delete by policy %s, delete slots: %s",
+		tc.Namespace, tc.Name, memberType, victims.List(), policy, value)
+	controller.RecordClusterEvent(s.deps.Recorder, tc, corev1.EventTypeNormal, eventReasonScaleInVictimsPicked,
+		controller.ClusterEventTarget{MemberType: memberType}, "pick pods %v to delete by policy %s", victims.List(), policy)
+
+	return true, nil
+}
+
+func (s *generalScaler) getScaleInCandidates(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType,
+	policy v1alpha1.ScaleInVictimPolicy, ordinals sets.Int32) ([]scaleInCandidate, error) {
+	// pod name -> store
+	stores := map[string]*scaleInCandidate{}
+	if policy == v1alpha1.ScaleInVictimPolicyStoreLoad && memberType == v1alpha1.TiKVMemberType && tc.TiKVBootStrapped() {
+		storesInfo, err := controller.GetPDClient(s.deps.PDControl, tc).GetStores()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stores info in TidbCluster %s/%s: %v", tc.Namespace, tc.Name, err)
+		}
+		for _, store := range storesInfo.Stores {
+			if store.Store == nil || store.Status == nil {
+				continue
+			}
+			status, ok := tc.Status.TiKV.Stores[strconv.FormatUint(store.Store.Id, 10)]
+			if !ok {
+				continue
+			}
+			stores[status.PodName] = &scaleInCandidate{
+				regionCount: store.Status.RegionCount,
+				leaderCount: store.Status.LeaderCount,
+			}
+		}
+	}
+
+	candidates := make([]scaleInCandidate, 0, ordinals.Len())
+	for _, ordinal := range ordinals.List() {
+		podName := ordinalPodName(memberType, tc.Name, ordinal)
+		c := scaleInCandidate{ordinal: ordinal}
+		if store, ok := stores[podName]; ok {
+			c.regionCount = store.regionCount
+			c.leaderCount = store.leaderCount
+		}
+
+		node, err := s.getNodeOfPod(tc.Namespace, podName)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			c.zone = getNodeZone(node)
+			c.pressure = isNodeUnderPressure(node)
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
+
+func (s *generalScaler) getNodeOfPod(ns, podName string) (*corev1.Node, error) {
+	if s.deps.NodeLister == nil {
+		// no permission to get nodes
+		return nil, nil
+	}
+	pod, err := s.deps.PodLister.Pods(ns).Get(podName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %v", ns, podName, err)
+	}
+	if pod.Spec.NodeName == "" {
+		return nil, nil
+	}
+	node, err := s.deps.NodeLister.Get(pod.Spec.NodeName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %v", pod.Spec.NodeName, err)
+	}
+	return node, nil
+}
+
+func getNodeZone(node *corev1.Node) string {
+	if zone, ok := node.Labels[corev1.LabelTopologyZone]; ok {
+		return zone
+	}
+	return node.Labels[corev1.LabelFailureDomainBetaZone]
+}
+
+func isNodeUnderPressure(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		switch cond.Type {
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+			if cond.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pickScaleInVictims picks count victims from candidates by the policy.
+// The pod with the higher ordinal is picked if candidates are equivalent.
+func pickScaleInVictims(policy v1alpha1.ScaleInVictimPolicy, candidates []scaleInCandidate, count int) sets.Int32 {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ordinal > candidates[j].ordinal
+	})
+
+	victims := sets.NewInt32()
+	switch policy {
+	case v1alpha1.ScaleInVictimPolicyZoneBalance:
+		zones := map[string]int{}
+		for _, c := range candidates {
+			zones[c.zone]++
+		}
+		for victims.Len() < count {
+			// pick the pod with the highest ordinal in the zone with the most pods
+			picked := -1
+			for i := range candidates {
+				if victims.Has(candidates[i].ordinal) {
+					continue
+				}
+				if picked < 0 || zones[candidates[i].zone] > zones[candidates[picked].zone] {
+					picked = i
+				}
+			}
+			if picked < 0 {
+				break
+			}
+			victims.Insert(candidates[picked].ordinal)
+			zones[candidates[picked].zone]--
+		}
+		return victims
+	case v1alpha1.ScaleInVictimPolicyStoreLoad:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].regionCount != candidates[j].regionCount {
+				return candidates[i].regionCount < candidates[j].regionCount
+			}
+			return candidates[i].leaderCount < candidates[j].leaderCount
+		})
+	case v1alpha1.ScaleInVictimPolicyNodePressure:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].pressure && !candidates[j].pressure
+		})
+	}
+
+	for i := 0; i < len(candidates) && victims.Len() < count; i++ {
+		victims.Insert(candidates[i].ordinal)
+	}
+	return victims
+}
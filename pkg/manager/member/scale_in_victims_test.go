@@ -0,0 +1,136 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/features"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestPickScaleInVictims(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name       string
+		policy     v1alpha1.ScaleInVictimPolicy
+		candidates []scaleInCandidate
+		count      int
+		expected   sets.Int32
+	}{
+		{
+			name:   "ordinal",
+			policy: v1alpha1.ScaleInVictimPolicyOrdinal,
+			candidates: []scaleInCandidate{
+				{ordinal: 0}, {ordinal: 1}, {ordinal: 2}, {ordinal: 3}, {ordinal: 4},
+			},
+			count:    2,
+			expected: sets.NewInt32(3, 4),
+		},
+		{
+			name:   "zone balance",
+			policy: v1alpha1.ScaleInVictimPolicyZoneBalance,
+			candidates: []scaleInCandidate{
+				{ordinal: 0, zone: "a"}, {ordinal: 1, zone: "a"}, {ordinal: 2, zone: "a"}, {ordinal: 3, zone: "a"},
+				{ordinal: 4, zone: "b"}, {ordinal: 5, zone: "b"}, {ordinal: 6, zone: "b"},
+				{ordinal: 7, zone: "c"}, {ordinal: 8, zone: "c"},
+			},
+			count: 3,
+			// 2 pods are left in each zone
+			expected: sets.NewInt32(2, 3, 6),
+		},
+		{
+			name:   "zone balance with the highest ordinals in one zone",
+			policy: v1alpha1.ScaleInVictimPolicyZoneBalance,
+			candidates: []scaleInCandidate{
+				{ordinal: 0, zone: "a"}, {ordinal: 1, zone: "b"}, {ordinal: 2, zone: "c"},
+				{ordinal: 3, zone: "a"}, {ordinal: 4, zone: "b"}, {ordinal: 5, zone: "c"},
+				{ordinal: 6, zone: "c"}, {ordinal: 7, zone: "c"}, {ordinal: 8, zone: "c"},
+			},
+			count:    3,
+			expected: sets.NewInt32(6, 7, 8),
+		},
+		{
+			name:   "store load",
+			policy: v1alpha1.ScaleInVictimPolicyStoreLoad,
+			candidates: []scaleInCandidate{
+				{ordinal: 0, regionCount: 10, leaderCount: 3},
+				{ordinal: 1, regionCount: 5, leaderCount: 3},
+				{ordinal: 2, regionCount: 5, leaderCount: 1},
+				{ordinal: 3, regionCount: 20, leaderCount: 10},
+			},
+			count:    2,
+			expected: sets.NewInt32(1, 2),
+		},
+		{
+			name:   "node pressure",
+			policy: v1alpha1.ScaleInVictimPolicyNodePressure,
+			candidates: []scaleInCandidate{
+				{ordinal: 0, pressure: true},
+				{ordinal: 1},
+				{ordinal: 2},
+				{ordinal: 3},
+			},
+			count:    2,
+			expected: sets.NewInt32(0, 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			victims := pickScaleInVictims(tt.policy, tt.candidates, tt.count)
+			g.Expect(victims.List()).To(Equal(tt.expected.List()))
+		})
+	}
+}
+
+func TestEnsureScaleInVictims(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	features.DefaultFeatureGate.Set("AdvancedStatefulSet=true")
+	defer features.DefaultFeatureGate.Set("AdvancedStatefulSet=false")
+
+	scaler, _, _, _ := newFakeTiDBScaler()
+	tc := newTidbClusterForPD()
+
+	oldSet := newStatefulSetForPDScale()
+	oldSet.Spec.Replicas = pointer.Int32Ptr(5)
+	newSet := oldSet.DeepCopy()
+	newSet.Spec.Replicas = pointer.Int32Ptr(3)
+
+	// do nothing with the default policy
+	updated, err := scaler.ensureScaleInVictims(tc, v1alpha1.TiDBMemberType, v1alpha1.ScaleInVictimPolicyOrdinal, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeFalse())
+
+	updated, err = scaler.ensureScaleInVictims(tc, v1alpha1.TiDBMemberType, v1alpha1.ScaleInVictimPolicyNodePressure, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeTrue())
+	g.Expect(tc.Annotations[label.AnnTiDBDeleteSlots]).To(Equal("[3,4]"))
+	events := collectEvents(scaler.deps.Recorder.(*record.FakeRecorder).Events)
+	g.Expect(events).To(ConsistOf(ContainSubstring(eventReasonScaleInVictimsPicked + " [tidb] pick pods [3 4]")))
+
+	// victims have been picked
+	helper.SetDeleteSlots(newSet, sets.NewInt32(3, 4))
+	updated, err = scaler.ensureScaleInVictims(tc, v1alpha1.TiDBMemberType, v1alpha1.ScaleInVictimPolicyNodePressure, oldSet, newSet)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(BeFalse())
+}
@@ -425,7 +425,7 @@ if retry_count == 10:
     sys.exit(1)
 
 {{- if .PasswordSet }}
-password_dir = '/etc/tidb/password'
+password_dir = '{{ .PasswordDir }}'
 for file in os.listdir(password_dir):
     if file.startswith('.'):
         continue
@@ -455,6 +455,7 @@ type TiDBInitStartScriptModel struct {
 	ClusterName     string
 	PermitHost      string
 	PasswordSet     bool
+	PasswordDir     string
 	InitSQL         bool
 	TLS             bool
 	SkipCA          bool
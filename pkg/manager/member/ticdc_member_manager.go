@@ -376,6 +376,7 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 	stsName := controller.TiCDCMemberName(tcName)
 	podLabels := util.CombineStringMap(stsLabels, baseTiCDCSpec.Labels())
 	podAnnotations := util.CombineStringMap(baseTiCDCSpec.Annotations(), controller.AnnProm(v1alpha1.DefaultTiCDCPort, "/metrics"))
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsRollingRestartAnnotations(&tc.Status.TiCDC))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiCDCLabelVal)
 	headlessSvcName := controller.TiCDCPeerMemberName(tcName)
 
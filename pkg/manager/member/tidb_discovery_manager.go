@@ -147,14 +147,14 @@ func getTidbDiscoveryService(obj metav1.Object, deploy *appsv1.Deployment, prefe
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "discovery",
-					Port:       10261,
-					TargetPort: intstr.FromInt(10261),
+					Port:       v1alpha1.DefaultDiscoveryPort,
+					TargetPort: intstr.FromInt(int(v1alpha1.DefaultDiscoveryPort)),
 					Protocol:   corev1.ProtocolTCP,
 				},
 				{
 					Name:       "proxy",
-					Port:       10262,
-					TargetPort: intstr.FromInt(10262),
+					Port:       v1alpha1.DefaultDiscoveryProxyPort,
+					TargetPort: intstr.FromInt(int(v1alpha1.DefaultDiscoveryProxyPort)),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -242,12 +242,12 @@ func (m *realTidbDiscoveryManager) getTidbDiscoveryDeployment(obj metav1.Object)
 			{
 				Name:          "discovery",
 				Protocol:      corev1.ProtocolTCP,
-				ContainerPort: 10261,
+				ContainerPort: v1alpha1.DefaultDiscoveryPort,
 			},
 			{
 				Name:          "proxy",
 				Protocol:      corev1.ProtocolTCP,
-				ContainerPort: 10262,
+				ContainerPort: v1alpha1.DefaultDiscoveryProxyPort,
 			},
 		},
 	}
@@ -330,7 +330,7 @@ func buildDiscoveryProb(probSpec *v1alpha1.Probe) *corev1.Probe {
 		ProbeHandler: corev1.ProbeHandler{
 			// only TCP socket is supported for now
 			TCPSocket: &corev1.TCPSocketAction{
-				Port: intstr.FromInt(10261),
+				Port: intstr.FromInt(int(v1alpha1.DefaultDiscoveryPort)),
 			},
 		},
 	}
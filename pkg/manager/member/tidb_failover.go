@@ -69,6 +69,9 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 		if time.Now().After(deadline) {
 			if len(tc.Status.TiDB.FailureMembers) >= int(maxFailoverCount) {
 				klog.Warningf("the failover count reaches the limit (%d), no more failover pods will be created", maxFailoverCount)
+				controller.RecordClusterEvent(f.deps.Recorder, tc, corev1.EventTypeWarning, eventReasonFailoverLimitReached,
+					controller.ClusterEventTarget{MemberType: v1alpha1.TiDBMemberType, PodName: tidbMember.Name},
+					"member is unhealthy but failure members count reached the limit %d, no more failover", maxFailoverCount)
 				break
 			}
 
@@ -91,6 +94,9 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			}
 			msg := fmt.Sprintf("tidb[%s] is unhealthy", tidbMember.Name)
 			f.deps.Recorder.Event(tc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, "tidb", tidbMember.Name, msg))
+			controller.RecordClusterEvent(f.deps.Recorder, tc, corev1.EventTypeWarning, eventReasonFailoverTriggered,
+				controller.ClusterEventTarget{MemberType: v1alpha1.TiDBMemberType, PodName: tidbMember.Name},
+				"member is unhealthy for more than %v, it's marked as failure member", f.deps.CLIConfig.TiDBFailoverPeriod)
 			break
 		}
 	}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	startscriptv1 "github.com/pingcap/tidb-operator/pkg/manager/member/startscript/v1"
 	"github.com/pingcap/tidb-operator/pkg/util"
@@ -77,6 +78,10 @@ func (m *tidbInitManager) Sync(ti *v1alpha1.TidbInitializer) error {
 		klog.Infof("TidbInitManager.Sync: Spec.TiDB is nil in tidbcluster %s, skip syncing TidbInitializer %s/%s", tcName, ns, ti.Name)
 		return nil
 	}
+	if err := backuputil.ValidateCredentialSource(ti.Spec.PasswordSecretSource, "passwordSecretSource",
+		fmt.Sprintf("configured in spec of TidbInitializer %s/%s", ns, ti.Name)); err != nil {
+		return fmt.Errorf("TidbInitManager.Sync: %v", err)
+	}
 
 	err = m.syncTiDBInitConfigMap(ti, tc)
 	if err != nil {
@@ -299,7 +304,20 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 			},
 		},
 	})
-	if ti.Spec.PasswordSecret != nil {
+	if source := ti.Spec.PasswordSecretSource; source != nil {
+		// the passwords in the path are already mounted, such as by an injector
+		if source.CSI != nil {
+			vms = append(vms, corev1.VolumeMount{
+				Name: passwdKey, ReadOnly: true, MountPath: passwdPath,
+			})
+			vs = append(vs, corev1.Volume{
+				Name: passwdKey,
+				VolumeSource: corev1.VolumeSource{
+					CSI: source.CSI.DeepCopy(),
+				},
+			})
+		}
+	} else if ti.Spec.PasswordSecret != nil {
 		vms = append(vms, corev1.VolumeMount{
 			Name: passwdKey, ReadOnly: true, MountPath: passwdPath,
 		})
@@ -414,7 +432,13 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool, s
 	if ti.Spec.InitSql != nil || ti.Spec.InitSqlConfigMap != nil {
 		initSQL = true
 	}
-	if ti.Spec.PasswordSecret != nil {
+	passwdDir := passwdPath
+	if source := ti.Spec.PasswordSecretSource; source != nil {
+		passwdSet = true
+		if source.CSI == nil && source.Path != "" {
+			passwdDir = source.Path
+		}
+	} else if ti.Spec.PasswordSecret != nil {
 		passwdSet = true
 	}
 
@@ -431,6 +455,7 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool, s
 		PermitHost:      permitHost,
 		InitSQL:         initSQL,
 		PasswordSet:     passwdSet,
+		PasswordDir:     passwdDir,
 		TiDBServicePort: tidbSvcPort,
 	}
 	if tlsClientEnabled {
@@ -21,10 +21,12 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 )
 
 func TestTiDBInitManagerSync(t *testing.T) {
@@ -128,6 +130,62 @@ func TestTiDBInitManagerSync(t *testing.T) {
 	}
 }
 
+func TestTiDBInitManagerPasswordSource(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tim, _, indexers := newFakeTiDBInitManager()
+	g.Expect(indexers.tc.Add(newTidbClusterForTiDB())).To(Succeed())
+
+	passwordVolume := func(job *batchv1.Job) *corev1.Volume {
+		for i, v := range job.Spec.Template.Spec.Volumes {
+			if v.Name == passwdKey {
+				return &job.Spec.Template.Spec.Volumes[i]
+			}
+		}
+		return nil
+	}
+
+	// password from secret
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.PasswordSecret = pointer.StringPtr("passwords")
+	job, err := tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(passwordVolume(job).Secret.SecretName).To(Equal("passwords"))
+	cm, err := getTiDBInitConfigMap(ti, false, false, 4000)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[startKey]).To(ContainSubstring("password_dir = '/etc/tidb/password'"))
+
+	// password from the CSI driver takes precedence over the secret
+	csi := &corev1.CSIVolumeSource{Driver: "secrets-store.csi.k8s.io"}
+	ti.Spec.PasswordSecretSource = &v1alpha1.CredentialSource{CSI: csi}
+	job, err = tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(passwordVolume(job).Secret).To(BeNil())
+	g.Expect(passwordVolume(job).CSI).To(Equal(csi))
+	cm, err = getTiDBInitConfigMap(ti, false, false, 4000)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[startKey]).To(ContainSubstring("password_dir = '/etc/tidb/password'"))
+
+	// password from the mounted path
+	ti.Spec.PasswordSecret = nil
+	ti.Spec.PasswordSecretSource = &v1alpha1.CredentialSource{Path: "/vault/secrets"}
+	job, err = tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(passwordVolume(job)).To(BeNil())
+	cm, err = getTiDBInitConfigMap(ti, false, false, 4000)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[startKey]).To(ContainSubstring("password_dir = '/vault/secrets'"))
+
+	// the invalid source is rejected
+	ti.Spec.PasswordSecretSource = &v1alpha1.CredentialSource{CSI: csi, Path: "/vault/secrets"}
+	err = tim.Sync(ti)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("only one of csi and path of passwordSecretSource should be configured"))
+	ti.Spec.PasswordSecretSource = &v1alpha1.CredentialSource{Path: "vault/secrets"}
+	err = tim.Sync(ti)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("path vault/secrets of passwordSecretSource should be an absolute path"))
+}
+
 func newFakeTiDBInitManager() (*tidbInitManager, *tidbMemberManager, *fakeIndexers) {
 	tmm, _, _, indexers := newFakeTiDBMemberManager()
 	indexers.job = tmm.deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
@@ -17,6 +17,7 @@ import (
 	"context"
 	"crypto/tls"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"path"
 	"regexp"
@@ -28,6 +29,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/manager/member/startscript"
@@ -57,6 +59,10 @@ const (
 	defaultSlowLogVolume = "slowlog"
 	defaultSlowLogDir    = "/var/log/tidb"
 	defaultSlowLogFile   = defaultSlowLogDir + "/slowlog"
+	// defaultAuditLogVolume is the emptyDir volume of the audit log if no volume is specified
+	defaultAuditLogVolume = "auditlog"
+	// auditLogStorageEnv is the env of the JSON encoded storage provider of tidb-auditlog-uploader
+	auditLogStorageEnv = "AUDIT_LOG_STORAGE"
 	// clusterCertPath is where the cert for inter-cluster communication stored (if any)
 	clusterCertPath = "/var/lib/tidb-tls"
 	// serverCertPath is where the tidb-server cert stored (if any)
@@ -266,7 +272,7 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return err
 	}
 
-	newTiDBSet, err := getNewTiDBSetForTidbCluster(tc, cm)
+	newTiDBSet, err := getNewTiDBSetForTidbCluster(tc, cm, m.deps.CLIConfig.TiDBDiscoveryImage, m.deps.CLIConfig.SlowLogShipperImage, m.deps.SecretLister)
 	if err != nil {
 		return err
 	}
@@ -749,7 +755,11 @@ func getNewTiDBHeadlessServiceForTidbCluster(tc *v1alpha1.TidbCluster) *corev1.S
 	return svc
 }
 
-func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.StatefulSet, error) {
+// getNewTiDBSetForTidbCluster builds the TiDB StatefulSet, operatorImage is used by the sidecars
+// running the binaries of tidb-operator, e.g. the audit log uploader, and slowLogShipperImage is used by
+// the structured slow log shipper. secretLister is used to generate the credentials of the storage the
+// audit log is uploaded to.
+func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap, operatorImage, slowLogShipperImage string, secretLister corelisters.SecretLister) (*apps.StatefulSet, error) {
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 	setName := controller.TiDBMemberName(tcName)
@@ -908,41 +918,44 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			volMounts = append(volMounts, slowQueryLogVolumeMount)
 			slowLogFileEnvVal = defaultSlowLogFile
 		} else {
-			existVolume := false
-			for _, volMount := range storageVolMounts {
-				volMountName := fmt.Sprintf("%s-%s", v1alpha1.TiDBMemberType.String(), slowQueryLogVolumeName)
-				if volMount.Name == volMountName {
-					slowQueryLogVolumeMount = volMount
-					existVolume = true
-					break
-				}
-			}
-			if !existVolume {
-				for _, volMount := range tc.Spec.TiDB.AdditionalVolumeMounts {
-					if volMount.Name == slowQueryLogVolumeName {
-						slowQueryLogVolumeMount = volMount
-						existVolume = true
-						break
-					}
-				}
-			}
+			var existVolume bool
+			slowQueryLogVolumeMount, existVolume = getTiDBVolumeMount(tc, storageVolMounts, slowQueryLogVolumeName)
 			if !existVolume {
 				return nil, fmt.Errorf("failed to get slowLogVolume %s for cluster %s/%s", slowQueryLogVolumeName, ns, tcName)
 			}
 			slowLogFileEnvVal = path.Join(slowQueryLogVolumeMount.MountPath, slowQueryLogVolumeName)
 		}
-		containers = append(containers, corev1.Container{
-			Name:            v1alpha1.ContainerSlowLogTailer.String(),
-			Image:           tc.HelperImage(),
-			ImagePullPolicy: tc.HelperImagePullPolicy(),
-			Resources:       controller.ContainerResource(tc.Spec.TiDB.GetSlowLogTailerSpec().ResourceRequirements),
-			VolumeMounts:    []corev1.VolumeMount{slowQueryLogVolumeMount},
-			Command: []string{
-				"sh",
-				"-c",
-				fmt.Sprintf("touch %s; tail -n0 -F %s;", slowLogFileEnvVal, slowLogFileEnvVal),
-			},
-		})
+		containers = append(containers, getSlowLogTailerContainer(tc, slowLogShipperImage, slowLogFileEnvVal, slowQueryLogVolumeMount))
+	}
+	if tc.Spec.TiDB.IsAuditLogEnabled() {
+		// mount a dedicated volume for the audit log, tail it to STDOUT and upload the rotated files using sidecars.
+		auditLogFile := tc.Spec.TiDB.GetAuditLogFile()
+		var auditLogVolumeMount corev1.VolumeMount
+		if auditLogVolumeName := tc.Spec.TiDB.AuditLog.VolumeName; auditLogVolumeName == "" {
+			vols = append(vols, corev1.Volume{
+				Name: defaultAuditLogVolume,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			})
+			auditLogVolumeMount = corev1.VolumeMount{Name: defaultAuditLogVolume, MountPath: path.Dir(auditLogFile)}
+			volMounts = append(volMounts, auditLogVolumeMount)
+		} else {
+			var existVolume bool
+			auditLogVolumeMount, existVolume = getTiDBVolumeMount(tc, storageVolMounts, auditLogVolumeName)
+			if !existVolume {
+				return nil, fmt.Errorf("failed to get auditLogVolume %s for cluster %s/%s", auditLogVolumeName, ns, tcName)
+			}
+		}
+		containers = append(containers, getAuditLogTailerContainer(tc, auditLogFile, auditLogVolumeMount))
+		if tc.Spec.TiDB.AuditLog.Upload != nil {
+			uploader, credentialVols, err := getAuditLogUploaderContainer(tc, operatorImage, auditLogFile, auditLogVolumeMount, secretLister)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build the audit log uploader for cluster %s/%s: %v", ns, tcName, err)
+			}
+			containers = append(containers, uploader)
+			vols = append(vols, credentialVols...)
+		}
 	}
 
 	envs := []corev1.EnvVar{
@@ -1090,6 +1103,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	stsLabels := label.New().Instance(instanceName).TiDB()
 	podLabels := util.CombineStringMap(stsLabels, baseTiDBSpec.Labels())
 	podAnnotations := util.CombineStringMap(baseTiDBSpec.Annotations(), controller.AnnProm(v1alpha1.DefaultTiDBStatusPort, "/metrics"))
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsRollingRestartAnnotations(&tc.Status.TiDB))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiDBLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
@@ -1137,6 +1151,151 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	return tidbSet, nil
 }
 
+// getSlowLogTailerContainer returns the sidecar printing the slow log to STDOUT. With the json format,
+// the slow log is parsed by tidb-slowlog-shipper in shipperImage and shipped to the sink.
+func getSlowLogTailerContainer(tc *v1alpha1.TidbCluster, shipperImage, slowLogFile string, slowLogVolumeMount corev1.VolumeMount) corev1.Container {
+	spec := tc.Spec.TiDB.GetSlowLogTailerSpec()
+	if spec.Format != v1alpha1.SlowLogFormatJSON {
+		return corev1.Container{
+			Name:            v1alpha1.ContainerSlowLogTailer.String(),
+			Image:           tc.HelperImage(),
+			ImagePullPolicy: tc.HelperImagePullPolicy(),
+			Resources:       controller.ContainerResource(spec.ResourceRequirements),
+			VolumeMounts:    []corev1.VolumeMount{slowLogVolumeMount},
+			Command: []string{
+				"sh",
+				"-c",
+				fmt.Sprintf("touch %s; tail -n0 -F %s;", slowLogFile, slowLogFile),
+			},
+		}
+	}
+
+	volMounts := []corev1.VolumeMount{slowLogVolumeMount}
+	command := []string{
+		"/usr/local/bin/tidb-slowlog-shipper",
+		fmt.Sprintf("--file=%s", slowLogFile),
+	}
+	if sink := spec.Sink; sink != nil {
+		if sink.Type != "" {
+			command = append(command, fmt.Sprintf("--sink=%s", sink.Type))
+		}
+		switch sink.Type {
+		case v1alpha1.SlowLogSinkFile:
+			command = append(command, fmt.Sprintf("--sink-path=%s", sink.Path))
+			// the sink file is on one of the additional volumes
+			for _, volMount := range tc.Spec.TiDB.AdditionalVolumeMounts {
+				if volMount.Name != slowLogVolumeMount.Name && strings.HasPrefix(sink.Path, strings.TrimSuffix(volMount.MountPath, "/")+"/") {
+					volMounts = append(volMounts, volMount)
+				}
+			}
+		case v1alpha1.SlowLogSinkHTTP:
+			command = append(command, fmt.Sprintf("--sink-url=%s", sink.URL))
+		}
+	}
+	return corev1.Container{
+		Name:            v1alpha1.ContainerSlowLogTailer.String(),
+		Image:           shipperImage,
+		ImagePullPolicy: tc.HelperImagePullPolicy(),
+		Resources:       controller.ContainerResource(spec.ResourceRequirements),
+		VolumeMounts:    volMounts,
+		Command:         command,
+		Env: []corev1.EnvVar{
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+		},
+	}
+}
+
+// getTiDBVolumeMount returns the mount of the volume in `storageVolumes` or `additionalVolumes` of TiDB
+func getTiDBVolumeMount(tc *v1alpha1.TidbCluster, storageVolMounts []corev1.VolumeMount, volumeName string) (corev1.VolumeMount, bool) {
+	for _, volMount := range storageVolMounts {
+		volMountName := fmt.Sprintf("%s-%s", v1alpha1.TiDBMemberType.String(), volumeName)
+		if volMount.Name == volMountName {
+			return volMount, true
+		}
+	}
+	for _, volMount := range tc.Spec.TiDB.AdditionalVolumeMounts {
+		if volMount.Name == volumeName {
+			return volMount, true
+		}
+	}
+	return corev1.VolumeMount{}, false
+}
+
+// getAuditLogTailerContainer returns the sidecar printing the audit log to STDOUT
+func getAuditLogTailerContainer(tc *v1alpha1.TidbCluster, auditLogFile string, auditLogVolumeMount corev1.VolumeMount) corev1.Container {
+	return corev1.Container{
+		Name:            v1alpha1.ContainerAuditLogTailer.String(),
+		Image:           tc.HelperImage(),
+		ImagePullPolicy: tc.HelperImagePullPolicy(),
+		Resources:       controller.ContainerResource(tc.Spec.TiDB.AuditLog.ResourceRequirements),
+		VolumeMounts:    []corev1.VolumeMount{auditLogVolumeMount},
+		Command: []string{
+			"sh",
+			"-c",
+			fmt.Sprintf("touch %s; tail -n0 -F %s;", auditLogFile, auditLogFile),
+		},
+	}
+}
+
+// getAuditLogUploaderContainer returns the sidecar uploading the rotated audit log files by tidb-auditlog-uploader
+// in the tidb-operator image, and the volumes of the storage credentials provided by the CSI driver.
+func getAuditLogUploaderContainer(tc *v1alpha1.TidbCluster, operatorImage, auditLogFile string, auditLogVolumeMount corev1.VolumeMount,
+	secretLister corelisters.SecretLister) (corev1.Container, []corev1.Volume, error) {
+	upload := tc.Spec.TiDB.AuditLog.Upload
+	storage, err := json.Marshal(upload.StorageProvider)
+	if err != nil {
+		return corev1.Container{}, nil, err
+	}
+	// the storage provider may be completed by the secret, so a copy is used
+	certEnv, _, err := backuputil.GenerateStorageCertEnv(tc.Namespace, false, *upload.StorageProvider.DeepCopy(), secretLister)
+	if err != nil {
+		return corev1.Container{}, nil, err
+	}
+	credentialVols, credentialVolMounts := backuputil.GenerateStorageCredentialVolumes(upload.StorageProvider)
+
+	command := []string{
+		"/usr/local/bin/tidb-auditlog-uploader",
+		fmt.Sprintf("--file=%s", auditLogFile),
+		fmt.Sprintf("--interval=%s", upload.GetInterval()),
+	}
+	if upload.Retention != "" {
+		command = append(command, fmt.Sprintf("--retention=%s", upload.Retention))
+	}
+	if upload.ObjectLockMode != "" {
+		command = append(command, fmt.Sprintf("--object-lock-mode=%s", upload.ObjectLockMode))
+	}
+	env := []corev1.EnvVar{
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name:  auditLogStorageEnv,
+			Value: string(storage),
+		},
+	}
+	return corev1.Container{
+		Name:            v1alpha1.ContainerAuditLogUploader.String(),
+		Image:           operatorImage,
+		ImagePullPolicy: tc.HelperImagePullPolicy(),
+		Resources:       controller.ContainerResource(tc.Spec.TiDB.AuditLog.ResourceRequirements),
+		VolumeMounts:    append([]corev1.VolumeMount{auditLogVolumeMount}, credentialVolMounts...),
+		Command:         command,
+		Env:             append(env, certEnv...),
+	}, credentialVols, nil
+}
+
 func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
 	if set == nil {
 		// skip if not created yet
@@ -15,6 +15,7 @@ package member
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path"
 	"strings"
@@ -1314,7 +1315,7 @@ func TestGetNewTiDBSetForTidbCluster(t *testing.T) {
 	for i := range tests {
 		tt := tests[i]
 		t.Run(tt.name, func(t *testing.T) {
-			sts, _ := getNewTiDBSetForTidbCluster(&tt.tc, tt.cm)
+			sts, _ := getNewTiDBSetForTidbCluster(&tt.tc, tt.cm, "", "", nil)
 			tt.testSts(sts)
 		})
 	}
@@ -1352,6 +1353,129 @@ func checkCustomizedStartupProbeEnabled(g *WithT, spec v1.PodSpec, expectedProbe
 	}))
 }
 
+func TestGetSlowLogTailerContainer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	volMount := corev1.VolumeMount{Name: "slowlog", MountPath: "/var/log/tidb"}
+	c := getSlowLogTailerContainer(tc, "pingcap/tidb-operator:v1.6.0", "/var/log/tidb/slowlog", volMount)
+	g.Expect(c.Image).To(Equal(tc.HelperImage()))
+	g.Expect(c.Command).To(Equal([]string{"sh", "-c", "touch /var/log/tidb/slowlog; tail -n0 -F /var/log/tidb/slowlog;"}))
+
+	tc.Spec.TiDB.SlowLogTailer = &v1alpha1.TiDBSlowLogTailerSpec{Format: v1alpha1.SlowLogFormatJSON}
+	c = getSlowLogTailerContainer(tc, "pingcap/tidb-operator:v1.6.0", "/var/log/tidb/slowlog", volMount)
+	g.Expect(c.Image).To(Equal("pingcap/tidb-operator:v1.6.0"))
+	g.Expect(c.Command).To(Equal([]string{"/usr/local/bin/tidb-slowlog-shipper", "--file=/var/log/tidb/slowlog"}))
+	g.Expect(c.Env).To(HaveLen(1))
+	g.Expect(c.Env[0].Name).To(Equal("POD_NAME"))
+
+	tc.Spec.TiDB.SlowLogTailer.Sink = &v1alpha1.SlowLogSinkSpec{Type: v1alpha1.SlowLogSinkHTTP, URL: "http://vector:8080"}
+	c = getSlowLogTailerContainer(tc, "pingcap/tidb-operator:v1.6.0", "/var/log/tidb/slowlog", volMount)
+	g.Expect(c.Command).To(Equal([]string{"/usr/local/bin/tidb-slowlog-shipper", "--file=/var/log/tidb/slowlog", "--sink=http", "--sink-url=http://vector:8080"}))
+
+	sinkMount := corev1.VolumeMount{Name: "archive", MountPath: "/archive"}
+	tc.Spec.TiDB.AdditionalVolumeMounts = []corev1.VolumeMount{sinkMount, {Name: "other", MountPath: "/other"}}
+	tc.Spec.TiDB.SlowLogTailer.Sink = &v1alpha1.SlowLogSinkSpec{Type: v1alpha1.SlowLogSinkFile, Path: "/archive/slowlog.json"}
+	c = getSlowLogTailerContainer(tc, "pingcap/tidb-operator:v1.6.0", "/var/log/tidb/slowlog", volMount)
+	g.Expect(c.Command).To(Equal([]string{"/usr/local/bin/tidb-slowlog-shipper", "--file=/var/log/tidb/slowlog", "--sink=file", "--sink-path=/archive/slowlog.json"}))
+	g.Expect(c.VolumeMounts).To(Equal([]corev1.VolumeMount{volMount, sinkMount}))
+
+	// the shipper doesn't use the image of the operator, so TiDB is not rolling updated with the operator
+	sts, err := getNewTiDBSetForTidbCluster(tc, nil, "pingcap/tidb-operator:v1.6.1", "pingcap/tidb-operator:v1.6.0", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	var shipper *corev1.Container
+	for i := range sts.Spec.Template.Spec.Containers {
+		if sts.Spec.Template.Spec.Containers[i].Name == v1alpha1.ContainerSlowLogTailer.String() {
+			shipper = &sts.Spec.Template.Spec.Containers[i]
+		}
+	}
+	g.Expect(shipper).NotTo(BeNil())
+	g.Expect(shipper.Image).To(Equal("pingcap/tidb-operator:v1.6.0"))
+}
+
+func TestGetNewTiDBSetWithAuditLog(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	secretLister := corelisters.NewSecretLister(indexer)
+	getContainer := func(sts *apps.StatefulSet, name v1alpha1.ContainerName) *corev1.Container {
+		for i := range sts.Spec.Template.Spec.Containers {
+			if sts.Spec.Template.Spec.Containers[i].Name == name.String() {
+				return &sts.Spec.Template.Spec.Containers[i]
+			}
+		}
+		return nil
+	}
+
+	// the audit log is written to an emptyDir volume and tailed by the sidecar
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.AuditLog = &v1alpha1.TiDBAuditLogSpec{}
+	sts, err := getNewTiDBSetForTidbCluster(tc, nil, "pingcap/tidb-operator:v1.6.0", "", secretLister)
+	g.Expect(err).NotTo(HaveOccurred())
+	volMount := corev1.VolumeMount{Name: "auditlog", MountPath: "/var/log/tidb-audit"}
+	g.Expect(getContainer(sts, v1alpha1.ContainerName(v1alpha1.TiDBMemberType)).VolumeMounts).To(ContainElement(volMount))
+	tailer := getContainer(sts, v1alpha1.ContainerAuditLogTailer)
+	g.Expect(tailer).NotTo(BeNil())
+	g.Expect(tailer.Image).To(Equal(tc.HelperImage()))
+	g.Expect(tailer.VolumeMounts).To(Equal([]corev1.VolumeMount{volMount}))
+	g.Expect(tailer.Command).To(Equal([]string{"sh", "-c", "touch /var/log/tidb-audit/tidb-audit.log; tail -n0 -F /var/log/tidb-audit/tidb-audit.log;"}))
+	g.Expect(getContainer(sts, v1alpha1.ContainerAuditLogUploader)).To(BeNil())
+
+	// the volume of the audit log doesn't exist
+	tc.Spec.TiDB.AuditLog.VolumeName = "audit"
+	_, err = getNewTiDBSetForTidbCluster(tc, nil, "pingcap/tidb-operator:v1.6.0", "", secretLister)
+	g.Expect(err).To(HaveOccurred())
+
+	// the rotated files on the additional volume are uploaded to s3 with the object lock
+	auditMount := corev1.VolumeMount{Name: "audit", MountPath: "/audit"}
+	tc.Spec.TiDB.AdditionalVolumeMounts = []corev1.VolumeMount{auditMount}
+	tc.Spec.TiDB.AuditLog = &v1alpha1.TiDBAuditLogSpec{
+		File:       "/audit/tidb-audit.log",
+		VolumeName: "audit",
+		Upload: &v1alpha1.AuditLogUploadSpec{
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{Bucket: "audit", Prefix: "basic", SecretName: "s3-secret"},
+			},
+			Retention:      "2160h",
+			ObjectLockMode: "COMPLIANCE",
+		},
+	}
+	_, err = getNewTiDBSetForTidbCluster(tc, nil, "pingcap/tidb-operator:v1.6.0", "", secretLister)
+	g.Expect(err).To(MatchError(ContainSubstring("s3-secret")))
+
+	g.Expect(indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-secret", Namespace: tc.Namespace},
+		Data:       map[string][]byte{"access_key": []byte("ak"), "secret_key": []byte("sk")},
+	})).To(Succeed())
+	sts, err = getNewTiDBSetForTidbCluster(tc, nil, "pingcap/tidb-operator:v1.6.0", "", secretLister)
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, vol := range sts.Spec.Template.Spec.Volumes {
+		g.Expect(vol.Name).NotTo(Equal("auditlog"))
+	}
+	uploader := getContainer(sts, v1alpha1.ContainerAuditLogUploader)
+	g.Expect(uploader).NotTo(BeNil())
+	g.Expect(uploader.Image).To(Equal("pingcap/tidb-operator:v1.6.0"))
+	g.Expect(uploader.VolumeMounts).To(Equal([]corev1.VolumeMount{auditMount}))
+	g.Expect(uploader.Command).To(Equal([]string{
+		"/usr/local/bin/tidb-auditlog-uploader",
+		"--file=/audit/tidb-audit.log",
+		"--interval=1m0s",
+		"--retention=2160h",
+		"--object-lock-mode=COMPLIANCE",
+	}))
+	envs := map[string]corev1.EnvVar{}
+	for _, env := range uploader.Env {
+		envs[env.Name] = env
+	}
+	g.Expect(envs).To(HaveKey("POD_NAME"))
+	storage := v1alpha1.StorageProvider{}
+	g.Expect(json.Unmarshal([]byte(envs["AUDIT_LOG_STORAGE"].Value), &storage)).To(Succeed())
+	g.Expect(storage).To(Equal(tc.Spec.TiDB.AuditLog.Upload.StorageProvider))
+	g.Expect(envs["AWS_ACCESS_KEY_ID"].ValueFrom.SecretKeyRef.Name).To(Equal("s3-secret"))
+	// the spec is not completed by the defaults of the storage
+	g.Expect(tc.Spec.TiDB.AuditLog.Upload.S3.Acl).To(BeEmpty())
+}
+
 func TestTiDBInitContainers(t *testing.T) {
 	privileged := true
 	asRoot := false
@@ -1715,7 +1839,7 @@ func TestTiDBInitContainers(t *testing.T) {
 	for i := range tests {
 		tt := tests[i]
 		t.Run(tt.name, func(t *testing.T) {
-			sts, _ := getNewTiDBSetForTidbCluster(&tt.tc, nil)
+			sts, _ := getNewTiDBSetForTidbCluster(&tt.tc, nil, "", "", nil)
 			if diff := cmp.Diff(tt.expectedInit, sts.Spec.Template.Spec.InitContainers); diff != "" {
 				t.Errorf("unexpected InitContainers in Statefulset (-want, +got): %s", diff)
 			}
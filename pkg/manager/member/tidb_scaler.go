@@ -108,6 +108,15 @@ func (s *tidbScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 		return nil
 	}
 
+	updated, err := s.ensureScaleInVictims(tc, v1alpha1.TiDBMemberType, tc.Spec.TiDB.ScalePolicy.GetScaleInVictimPolicy(), oldSet, newSet)
+	if err != nil || updated {
+		resetReplicas(newSet, oldSet)
+		if err != nil {
+			return err
+		}
+		return controller.RequeueErrorf("TidbCluster: [%s/%s], wait for the delete slots of tidb to be updated before scaling in", tc.Namespace, tc.Name)
+	}
+
 	scaleInParallelism := tc.Spec.TiDB.GetScaleInParallelism()
 
 	_, ordinals, replicas, deleteSlots := scaleMulti(oldSet, newSet, scaleInParallelism)
@@ -67,6 +67,10 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 			ns, tcName,
 			tc.Status.PD.Phase, tc.Status.TiKV.Phase, tc.Status.TiFlash.Phase,
 			tc.Status.Pump.Phase, tc.Status.TiDB.Phase)
+		controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeNormal, eventReasonUpgradeBlocked,
+			controller.ClusterEventTarget{MemberType: v1alpha1.TiDBMemberType},
+			"pd status is %s, tikv status is %s, tiflash status is %s, pump status is %s, tidb status is %s, can not upgrade tidb",
+			tc.Status.PD.Phase, tc.Status.TiKV.Phase, tc.Status.TiFlash.Phase, tc.Status.Pump.Phase, tc.Status.TiDB.Phase)
 		_, podSpec, err := GetLastAppliedConfig(oldSet)
 		if err != nil {
 			return err
@@ -0,0 +1,540 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+
+	"github.com/go-sql-driver/mysql"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tidbUsersAdminUserKey and tidbUsersAdminPasswordKey are the keys in the secret of the admin account
+	tidbUsersAdminUserKey     = "user"
+	tidbUsersAdminPasswordKey = "password"
+
+	tidbUsersSyncTimeout = 30 * time.Second
+
+	eventReasonFailedSyncSQLUser = "FailedSyncSQLUser"
+	eventReasonSQLUserDrifted    = "SQLUserDrifted"
+	eventReasonSQLUserDropped    = "SQLUserDropped"
+)
+
+// userSQLConn executes the SQL statements managing the users
+type userSQLConn interface {
+	Exec(ctx context.Context, query string) error
+	// UserExists returns whether the user or role exists
+	UserExists(ctx context.Context, name, host string) (bool, error)
+	// ShowGrants returns the result of `SHOW GRANTS FOR <user>`
+	ShowGrants(ctx context.Context, user string) ([]string, error)
+	Close() error
+}
+
+type dbUserSQLConn struct {
+	db *sql.DB
+}
+
+func (c *dbUserSQLConn) Exec(ctx context.Context, query string) error {
+	_, err := c.db.ExecContext(ctx, query)
+	return err
+}
+
+func (c *dbUserSQLConn) UserExists(ctx context.Context, name, host string) (bool, error) {
+	var count int
+	row := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM mysql.user WHERE User = ? AND Host = ?", name, host)
+	if err := row.Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (c *dbUserSQLConn) ShowGrants(ctx context.Context, user string) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, "SHOW GRANTS FOR "+user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+func (c *dbUserSQLConn) Close() error {
+	return c.db.Close()
+}
+
+// TiDBUserManager reconciles the SQL users and roles in `spec.tidb.users` against TiDB continuously.
+// The failures are recorded in the status and the events instead of blocking the reconciliation
+// of the tidb cluster, and the users are synced again in the next round.
+type TiDBUserManager struct {
+	deps    *controller.Dependencies
+	now     func() time.Time
+	connect func(tc *v1alpha1.TidbCluster, user, password string) (userSQLConn, error)
+}
+
+// NewTiDBUserManager returns a *TiDBUserManager
+func NewTiDBUserManager(deps *controller.Dependencies) *TiDBUserManager {
+	m := &TiDBUserManager{
+		deps: deps,
+		now:  time.Now,
+	}
+	m.connect = m.connectTiDB
+	return m
+}
+
+func (m *TiDBUserManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.TiDB == nil || tc.Spec.TiDB.Users == nil {
+		tc.Status.TiDB.Users = nil
+		return nil
+	}
+	if tc.Status.TiDB.StatefulSet == nil || tc.Status.TiDB.StatefulSet.ReadyReplicas == 0 {
+		klog.V(4).Infof("tidb cluster %s/%s: wait for TiDB ready to sync SQL users", tc.Namespace, tc.Name)
+		return nil
+	}
+	if tc.Status.TiDB.Users == nil {
+		tc.Status.TiDB.Users = map[string]v1alpha1.TiDBUserStatus{}
+	}
+	users := tc.Spec.TiDB.Users.Users
+
+	conn, admin, err := m.connectAdmin(tc)
+	if err != nil {
+		for i := range users {
+			m.recordFailure(tc, &users[i], err)
+		}
+		return nil
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), tidbUsersSyncTimeout)
+	defer cancel()
+
+	keys := sets.NewString()
+	for i := range users {
+		user := &users[i]
+		keys.Insert(user.Key())
+		// the admin account in the secret can not be validated by the webhook
+		if user.Name == admin {
+			m.recordFailure(tc, user, fmt.Errorf("%s is the admin account used to manage the users", admin))
+			continue
+		}
+		status := tc.Status.TiDB.Users[user.Key()]
+		if err := m.syncUser(ctx, tc, conn, user, &status); err != nil {
+			tc.Status.TiDB.Users[user.Key()] = status
+			m.recordFailure(tc, user, err)
+			continue
+		}
+		status.Synced = true
+		status.Message = ""
+		tc.Status.TiDB.Users[user.Key()] = status
+	}
+
+	// the users and roles removed from the spec are dropped if they are created by the operator
+	for key, status := range tc.Status.TiDB.Users {
+		if keys.Has(key) {
+			continue
+		}
+		if !status.Owned {
+			delete(tc.Status.TiDB.Users, key)
+			klog.Infof("tidb cluster %s/%s: SQL user %s is not managed anymore, it's not dropped because it's not created by the operator",
+				tc.Namespace, tc.Name, key)
+			continue
+		}
+		name, host := v1alpha1.ParseTiDBRole(key)
+		stmt := "DROP USER IF EXISTS "
+		if status.Role {
+			stmt = "DROP ROLE IF EXISTS "
+		}
+		if err := conn.Exec(ctx, stmt+sqlUserIdent(name, host)); err != nil {
+			klog.Errorf("tidb cluster %s/%s: failed to drop SQL user %s, error: %v", tc.Namespace, tc.Name, key, err)
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, eventReasonFailedSyncSQLUser, "failed to drop %s: %v", key, err)
+			status.Synced = false
+			status.Message = err.Error()
+			tc.Status.TiDB.Users[key] = status
+			continue
+		}
+		delete(tc.Status.TiDB.Users, key)
+		klog.Infof("tidb cluster %s/%s: SQL user %s is dropped", tc.Namespace, tc.Name, key)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, eventReasonSQLUserDropped, "%s is dropped", key)
+	}
+	return nil
+}
+
+func (m *TiDBUserManager) recordFailure(tc *v1alpha1.TidbCluster, user *v1alpha1.TiDBUser, err error) {
+	klog.Errorf("tidb cluster %s/%s: failed to sync SQL user %s, error: %v", tc.Namespace, tc.Name, user.Key(), err)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, eventReasonFailedSyncSQLUser, "failed to sync %s: %v", user.Key(), err)
+	status := tc.Status.TiDB.Users[user.Key()]
+	status.Role = user.Role
+	status.Synced = false
+	status.Message = err.Error()
+	tc.Status.TiDB.Users[user.Key()] = status
+}
+
+// syncUser creates the user or role, changes the password if it's changed in the secret, and applies
+// the grants and roles if they are changed in the spec or by SQL
+func (m *TiDBUserManager) syncUser(ctx context.Context, tc *v1alpha1.TidbCluster, conn userSQLConn,
+	user *v1alpha1.TiDBUser, status *v1alpha1.TiDBUserStatus) error {
+	ident := sqlUserIdent(user.Name, user.GetHost())
+	applied := false
+	status.Role = user.Role
+
+	exists, err := conn.UserExists(ctx, user.Name, user.GetHost())
+	if err != nil {
+		return fmt.Errorf("check user failed: %v", err)
+	}
+	if user.Role {
+		if !exists {
+			if err := conn.Exec(ctx, "CREATE ROLE "+ident); err != nil {
+				return fmt.Errorf("create role failed: %v", err)
+			}
+			klog.Infof("tidb cluster %s/%s: SQL role %s is created", tc.Namespace, tc.Name, user.Key())
+			// only the users and roles created by the operator are dropped after they are removed from the spec
+			status.Owned = true
+			applied = true
+		}
+	} else {
+		password, version, err := m.getSecretValueAndVersion(tc.Namespace, user.PasswordSecret.Name, user.PasswordSecret.Key)
+		if err != nil {
+			return err
+		}
+		identified := ident + " IDENTIFIED BY " + sqlQuote(password)
+		if !exists {
+			if err := conn.Exec(ctx, "CREATE USER "+identified); err != nil {
+				return fmt.Errorf("create user failed: %v", err)
+			}
+			klog.Infof("tidb cluster %s/%s: SQL user %s is created", tc.Namespace, tc.Name, user.Key())
+			status.Owned = true
+			status.PasswordSecretVersion = version
+			applied = true
+		} else if status.PasswordSecretVersion != version {
+			// the password is set again if the user exists before it's managed
+			if err := conn.Exec(ctx, "ALTER USER "+identified); err != nil {
+				return fmt.Errorf("change password failed: %v", err)
+			}
+			klog.Infof("tidb cluster %s/%s: password of SQL user %s is changed", tc.Namespace, tc.Name, user.Key())
+			status.PasswordSecretVersion = version
+			applied = true
+		}
+	}
+
+	grantsHash, err := mngerutils.Sha256Sum([]interface{}{user.Grants, user.Roles})
+	if err != nil {
+		return err
+	}
+	observed, err := conn.ShowGrants(ctx, ident)
+	if err != nil {
+		return fmt.Errorf("show grants failed: %v", err)
+	}
+	observedHash, err := hashGrants(observed)
+	if err != nil {
+		return err
+	}
+	if status.GrantsHash != grantsHash || status.ObservedGrantsHash != observedHash {
+		if status.GrantsHash == grantsHash && status.ObservedGrantsHash != "" {
+			now := metav1.NewTime(m.now())
+			status.LastDriftTime = &now
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, eventReasonSQLUserDrifted,
+				"grants of %s are changed by SQL, they are reverted to the spec", user.Key())
+		}
+		if err := m.applyGrants(ctx, conn, user, ident, observed, status.GrantsHash != grantsHash); err != nil {
+			return err
+		}
+		if observed, err = conn.ShowGrants(ctx, ident); err != nil {
+			return fmt.Errorf("show grants failed: %v", err)
+		}
+		if status.ObservedGrantsHash, err = hashGrants(observed); err != nil {
+			return err
+		}
+		klog.Infof("tidb cluster %s/%s: grants of SQL user %s are applied", tc.Namespace, tc.Name, user.Key())
+		status.GrantsHash = grantsHash
+		applied = true
+	}
+
+	if applied {
+		now := metav1.NewTime(m.now())
+		status.LastSyncTime = &now
+	}
+	return nil
+}
+
+// applyGrants revokes the privileges and roles shown by TiDB but not in the spec, and grants the ones
+// in the spec but not shown by TiDB. The default roles are set if the roles are changed.
+func (m *TiDBUserManager) applyGrants(ctx context.Context, conn userSQLConn, user *v1alpha1.TiDBUser, ident string,
+	observed []string, specChanged bool) error {
+	current := parseShowGrants(observed)
+	desired := newTiDBGrants()
+	for _, grant := range user.Grants {
+		desired.add(grant.Privileges, sqlGrantLevel(grant.On), grant.WithGrantOption)
+	}
+	for _, role := range user.Roles {
+		desired.roles.Insert(sqlUserIdent(v1alpha1.ParseTiDBRole(role)))
+	}
+
+	var stmts []string
+	for _, level := range sets.StringKeySet(current.privileges).List() {
+		if revoked := current.privileges[level].Difference(desired.privileges[level]); revoked.Len() > 0 {
+			stmts = append(stmts, fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.Join(revoked.List(), ", "), level, ident))
+		}
+	}
+	for _, level := range current.grantOption.Difference(desired.grantOption).List() {
+		stmts = append(stmts, fmt.Sprintf("REVOKE GRANT OPTION ON %s FROM %s", level, ident))
+	}
+	revokedRoles := current.roles.Difference(desired.roles)
+	if revokedRoles.Len() > 0 {
+		stmts = append(stmts, fmt.Sprintf("REVOKE %s FROM %s", strings.Join(revokedRoles.List(), ", "), ident))
+	}
+	for _, level := range sets.StringKeySet(desired.privileges).List() {
+		granted := desired.privileges[level].Difference(current.privileges[level])
+		grantOption := desired.grantOption.Has(level) && !current.grantOption.Has(level)
+		if granted.Len() == 0 && !grantOption {
+			continue
+		}
+		if granted.Len() == 0 {
+			// GRANT OPTION is granted along with the privileges
+			granted = desired.privileges[level]
+		}
+		stmt := fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(granted.List(), ", "), level, ident)
+		if desired.grantOption.Has(level) {
+			stmt += " WITH GRANT OPTION"
+		}
+		stmts = append(stmts, stmt)
+	}
+	grantedRoles := desired.roles.Difference(current.roles)
+	if grantedRoles.Len() > 0 {
+		stmts = append(stmts, fmt.Sprintf("GRANT %s TO %s", strings.Join(grantedRoles.List(), ", "), ident))
+	}
+	// the default roles are not shown by `SHOW GRANTS`
+	if !user.Role && (specChanged || revokedRoles.Len() > 0 || grantedRoles.Len() > 0) {
+		defaultRole := "NONE"
+		if len(user.Roles) > 0 {
+			defaultRole = "ALL"
+		}
+		stmts = append(stmts, fmt.Sprintf("SET DEFAULT ROLE %s TO %s", defaultRole, ident))
+	}
+
+	for _, stmt := range stmts {
+		if err := conn.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("execute %q failed: %v", stmt, err)
+		}
+	}
+	return nil
+}
+
+// tidbGrants is the privileges and roles granted to a user or role
+type tidbGrants struct {
+	// privileges is the privileges granted on each level, such as `db`.*
+	privileges map[string]sets.String
+	// grantOption is the levels with GRANT OPTION
+	grantOption sets.String
+	// roles is the roles granted, such as 'r'@'%'
+	roles sets.String
+}
+
+func newTiDBGrants() *tidbGrants {
+	return &tidbGrants{
+		privileges:  map[string]sets.String{},
+		grantOption: sets.NewString(),
+		roles:       sets.NewString(),
+	}
+}
+
+// add adds the privileges on the level, USAGE is skipped because it means no privileges
+func (g *tidbGrants) add(privileges []string, level string, grantOption bool) {
+	for _, privilege := range privileges {
+		privilege = strings.ToUpper(strings.TrimSpace(privilege))
+		switch privilege {
+		case "", "USAGE":
+			continue
+		case "ALL":
+			privilege = "ALL PRIVILEGES"
+		}
+		if g.privileges[level] == nil {
+			g.privileges[level] = sets.NewString()
+		}
+		g.privileges[level].Insert(privilege)
+	}
+	if grantOption {
+		g.grantOption.Insert(level)
+	}
+}
+
+// parseShowGrants parses the lines of `SHOW GRANTS`, such as
+// "GRANT SELECT,INSERT ON `db`.* TO 'u'@'%' WITH GRANT OPTION" and "GRANT 'r'@'%' TO 'u'@'%'"
+func parseShowGrants(grants []string) *tidbGrants {
+	g := newTiDBGrants()
+	for _, grant := range grants {
+		grant = strings.TrimSpace(grant)
+		if !strings.HasPrefix(grant, "GRANT ") {
+			continue
+		}
+		grantOption := strings.HasSuffix(grant, " WITH GRANT OPTION")
+		grant = strings.TrimSuffix(grant, " WITH GRANT OPTION")
+		to := strings.LastIndex(grant, " TO ")
+		if to < 0 {
+			continue
+		}
+		granted := strings.TrimPrefix(grant[:to], "GRANT ")
+		on := strings.Index(granted, " ON ")
+		if on < 0 {
+			// roles granted to the user
+			for _, role := range strings.Split(granted, ",") {
+				g.roles.Insert(strings.TrimSpace(role))
+			}
+			continue
+		}
+		g.add(strings.Split(granted[:on], ","), granted[on+len(" ON "):], grantOption)
+	}
+	return g
+}
+
+func hashGrants(grants []string) (string, error) {
+	sorted := append([]string(nil), grants...)
+	sort.Strings(sorted)
+	return mngerutils.Sha256Sum(sorted)
+}
+
+// connectAdmin connects to TiDB with the account used to manage the users, and returns the name of the account
+func (m *TiDBUserManager) connectAdmin(tc *v1alpha1.TidbCluster) (userSQLConn, string, error) {
+	user, password, err := getTiDBAdminAccount(m.deps.SecretLister, tc)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := m.connect(tc, user, password)
+	if err != nil {
+		return nil, "", fmt.Errorf("connect to TiDB as %s failed: %v", user, err)
+	}
+	return conn, user, nil
+}
+
+// getTiDBAdminAccount returns the account the operator manages TiDB with, which is the one in
+// `spec.tidb.users.adminSecretName` if it's set, or root with the password set by TidbInitializer.
+func getTiDBAdminAccount(secretLister corelisterv1.SecretLister, tc *v1alpha1.TidbCluster) (string, string, error) {
+	if tc.Spec.TiDB.Users != nil && tc.Spec.TiDB.Users.AdminSecretName != "" {
+		secretName := tc.Spec.TiDB.Users.AdminSecretName
+		secret, err := secretLister.Secrets(tc.Namespace).Get(secretName)
+		if err != nil {
+			return "", "", fmt.Errorf("get secret %s/%s failed: %v", tc.Namespace, secretName, err)
+		}
+		user := string(secret.Data[tidbUsersAdminUserKey])
+		if user == "" {
+			return "", "", fmt.Errorf("key %s is not found in secret %s/%s", tidbUsersAdminUserKey, tc.Namespace, secretName)
+		}
+		// the password of the admin account may be empty
+		return user, string(secret.Data[tidbUsersAdminPasswordKey]), nil
+	}
+
+	secretName := controller.TiDBInitSecret(tc.Name)
+	secret, err := secretLister.Secrets(tc.Namespace).Get(secretName)
+	if err != nil {
+		return "", "", fmt.Errorf("get secret %s/%s of the root password failed: %v", tc.Namespace, secretName, err)
+	}
+	return "root", string(secret.Data[constants.TidbRootKey]), nil
+}
+
+// connectTiDB connects to the TiDB service, the TLS client secret of TiDB is used if TLS is enabled
+func (m *TiDBUserManager) connectTiDB(tc *v1alpha1.TidbCluster, user, password string) (userSQLConn, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s.%s.svc:%d", controller.TiDBMemberName(tc.Name), tc.Namespace, tc.Spec.TiDB.GetServicePort())
+	cfg.Timeout = pdapi.DefaultTimeout
+	if tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() {
+		tlsConfig, err := pdapi.GetTLSConfig(m.deps.SecretLister, pdapi.Namespace(tc.Namespace), util.TiDBClientTLSSecretName(tc.Name, nil), pdapi.TLSServer{
+			Namespace:     pdapi.Namespace(tc.Namespace),
+			ClusterName:   tc.Name,
+			ClusterDomain: tc.Spec.ClusterDomain,
+			Component:     v1alpha1.TiDBMemberType,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cfg.TLS = tlsConfig
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	ctx, cancel := context.WithTimeout(context.Background(), pdapi.DefaultTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &dbUserSQLConn{db: db}, nil
+}
+
+// getSecretValueAndVersion returns the value of the key in the secret and the version of the value, which is
+// changed if the secret is updated or recreated, so the password isn't kept in the status in any form
+func (m *TiDBUserManager) getSecretValueAndVersion(ns, name, key string) (string, string, error) {
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(name)
+	if err != nil {
+		return "", "", fmt.Errorf("get secret %s/%s failed: %v", ns, name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return "", "", fmt.Errorf("key %s is not found in secret %s/%s", key, ns, name)
+	}
+	return string(value), fmt.Sprintf("%s/%s/%s", secret.UID, secret.ResourceVersion, key), nil
+}
+
+// sqlQuote quotes the string literal in SQL
+func sqlQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
+
+// sqlUserIdent returns the account name in SQL, such as `'name'@'host'`
+func sqlUserIdent(name, host string) string {
+	return sqlQuote(name) + "@" + sqlQuote(host)
+}
+
+// sqlGrantLevel quotes the level of the privileges, such as `db`.* and `db`.`table`
+func sqlGrantLevel(on string) string {
+	parts := strings.SplitN(on, ".", 2)
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = "`" + strings.ReplaceAll(part, "`", "``") + "`"
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+type FakeTiDBUserManager struct {
+}
+
+func (f *FakeTiDBUserManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
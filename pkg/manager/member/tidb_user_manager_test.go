@@ -0,0 +1,318 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakeUserSQLConn records the statements, the users and their grants are kept as they are created and granted
+type fakeUserSQLConn struct {
+	stmts  []string
+	users  sets.String
+	grants map[string]*tidbGrants
+	err    error
+}
+
+func newFakeUserSQLConn() *fakeUserSQLConn {
+	return &fakeUserSQLConn{users: sets.NewString(), grants: map[string]*tidbGrants{}}
+}
+
+func (c *fakeUserSQLConn) Exec(ctx context.Context, query string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.stmts = append(c.stmts, query)
+	switch {
+	case strings.HasPrefix(query, "CREATE "):
+		user := strings.SplitN(query, " ", 4)[2]
+		c.users.Insert(user)
+		c.grants[user] = newTiDBGrants()
+	case strings.HasPrefix(query, "DROP "):
+		user := query[strings.LastIndex(query, " ")+1:]
+		c.users.Delete(user)
+		delete(c.grants, user)
+	case strings.HasPrefix(query, "GRANT "):
+		c.grant(query)
+	case strings.HasPrefix(query, "REVOKE GRANT OPTION ON "):
+		user := query[strings.LastIndex(query, " FROM ")+len(" FROM "):]
+		level := strings.TrimPrefix(query[:strings.LastIndex(query, " FROM ")], "REVOKE GRANT OPTION ON ")
+		c.grants[user].grantOption.Delete(level)
+	case strings.HasPrefix(query, "REVOKE "):
+		// REVOKE has the same syntax as GRANT
+		from := strings.LastIndex(query, " FROM ")
+		user := query[from+len(" FROM "):]
+		revoked := parseShowGrants([]string{"GRANT " + strings.TrimPrefix(query[:from], "REVOKE ") + " TO " + user})
+		current := c.grants[user]
+		for level, privileges := range revoked.privileges {
+			current.privileges[level] = current.privileges[level].Difference(privileges)
+			if current.privileges[level].Len() == 0 {
+				delete(current.privileges, level)
+			}
+		}
+		current.roles = current.roles.Difference(revoked.roles)
+	}
+	return nil
+}
+
+// grant adds the grants of a GRANT statement or a line of `SHOW GRANTS`
+func (c *fakeUserSQLConn) grant(query string) {
+	user := strings.TrimSuffix(query[strings.LastIndex(query, " TO ")+len(" TO "):], " WITH GRANT OPTION")
+	granted := parseShowGrants([]string{query})
+	current := c.grants[user]
+	for level, privileges := range granted.privileges {
+		current.add(privileges.List(), level, granted.grantOption.Has(level))
+	}
+	current.roles = current.roles.Union(granted.roles)
+}
+
+func (c *fakeUserSQLConn) UserExists(ctx context.Context, name, host string) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	return c.users.Has(sqlUserIdent(name, host)), nil
+}
+
+func (c *fakeUserSQLConn) ShowGrants(ctx context.Context, user string) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	grants := []string{fmt.Sprintf("GRANT USAGE ON *.* TO %s", user)}
+	current := c.grants[user]
+	for _, level := range sets.StringKeySet(current.privileges).List() {
+		grant := fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(current.privileges[level].List(), ","), level, user)
+		if current.grantOption.Has(level) {
+			grant += " WITH GRANT OPTION"
+		}
+		grants = append(grants, grant)
+	}
+	if current.roles.Len() > 0 {
+		grants = append(grants, fmt.Sprintf("GRANT %s TO %s", strings.Join(current.roles.List(), ","), user))
+	}
+	return grants, nil
+}
+
+func (c *fakeUserSQLConn) Close() error {
+	return nil
+}
+
+func TestTiDBUserManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewTiDBUserManager(deps)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	conn := newFakeUserSQLConn()
+	var adminUser string
+	m.connect = func(tc *v1alpha1.TidbCluster, user, password string) (userSQLConn, error) {
+		adminUser = user
+		return conn, nil
+	}
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	events := deps.Recorder.(*record.FakeRecorder).Events
+	resourceVersion := 0
+	setSecret := func(name string, data map[string]string) {
+		resourceVersion++
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       corev1.NamespaceDefault,
+				Name:            name,
+				UID:             types.UID(name),
+				ResourceVersion: strconv.Itoa(resourceVersion),
+			},
+			Data: map[string][]byte{},
+		}
+		for k, v := range data {
+			secret.Data[k] = []byte(v)
+		}
+		g.Expect(secretIndexer.Update(secret)).To(Succeed())
+	}
+	resetStmts := func() {
+		conn.stmts = nil
+		for len(events) > 0 {
+			<-events
+		}
+	}
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiDB.Users = &v1alpha1.TiDBUsers{
+		Users: []v1alpha1.TiDBUser{
+			{
+				Name:   "reader",
+				Role:   true,
+				Grants: []v1alpha1.TiDBGrant{{Privileges: []string{"select"}, On: "app.*"}},
+			},
+			{
+				Name:           "app",
+				PasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "app-password"}, Key: "password"},
+				Grants:         []v1alpha1.TiDBGrant{{Privileges: []string{"insert", "update"}, On: "app.orders", WithGrantOption: true}},
+				Roles:          []string{"reader"},
+			},
+		},
+	}
+	appIdent := "'app'@'%'"
+
+	// nothing is done before tidb is ready
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(conn.stmts).To(BeEmpty())
+	g.Expect(tc.Status.TiDB.Users).To(BeEmpty())
+
+	tc.Status.TiDB.StatefulSet = &appsv1.StatefulSetStatus{ReadyReplicas: 1}
+	// the users are not synced by root with the empty password if the secret of the root password doesn't exist
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(adminUser).To(BeEmpty())
+	g.Expect(tc.Status.TiDB.Users["reader@%"].Synced).To(BeFalse())
+	g.Expect(tc.Status.TiDB.Users["reader@%"].Message).To(ContainSubstring(controller.TiDBInitSecret(tc.Name)))
+	resetStmts()
+
+	setSecret(controller.TiDBInitSecret(tc.Name), map[string]string{constants.TidbRootKey: "root-password"})
+
+	// the failures are recorded in the status without blocking the reconciliation
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(adminUser).To(Equal("root"))
+	g.Expect(tc.Status.TiDB.Users["reader@%"].Synced).To(BeTrue())
+	g.Expect(tc.Status.TiDB.Users["app@%"].Synced).To(BeFalse())
+	g.Expect(tc.Status.TiDB.Users["app@%"].Message).To(ContainSubstring("app-password"))
+	g.Expect(<-events).To(ContainSubstring(eventReasonFailedSyncSQLUser))
+	resetStmts()
+
+	// the users are created and granted
+	setSecret("app-password", map[string]string{"password": "p1"})
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(conn.stmts).To(Equal([]string{
+		"CREATE USER 'app'@'%' IDENTIFIED BY 'p1'",
+		"GRANT INSERT, UPDATE ON `app`.`orders` TO 'app'@'%' WITH GRANT OPTION",
+		"GRANT 'reader'@'%' TO 'app'@'%'",
+		"SET DEFAULT ROLE ALL TO 'app'@'%'",
+	}))
+	status := tc.Status.TiDB.Users["app@%"]
+	g.Expect(status.Synced).To(BeTrue())
+	g.Expect(status.Owned).To(BeTrue())
+	g.Expect(status.Message).To(BeEmpty())
+	g.Expect(status.PasswordSecretVersion).To(Equal(fmt.Sprintf("app-password/%d/password", resourceVersion)))
+	g.Expect(status.LastSyncTime.Time).To(Equal(now))
+	g.Expect(tc.Status.TiDB.Users["reader@%"].Role).To(BeTrue())
+	g.Expect(tc.Status.TiDB.Users["reader@%"].Owned).To(BeTrue())
+	resetStmts()
+
+	// nothing is changed if the users are in sync
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(conn.stmts).To(BeEmpty())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(tc.Status.TiDB.Users["app@%"]).To(Equal(status))
+
+	// the password is changed after it's rotated in the secret
+	now = now.Add(time.Hour)
+	setSecret("app-password", map[string]string{"password": "it's"})
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(conn.stmts).To(Equal([]string{`ALTER USER 'app'@'%' IDENTIFIED BY 'it''s'`}))
+	g.Expect(tc.Status.TiDB.Users["app@%"].PasswordSecretVersion).To(Equal(fmt.Sprintf("app-password/%d/password", resourceVersion)))
+	g.Expect(tc.Status.TiDB.Users["app@%"].LastSyncTime.Time).To(Equal(now))
+
+	// only the grants changed by SQL are reverted
+	conn.grant("GRANT ALL PRIVILEGES ON *.* TO 'app'@'%'")
+	conn.grants[appIdent].roles.Delete("'reader'@'%'")
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(<-events).To(ContainSubstring(eventReasonSQLUserDrifted))
+	g.Expect(conn.stmts).To(Equal([]string{
+		"REVOKE ALL PRIVILEGES ON *.* FROM 'app'@'%'",
+		"GRANT 'reader'@'%' TO 'app'@'%'",
+		"SET DEFAULT ROLE ALL TO 'app'@'%'",
+	}))
+	g.Expect(tc.Status.TiDB.Users["app@%"].LastDriftTime.Time).To(Equal(now))
+
+	// only the changes of the grants in the spec are applied
+	tc.Spec.TiDB.Users.Users[1].Grants = []v1alpha1.TiDBGrant{{Privileges: []string{"insert", "update", "delete"}, On: "app.orders"}}
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(conn.stmts).To(Equal([]string{
+		"REVOKE GRANT OPTION ON `app`.`orders` FROM 'app'@'%'",
+		"GRANT DELETE ON `app`.`orders` TO 'app'@'%'",
+		"SET DEFAULT ROLE ALL TO 'app'@'%'",
+	}))
+
+	// the existing users are managed without being owned
+	conn.users.Insert("'legacy'@'%'")
+	conn.grants["'legacy'@'%'"] = newTiDBGrants()
+	setSecret("legacy-password", map[string]string{"password": "p2"})
+	tc.Spec.TiDB.Users.Users = append(tc.Spec.TiDB.Users.Users, v1alpha1.TiDBUser{
+		Name:           "legacy",
+		PasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "legacy-password"}, Key: "password"},
+	})
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(conn.stmts).To(Equal([]string{
+		"ALTER USER 'legacy'@'%' IDENTIFIED BY 'p2'",
+		"SET DEFAULT ROLE NONE TO 'legacy'@'%'",
+	}))
+	g.Expect(tc.Status.TiDB.Users["legacy@%"].Synced).To(BeTrue())
+	g.Expect(tc.Status.TiDB.Users["legacy@%"].Owned).To(BeFalse())
+
+	// the users removed from the spec are dropped only if they are created by the operator
+	tc.Spec.TiDB.Users.Users = tc.Spec.TiDB.Users.Users[1:2]
+	tc.Spec.TiDB.Users.Users[0].Roles = nil
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(conn.stmts).To(ConsistOf(
+		"REVOKE 'reader'@'%' FROM 'app'@'%'",
+		"SET DEFAULT ROLE NONE TO 'app'@'%'",
+		"DROP ROLE IF EXISTS 'reader'@'%'",
+	))
+	g.Expect(conn.users.List()).To(ConsistOf(appIdent, "'legacy'@'%'"))
+	g.Expect(tc.Status.TiDB.Users).To(HaveLen(1))
+	g.Expect(tc.Status.TiDB.Users).To(HaveKey("app@%"))
+
+	// the admin account in the secret is used, and it can not be managed
+	tc.Spec.TiDB.Users.AdminSecretName = "admin"
+	setSecret("admin", map[string]string{tidbUsersAdminUserKey: "operator", tidbUsersAdminPasswordKey: "secret"})
+	tc.Spec.TiDB.Users.Users = append(tc.Spec.TiDB.Users.Users, v1alpha1.TiDBUser{Name: "operator", Role: true})
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(adminUser).To(Equal("operator"))
+	g.Expect(conn.stmts).To(BeEmpty())
+	g.Expect(tc.Status.TiDB.Users["operator@%"].Synced).To(BeFalse())
+	g.Expect(tc.Status.TiDB.Users["operator@%"].Message).To(ContainSubstring("admin account"))
+
+	conn.err = fmt.Errorf("connection refused")
+	resetStmts()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TiDB.Users["app@%"].Synced).To(BeFalse())
+	g.Expect(tc.Status.TiDB.Users["app@%"].Message).To(ContainSubstring("connection refused"))
+
+	// the status is cleared after the users are not managed
+	tc.Spec.TiDB.Users = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TiDB.Users).To(BeNil())
+}
@@ -63,6 +63,9 @@ func (u *tiflashUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Statefu
 		klog.Infof("TidbCluster: [%s/%s]'s pd status is %s, tiflash status is %s, can not upgrade tiflash",
 			ns, tcName,
 			tc.Status.PD.Phase, tc.Status.TiFlash.Phase)
+		controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeNormal, eventReasonUpgradeBlocked,
+			controller.ClusterEventTarget{MemberType: v1alpha1.TiFlashMemberType},
+			"pd status is %s, tiflash status is %s, can not upgrade tiflash", tc.Status.PD.Phase, tc.Status.TiFlash.Phase)
 		_, podSpec, err := GetLastAppliedConfig(oldSet)
 		if err != nil {
 			return err
@@ -134,7 +137,7 @@ func (u *tiflashUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Statefu
 			}
 
 			if larger, err := tiflashEqualOrGreaterThanV512.Check(tc.TiFlashVersion()); err == nil && larger {
-				status, err := u.deps.TiFlashControl.GetTiFlashPodClient(tc.Namespace, tc.Name, podName, tc.IsTLSClusterEnabled()).GetStoreStatus()
+				status, err := u.deps.TiFlashControl.GetTiFlashPodClient(tc.Namespace, tc.Name, podName, tc.Spec.ClusterDomain, tc.IsTLSClusterEnabled()).GetStoreStatus()
 				if err != nil {
 					return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded TiFlash pod: [%s], get store status failed: %s", ns, tcName, podName, err)
 				}
@@ -317,13 +317,39 @@ func (m *tikvMemberManager) syncTiKVConfigMap(tc *v1alpha1.TidbCluster, set *app
 		}
 	}
 
-	err = mngerutils.UpdateConfigMapIfNeed(m.deps.ConfigMapLister, tc.BaseTiKVSpec().ConfigUpdateStrategy(), inUseName, newCm)
+	strategy := tc.BaseTiKVSpec().ConfigUpdateStrategy()
+	if strategy == v1alpha1.ConfigUpdateStrategyDynamic {
+		return syncDynamicConfigMap(m.deps, tc, v1alpha1.TiKVMemberType, &tc.Status.TiKV.DynamicConfig,
+			set, inUseName, newCm, isTiKVOnlineConfig, func(config map[string]interface{}) error {
+				return m.updateTiKVConfigOnline(tc, config)
+			})
+	}
+	tc.Status.TiKV.DynamicConfig = nil
+
+	err = mngerutils.UpdateConfigMapIfNeed(m.deps.ConfigMapLister, strategy, inUseName, newCm)
 	if err != nil {
 		return nil, err
 	}
 	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 }
 
+// updateTiKVConfigOnline changes the config of the stores that are up through the config API, the other
+// stores load the new config from the ConfigMap after they are restarted
+func (m *tikvMemberManager) updateTiKVConfigOnline(tc *v1alpha1.TidbCluster, config map[string]interface{}) error {
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.State != v1alpha1.TiKVStateUp {
+			klog.Infof("tidb cluster %s/%s: skip updating config of TiKV %s online, state: %s",
+				tc.Namespace, tc.Name, store.PodName, store.State)
+			continue
+		}
+		client := m.deps.TiKVControl.GetTiKVPodClient(tc.Namespace, tc.Name, store.PodName, tc.Spec.ClusterDomain, tc.IsTLSClusterEnabled())
+		if err := client.UpdateConfig(config); err != nil {
+			return fmt.Errorf("update config of TiKV %s failed: %w", store.PodName, err)
+		}
+	}
+	return nil
+}
+
 func getNewServiceForTidbCluster(tc *v1alpha1.TidbCluster, svcConfig SvcConfig) *corev1.Service {
 	ns := tc.Namespace
 	tcName := tc.Name
@@ -492,6 +518,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	podLabels := util.CombineStringMap(stsLabels.Labels(), baseTiKVSpec.Labels())
 	setName := controller.TiKVMemberName(tcName)
 	podAnnotations := util.CombineStringMap(baseTiKVSpec.Annotations(), controller.AnnProm(v1alpha1.DefaultTiKVStatusPort, "/metrics"))
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsRollingRestartAnnotations(&tc.Status.TiKV))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiKVLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiKV.Limits)
 	headlessSvcName := controller.TiKVPeerMemberName(tcName)
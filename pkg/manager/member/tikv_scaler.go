@@ -109,6 +109,15 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 		return nil
 	}
 
+	updated, err := s.ensureScaleInVictims(tc, v1alpha1.TiKVMemberType, tc.Spec.TiKV.ScalePolicy.GetScaleInVictimPolicy(), oldSet, newSet)
+	if err != nil || updated {
+		resetReplicas(newSet, oldSet)
+		if err != nil {
+			return err
+		}
+		return controller.RequeueErrorf("TidbCluster: [%s/%s], wait for the delete slots of tikv to be updated before scaling in", tc.Namespace, tc.Name)
+	}
+
 	scaleInParallelism := tc.Spec.TiKV.GetScaleInParallelism()
 
 	_, ordinals, replicas, deleteSlots := scaleMulti(oldSet, newSet, scaleInParallelism)
@@ -336,13 +345,17 @@ func (s *tikvScaler) preCheckUpStores(tc *v1alpha1.TidbCluster, podName string,
 	if upNumber < maxReplicas {
 		errMsg := fmt.Sprintf("the number of stores in Up state of TidbCluster [%s/%s] is %d, less than MaxReplicas in PD configuration(%d), can't scale in TiKV, podname %s ", tc.GetNamespace(), tc.GetName(), upNumber, maxReplicas, podName)
 		klog.Error(errMsg)
-		s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
+		controller.RecordClusterEvent(s.deps.Recorder, tc, v1.EventTypeWarning, eventReasonScaleInBlocked,
+			controller.ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType, PodName: podName},
+			"%d stores are Up, less than max-replicas %d in PD", upNumber, maxReplicas)
 		return false
 	} else if upNumber == maxReplicas {
 		if storeState == v1alpha1.TiKVStateUp {
 			errMsg := fmt.Sprintf("can't scale in TiKV of TidbCluster [%s/%s], cause the number of up stores is equal to MaxReplicas in PD configuration(%d), and the store in Pod %s which is going to be deleted is up too. MaxReplicas can be update online using pd-ctl or SQL statements, refer to https://docs.pingcap.com/tidb/stable/dynamic-config", tc.GetNamespace(), tc.GetName(), maxReplicas, podName)
 			klog.Error(errMsg)
-			s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
+			controller.RecordClusterEvent(s.deps.Recorder, tc, v1.EventTypeWarning, eventReasonScaleInBlocked,
+				controller.ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType, PodName: podName},
+				"%d stores are Up, equal to max-replicas in PD, the store to be deleted is Up", upNumber)
 			return false
 		}
 	}
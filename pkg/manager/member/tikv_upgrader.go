@@ -73,6 +73,8 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 	case *v1alpha1.TidbCluster:
 		if notReadyReason := u.isTiKVReadyToUpgrade(meta); notReadyReason != "" {
 			klog.Infof("TidbCluster: [%s/%s], can not upgrade tikv because: %s", ns, tcName, notReadyReason)
+			controller.RecordClusterEvent(u.deps.Recorder, meta, corev1.EventTypeNormal, eventReasonUpgradeBlocked,
+				controller.ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType}, "can not upgrade tikv because: %s", notReadyReason)
 			_, podSpec, err := GetLastAppliedConfig(oldSet)
 			if err != nil {
 				return err
@@ -159,6 +161,9 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 
 		// verify that cluster is stable before each node upgrade
 		if unstableReason := u.isClusterStable(tc); unstableReason != "" {
+			controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeNormal, eventReasonUpgradeWaitingForStability,
+				controller.ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType, PodName: TikvPodName(tcName, i)},
+				"wait for the cluster to be stable before upgrading the pod: %s", unstableReason)
 			return controller.RequeueErrorf("cluster is unstable: %s", unstableReason)
 		}
 
@@ -228,6 +233,9 @@ func (u *tikvUpgrader) evictLeaderBeforeUpgrade(tc *v1alpha1.TidbCluster, upgrad
 		}
 		if time.Now().After(evictLeaderBeginTime.Add(evictLeaderTimeout)) {
 			klog.Infof("%s: evict leader timeout with threshold %v, so ready to upgrade", logPrefix, evictLeaderTimeout)
+			controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeWarning, eventReasonEvictLeaderTimeout,
+				controller.ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType, PodName: upgradePod.Name, StoreID: strconv.FormatUint(storeID, 10)},
+				"leaders are not evicted in %v, upgrade the pod anyway", evictLeaderTimeout)
 			return true, nil
 		}
 
@@ -318,6 +326,9 @@ func (u *tikvUpgrader) endEvictLeaderAfterUpgrade(tc *v1alpha1.TidbCluster, pod
 		timeout := tc.TiKVWaitLeaderTransferBackTimeout()
 		if time.Now().After(evictLeaderEndTime.Add(timeout)) {
 			klog.Infof("%s: time out with threshold %v, so skip waiting leaders for transfer back", logPrefix, timeout)
+			controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeWarning, eventReasonTransferLeaderBackTimeout,
+				controller.ClusterEventTarget{MemberType: v1alpha1.TiKVMemberType, PodName: pod.Name, StoreID: store.ID},
+				"leader count is %d after %v, less than 2/3 of %d before upgrade, upgrade the next pod anyway", store.LeaderCount, timeout, leaderCountBefore)
 			return true
 		}
 
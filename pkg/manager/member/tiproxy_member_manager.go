@@ -320,14 +320,14 @@ func (m *tiproxyMemberManager) syncProxyService(tc *v1alpha1.TidbCluster, peer b
 		newSvc.Spec.Ports = append(newSvc.Spec.Ports,
 			corev1.ServicePort{
 				Name:       "tiproxy-api",
-				Port:       3080,
-				TargetPort: intstr.FromInt(int(3080)),
+				Port:       v1alpha1.DefaultTiProxyStatusPort,
+				TargetPort: intstr.FromInt(int(v1alpha1.DefaultTiProxyStatusPort)),
 				Protocol:   corev1.ProtocolTCP,
 			},
 			corev1.ServicePort{
 				Name:       "tiproxy-peer",
-				Port:       3081,
-				TargetPort: intstr.FromInt(int(3081)),
+				Port:       v1alpha1.DefaultTiProxyPeerPort,
+				TargetPort: intstr.FromInt(int(v1alpha1.DefaultTiProxyPeerPort)),
 				Protocol:   corev1.ProtocolTCP,
 			},
 		)
@@ -338,14 +338,14 @@ func (m *tiproxyMemberManager) syncProxyService(tc *v1alpha1.TidbCluster, peer b
 		newSvc.Spec.Ports = append(newSvc.Spec.Ports,
 			corev1.ServicePort{
 				Name:       "tiproxy-api",
-				Port:       3080,
-				TargetPort: intstr.FromInt(int(3080)),
+				Port:       v1alpha1.DefaultTiProxyStatusPort,
+				TargetPort: intstr.FromInt(int(v1alpha1.DefaultTiProxyStatusPort)),
 				Protocol:   corev1.ProtocolTCP,
 			},
 			corev1.ServicePort{
 				Name:       "tiproxy-sql",
-				Port:       6000,
-				TargetPort: intstr.FromInt(int(6000)),
+				Port:       v1alpha1.DefaultTiProxyServerPort,
+				TargetPort: intstr.FromInt(int(v1alpha1.DefaultTiProxyServerPort)),
 				Protocol:   corev1.ProtocolTCP,
 			},
 		)
@@ -392,7 +392,8 @@ func (m *tiproxyMemberManager) getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *c
 	stsLabels := labelTiProxy(tc)
 	stsName := controller.TiProxyMemberName(tcName)
 	podLabels := util.CombineStringMap(stsLabels, baseTiProxySpec.Labels())
-	podAnnotations := util.CombineStringMap(baseTiProxySpec.Annotations(), controller.AnnProm(3080, "/api/metrics"))
+	podAnnotations := util.CombineStringMap(baseTiProxySpec.Annotations(), controller.AnnProm(v1alpha1.DefaultTiProxyStatusPort, "/api/metrics"))
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsRollingRestartAnnotations(&tc.Status.TiProxy))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiProxyLabelVal)
 	headlessSvcName := controller.TiProxyPeerMemberName(tcName)
 
@@ -466,17 +467,17 @@ func (m *tiproxyMemberManager) getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *c
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "tiproxy",
-				ContainerPort: int32(6000),
+				ContainerPort: v1alpha1.DefaultTiProxyServerPort,
 				Protocol:      corev1.ProtocolTCP,
 			},
 			{
 				Name:          "tiproxy-api",
-				ContainerPort: int32(3080),
+				ContainerPort: v1alpha1.DefaultTiProxyStatusPort,
 				Protocol:      corev1.ProtocolTCP,
 			},
 			{
 				Name:          "tiproxy-peer",
-				ContainerPort: int32(3081),
+				ContainerPort: v1alpha1.DefaultTiProxyPeerPort,
 				Protocol:      corev1.ProtocolTCP,
 			},
 		},
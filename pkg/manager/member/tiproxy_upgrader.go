@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/third_party/k8s"
 
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
@@ -52,6 +53,8 @@ func (u *tiproxyUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Statefu
 	if tc.Status.TiProxy.Phase == v1alpha1.ScalePhase {
 		klog.Infof("TidbCluster: [%s/%s]'s tiproxy status is %v, can not upgrade tiproxy",
 			ns, tcName, tc.Status.TiProxy.Phase)
+		controller.RecordClusterEvent(u.deps.Recorder, tc, corev1.EventTypeNormal, eventReasonUpgradeBlocked,
+			controller.ClusterEventTarget{MemberType: v1alpha1.TiProxyMemberType}, "tiproxy status is %s, can not upgrade tiproxy", tc.Status.TiProxy.Phase)
 		_, podSpec, err := GetLastAppliedConfig(oldSet)
 		if err != nil {
 			return err
@@ -0,0 +1,490 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// internalCAValidity is the validity of the CA generated by the operator
+	internalCAValidity = 10 * 365 * 24 * time.Hour
+	// internalCARotationDelay is how long the components are given to load the trusted CAs including the new CA
+	// before the certificates are issued by it, which covers the sync period of the mounted secrets by kubelet
+	internalCARotationDelay = 10 * time.Minute
+
+	// internalCANextCertKey and internalCANextKeyKey are the keys of the CA secret storing the new CA
+	// during the rotation, `tls.crt` and `tls.key` store the CA signing the certificates and `ca.crt` stores
+	// the trusted CAs
+	internalCANextCertKey = "next.crt"
+	internalCANextKeyKey  = "next.key"
+
+	eventReasonCertificateIssued  = "CertificateIssued"
+	eventReasonInternalCAExpiring = "InternalCAExpiring"
+	eventReasonInternalCARotating = "InternalCARotating"
+	eventReasonInternalCARotated  = "InternalCARotated"
+)
+
+// certRequest describes a certificate issued by the internal CA
+type certRequest struct {
+	secretName string
+	commonName string
+	hosts      []string
+	uris       []string
+}
+
+// internalCA is the CA signing the certificates and the CAs trusted by the components
+type internalCA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	keyPEM  []byte
+	// bundle is written to `ca.crt` of the issued secrets, it contains the new CA and the replaced CA
+	// besides the signing CA during the rotation
+	bundle []byte
+}
+
+// TLSCertManager issues the TLS certificates of the components from the internal CA,
+// and renews them before they expire. The CA generated by the operator is rotated before it expires.
+type TLSCertManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+}
+
+// NewTLSCertManager returns a *TLSCertManager
+func NewTLSCertManager(deps *controller.Dependencies) *TLSCertManager {
+	return &TLSCertManager{
+		deps: deps,
+		now:  time.Now,
+	}
+}
+
+func (m *TLSCertManager) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.IsInternalCAEnabled() {
+		tc.Status.InternalCA = nil
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	ca, err := m.syncCA(tc)
+	if err != nil {
+		return fmt.Errorf("sync internal CA of tidb cluster %s/%s failed: %v", ns, tcName, err)
+	}
+
+	status := &v1alpha1.InternalCAStatus{
+		CANotAfter:   &metav1.Time{Time: ca.cert.NotAfter},
+		Certificates: map[string]v1alpha1.IssuedCertificate{},
+	}
+	for _, req := range getCertRequests(tc) {
+		cert, err := m.syncCert(tc, req, ca)
+		if err != nil {
+			return fmt.Errorf("sync certificate %s/%s of tidb cluster %s failed: %v", ns, req.secretName, tcName, err)
+		}
+		if cert != nil {
+			status.Certificates[req.secretName] = *cert
+		}
+	}
+	tc.Status.InternalCA = status
+	return nil
+}
+
+// syncCA returns the internal CA, the CA is generated if it doesn't exist, and it's rotated before it expires
+// if it's generated by the operator
+func (m *TLSCertManager) syncCA(tc *v1alpha1.TidbCluster) (*internalCA, error) {
+	secretName := tc.GetInternalCASecretName()
+	secret, err := m.deps.SecretLister.Secrets(tc.Namespace).Get(secretName)
+	if errors.IsNotFound(err) {
+		cert, key, err := crypto.NewCA(internalCACommonName(tc), internalCAValidity)
+		if err != nil {
+			return nil, err
+		}
+		secret = newInternalCASecret(tc, map[string][]byte{
+			corev1.TLSCertKey:              cert,
+			corev1.TLSPrivateKeyKey:        key,
+			corev1.ServiceAccountRootCAKey: cert,
+		})
+		if _, err := m.deps.TypedControl.CreateOrUpdateSecret(tc, secret); err != nil {
+			return nil, err
+		}
+		klog.Infof("tidb cluster %s/%s: internal CA is generated in secret %s", tc.Namespace, tc.Name, secretName)
+		return parseInternalCA(secret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, fmt.Errorf("%s or %s is not found in secret %s", corev1.TLSCertKey, corev1.TLSPrivateKeyKey, secretName)
+	}
+
+	if secret.Annotations[label.AnnTLSIssuedBy] != label.AnnTLSIssuedByInternalCAVal {
+		// the CA created by the users is not rotated by the operator
+		ca, err := parseInternalCA(secret)
+		if err != nil {
+			return nil, err
+		}
+		if m.isCAExpiring(tc, ca.cert) {
+			// the certificates can not outlive the CA, so they will not be renewed any more
+			m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, eventReasonInternalCAExpiring,
+				"the internal CA in secret %s expires at %s, it should be replaced", secretName, ca.cert.NotAfter.Format(time.RFC3339))
+		}
+		return ca, nil
+	}
+
+	data, err := m.rotateCA(tc, secret)
+	if err != nil {
+		return nil, err
+	}
+	desired := newInternalCASecret(tc, data)
+	if !reflect.DeepEqual(data, secret.Data) {
+		if _, err := m.deps.TypedControl.CreateOrUpdateSecret(tc, desired); err != nil {
+			return nil, err
+		}
+	}
+	return parseInternalCA(desired)
+}
+
+// newInternalCASecret returns the secret storing the internal CA generated by the operator
+func newInternalCASecret(tc *v1alpha1.TidbCluster, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        tc.GetInternalCASecretName(),
+			Namespace:   tc.Namespace,
+			Labels:      label.New().Instance(tc.GetInstanceName()),
+			Annotations: map[string]string{label.AnnTLSIssuedBy: label.AnnTLSIssuedByInternalCAVal},
+		},
+		Data: data,
+	}
+}
+
+// rotateCA returns the data of the CA secret after moving the rotation of the CA forward. The rotation is done
+// in two steps, so the components always trust the CAs of their peers:
+//  1. A new CA is generated and added to the trusted CAs before the signing CA can't cover a renewal of the
+//     certificates. The certificates are issued again with the new trusted CAs.
+//  2. The new CA signs the certificates after the components have loaded the trusted CAs, that is, some time
+//     later and no component is being upgraded.
+//
+// The replaced CA is trusted until it expires.
+func (m *TLSCertManager) rotateCA(tc *v1alpha1.TidbCluster, secret *corev1.Secret) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	for k, v := range secret.Data {
+		data[k] = v
+	}
+	now := m.now()
+	ca, err := parseInternalCA(secret)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := crypto.ParseCertsPEM(ca.bundle)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s in secret %s failed: %v", corev1.ServiceAccountRootCAKey, secret.Name, err)
+	}
+
+	if len(data[internalCANextCertKey]) == 0 {
+		if m.isCAExpiring(tc, ca.cert) {
+			certPEM, keyPEM, err := crypto.NewCA(internalCACommonName(tc), internalCAValidity)
+			if err != nil {
+				return nil, err
+			}
+			next, err := crypto.ParseCertPEM(certPEM)
+			if err != nil {
+				return nil, err
+			}
+			data[internalCANextCertKey] = certPEM
+			data[internalCANextKeyKey] = keyPEM
+			trusted = append(trusted, next)
+			msg := fmt.Sprintf("the internal CA in secret %s expires at %s, a new CA is trusted and will sign the certificates after %s",
+				secret.Name, ca.cert.NotAfter.Format(time.RFC3339), internalCARotationDelay)
+			klog.Infof("tidb cluster %s/%s: %s", tc.Namespace, tc.Name, msg)
+			m.deps.Recorder.Event(tc, corev1.EventTypeNormal, eventReasonInternalCARotating, msg)
+		}
+	} else {
+		next, err := crypto.ParseCertPEM(data[internalCANextCertKey])
+		if err != nil {
+			return nil, fmt.Errorf("parse %s in secret %s failed: %v", internalCANextCertKey, secret.Name, err)
+		}
+		// the CA is valid from an hour before it's generated
+		generated := next.NotBefore.Add(time.Hour)
+		if now.After(generated.Add(internalCARotationDelay)) && !isAnyComponentUpgrading(tc) {
+			data[corev1.TLSCertKey] = data[internalCANextCertKey]
+			data[corev1.TLSPrivateKeyKey] = data[internalCANextKeyKey]
+			delete(data, internalCANextCertKey)
+			delete(data, internalCANextKeyKey)
+			ca.cert = next
+			msg := fmt.Sprintf("the internal CA in secret %s is rotated, the new CA expires at %s", secret.Name, next.NotAfter.Format(time.RFC3339))
+			klog.Infof("tidb cluster %s/%s: %s", tc.Namespace, tc.Name, msg)
+			m.deps.Recorder.Event(tc, corev1.EventTypeNormal, eventReasonInternalCARotated, msg)
+		}
+	}
+
+	// the signing CA is always trusted, and the expired CAs are not trusted any more
+	var bundle []*x509.Certificate
+	for _, cert := range trusted {
+		if cert.Equal(ca.cert) || now.Before(cert.NotAfter) {
+			bundle = append(bundle, cert)
+		}
+	}
+	data[corev1.ServiceAccountRootCAKey] = crypto.EncodeCertsPEM(bundle...)
+	return data, nil
+}
+
+// isCAExpiring returns whether the CA can't cover a renewal of the certificates issued now
+func (m *TLSCertManager) isCAExpiring(tc *v1alpha1.TidbCluster, ca *x509.Certificate) bool {
+	spec := tc.Spec.TLSCluster.InternalCA
+	return m.now().Add(spec.GetCertValidity() + spec.GetRenewBefore()).After(ca.NotAfter)
+}
+
+// isAnyComponentUpgrading returns whether any component is being upgraded, which includes the rolling restart
+// to load the TLS secrets only loaded at startup
+func isAnyComponentUpgrading(tc *v1alpha1.TidbCluster) bool {
+	for _, status := range tc.AllComponentStatus() {
+		if status.GetPhase() == v1alpha1.UpgradePhase {
+			return true
+		}
+	}
+	return false
+}
+
+func internalCACommonName(tc *v1alpha1.TidbCluster) string {
+	return fmt.Sprintf("%s-%s-ca", tc.Namespace, tc.Name)
+}
+
+// parseInternalCA parses the internal CA from the secret, the signing CA is the only trusted CA if
+// the trusted CAs are not stored in the secret
+func parseInternalCA(secret *corev1.Secret) (*internalCA, error) {
+	ca := &internalCA{
+		certPEM: secret.Data[corev1.TLSCertKey],
+		keyPEM:  secret.Data[corev1.TLSPrivateKeyKey],
+		bundle:  secret.Data[corev1.ServiceAccountRootCAKey],
+	}
+	if len(ca.bundle) == 0 {
+		ca.bundle = ca.certPEM
+	}
+	cert, err := crypto.ParseCertPEM(ca.certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s in secret %s failed: %v", corev1.TLSCertKey, secret.Name, err)
+	}
+	ca.cert = cert
+	return ca, nil
+}
+
+// syncCert issues the certificate if it doesn't exist, or it's about to expire, or it doesn't match the request.
+// The secrets not created by the operator are skipped and nil is returned.
+func (m *TLSCertManager) syncCert(tc *v1alpha1.TidbCluster, req *certRequest, ca *internalCA) (*v1alpha1.IssuedCertificate, error) {
+	secret, err := m.deps.SecretLister.Secrets(tc.Namespace).Get(req.secretName)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	reason := "the certificate does not exist"
+	if err == nil {
+		if secret.Annotations[label.AnnTLSIssuedBy] != label.AnnTLSIssuedByInternalCAVal {
+			klog.V(4).Infof("tidb cluster %s/%s: secret %s is not issued by the internal CA, skip it", tc.Namespace, tc.Name, req.secretName)
+			return nil, nil
+		}
+		cert, renewReason := m.checkCert(tc, secret, req, ca)
+		if renewReason == "" {
+			return issuedCertificate(cert), nil
+		}
+		reason = renewReason
+	}
+
+	certPEM, keyPEM, err := crypto.IssueCert(ca.certPEM, ca.keyPEM, req.commonName, req.hosts, []string{"127.0.0.1", "::1"}, req.uris,
+		tc.Spec.TLSCluster.InternalCA.GetCertValidity())
+	if err != nil {
+		return nil, err
+	}
+	cert, err := crypto.ParseCertPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	newSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        req.secretName,
+			Namespace:   tc.Namespace,
+			Labels:      label.New().Instance(tc.GetInstanceName()),
+			Annotations: map[string]string{label.AnnTLSIssuedBy: label.AnnTLSIssuedByInternalCAVal},
+		},
+		Data: map[string][]byte{
+			corev1.ServiceAccountRootCAKey: ca.bundle,
+			corev1.TLSCertKey:              certPEM,
+			corev1.TLSPrivateKeyKey:        keyPEM,
+		},
+	}
+	if _, err := m.deps.TypedControl.CreateOrUpdateSecret(tc, newSecret); err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("certificate in secret %s is issued because %s, it expires at %s", req.secretName, reason, cert.NotAfter.Format(time.RFC3339))
+	klog.Infof("tidb cluster %s/%s: %s", tc.Namespace, tc.Name, msg)
+	m.deps.Recorder.Event(tc, corev1.EventTypeNormal, eventReasonCertificateIssued, msg)
+	return issuedCertificate(cert), nil
+}
+
+// checkCert returns the certificate in the secret and the reason to renew it, an empty reason means
+// the certificate is still valid
+func (m *TLSCertManager) checkCert(tc *v1alpha1.TidbCluster, secret *corev1.Secret, req *certRequest, ca *internalCA) (*x509.Certificate, string) {
+	cert, err := crypto.ParseCertPEM(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, fmt.Sprintf("the certificate is invalid: %v", err)
+	}
+	if len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, "the private key does not exist"
+	}
+	if !bytes.Equal(secret.Data[corev1.ServiceAccountRootCAKey], ca.bundle) {
+		return nil, "the trusted CAs are changed"
+	}
+	if err := cert.CheckSignatureFrom(ca.cert); err != nil {
+		return nil, "the CA is changed"
+	}
+	if !sets.NewString(cert.DNSNames...).Equal(sets.NewString(req.hosts...)) {
+		return nil, "the hosts are changed"
+	}
+	uris := sets.NewString()
+	for _, uri := range cert.URIs {
+		uris.Insert(uri.String())
+	}
+	if !uris.Equal(sets.NewString(req.uris...)) {
+		return nil, "the URI SANs are changed"
+	}
+	renewBefore := tc.Spec.TLSCluster.InternalCA.GetRenewBefore()
+	// the certificate already expires with the CA can not be renewed
+	if m.now().Add(renewBefore).After(cert.NotAfter) && cert.NotAfter.Before(ca.cert.NotAfter) {
+		return nil, fmt.Sprintf("the certificate expires at %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	return cert, ""
+}
+
+func issuedCertificate(cert *x509.Certificate) *v1alpha1.IssuedCertificate {
+	return &v1alpha1.IssuedCertificate{
+		SerialNumber: fmt.Sprintf("%x", cert.SerialNumber),
+		NotAfter:     metav1.NewTime(cert.NotAfter),
+	}
+}
+
+// getCertRequests returns the certificates of the components deployed in the tidb cluster
+func getCertRequests(tc *v1alpha1.TidbCluster) []*certRequest {
+	tcName := tc.GetName()
+	var reqs []*certRequest
+	component := func(name string, services, peerServices []string, memberTypes ...v1alpha1.MemberType) {
+		reqs = append(reqs, &certRequest{
+			secretName: util.ClusterTLSSecretName(tcName, name),
+			commonName: name,
+			hosts:      getCertHosts(tc, services, peerServices),
+			uris:       getCertURIs(tc, memberTypes...),
+		})
+	}
+
+	if tc.Spec.PD != nil || len(tc.Spec.PDMS) > 0 {
+		// the secret of PD is shared by PD microservices and discovery
+		services := []string{controller.PDMemberName(tcName), controller.DiscoveryMemberName(tcName)}
+		peerServices := []string{controller.PDPeerMemberName(tcName)}
+		memberTypes := []v1alpha1.MemberType{v1alpha1.PDMemberType}
+		for _, pdms := range tc.Spec.PDMS {
+			services = append(services, controller.PDMSMemberName(tcName, pdms.Name))
+			peerServices = append(peerServices, controller.PDMSPeerMemberName(tcName, pdms.Name))
+			memberTypes = append(memberTypes, v1alpha1.PDMSMemberType(pdms.Name))
+		}
+		component(label.PDLabelVal, services, peerServices, memberTypes...)
+	}
+	if tc.Spec.TiKV != nil {
+		component(label.TiKVLabelVal, []string{controller.TiKVMemberName(tcName)}, []string{controller.TiKVPeerMemberName(tcName)}, v1alpha1.TiKVMemberType)
+	}
+	if tc.Spec.TiFlash != nil {
+		component(label.TiFlashLabelVal, []string{controller.TiFlashMemberName(tcName)}, []string{controller.TiFlashPeerMemberName(tcName)}, v1alpha1.TiFlashMemberType)
+	}
+	if tc.Spec.TiDB != nil {
+		component(label.TiDBLabelVal, []string{controller.TiDBMemberName(tcName)}, []string{controller.TiDBPeerMemberName(tcName)}, v1alpha1.TiDBMemberType)
+	}
+	if tc.Spec.TiProxy != nil {
+		component(label.TiProxyLabelVal, []string{controller.TiProxyMemberName(tcName)}, []string{controller.TiProxyPeerMemberName(tcName)}, v1alpha1.TiProxyMemberType)
+	}
+	if tc.Spec.TiCDC != nil {
+		component(label.TiCDCLabelVal, []string{controller.TiCDCMemberName(tcName)}, []string{controller.TiCDCPeerMemberName(tcName)}, v1alpha1.TiCDCMemberType)
+	}
+	if tc.Spec.Pump != nil {
+		component(label.PumpLabelVal, nil, []string{controller.PumpPeerMemberName(tcName)}, v1alpha1.PumpMemberType)
+	}
+	reqs = append(reqs, &certRequest{
+		secretName: util.ClusterClientTLSSecretName(tcName),
+		commonName: "TiDB Operator Client",
+	})
+
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() {
+		reqs = append(reqs, &certRequest{
+			secretName: util.TiDBServerTLSSecretName(tcName),
+			commonName: "TiDB Server",
+			hosts:      getCertHosts(tc, []string{controller.TiDBMemberName(tcName)}, []string{controller.TiDBPeerMemberName(tcName)}),
+			uris:       getCertURIs(tc, v1alpha1.TiDBMemberType),
+		}, &certRequest{
+			secretName: util.TiDBClientTLSSecretName(tcName, nil),
+			commonName: "TiDB Client",
+		})
+	}
+	return reqs
+}
+
+// getCertHosts returns the DNS names of the services, the pods behind the headless peer services
+// are covered by the wildcard names. The names with the cluster domain are included so the
+// peers across Kubernetes can access the pods.
+func getCertHosts(tc *v1alpha1.TidbCluster, services, peerServices []string) []string {
+	ns := tc.GetNamespace()
+	hosts := sets.NewString("localhost")
+	add := func(name string) {
+		hosts.Insert(name, fmt.Sprintf("%s.%s", name, ns), fmt.Sprintf("%s.%s.svc", name, ns))
+		if tc.Spec.ClusterDomain != "" {
+			hosts.Insert(fmt.Sprintf("%s.%s.svc.%s", name, ns, tc.Spec.ClusterDomain))
+		}
+	}
+	for _, svc := range services {
+		add(svc)
+	}
+	for _, svc := range peerServices {
+		add(svc)
+		add("*." + svc)
+	}
+	return hosts.List()
+}
+
+// getCertURIs returns the URI SANs identifying the servers of the components, so the certificates pass
+// the verification of the operator if it pins the URI SANs of the servers
+func getCertURIs(tc *v1alpha1.TidbCluster, memberTypes ...v1alpha1.MemberType) []string {
+	var uris []string
+	for _, memberType := range memberTypes {
+		uris = append(uris, pdapi.GetServerURISAN(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.Spec.ClusterDomain, memberType))
+	}
+	return uris
+}
+
+type FakeTLSCertManager struct {
+}
+
+func (f *FakeTLSCertManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
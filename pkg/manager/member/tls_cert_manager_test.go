@@ -0,0 +1,260 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTLSCertManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	certValidity := 90 * 24 * time.Hour
+	renewBefore := 30 * 24 * time.Hour
+
+	deps := controller.NewFakeDependencies()
+	m := NewTLSCertManager(deps)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	events := deps.Recorder.(*record.FakeRecorder).Events
+	// syncInformer adds the secrets created by the manager to the lister
+	syncInformer := func() {
+		list := &corev1.SecretList{}
+		g.Expect(cli.List(context.TODO(), list)).To(Succeed())
+		for i := range list.Items {
+			g.Expect(secretIndexer.Update(&list.Items[i])).To(Succeed())
+		}
+	}
+	getSecret := func(name string) *corev1.Secret {
+		obj, exist, err := secretIndexer.GetByKey("default/" + name)
+		g.Expect(err).To(Succeed())
+		g.Expect(exist).To(BeTrue())
+		return obj.(*corev1.Secret)
+	}
+	drainEvents := func() int {
+		count := 0
+		for len(events) > 0 {
+			<-events
+			count++
+		}
+		return count
+	}
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiFlash = nil
+	tc.Spec.TiProxy = nil
+
+	// nothing is issued if the internal CA is not enabled
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.InternalCA).To(BeNil())
+	syncInformer()
+	g.Expect(secretIndexer.ListKeys()).To(BeEmpty())
+
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true, InternalCA: &v1alpha1.InternalCA{}}
+	// the secret created by the users is not managed by the operator
+	userSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: util.ClusterTLSSecretName(tc.Name, label.TiDBLabelVal)},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("user")},
+	}
+	g.Expect(secretIndexer.Add(userSecret)).To(Succeed())
+
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(drainEvents()).To(Equal(3))
+	status := tc.Status.InternalCA
+	g.Expect(status).NotTo(BeNil())
+	g.Expect(status.CANotAfter).NotTo(BeNil())
+	g.Expect(status.Certificates).To(HaveLen(3))
+	g.Expect(status.Certificates).To(HaveKey(util.ClusterTLSSecretName(tc.Name, label.PDLabelVal)))
+	g.Expect(status.Certificates).To(HaveKey(util.ClusterTLSSecretName(tc.Name, label.TiKVLabelVal)))
+	g.Expect(status.Certificates).To(HaveKey(util.ClusterClientTLSSecretName(tc.Name)))
+	g.Expect(getSecret(userSecret.Name).Data[corev1.TLSCertKey]).To(Equal([]byte("user")))
+
+	ca := getSecret(tc.GetInternalCASecretName())
+	pdSecret := getSecret(util.ClusterTLSSecretName(tc.Name, label.PDLabelVal))
+	g.Expect(pdSecret.Data[corev1.ServiceAccountRootCAKey]).To(Equal(ca.Data[corev1.TLSCertKey]))
+	pdCert, err := crypto.ParseCertPEM(pdSecret.Data[corev1.TLSCertKey])
+	g.Expect(err).To(Succeed())
+	g.Expect(pdCert.DNSNames).To(ContainElements("test-pd", "test-pd.default.svc", "*.test-pd-peer.default.svc", "test-discovery"))
+	g.Expect(pdCert.NotAfter).To(BeTemporally("~", now.Add(certValidity), time.Minute))
+	// the URI SAN of the servers can be pinned by the operator
+	g.Expect(pdCert.URIs).To(HaveLen(1))
+	g.Expect(pdCert.URIs[0].String()).To(Equal("spiffe://cluster.local/ns/default/tc/test/pd"))
+
+	// nothing is issued again if the certificates are valid
+	serial := tc.Status.InternalCA.Certificates[pdSecret.Name].SerialNumber
+	g.Expect(serial).NotTo(BeEmpty())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(drainEvents()).To(Equal(0))
+	g.Expect(tc.Status.InternalCA.Certificates[pdSecret.Name].SerialNumber).To(Equal(serial))
+
+	// the certificate is issued again if the hosts are changed
+	tc.Spec.TiKV = nil
+	tc.Spec.ClusterDomain = "cluster-1.com"
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(drainEvents()).To(Equal(1))
+	g.Expect(tc.Status.InternalCA.Certificates).To(HaveLen(2))
+	g.Expect(tc.Status.InternalCA.Certificates[pdSecret.Name].SerialNumber).NotTo(Equal(serial))
+	// the trust domain of the URI SAN is the cluster domain
+	pdCert, err = crypto.ParseCertPEM(getSecret(pdSecret.Name).Data[corev1.TLSCertKey])
+	g.Expect(err).To(Succeed())
+	g.Expect(pdCert.URIs[0].String()).To(Equal("spiffe://cluster-1.com/ns/default/tc/test/pd"))
+	serial = tc.Status.InternalCA.Certificates[pdSecret.Name].SerialNumber
+
+	// the certificates are renewed before they expire
+	now = now.Add(certValidity - renewBefore + time.Hour)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(drainEvents()).To(Equal(2))
+	g.Expect(tc.Status.InternalCA.Certificates[pdSecret.Name].SerialNumber).NotTo(Equal(serial))
+
+	// the status is cleared after the internal CA is disabled
+	tc.Spec.TLSCluster.InternalCA = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.InternalCA).To(BeNil())
+}
+
+func TestTLSCertManagerRotateCA(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewTLSCertManager(deps)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	cli := deps.GenericControl.(*controller.FakeGenericControl).FakeCli
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	events := deps.Recorder.(*record.FakeRecorder).Events
+	syncInformer := func() {
+		list := &corev1.SecretList{}
+		g.Expect(cli.List(context.TODO(), list)).To(Succeed())
+		for i := range list.Items {
+			g.Expect(secretIndexer.Update(&list.Items[i])).To(Succeed())
+		}
+	}
+	getSecret := func(name string) *corev1.Secret {
+		obj, exist, err := secretIndexer.GetByKey("default/" + name)
+		g.Expect(err).To(Succeed())
+		g.Expect(exist).To(BeTrue())
+		return obj.(*corev1.Secret)
+	}
+	collectReasons := func() []string {
+		var reasons []string
+		for len(events) > 0 {
+			reasons = append(reasons, strings.Fields(<-events)[1])
+		}
+		return reasons
+	}
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV = nil
+	tc.Spec.TiFlash = nil
+	tc.Spec.TiProxy = nil
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true, InternalCA: &v1alpha1.InternalCA{}}
+	pdSecretName := util.ClusterTLSSecretName(tc.Name, label.PDLabelVal)
+	pdCert := func() *x509.Certificate {
+		cert, err := crypto.ParseCertPEM(getSecret(pdSecretName).Data[corev1.TLSCertKey])
+		g.Expect(err).To(Succeed())
+		return cert
+	}
+
+	// the CA can't cover a renewal of the certificates, which is 120 days by default
+	oldCert, oldKey, err := crypto.NewCA("old", 100*24*time.Hour)
+	g.Expect(err).To(Succeed())
+	oldCA, err := crypto.ParseCertPEM(oldCert)
+	g.Expect(err).To(Succeed())
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: tc.GetInternalCASecretName()},
+		Data:       map[string][]byte{corev1.TLSCertKey: oldCert, corev1.TLSPrivateKeyKey: oldKey},
+	}
+
+	// the CA created by the users is not rotated
+	g.Expect(cli.Create(context.TODO(), caSecret.DeepCopy())).To(Succeed())
+	syncInformer()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(collectReasons()).To(ContainElement(eventReasonInternalCAExpiring))
+	g.Expect(getSecret(caSecret.Name).Data).NotTo(HaveKey(internalCANextCertKey))
+
+	// the new CA is trusted first
+	caSecret = getSecret(caSecret.Name).DeepCopy()
+	caSecret.Annotations = map[string]string{label.AnnTLSIssuedBy: label.AnnTLSIssuedByInternalCAVal}
+	g.Expect(cli.Update(context.TODO(), caSecret)).To(Succeed())
+	syncInformer()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	reasons := collectReasons()
+	g.Expect(reasons).To(ContainElement(eventReasonInternalCARotating))
+	g.Expect(reasons).NotTo(ContainElement(eventReasonInternalCAExpiring))
+	caSecret = getSecret(caSecret.Name)
+	g.Expect(caSecret.Data[corev1.TLSCertKey]).To(Equal(oldCert))
+	nextCA, err := crypto.ParseCertPEM(caSecret.Data[internalCANextCertKey])
+	g.Expect(err).To(Succeed())
+	trusted, err := crypto.ParseCertsPEM(caSecret.Data[corev1.ServiceAccountRootCAKey])
+	g.Expect(err).To(Succeed())
+	g.Expect(trusted).To(HaveLen(2))
+	bundle := caSecret.Data[corev1.ServiceAccountRootCAKey]
+	g.Expect(getSecret(pdSecretName).Data[corev1.ServiceAccountRootCAKey]).To(Equal(caSecret.Data[corev1.ServiceAccountRootCAKey]))
+	g.Expect(pdCert().CheckSignatureFrom(oldCA)).To(Succeed())
+
+	// the new CA doesn't sign the certificates until the components load the trusted CAs
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(collectReasons()).To(BeEmpty())
+	now = now.Add(internalCARotationDelay + time.Minute)
+	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	g.Expect(collectReasons()).To(BeEmpty())
+	g.Expect(pdCert().CheckSignatureFrom(oldCA)).To(Succeed())
+
+	tc.Status.TiDB.Phase = v1alpha1.NormalPhase
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	reasons = collectReasons()
+	g.Expect(reasons).To(ContainElement(eventReasonInternalCARotated))
+	g.Expect(reasons).To(ContainElement(eventReasonCertificateIssued))
+	caSecret = getSecret(caSecret.Name)
+	g.Expect(caSecret.Data).NotTo(HaveKey(internalCANextCertKey))
+	g.Expect(caSecret.Data).NotTo(HaveKey(internalCANextKeyKey))
+	g.Expect(tc.Status.InternalCA.CANotAfter.Time).To(BeTemporally("==", nextCA.NotAfter))
+	// the replaced CA is still trusted until it expires
+	g.Expect(caSecret.Data[corev1.ServiceAccountRootCAKey]).To(Equal(bundle))
+	g.Expect(pdCert().CheckSignatureFrom(nextCA)).To(Succeed())
+
+	now = oldCA.NotAfter.Add(time.Hour)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	syncInformer()
+	trusted, err = crypto.ParseCertsPEM(getSecret(caSecret.Name).Data[corev1.ServiceAccountRootCAKey])
+	g.Expect(err).To(Succeed())
+	g.Expect(trusted).To(HaveLen(1))
+	g.Expect(trusted[0].Equal(nextCA)).To(BeTrue())
+}
@@ -0,0 +1,430 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	mngerutils "github.com/pingcap/tidb-operator/pkg/manager/utils"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/third_party/k8s"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tlsReloadTimeout is how long the pods are given to reload the rotated certificates before they are
+	// restarted, which covers the sync period of the mounted secrets by kubelet
+	tlsReloadTimeout = 10 * time.Minute
+
+	eventReasonTLSCertRotated      = "TLSCertificateRotated"
+	eventReasonTLSCertReloadFailed = "TLSCertificateReloadFailed"
+)
+
+// tlsSecrets are the TLS secrets mounted by a component
+type tlsSecrets struct {
+	// online are the secrets reloaded by the component when new connections are created,
+	// the mounted files are updated in place by kubelet after the secrets change
+	online []string
+	// served is the secret of the certificate served by the component on the port returned by tlsServedPort,
+	// it's one of the online secrets and verified to be served by the pods after the online secrets change
+	served string
+	// statement are the secrets reloaded by `ALTER INSTANCE RELOAD TLS`
+	statement []string
+	// restart are the secrets only loaded by the component at startup
+	restart []string
+}
+
+// getTLSSecrets returns the TLS secrets mounted by the component
+func getTLSSecrets(tc *v1alpha1.TidbCluster, status v1alpha1.ComponentStatus) tlsSecrets {
+	tcName := tc.GetName()
+	tlsCluster := tc.IsTLSClusterEnabled()
+	tlsTiDB := tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled()
+	secrets := tlsSecrets{}
+	clusterSecrets := func(component string) []string {
+		if !tlsCluster {
+			return nil
+		}
+		secrets.served = util.ClusterTLSSecretName(tcName, component)
+		return []string{secrets.served, util.ClusterClientTLSSecretName(tcName)}
+	}
+
+	switch status.(type) {
+	case *v1alpha1.PDStatus:
+		secrets.online = clusterSecrets(label.PDLabelVal)
+		if tlsTiDB && !tc.SkipTLSWhenConnectTiDB() {
+			secrets.online = append(secrets.online, util.TiDBClientTLSSecretName(tcName, tc.Spec.PD.TLSClientSecretName))
+		}
+	case *v1alpha1.PDMSStatus:
+		secrets.online = clusterSecrets(label.PDLabelVal)
+	case *v1alpha1.TiKVStatus:
+		secrets.online = clusterSecrets(label.TiKVLabelVal)
+	case *v1alpha1.TiFlashStatus:
+		secrets.online = clusterSecrets(label.TiFlashLabelVal)
+	case *v1alpha1.TiCDCStatus:
+		secrets.online = clusterSecrets(label.TiCDCLabelVal)
+	case *v1alpha1.TiProxyStatus:
+		secrets.online = clusterSecrets(label.TiProxyLabelVal)
+		if tlsTiDB {
+			secrets.online = append(secrets.online, util.TiDBServerTLSSecretName(tcName))
+			if tc.Spec.TiProxy.SSLEnableTiDB || !tc.SkipTLSWhenConnectTiDB() {
+				secrets.online = append(secrets.online, util.TiDBClientTLSSecretName(tcName, tc.Spec.TiProxy.TLSClientSecretName))
+			}
+		}
+	case *v1alpha1.TiDBStatus:
+		secrets.online = clusterSecrets(label.TiDBLabelVal)
+		if tlsTiDB {
+			secrets.statement = []string{util.TiDBServerTLSSecretName(tcName)}
+		}
+	case *v1alpha1.PumpStatus:
+		// pump only loads the certificates at startup
+		secrets.restart = clusterSecrets(label.PumpLabelVal)
+		secrets.served = ""
+	}
+	return secrets
+}
+
+// tlsServedPort returns the port on which the component serves the certificate of the cluster TLS secret
+func tlsServedPort(memberType v1alpha1.MemberType) int32 {
+	switch memberType {
+	case v1alpha1.PDMemberType, v1alpha1.PDMSTSOMemberType, v1alpha1.PDMSSchedulingMemberType:
+		return v1alpha1.DefaultPDClientPort
+	case v1alpha1.TiKVMemberType:
+		return v1alpha1.DefaultTiKVStatusPort
+	case v1alpha1.TiFlashMemberType:
+		return v1alpha1.DefaultTiFlashProxyStatusPort
+	case v1alpha1.TiCDCMemberType:
+		return v1alpha1.DefaultTiCDCPort
+	case v1alpha1.TiProxyMemberType:
+		return v1alpha1.DefaultTiProxyStatusPort
+	case v1alpha1.TiDBMemberType:
+		return v1alpha1.DefaultTiDBStatusPort
+	}
+	return 0
+}
+
+// IsTLSSecretUsed returns whether the secret is mounted as a TLS secret by any component of the tidb cluster
+func IsTLSSecretUsed(tc *v1alpha1.TidbCluster, secretName string) bool {
+	for _, status := range tc.AllComponentStatus() {
+		secrets := getTLSSecrets(tc, status)
+		for _, name := range append(append(secrets.online, secrets.statement...), secrets.restart...) {
+			if name == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TLSReloadManager detects the rotation of the TLS secrets mounted by the components, and makes
+// the components load the rotated certificates in the cheapest way they support:
+//   - The components reloading the certificates online are verified to serve the rotated certificates.
+//   - TiDB is made to reload the certificate of the MySQL protocol by `ALTER INSTANCE RELOAD TLS`.
+//   - The pods of the components only loading the certificates at startup are restarted in order by
+//     the upgraders, with the leaders evicted before the restart.
+//
+// The pods that don't load the rotated certificates in tlsReloadTimeout are restarted as well.
+type TLSReloadManager struct {
+	deps *controller.Dependencies
+	now  func() time.Time
+	// servedCert returns the certificate served by the pod of the component
+	servedCert func(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) (*x509.Certificate, error)
+}
+
+// NewTLSReloadManager returns a *TLSReloadManager
+func NewTLSReloadManager(deps *controller.Dependencies) *TLSReloadManager {
+	m := &TLSReloadManager{
+		deps: deps,
+		now:  time.Now,
+	}
+	m.servedCert = m.dialServedCert
+	return m
+}
+
+func (m *TLSReloadManager) Sync(tc *v1alpha1.TidbCluster) error {
+	for _, status := range tc.AllComponentStatus() {
+		if err := m.syncComponent(tc, status); err != nil {
+			return fmt.Errorf("sync TLS certificates of %s of tidb cluster %s/%s failed: %v",
+				status.MemberType(), tc.GetNamespace(), tc.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func (m *TLSReloadManager) syncComponent(tc *v1alpha1.TidbCluster, status v1alpha1.ComponentStatus) error {
+	ns := tc.GetNamespace()
+	memberType := status.MemberType()
+	secrets := getTLSSecrets(tc, status)
+	onlineHash, err := m.hashSecrets(ns, secrets.online)
+	if err != nil {
+		return err
+	}
+	reloadHash, err := m.hashSecrets(ns, secrets.statement)
+	if err != nil {
+		return err
+	}
+	restartHash, err := m.hashSecrets(ns, secrets.restart)
+	if err != nil {
+		return err
+	}
+
+	old := status.GetTLSCertRotation()
+	if onlineHash == "" && reloadHash == "" && restartHash == "" {
+		status.SetTLSCertRotation(nil)
+		return nil
+	}
+	if old == nil {
+		// the running pods have loaded the current certificates when the secrets are observed first
+		status.SetTLSCertRotation(&v1alpha1.TLSCertRotationStatus{
+			OnlineHash:  onlineHash,
+			ReloadHash:  reloadHash,
+			RestartHash: restartHash,
+		})
+		return nil
+	}
+	if old.OnlineHash == onlineHash && old.ReloadHash == reloadHash && old.RestartHash == restartHash {
+		return nil
+	}
+
+	rotation := old.DeepCopy()
+	if !rotation.Reloading {
+		rotation.Reloading = true
+		rotation.LastReloadStrategy = v1alpha1.TLSReloadStrategyOnline
+		rotation.LastRotationTime = &metav1.Time{Time: m.now()}
+	}
+	timeout := m.now().After(rotation.LastRotationTime.Add(tlsReloadTimeout))
+	var pending []string
+	// reloaded returns whether the class of the secrets are loaded by the pods, the pods are restarted
+	// if they fail to load the secrets in time
+	reloaded := func(hash string, err error) bool {
+		if err == nil {
+			return true
+		}
+		if !timeout {
+			pending = append(pending, err.Error())
+			return false
+		}
+		rotation.RollingRestartHash = hash
+		rotation.LastReloadStrategy = v1alpha1.TLSReloadStrategyRollingRestart
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, eventReasonTLSCertReloadFailed,
+			"%s fails to reload the rotated TLS certificates in %s, the pods are restarted: %v", memberType, tlsReloadTimeout, err)
+		return true
+	}
+
+	// the secrets only loaded at startup are removed if restartHash is empty,
+	// the pods are not restarted since they don't use them any more
+	if old.RestartHash != restartHash {
+		if restartHash != "" {
+			rotation.LastReloadStrategy = v1alpha1.TLSReloadStrategyRollingRestart
+			rotation.RollingRestartHash = restartHash
+		}
+		rotation.RestartHash = restartHash
+	}
+	if old.ReloadHash != reloadHash {
+		if reloadHash == "" || reloaded(reloadHash, m.reloadTiDBTLS(tc, secrets.statement[0])) {
+			if reloadHash != "" && rotation.LastReloadStrategy == v1alpha1.TLSReloadStrategyOnline {
+				rotation.LastReloadStrategy = v1alpha1.TLSReloadStrategyAlterInstance
+			}
+			rotation.ReloadHash = reloadHash
+		}
+	}
+	if old.OnlineHash != onlineHash {
+		if onlineHash == "" || secrets.served == "" || reloaded(onlineHash, m.verifyServedCert(tc, memberType, secrets.served)) {
+			rotation.OnlineHash = onlineHash
+		}
+	}
+	status.SetTLSCertRotation(rotation)
+
+	if len(pending) > 0 {
+		// the pods are verified again in the next round of the reconciliation
+		klog.Infof("tidb cluster %s/%s: wait for %s to reload the rotated TLS certificates: %s",
+			ns, tc.GetName(), memberType, strings.Join(pending, "; "))
+		return nil
+	}
+	rotation.Reloading = false
+	msg := fmt.Sprintf("TLS certificates of %s are rotated, they are reloaded by %s", memberType, rotation.LastReloadStrategy)
+	klog.Infof("tidb cluster %s/%s: %s", ns, tc.GetName(), msg)
+	m.deps.Recorder.Event(tc, corev1.EventTypeNormal, eventReasonTLSCertRotated, msg)
+	return nil
+}
+
+// reloadTiDBTLS makes the ready tidb pods reload the certificate of the MySQL protocol, it returns an error if
+// any of them doesn't serve the certificate in the secret, for example, the mounted files are not updated yet
+func (m *TLSReloadManager) reloadTiDBTLS(tc *v1alpha1.TidbCluster, secretName string) error {
+	expected, err := m.getSecretCert(tc.GetNamespace(), secretName)
+	if err != nil {
+		return err
+	}
+	pods, err := m.listReadyPods(tc, v1alpha1.TiDBMemberType)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return nil
+	}
+	user, password, err := m.getTiDBReloadAccount(tc)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+		if err != nil {
+			return err
+		}
+		served, err := m.deps.TiDBControl.ReloadTLS(tc, ordinal, user, password)
+		if err != nil {
+			return fmt.Errorf("reload TLS of pod %s failed: %v", pod.Name, err)
+		}
+		if !served.Equal(expected) {
+			return fmt.Errorf("pod %s doesn't serve the certificate in secret %s after reloading", pod.Name, secretName)
+		}
+	}
+	return nil
+}
+
+// getTiDBReloadAccount returns the account to run `ALTER INSTANCE RELOAD TLS` with, which is the admin account
+// of the users, except that root with the empty password is used if the cluster is not initialized by
+// TidbInitializer, as the certificates of such clusters are rotated as well.
+func (m *TLSReloadManager) getTiDBReloadAccount(tc *v1alpha1.TidbCluster) (string, string, error) {
+	if tc.Spec.TiDB.Users == nil || tc.Spec.TiDB.Users.AdminSecretName == "" {
+		_, err := m.deps.SecretLister.Secrets(tc.GetNamespace()).Get(controller.TiDBInitSecret(tc.GetName()))
+		if errors.IsNotFound(err) {
+			return "root", "", nil
+		}
+	}
+	return getTiDBAdminAccount(m.deps.SecretLister, tc)
+}
+
+// verifyServedCert returns an error if any ready pod of the component doesn't serve the certificate in the secret
+func (m *TLSReloadManager) verifyServedCert(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, secretName string) error {
+	expected, err := m.getSecretCert(tc.GetNamespace(), secretName)
+	if err != nil {
+		return err
+	}
+	pods, err := m.listReadyPods(tc, memberType)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		served, err := m.servedCert(tc, memberType, pod)
+		if err != nil {
+			return fmt.Errorf("get the certificate served by pod %s failed: %v", pod.Name, err)
+		}
+		if !served.Equal(expected) {
+			return fmt.Errorf("pod %s doesn't serve the certificate in secret %s", pod.Name, secretName)
+		}
+	}
+	return nil
+}
+
+// dialServedCert returns the certificate served by the pod on the port returned by tlsServedPort
+func (m *TLSReloadManager) dialServedCert(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) (*x509.Certificate, error) {
+	config, err := pdapi.GetComponentTLSConfig(m.deps.SecretLister, pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.Spec.ClusterDomain, memberType)
+	if err != nil {
+		return nil, err
+	}
+	host := fmt.Sprintf("%s.%s.%s.svc", pod.Spec.Hostname, pod.Spec.Subdomain, pod.Namespace)
+	if tc.Spec.ClusterDomain != "" {
+		host = fmt.Sprintf("%s.%s", host, tc.Spec.ClusterDomain)
+	}
+	dialer := &net.Dialer{Timeout: pdapi.DefaultTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", fmt.Sprintf("%s:%d", host, tlsServedPort(memberType)), config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+// listReadyPods returns the ready pods of the component, the pods not ready load the certificates when they start
+func (m *TLSReloadManager) listReadyPods(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) ([]*corev1.Pod, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(string(memberType)).Selector()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := m.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	var ready []*corev1.Pod
+	for _, pod := range pods {
+		if k8s.IsPodReady(pod) {
+			ready = append(ready, pod)
+		}
+	}
+	return ready, nil
+}
+
+// getSecretCert returns the certificate in the secret
+func (m *TLSReloadManager) getSecretCert(ns, secretName string) (*x509.Certificate, error) {
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(secretName)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ParseCertPEM(secret.Data[corev1.TLSCertKey])
+}
+
+// hashSecrets returns the hash of the contents of the secrets, the secrets not found are skipped
+// since the pods can not start without them
+func (m *TLSReloadManager) hashSecrets(ns string, names []string) (string, error) {
+	contents := map[string]map[string][]byte{}
+	for _, name := range names {
+		secret, err := m.deps.SecretLister.Secrets(ns).Get(name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		contents[name] = secret.Data
+	}
+	if len(contents) == 0 {
+		return "", nil
+	}
+	sum, err := mngerutils.Sha256Sum(contents)
+	if err != nil {
+		return "", err
+	}
+	return sum[:16], nil
+}
+
+// tlsRollingRestartAnnotations returns the pod annotation to restart the pods in order after the TLS secrets
+// only loaded at startup are rotated, or the pods fail to reload the rotated certificates
+func tlsRollingRestartAnnotations(status v1alpha1.ComponentStatus) map[string]string {
+	rotation := status.GetTLSCertRotation()
+	if rotation == nil || rotation.RollingRestartHash == "" {
+		return nil
+	}
+	return map[string]string{label.AnnTLSCertHash: rotation.RollingRestartHash}
+}
+
+type FakeTLSReloadManager struct {
+}
+
+func (f *FakeTLSReloadManager) Sync(tc *v1alpha1.TidbCluster) error {
+	return nil
+}
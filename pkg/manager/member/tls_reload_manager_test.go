@@ -0,0 +1,232 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTLSReloadManagerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewTLSReloadManager(deps)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	tidbControl := deps.TiDBControl.(*controller.FakeTiDBControl)
+	events := deps.Recorder.(*record.FakeRecorder).Events
+
+	caCert, caKey, err := crypto.NewCA("ca", time.Hour)
+	g.Expect(err).To(Succeed())
+	newCert := func() *x509.Certificate {
+		certPEM, _, err := crypto.IssueCert(caCert, caKey, "test", nil, nil, nil, time.Hour)
+		g.Expect(err).To(Succeed())
+		cert, err := crypto.ParseCertPEM(certPEM)
+		g.Expect(err).To(Succeed())
+		return cert
+	}
+	setSecret := func(name string, cert *x509.Certificate) {
+		g.Expect(secretIndexer.Update(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name},
+			Data:       map[string][]byte{corev1.TLSCertKey: crypto.EncodeCertsPEM(cert)},
+		})).To(Succeed())
+	}
+	// the certificates served by the pods
+	served := map[string]*x509.Certificate{}
+	m.servedCert = func(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) (*x509.Certificate, error) {
+		return served[pod.Name], nil
+	}
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiFlash = nil
+	tc.Spec.TiProxy = nil
+	tc.Spec.Pump = &v1alpha1.PumpSpec{}
+	for _, memberType := range []v1alpha1.MemberType{v1alpha1.TiKVMemberType, v1alpha1.TiDBMemberType} {
+		g.Expect(podIndexer.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      fmt.Sprintf("test-%s-0", memberType),
+				Labels:    label.New().Instance(tc.GetInstanceName()).Component(string(memberType)),
+			},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		})).To(Succeed())
+	}
+
+	// nothing is recorded if TLS is not enabled
+	g.Expect(m.Sync(tc)).To(Succeed())
+	for _, status := range tc.AllComponentStatus() {
+		g.Expect(status.GetTLSCertRotation()).To(BeNil())
+	}
+
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{Enabled: true}
+	tikvSecret := util.ClusterTLSSecretName(tc.Name, label.TiKVLabelVal)
+	tidbServerSecret := util.TiDBServerTLSSecretName(tc.Name)
+	pumpSecret := util.ClusterTLSSecretName(tc.Name, label.PumpLabelVal)
+	for _, name := range []string{
+		util.ClusterTLSSecretName(tc.Name, label.PDLabelVal),
+		tikvSecret,
+		util.ClusterTLSSecretName(tc.Name, label.TiDBLabelVal),
+		util.ClusterClientTLSSecretName(tc.Name),
+		tidbServerSecret,
+		pumpSecret,
+	} {
+		setSecret(name, newCert())
+	}
+
+	// the secrets observed first are not rotations
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(tc.Status.PD.TLSCertRotation.OnlineHash).NotTo(BeEmpty())
+	g.Expect(tc.Status.PD.TLSCertRotation.LastRotationTime).To(BeNil())
+	g.Expect(tc.Status.TiDB.TLSCertRotation.ReloadHash).NotTo(BeEmpty())
+	g.Expect(tc.Status.Pump.TLSCertRotation.RestartHash).NotTo(BeEmpty())
+	g.Expect(tlsRollingRestartAnnotations(&tc.Status.TiDB)).To(BeNil())
+
+	// nothing is changed if the secrets are not changed
+	tikvRotation := tc.Status.TiKV.TLSCertRotation.DeepCopy()
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(tc.Status.TiKV.TLSCertRotation).To(Equal(tikvRotation))
+
+	// tikv reloads the certificates online, which are verified to be served by the pods
+	tikvCert := newCert()
+	setSecret(tikvSecret, tikvCert)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(tc.Status.TiKV.TLSCertRotation.Reloading).To(BeTrue())
+	g.Expect(tc.Status.TiKV.TLSCertRotation.OnlineHash).To(Equal(tikvRotation.OnlineHash))
+	g.Expect(tc.Status.TiKV.TLSCertRotation.LastRotationTime.Time).To(Equal(now))
+	served["test-tikv-0"] = tikvCert
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(collectEvents(events)).To(ConsistOf(ContainSubstring(eventReasonTLSCertRotated)))
+	g.Expect(tc.Status.TiKV.TLSCertRotation.Reloading).To(BeFalse())
+	g.Expect(tc.Status.TiKV.TLSCertRotation.OnlineHash).NotTo(Equal(tikvRotation.OnlineHash))
+	g.Expect(tc.Status.TiKV.TLSCertRotation.LastReloadStrategy).To(Equal(v1alpha1.TLSReloadStrategyOnline))
+	g.Expect(tlsRollingRestartAnnotations(&tc.Status.TiKV)).To(BeNil())
+	g.Expect(tc.Status.PD.TLSCertRotation.LastRotationTime).To(BeNil())
+
+	// tidb reloads the certificate of the MySQL protocol by `ALTER INSTANCE RELOAD TLS`
+	now = now.Add(time.Hour)
+	tidbCert := newCert()
+	setSecret(tidbServerSecret, tidbCert)
+	tidbControl.SetReloadTLS(newCert(), nil)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(tc.Status.TiDB.TLSCertRotation.Reloading).To(BeTrue())
+	tidbControl.SetReloadTLS(tidbCert, nil)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(collectEvents(events)).To(ConsistOf(ContainSubstring(eventReasonTLSCertRotated)))
+	g.Expect(tidbControl.ReloadedTLS).To(Equal([]int32{0, 0}))
+	rotation := tc.Status.TiDB.TLSCertRotation
+	g.Expect(rotation.Reloading).To(BeFalse())
+	g.Expect(rotation.LastReloadStrategy).To(Equal(v1alpha1.TLSReloadStrategyAlterInstance))
+	g.Expect(rotation.LastRotationTime.Time).To(Equal(now))
+	g.Expect(tlsRollingRestartAnnotations(&tc.Status.TiDB)).To(BeNil())
+
+	// tikv is restarted if it doesn't serve the rotated certificates in time
+	setSecret(tikvSecret, newCert())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(events).To(BeEmpty())
+	now = now.Add(tlsReloadTimeout + time.Minute)
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(collectEvents(events)).To(ConsistOf(
+		ContainSubstring(eventReasonTLSCertReloadFailed),
+		ContainSubstring(eventReasonTLSCertRotated),
+	))
+	rotation = tc.Status.TiKV.TLSCertRotation
+	g.Expect(rotation.Reloading).To(BeFalse())
+	g.Expect(rotation.LastReloadStrategy).To(Equal(v1alpha1.TLSReloadStrategyRollingRestart))
+	g.Expect(rotation.RollingRestartHash).To(Equal(rotation.OnlineHash))
+	g.Expect(tlsRollingRestartAnnotations(&tc.Status.TiKV)).To(Equal(map[string]string{label.AnnTLSCertHash: rotation.OnlineHash}))
+
+	// pump is restarted to load the certificates
+	setSecret(pumpSecret, newCert())
+	g.Expect(m.Sync(tc)).To(Succeed())
+	g.Expect(collectEvents(events)).To(ConsistOf(ContainSubstring(eventReasonTLSCertRotated)))
+	rotation = tc.Status.Pump.TLSCertRotation
+	g.Expect(rotation.LastReloadStrategy).To(Equal(v1alpha1.TLSReloadStrategyRollingRestart))
+	g.Expect(rotation.RollingRestartHash).To(Equal(rotation.RestartHash))
+	g.Expect(tlsRollingRestartAnnotations(&tc.Status.Pump)).To(Equal(map[string]string{label.AnnTLSCertHash: rotation.RestartHash}))
+
+	// the status is cleared after TLS is disabled
+	tc.Spec.TLSCluster = nil
+	tc.Spec.TiDB.TLSClient = nil
+	g.Expect(m.Sync(tc)).To(Succeed())
+	for _, status := range tc.AllComponentStatus() {
+		g.Expect(status.GetTLSCertRotation()).To(BeNil())
+	}
+}
+
+func TestIsTLSSecretUsed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	g.Expect(IsTLSSecretUsed(tc, util.ClusterTLSSecretName(tc.Name, label.TiKVLabelVal))).To(BeFalse())
+
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	g.Expect(IsTLSSecretUsed(tc, util.ClusterTLSSecretName(tc.Name, label.TiKVLabelVal))).To(BeTrue())
+	g.Expect(IsTLSSecretUsed(tc, util.ClusterClientTLSSecretName(tc.Name))).To(BeTrue())
+	g.Expect(IsTLSSecretUsed(tc, util.TiDBServerTLSSecretName(tc.Name))).To(BeFalse())
+	g.Expect(IsTLSSecretUsed(tc, "other")).To(BeFalse())
+
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{Enabled: true}
+	g.Expect(IsTLSSecretUsed(tc, util.TiDBServerTLSSecretName(tc.Name))).To(BeTrue())
+}
+
+func TestGetTiDBReloadAccount(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	m := NewTLSReloadManager(deps)
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	tc := newTidbClusterForPD()
+
+	// root with the empty password is used if the cluster is not initialized by TidbInitializer
+	user, password, err := m.getTiDBReloadAccount(tc)
+	g.Expect(err).To(Succeed())
+	g.Expect(user).To(Equal("root"))
+	g.Expect(password).To(BeEmpty())
+
+	g.Expect(secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: controller.TiDBInitSecret(tc.Name)},
+		Data:       map[string][]byte{constants.TidbRootKey: []byte("root-password")},
+	})).To(Succeed())
+	user, password, err = m.getTiDBReloadAccount(tc)
+	g.Expect(err).To(Succeed())
+	g.Expect(user).To(Equal("root"))
+	g.Expect(password).To(Equal("root-password"))
+
+	// the admin secret of the users must exist if it's set
+	tc.Spec.TiDB.Users = &v1alpha1.TiDBUsers{AdminSecretName: "admin"}
+	_, _, err = m.getTiDBReloadAccount(tc)
+	g.Expect(err).To(MatchError(ContainSubstring("admin")))
+}
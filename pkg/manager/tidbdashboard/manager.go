@@ -36,11 +36,14 @@ import (
 // Manager manages the specific kubernetes native resources for tidb dashboard.
 type Manager struct {
 	deps *controller.Dependencies
+	// getDashboardURL returns the url of the API of tidb dashboard
+	getDashboardURL func(td *v1alpha1.TidbDashboard) string
 }
 
 func NewManager(deps *controller.Dependencies) *Manager {
 	return &Manager{
-		deps: deps,
+		deps:            deps,
+		getDashboardURL: getDashboardURL,
 	}
 }
 
@@ -57,7 +60,7 @@ func (m *Manager) Sync(td *v1alpha1.TidbDashboard, tc *v1alpha1.TidbCluster) err
 		return err
 	}
 
-	return nil
+	return m.syncSSO(td)
 }
 
 func (m *Manager) syncService(td *v1alpha1.TidbDashboard) error {
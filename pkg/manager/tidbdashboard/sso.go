@@ -0,0 +1,304 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbdashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ssoClientIDKey is the key of the OIDC client id in the secret of the SSO
+	ssoClientIDKey = "clientID"
+	// ssoPasswordKey is the key of the password in the secret of the impersonated SQL user
+	ssoPasswordKey = "password"
+
+	// defaultPathPrefix is the path prefix used by tidb dashboard if it's not set
+	defaultPathPrefix = "/dashboard"
+
+	// impersonateStatusSuccess is the status of the impersonation which can be used to log in
+	impersonateStatusSuccess = "success"
+
+	eventReasonFailedSyncSSO = "FailedSyncSSO"
+
+	dashboardRequestTimeout = 10 * time.Second
+)
+
+// dashboardSSOConfig is the SSO config in the API of tidb dashboard
+type dashboardSSOConfig struct {
+	Enabled      bool   `json:"enabled"`
+	ClientID     string `json:"client_id"`
+	DiscoveryURL string `json:"discovery_url"`
+	IsReadOnly   bool   `json:"is_read_only"`
+}
+
+// dashboardImpersonation is the impersonated SQL user in the API of tidb dashboard
+type dashboardImpersonation struct {
+	SQLUser               string  `json:"sql_user"`
+	LastImpersonateStatus *string `json:"last_impersonate_status"`
+}
+
+// tokenTransport sets the token returned by the login to the requests
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+// dashboardClient calls the API of tidb dashboard used to manage the SSO
+type dashboardClient struct {
+	httpClient *http.Client
+	// url is the url of the API, which is `<dashboard>/<path prefix>/api`
+	url string
+}
+
+func newDashboardClient(url string) *dashboardClient {
+	return &dashboardClient{
+		httpClient: &http.Client{Timeout: dashboardRequestTimeout},
+		url:        strings.TrimSuffix(url, "/"),
+	}
+}
+
+// login logs in as the SQL user, the token is used by the following requests
+func (c *dashboardClient) login(user, password string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":     0,
+		"username": user,
+		"password": password,
+	})
+	if err != nil {
+		return err
+	}
+	data, err := httputil.PostBodyOK(c.httpClient, c.url+"/user/login", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	res := struct {
+		Token string `json:"token"`
+	}{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if res.Token == "" {
+		return fmt.Errorf("no token is returned by the login")
+	}
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.httpClient = &http.Client{
+		Timeout:   c.httpClient.Timeout,
+		Transport: &tokenTransport{token: res.Token, base: base},
+	}
+	return nil
+}
+
+func (c *dashboardClient) getSSOConfig() (*dashboardSSOConfig, error) {
+	data, err := httputil.GetBodyOK(c.httpClient, c.url+"/user/sso/config")
+	if err != nil {
+		return nil, err
+	}
+	cfg := &dashboardSSOConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *dashboardClient) setSSOConfig(cfg *dashboardSSOConfig) error {
+	body, err := json.Marshal(map[string]interface{}{"config": cfg})
+	if err != nil {
+		return err
+	}
+	_, err = httputil.DoBodyOK(c.httpClient, c.url+"/user/sso/config", http.MethodPut, bytes.NewReader(body))
+	return err
+}
+
+func (c *dashboardClient) listImpersonations() ([]dashboardImpersonation, error) {
+	data, err := httputil.GetBodyOK(c.httpClient, c.url+"/user/sso/impersonations/list")
+	if err != nil {
+		return nil, err
+	}
+	var impersonations []dashboardImpersonation
+	if err := json.Unmarshal(data, &impersonations); err != nil {
+		return nil, err
+	}
+	return impersonations, nil
+}
+
+// createImpersonation creates or overwrites the impersonation of the SQL user
+func (c *dashboardClient) createImpersonation(user, password string) error {
+	body, err := json.Marshal(map[string]string{
+		"sql_user": user,
+		"password": password,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, c.url+"/user/sso/impersonation", bytes.NewReader(body))
+	return err
+}
+
+// getDashboardURL returns the url of the API of tidb dashboard
+func getDashboardURL(td *v1alpha1.TidbDashboard) string {
+	pathPrefix := defaultPathPrefix
+	if td.Spec.PathPrefix != nil && *td.Spec.PathPrefix != "" {
+		pathPrefix = "/" + strings.Trim(*td.Spec.PathPrefix, "/")
+	}
+	return fmt.Sprintf("http://%s.%s:%d%s/api", ServiceName(td.Name), td.Namespace, port, pathPrefix)
+}
+
+// secretVersion identifies the content of the secret, it's changed if the secret is updated or recreated
+func secretVersion(secret *corev1.Secret) string {
+	return fmt.Sprintf("%s/%s", secret.UID, secret.ResourceVersion)
+}
+
+// syncSSO applies the SSO config to tidb dashboard after it's ready.
+// The failure is recorded in the status and the event, and returned to apply the SSO config again.
+func (m *Manager) syncSSO(td *v1alpha1.TidbDashboard) error {
+	if td.Spec.SSO == nil {
+		td.Status.SSO = nil
+		return nil
+	}
+	if td.Status.StatefulSet == nil || td.Status.StatefulSet.ReadyReplicas == 0 {
+		return nil
+	}
+	if td.Status.SSO == nil {
+		td.Status.SSO = &v1alpha1.TidbDashboardSSOStatus{}
+	}
+	status := td.Status.SSO
+
+	applied, err := m.applySSO(td, status)
+	if err != nil {
+		m.deps.Recorder.Event(td, corev1.EventTypeWarning, eventReasonFailedSyncSSO, err.Error())
+		status.Synced = false
+		status.Message = err.Error()
+		return fmt.Errorf("failed to sync SSO of tidb dashboard %s/%s, error: %v", td.Namespace, td.Name, err)
+	}
+	status.Synced = true
+	status.Message = ""
+	if applied {
+		now := metav1.Now()
+		status.LastSyncTime = &now
+	}
+	return nil
+}
+
+// applySSO creates the impersonations and updates the SSO config if they are changed, and returns
+// whether any change is applied.
+func (m *Manager) applySSO(td *v1alpha1.TidbDashboard, status *v1alpha1.TidbDashboardSSOStatus) (bool, error) {
+	sso := td.Spec.SSO
+	clientID, err := m.getSecretValue(td.Namespace, sso.SecretName, ssoClientIDKey)
+	if err != nil {
+		return false, err
+	}
+	passwords := make([]string, 0, len(sso.Impersonations))
+	versions := make([]string, 0, len(sso.Impersonations))
+	for _, i := range sso.Impersonations {
+		password, version, err := m.getSecretValueAndVersion(td.Namespace, i.SecretName, ssoPasswordKey)
+		if err != nil {
+			return false, err
+		}
+		passwords = append(passwords, password)
+		versions = append(versions, version)
+	}
+	if len(passwords) == 0 {
+		return false, fmt.Errorf("no SQL user is impersonated")
+	}
+
+	cli := newDashboardClient(m.getDashboardURL(td))
+	if err := cli.login(sso.Impersonations[0].User, passwords[0]); err != nil {
+		return false, fmt.Errorf("login as SQL user %s failed: %v", sso.Impersonations[0].User, err)
+	}
+
+	impersonations, err := cli.listImpersonations()
+	if err != nil {
+		return false, fmt.Errorf("list impersonations failed: %v", err)
+	}
+	succeeded := map[string]bool{}
+	for _, i := range impersonations {
+		if i.LastImpersonateStatus != nil && *i.LastImpersonateStatus == impersonateStatusSuccess {
+			succeeded[i.SQLUser] = true
+		}
+	}
+
+	applied := false
+	// the impersonation is created again if the secret of the password is changed, so that the
+	// users logged in by SSO are not affected.
+	secretVersions := map[string]string{}
+	for idx, i := range sso.Impersonations {
+		if !succeeded[i.User] || status.ImpersonationSecretVersions[i.User] != versions[idx] {
+			if err := cli.createImpersonation(i.User, passwords[idx]); err != nil {
+				return false, fmt.Errorf("create impersonation of SQL user %s failed: %v", i.User, err)
+			}
+			klog.Infof("tidb dashboard %s/%s: impersonation of SQL user %s is created", td.Namespace, td.Name, i.User)
+			applied = true
+		}
+		secretVersions[i.User] = versions[idx]
+	}
+	status.ImpersonationSecretVersions = secretVersions
+
+	expected := &dashboardSSOConfig{
+		Enabled:      true,
+		ClientID:     clientID,
+		DiscoveryURL: sso.Issuer,
+		IsReadOnly:   sso.ReadOnly,
+	}
+	current, err := cli.getSSOConfig()
+	if err != nil {
+		return false, fmt.Errorf("get SSO config failed: %v", err)
+	}
+	if *current != *expected {
+		if err := cli.setSSOConfig(expected); err != nil {
+			return false, fmt.Errorf("set SSO config failed: %v", err)
+		}
+		klog.Infof("tidb dashboard %s/%s: SSO config is updated", td.Namespace, td.Name)
+		applied = true
+	}
+	return applied, nil
+}
+
+func (m *Manager) getSecretValue(ns, name, key string) (string, error) {
+	value, _, err := m.getSecretValueAndVersion(ns, name, key)
+	return value, err
+}
+
+// getSecretValueAndVersion returns the value of the key in the secret and the version of the secret
+func (m *Manager) getSecretValueAndVersion(ns, name, key string) (string, string, error) {
+	secret, err := m.deps.SecretLister.Secrets(ns).Get(name)
+	if err != nil {
+		return "", "", fmt.Errorf("get secret %s/%s failed: %v", ns, name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return "", "", fmt.Errorf("key %s is not found in secret %s/%s", key, ns, name)
+	}
+	return string(value), secretVersion(secret), nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbdashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	. "github.com/onsi/gomega"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+// fakeDashboard implements the part of the API of tidb dashboard used by the SSO
+type fakeDashboard struct {
+	sync.Mutex
+	passwords      map[string]string
+	config         dashboardSSOConfig
+	impersonations map[string]string
+	configSets     int
+	creates        int
+}
+
+func (f *fakeDashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	reply := func(v interface{}) { json.NewEncoder(w).Encode(v) }
+
+	if r.URL.Path == "/dashboard/api/user/login" {
+		user, _ := body["username"].(string)
+		if password, ok := f.passwords[user]; !ok || password != body["password"] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reply(map[string]string{"token": "token-" + user})
+		return
+	}
+	if r.Header.Get("Authorization") == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/dashboard/api/user/sso/config":
+		if r.Method == http.MethodPut {
+			f.configSets++
+			data, _ := json.Marshal(body["config"])
+			json.Unmarshal(data, &f.config)
+		}
+		reply(f.config)
+	case "/dashboard/api/user/sso/impersonations/list":
+		var list []dashboardImpersonation
+		for user, password := range f.impersonations {
+			status := impersonateStatusSuccess
+			if f.passwords[user] != password {
+				status = "auth_fail"
+			}
+			list = append(list, dashboardImpersonation{SQLUser: user, LastImpersonateStatus: &status})
+		}
+		reply(list)
+	case "/dashboard/api/user/sso/impersonation":
+		f.creates++
+		f.impersonations[body["sql_user"].(string)] = body["password"].(string)
+		reply(map[string]interface{}{})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSyncSSO(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fake := &fakeDashboard{
+		passwords:      map[string]string{"dashboard": "pass", "viewer": "view"},
+		impersonations: map[string]string{},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	deps := controller.NewFakeDependencies()
+	manager := NewManager(deps)
+	manager.getDashboardURL = func(td *v1alpha1.TidbDashboard) string {
+		return server.URL + "/dashboard/api"
+	}
+
+	oidcSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "oidc"},
+		Data:       map[string][]byte{ssoClientIDKey: []byte("client-id")},
+	}
+	userSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dashboard-user", UID: "user-uid", ResourceVersion: "1"},
+		Data:       map[string][]byte{ssoPasswordKey: []byte("pass")},
+	}
+	viewerSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "viewer-user", UID: "viewer-uid", ResourceVersion: "1"},
+		Data:       map[string][]byte{ssoPasswordKey: []byte("view")},
+	}
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	g.Expect(secretIndexer.Add(oidcSecret)).To(Succeed())
+	g.Expect(secretIndexer.Add(userSecret)).To(Succeed())
+	g.Expect(secretIndexer.Add(viewerSecret)).To(Succeed())
+
+	td := &v1alpha1.TidbDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "basic"},
+		Spec: v1alpha1.TidbDashboardSpec{
+			SSO: &v1alpha1.TidbDashboardSSO{
+				Issuer:     "https://example.okta.com",
+				SecretName: oidcSecret.Name,
+				Impersonations: []v1alpha1.TidbDashboardSQLUser{
+					{User: "dashboard", SecretName: userSecret.Name},
+					{User: "viewer", SecretName: viewerSecret.Name},
+				},
+			},
+		},
+	}
+
+	// the SSO is not applied before the pod is ready
+	td.Status.StatefulSet = &apps.StatefulSetStatus{}
+	g.Expect(manager.syncSSO(td)).To(Succeed())
+	g.Expect(td.Status.SSO).To(BeNil())
+	g.Expect(fake.creates).To(Equal(0))
+
+	td.Status.StatefulSet.ReadyReplicas = 1
+	g.Expect(manager.syncSSO(td)).To(Succeed())
+	g.Expect(td.Status.SSO.Synced).To(BeTrue())
+	g.Expect(td.Status.SSO.LastSyncTime).NotTo(BeNil())
+	// the password is not published in the status in any form
+	g.Expect(td.Status.SSO.ImpersonationSecretVersions).To(Equal(map[string]string{
+		"dashboard": "user-uid/1",
+		"viewer":    "viewer-uid/1",
+	}))
+	g.Expect(fake.impersonations).To(Equal(map[string]string{"dashboard": "pass", "viewer": "view"}))
+	g.Expect(fake.config).To(Equal(dashboardSSOConfig{Enabled: true, ClientID: "client-id", DiscoveryURL: "https://example.okta.com"}))
+	g.Expect(fake.creates).To(Equal(2))
+	g.Expect(fake.configSets).To(Equal(1))
+
+	// nothing is applied if there is no change
+	lastSyncTime := td.Status.SSO.LastSyncTime
+	g.Expect(manager.syncSSO(td)).To(Succeed())
+	g.Expect(td.Status.SSO.LastSyncTime).To(Equal(lastSyncTime))
+	g.Expect(fake.creates).To(Equal(2))
+	g.Expect(fake.configSets).To(Equal(1))
+
+	// the changes made in the UI are reverted
+	fake.config.IsReadOnly = true
+	g.Expect(manager.syncSSO(td)).To(Succeed())
+	g.Expect(fake.config.IsReadOnly).To(BeFalse())
+	g.Expect(fake.configSets).To(Equal(2))
+
+	// the impersonation is created again after the password is changed
+	fake.passwords["dashboard"] = "new-pass"
+	newUserSecret := userSecret.DeepCopy()
+	newUserSecret.Data[ssoPasswordKey] = []byte("new-pass")
+	newUserSecret.ResourceVersion = "2"
+	g.Expect(secretIndexer.Update(newUserSecret)).To(Succeed())
+	td.Spec.SSO.ReadOnly = true
+	g.Expect(manager.syncSSO(td)).To(Succeed())
+	g.Expect(td.Status.SSO.Synced).To(BeTrue())
+	g.Expect(td.Status.SSO.ImpersonationSecretVersions).To(HaveKeyWithValue("dashboard", "user-uid/2"))
+	g.Expect(fake.impersonations).To(HaveKeyWithValue("dashboard", "new-pass"))
+	g.Expect(fake.config.IsReadOnly).To(BeTrue())
+	// only the impersonation of the changed user is created again
+	g.Expect(fake.creates).To(Equal(3))
+
+	// the failure is returned and recorded in the status and the event
+	g.Expect(secretIndexer.Delete(oidcSecret)).To(Succeed())
+	g.Expect(manager.syncSSO(td)).To(HaveOccurred())
+	g.Expect(td.Status.SSO.Synced).To(BeFalse())
+	g.Expect(td.Status.SSO.Message).To(ContainSubstring("oidc"))
+	events := deps.Recorder.(*record.FakeRecorder).Events
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(<-events).To(ContainSubstring(eventReasonFailedSyncSSO))
+
+	// the status is cleared if the SSO is not configured
+	td.Spec.SSO = nil
+	g.Expect(manager.syncSSO(td)).To(Succeed())
+	g.Expect(td.Status.SSO).To(BeNil())
+}
+
+func TestGetDashboardURL(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	td := &v1alpha1.TidbDashboard{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "basic"}}
+	g.Expect(getDashboardURL(td)).To(Equal("http://basic-tidb-dashboard-exposed.ns:12333/dashboard/api"))
+
+	td.Spec.PathPrefix = pointer.StringPtr("/tidb/dashboard/")
+	g.Expect(getDashboardURL(td)).To(Equal("http://basic-tidb-dashboard-exposed.ns:12333/tidb/dashboard/api"))
+}
@@ -40,3 +40,8 @@ func TCClientTLSSecretName(tngm string) string {
 func assetKey(tcName, tcNS, field string) string {
 	return fmt.Sprintf("%s_%s_%s", tcName, tcNS, field)
 }
+
+// NGMonitoringExportName return name of cronjob which exports the data of ng monitoring
+func NGMonitoringExportName(tngm string) string {
+	return fmt.Sprintf("%s-ng-monitoring-export", tngm)
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbngmonitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ngmExportComponent is the component label of the export jobs, it must be different from
+	// ng monitoring to keep the pods out of the selector of the statefulset.
+	ngmExportComponent = "ng-monitoring-export"
+	ngmExportContainer = "export"
+)
+
+// syncExport creates or updates the cronjob exporting the data of ng monitoring,
+// the cronjob is deleted if the export is disabled.
+func (m *ngMonitoringManager) syncExport(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) error {
+	ns := tngm.GetNamespace()
+	name := tngm.GetName()
+
+	if tngm.Spec.Paused {
+		return nil
+	}
+
+	if !tngm.IsExportEnabled() {
+		cj := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: NGMonitoringExportName(name)}}
+		exist, err := m.deps.TypedControl.Exist(client.ObjectKeyFromObject(cj), cj)
+		if err != nil || !exist {
+			return err
+		}
+		return m.deps.TypedControl.Delete(tngm, cj)
+	}
+
+	storageEnv, reason, err := backuputil.GenerateStorageCertEnv(ns, false, tngm.Spec.NGMonitoring.Export.StorageProvider, m.deps.SecretLister)
+	if err != nil {
+		return fmt.Errorf("tidb ng monitoring %s/%s, %s: %v", ns, name, reason, err)
+	}
+
+	cj, err := GenerateNGMonitoringExportCronJob(tngm, tc, m.deps.CLIConfig.TiDBBackupManagerImage, storageEnv)
+	if err != nil {
+		return err
+	}
+	_, err = m.deps.TypedControl.CreateOrUpdateCronJob(tngm, cj)
+	return err
+}
+
+// GenerateNGMonitoringExportCronJob generates the cronjob which runs the `ngm-export` command of backup manager
+func GenerateNGMonitoringExportCronJob(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster, image string, storageEnv []corev1.EnvVar) (*batchv1.CronJob, error) {
+	ns := tngm.GetNamespace()
+	name := tngm.GetName()
+	export := tngm.Spec.NGMonitoring.Export
+
+	storage, err := json.Marshal(export.StorageProvider)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{
+		"ngm-export",
+		fmt.Sprintf("--namespace=%s", ns),
+		fmt.Sprintf("--name=%s", name),
+		fmt.Sprintf("--url=%s", getNGMonitoringURL(tngm, tc)),
+		fmt.Sprintf("--window=%s", export.GetWindow()),
+		fmt.Sprintf("--storage=%s", storage),
+	}
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if tc.IsTLSClusterEnabled() {
+		args = append(args,
+			fmt.Sprintf("--ca=%s", path.Join(ngmTCClientTLSMountDir, assetKey(tc.Name, tc.Namespace, corev1.ServiceAccountRootCAKey))),
+			fmt.Sprintf("--cert=%s", path.Join(ngmTCClientTLSMountDir, assetKey(tc.Name, tc.Namespace, corev1.TLSCertKey))),
+			fmt.Sprintf("--key=%s", path.Join(ngmTCClientTLSMountDir, assetKey(tc.Name, tc.Namespace, corev1.TLSPrivateKeyKey))),
+		)
+		volumes = append(volumes, corev1.Volume{
+			Name: "tc-client-tls", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: TCClientTLSSecretName(name),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: "tc-client-tls", ReadOnly: true, MountPath: ngmTCClientTLSMountDir,
+		})
+	}
+	if export.Local != nil {
+		volumes = append(volumes, export.Local.Volume)
+		volumeMounts = append(volumeMounts, export.Local.VolumeMount)
+	}
+
+	spec := tngm.BaseNGMonitoringSpec()
+	podLabels := label.NewTiDBNGMonitoring().Instance(tngm.GetInstanceName()).Component(ngmExportComponent)
+	meta := metav1.ObjectMeta{
+		Name:            NGMonitoringExportName(name),
+		Namespace:       ns,
+		Labels:          podLabels.Copy(),
+		OwnerReferences: []metav1.OwnerReference{controller.GetTiDBNGMonitoringOwnerRef(tngm)},
+	}
+
+	cj := &batchv1.CronJob{
+		ObjectMeta: meta,
+		Spec: batchv1.CronJobSpec{
+			Schedule:          export.GetSchedule(),
+			Suspend:           pointer.BoolPtr(export.Suspend),
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: pointer.Int32Ptr(2),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: podLabels.Copy(),
+						},
+						Spec: corev1.PodSpec{
+							ServiceAccountName: export.ServiceAccount,
+							Containers: []corev1.Container{
+								{
+									Name:            ngmExportContainer,
+									Image:           image,
+									ImagePullPolicy: corev1.PullIfNotPresent,
+									Args:            args,
+									Env:             storageEnv,
+									VolumeMounts:    volumeMounts,
+								},
+							},
+							Volumes:          volumes,
+							RestartPolicy:    corev1.RestartPolicyNever,
+							ImagePullSecrets: spec.ImagePullSecrets(),
+							Tolerations:      spec.Tolerations(),
+						},
+					},
+				},
+			},
+		},
+	}
+	return cj, nil
+}
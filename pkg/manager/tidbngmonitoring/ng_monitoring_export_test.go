@@ -0,0 +1,117 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbngmonitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerateNGMonitoringExportCronJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newInputs := func() (*v1alpha1.TidbNGMonitoring, *v1alpha1.TidbCluster) {
+		tngm := &v1alpha1.TidbNGMonitoring{}
+		tngm.Name = "ngm"
+		tngm.Namespace = "default"
+		tngm.Spec.NGMonitoring.Export = &v1alpha1.NGMonitoringExport{
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{Bucket: "bucket", Prefix: "ngm"},
+			},
+		}
+		tc := &v1alpha1.TidbCluster{}
+		tc.Name = "tc"
+		tc.Namespace = "tc-ns"
+		return tngm, tc
+	}
+
+	t.Run("default", func(t *testing.T) {
+		tngm, tc := newInputs()
+		env := []corev1.EnvVar{{Name: "AWS_ACCESS_KEY_ID", Value: "key"}}
+		cj, err := GenerateNGMonitoringExportCronJob(tngm, tc, "pingcap/tidb-backup-manager:latest", env)
+		g.Expect(err).Should(Succeed())
+
+		g.Expect(cj.Name).Should(Equal("ngm-ng-monitoring-export"))
+		g.Expect(cj.Namespace).Should(Equal("default"))
+		g.Expect(cj.OwnerReferences).Should(HaveLen(1))
+		g.Expect(cj.Spec.Schedule).Should(Equal("0 * * * *"))
+		g.Expect(*cj.Spec.Suspend).Should(BeFalse())
+		g.Expect(cj.Spec.ConcurrencyPolicy).Should(Equal(batchv1.ForbidConcurrent))
+
+		// the pods of export must not be selected by the statefulset of ng monitoring
+		podLabels := cj.Spec.JobTemplate.Spec.Template.Labels
+		g.Expect(podLabels[label.ComponentLabelKey]).Should(Equal(ngmExportComponent))
+
+		podSpec := cj.Spec.JobTemplate.Spec.Template.Spec
+		g.Expect(podSpec.RestartPolicy).Should(Equal(corev1.RestartPolicyNever))
+		g.Expect(podSpec.Volumes).Should(BeEmpty())
+		g.Expect(podSpec.Containers).Should(HaveLen(1))
+		container := podSpec.Containers[0]
+		g.Expect(container.Image).Should(Equal("pingcap/tidb-backup-manager:latest"))
+		g.Expect(container.Env).Should(Equal(env))
+		g.Expect(container.Args).Should(Equal([]string{
+			"ngm-export",
+			"--namespace=default",
+			"--name=ngm",
+			"--url=http://ngm-ng-monitoring.default.svc:12020",
+			"--window=1h0m0s",
+			`--storage={"s3":{"provider":"","bucket":"bucket","prefix":"ngm"}}`,
+		}))
+	})
+
+	t.Run("tls and local storage", func(t *testing.T) {
+		tngm, tc := newInputs()
+		tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+		tngm.Spec.ClusterDomain = "cluster.local"
+		tngm.Spec.NGMonitoring.Export = &v1alpha1.NGMonitoringExport{
+			StorageProvider: v1alpha1.StorageProvider{
+				Local: &v1alpha1.LocalStorageProvider{
+					Volume:      corev1.Volume{Name: "nfs"},
+					VolumeMount: corev1.VolumeMount{Name: "nfs", MountPath: "/nfs"},
+				},
+			},
+			Schedule: "*/30 * * * *",
+			Window:   &metav1.Duration{Duration: 30 * time.Minute},
+			Suspend:  true,
+		}
+		cj, err := GenerateNGMonitoringExportCronJob(tngm, tc, "image", nil)
+		g.Expect(err).Should(Succeed())
+
+		g.Expect(cj.Spec.Schedule).Should(Equal("*/30 * * * *"))
+		g.Expect(*cj.Spec.Suspend).Should(BeTrue())
+		podSpec := cj.Spec.JobTemplate.Spec.Template.Spec
+		g.Expect(podSpec.Volumes).Should(HaveLen(2))
+		g.Expect(podSpec.Volumes[0].Secret.SecretName).Should(Equal(TCClientTLSSecretName(tngm.Name)))
+		container := podSpec.Containers[0]
+		g.Expect(container.VolumeMounts).Should(ConsistOf(
+			corev1.VolumeMount{Name: "tc-client-tls", ReadOnly: true, MountPath: ngmTCClientTLSMountDir},
+			corev1.VolumeMount{Name: "nfs", MountPath: "/nfs"},
+		))
+		g.Expect(container.Args).Should(ContainElements(
+			"--url=https://ngm-ng-monitoring.default.svc.cluster.local:12020",
+			"--window=30m0s",
+			"--ca=/var/lib/tc-client-tls/tc_tc-ns_ca.crt",
+			"--cert=/var/lib/tc-client-tls/tc_tc-ns_tls.crt",
+			"--key=/var/lib/tc-client-tls/tc_tc-ns_tls.key",
+		))
+	})
+}
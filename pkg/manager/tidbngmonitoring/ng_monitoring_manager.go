@@ -47,12 +47,17 @@ const (
 
 type ngMonitoringManager struct {
 	deps *controller.Dependencies
+
+	// getVolumeUsage returns the used and total bytes of the data volume of the pod
+	getVolumeUsage func(tngm *v1alpha1.TidbNGMonitoring, pod *corev1.Pod) (uint64, uint64, error)
 }
 
 func NewNGMonitorManager(deps *controller.Dependencies) *ngMonitoringManager {
-	return &ngMonitoringManager{
+	m := &ngMonitoringManager{
 		deps: deps,
 	}
+	m.getVolumeUsage = m.getVolumeUsageFromSidecar
+	return m
 }
 
 func (m *ngMonitoringManager) Sync(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) error {
@@ -68,7 +73,17 @@ func (m *ngMonitoringManager) Sync(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1
 		return err
 	}
 
-	return nil
+	err = m.syncExport(tngm, tc)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncProfilingRetention(tngm, tc)
+	if err != nil {
+		return err
+	}
+
+	return m.autoscaleStorage(tngm)
 }
 
 func (m *ngMonitoringManager) syncService(tngm *v1alpha1.TidbNGMonitoring) error {
@@ -375,6 +390,19 @@ func GenerateNGMonitoringStatefulSet(tngm *v1alpha1.TidbNGMonitoring, tc *v1alph
 	// additional volumes and mounts
 	builder.PodTemplateSpecBuilder().ContainerBuilder(nmContainerName).AddVolumeMounts(spec.AdditionalVolumeMounts()...)
 	builder.PodTemplateSpecBuilder().AddVolumes(spec.AdditionalVolumes()...)
+	// the sidecar reporting the disk usage for storage autoscaling
+	if tngm.Spec.NGMonitoring.StorageAutoscaling != nil {
+		podSpec := &builder.PodTemplateSpecBuilder().Get().Spec
+		podSpec.Containers = append(podSpec.Containers, corev1.Container{
+			Name:            ngmDiskUsageContainerName,
+			Image:           tc.HelperImage(),
+			ImagePullPolicy: tc.HelperImagePullPolicy(),
+			Command:         []string{"/bin/sh", "-c", ngmDiskUsageScript},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: dataVolumeName, ReadOnly: true, MountPath: ngmPodDataVolumeMountDir},
+			},
+		})
+	}
 	// additional containers
 	builder.PodTemplateSpecBuilder().Get().Spec.Containers, err = member.MergePatchContainers(builder.PodTemplateSpecBuilder().Get().Spec.Containers, spec.AdditionalContainers())
 	if err != nil {
@@ -418,6 +446,14 @@ func GenerateNGMonitoringConfigMap(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1
 		ngmConfig.Set("security.key-path", path.Join(ngmTCClientTLSMountDir, assetKey(tcName, tcNS, corev1.TLSPrivateKeyKey)))
 	}
 
+	if period := getTopSQLRetentionPeriod(tngm.Spec.NGMonitoring.Retention); period != "" {
+		if ngmConfig == nil {
+			ngmConfig = config.New(map[string]interface{}{})
+		}
+
+		ngmConfig.Set("tsdb.retention-period", period)
+	}
+
 	confText, err := ngmConfig.MarshalTOML()
 	if err != nil {
 		return nil, err
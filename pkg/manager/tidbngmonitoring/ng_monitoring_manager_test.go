@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -484,6 +485,26 @@ func TestGenerateNGMonitoringStatefulSet(t *testing.T) {
 				g.Expect(sts.Spec.Template.Spec.DNSPolicy).Should(Equal(corev1.DNSClusterFirstWithHostNet))
 			},
 		},
+		{
+			name: "should add the disk usage sidecar when storage autoscaling is enabled",
+			setInputs: func(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) {
+				tngm.Spec.NGMonitoring.StorageAutoscaling = &v1alpha1.StorageAutoscaling{
+					Step:    resource.MustParse("5Gi"),
+					MaxSize: resource.MustParse("20Gi"),
+				}
+			},
+			expectFn: func(sts *apps.StatefulSet, err error) {
+				g.Expect(err).Should(Succeed())
+				containers := sts.Spec.Template.Spec.Containers
+				g.Expect(containers).Should(HaveLen(2))
+				g.Expect(containers[1].Name).Should(Equal(ngmDiskUsageContainerName))
+				// the disk usage is read by exec instead of serving it
+				g.Expect(containers[1].Ports).Should(BeEmpty())
+				g.Expect(containers[1].VolumeMounts).Should(ConsistOf(corev1.VolumeMount{
+					Name: v1alpha1.NGMonitoringMemberType.String(), ReadOnly: true, MountPath: ngmPodDataVolumeMountDir,
+				}))
+			},
+		},
 		{
 			name: "should set resouce",
 			setInputs: func(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) {
@@ -793,6 +814,22 @@ func TestGenerateNGMonitoringConfigMap(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "should set retention of top sql",
+			setInputs: func(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) {
+				tngm.Spec.NGMonitoring.Retention = &v1alpha1.NGMonitoringRetention{
+					TopSQL: &metav1.Duration{Duration: 30*24*time.Hour + 30*time.Minute},
+				}
+			},
+			expectFn: func(tngm *v1alpha1.TidbNGMonitoring, cm *corev1.ConfigMap, err error) {
+				g.Expect(err).Should(Succeed())
+
+				cfg := config.New(nil)
+				err = cfg.UnmarshalTOML([]byte(cm.Data[ngmConfigMapConfigKey]))
+				g.Expect(err).Should(Succeed())
+				g.Expect(cfg.Get("tsdb.retention-period").MustString()).Should(Equal("720h"))
+			},
+		},
 		{
 			name: "should set config about cert when tls is enable",
 			setInputs: func(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) {
@@ -0,0 +1,332 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbngmonitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/manager/volumes"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/third_party/k8s"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog/v2"
+)
+
+const (
+	ngmAPITimeout = 5 * time.Second
+
+	// ngmDiskUsageContainerName is the sidecar reporting the disk usage of the data volume when
+	// storage autoscaling is enabled. It serves nothing, df of busybox is run in it by exec.
+	ngmDiskUsageContainerName = "disk-usage"
+)
+
+// ngmDiskUsageScript keeps the disk usage sidecar running until the pod is deleted
+var ngmDiskUsageScript = `trap 'exit 0' TERM
+while true; do
+  sleep 3600 &
+  wait $!
+done
+`
+
+// ngmDiskUsageCommand reports the disk usage in the POSIX format of df, which is
+// "Filesystem 1024-blocks Used Available Capacity Mounted-on".
+var ngmDiskUsageCommand = []string{"df", "-P", "-k", ngmPodDataVolumeMountDir}
+
+// ngmConfig is the dynamic config of ng monitoring
+type ngmConfig struct {
+	ContinuousProfiling ngmContinuousProfilingConfig `json:"continuous_profiling"`
+}
+
+type ngmContinuousProfilingConfig struct {
+	DataRetentionSeconds int64 `json:"data_retention_seconds"`
+}
+
+// getTopSQLRetentionPeriod returns the retention period of the tsdb in ng monitoring, it's in hours.
+func getTopSQLRetentionPeriod(retention *v1alpha1.NGMonitoringRetention) string {
+	if retention == nil || retention.TopSQL == nil {
+		return ""
+	}
+	return fmt.Sprintf("%dh", int64(retention.TopSQL.Duration/time.Hour))
+}
+
+// getNGMonitoringURL returns the url of the http api of ng monitoring
+func getNGMonitoringURL(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) string {
+	scheme := "http"
+	if tc.IsTLSClusterEnabled() {
+		scheme = "https"
+	}
+	host := fmt.Sprintf("%s.%s.svc", NGMonitoringHeadlessServiceName(tngm.Name), tngm.Namespace)
+	if tngm.Spec.ClusterDomain != "" {
+		host = host + "." + tngm.Spec.ClusterDomain
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, host, ngmServicePort)
+}
+
+// syncProfilingRetention sets the retention of continuous profiling data through the dynamic config of ng monitoring.
+func (m *ngMonitoringManager) syncProfilingRetention(tngm *v1alpha1.TidbNGMonitoring, tc *v1alpha1.TidbCluster) error {
+	retention := tngm.Spec.NGMonitoring.Retention
+	if tngm.Spec.Paused || retention == nil || retention.Profiling == nil {
+		return nil
+	}
+	// wait until ng monitoring is ready
+	sts := tngm.Status.NGMonitoring.StatefulSet
+	if sts == nil || sts.ReadyReplicas == 0 {
+		return nil
+	}
+
+	httpClient := &http.Client{Timeout: ngmAPITimeout}
+	if tc.IsTLSClusterEnabled() {
+		tlsConfig, err := pdapi.GetTLSConfig(m.deps.SecretLister, pdapi.Namespace(tc.Namespace), util.ClusterClientTLSSecretName(tc.Name), pdapi.TLSServer{})
+		if err != nil {
+			return err
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	seconds := int64(retention.Profiling.Duration / time.Second)
+	if err := setProfilingRetention(httpClient, getNGMonitoringURL(tngm, tc), seconds); err != nil {
+		return fmt.Errorf("set profiling retention of tidb ng monitoring %s/%s failed: %w", tngm.Namespace, tngm.Name, err)
+	}
+	return nil
+}
+
+// setProfilingRetention updates the retention of continuous profiling data if it's changed
+func setProfilingRetention(httpClient *http.Client, baseURL string, seconds int64) error {
+	data, err := httputil.GetBodyOK(httpClient, baseURL+"/config")
+	if err != nil {
+		return err
+	}
+	current := &ngmConfig{}
+	if err := json.Unmarshal(data, current); err != nil {
+		return err
+	}
+	if current.ContinuousProfiling.DataRetentionSeconds == seconds {
+		return nil
+	}
+
+	data, err = json.Marshal(map[string]interface{}{
+		"continuous_profiling": map[string]interface{}{
+			"data_retention_seconds": seconds,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := httputil.PostBodyOK(httpClient, baseURL+"/config", bytes.NewReader(data)); err != nil {
+		return err
+	}
+	klog.Infof("set profiling retention of ng monitoring %s from %ds to %ds", baseURL, current.ContinuousProfiling.DataRetentionSeconds, seconds)
+	return nil
+}
+
+// autoscaleStorage expands the data volume of ng monitoring whose disk usage crosses the threshold.
+// Unlike the stores of TidbCluster, the disk usage is reported by the sidecar of ng monitoring.
+func (m *ngMonitoringManager) autoscaleStorage(tngm *v1alpha1.TidbNGMonitoring) error {
+	as := tngm.Spec.NGMonitoring.StorageAutoscaling
+	if tngm.Spec.Paused || as == nil {
+		return nil
+	}
+	ns := tngm.Namespace
+	threshold := volumes.GetStorageAutoscalingThreshold(as)
+
+	selector, err := label.NewTiDBNGMonitoring().Instance(tngm.GetInstanceName()).NGMonitoring().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("autoscaleStorage: failed to list pod for tidb ng monitoring %s/%s, selector %s, error: %s", ns, tngm.Name, selector, err)
+	}
+
+	volName := v1alpha1.NGMonitoringMemberType.String()
+	now := time.Now()
+	errs := []error{}
+	for _, pod := range pods {
+		if !k8s.IsPodReady(pod) {
+			continue
+		}
+		pvcName := fmt.Sprintf("%s-%s", volName, pod.Name)
+		pvc, err := m.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		used, capacity, err := m.getVolumeUsage(tngm, pod)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get usage of volume %s of pod %s/%s failed: %w", volName, ns, pod.Name, err))
+			continue
+		}
+		if capacity == 0 {
+			continue
+		}
+		percent := int(used * 100 / capacity)
+
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if percent >= threshold && size.Cmp(as.MaxSize) >= 0 {
+			// only warn when the volume reaches the max size instead of in every reconciliation
+			if pvc.Annotations[volumes.AnnoKeyPVCAutoscaleMaxSizeReached] == as.MaxSize.String() {
+				continue
+			}
+			klog.Warningf("volume %s/%s of tidb ng monitoring %s can't be expanded any more, disk usage: %d%%, max size: %s",
+				ns, pvc.Name, tngm.Name, percent, as.MaxSize.String())
+			m.deps.Recorder.Eventf(tngm, corev1.EventTypeWarning, volumes.EventReasonStorageAutoscalingMaxSizeReached,
+				"volume %s of pod %s reaches the max size %s, disk usage: %d%%", volName, pod.Name, as.MaxSize.String(), percent)
+			if err := volumes.PatchPVCAnnotations(context.TODO(), m.deps.KubeClientset, pvc, map[string]interface{}{
+				volumes.AnnoKeyPVCAutoscaleMaxSizeReached: as.MaxSize.String(),
+			}); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if _, reached := pvc.Annotations[volumes.AnnoKeyPVCAutoscaleMaxSizeReached]; reached {
+			// the volume can be expanded again or its disk usage drops below the threshold
+			if err := volumes.PatchPVCAnnotations(context.TODO(), m.deps.KubeClientset, pvc, map[string]interface{}{
+				volumes.AnnoKeyPVCAutoscaleMaxSizeReached: nil,
+			}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		if percent < threshold {
+			continue
+		}
+		if !volumes.IsStorageAutoscaleCooledDown(pvc, now) {
+			klog.V(4).Infof("volume %s/%s of tidb ng monitoring %s was expanded recently, skip it", ns, pvc.Name, tngm.Name)
+			continue
+		}
+		// wait until the last expansion is finished
+		if current := pvc.Status.Capacity[corev1.ResourceStorage]; current.Cmp(size) < 0 {
+			klog.V(4).Infof("volume %s/%s of tidb ng monitoring %s is still expanding to %s", ns, pvc.Name, tngm.Name, size.String())
+			continue
+		}
+
+		newSize := size.DeepCopy()
+		newSize.Add(as.Step)
+		if newSize.Cmp(as.MaxSize) > 0 {
+			newSize = as.MaxSize.DeepCopy()
+		}
+		if err := m.expandPVC(pvc, newSize, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		klog.Infof("expand volume %s/%s of tidb ng monitoring %s from %s to %s, disk usage: %d%%",
+			ns, pvc.Name, tngm.Name, size.String(), newSize.String(), percent)
+		m.deps.Recorder.Eventf(tngm, corev1.EventTypeNormal, volumes.EventReasonStorageAutoscaled,
+			"expand volume %s of pod %s from %s to %s, disk usage: %d%%", volName, pod.Name, size.String(), newSize.String(), percent)
+	}
+
+	return errutil.NewAggregate(errs)
+}
+
+// expandPVC patches the storage request of the pvc, the pvc is not updated because
+// the fields unknown to the operator may be cleared.
+func (m *ngMonitoringManager) expandPVC(pvc *corev1.PersistentVolumeClaim, size resource.Quantity, now time.Time) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				volumes.AnnoKeyPVCLastAutoscaleTimestamp: now.Format(time.RFC3339),
+			},
+		},
+		"spec": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"requests": map[string]string{
+					string(corev1.ResourceStorage): size.String(),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(context.TODO(), pvc.Name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("expand pvc %s/%s failed: %w", pvc.Namespace, pvc.Name, err)
+	}
+	return nil
+}
+
+// getVolumeUsageFromSidecar returns the used and total bytes of the data volume of the pod, which
+// are reported by df run in the disk usage sidecar by exec. The usage is not served over the network,
+// so it can't be read by anyone without the permission to exec into the pod.
+func (m *ngMonitoringManager) getVolumeUsageFromSidecar(tngm *v1alpha1.TidbNGMonitoring, pod *corev1.Pod) (uint64, uint64, error) {
+	if m.deps.RESTConfig == nil {
+		return 0, 0, fmt.Errorf("exec into pod %s/%s is not supported without the config of the client", pod.Namespace, pod.Name)
+	}
+	req := m.deps.KubeClientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: ngmDiskUsageContainerName,
+			Command:   ngmDiskUsageCommand,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(m.deps.RESTConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return 0, 0, fmt.Errorf("exec into pod %s/%s failed: %w", pod.Namespace, pod.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ngmAPITimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return 0, 0, fmt.Errorf("run %v in pod %s/%s failed: %w, stderr: %s", ngmDiskUsageCommand, pod.Namespace, pod.Name, err, stderr.String())
+	}
+	// the first line is the header
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	return parseVolumeUsage([]byte(lines[len(lines)-1]))
+}
+
+// parseVolumeUsage parses the disk usage in the POSIX format of df with 1024-byte blocks.
+func parseVolumeUsage(data []byte) (uint64, uint64, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) < 4 {
+		return 0, 0, fmt.Errorf("invalid disk usage %q", string(data))
+	}
+	total, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid total blocks in disk usage %q: %w", string(data), err)
+	}
+	used, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid used blocks in disk usage %q: %w", string(data), err)
+	}
+	return used * 1024, total * 1024, nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbngmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager/volumes"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSetProfilingRetention(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	retention := int64(259200)
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/config"))
+		if r.Method == http.MethodPost {
+			posts++
+			cfg := &ngmConfig{}
+			g.Expect(json.NewDecoder(r.Body).Decode(cfg)).To(Succeed())
+			retention = cfg.ContinuousProfiling.DataRetentionSeconds
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"continuous_profiling": map[string]interface{}{
+				"enable":                 true,
+				"data_retention_seconds": retention,
+			},
+		})
+	}))
+	defer server.Close()
+
+	g.Expect(setProfilingRetention(server.Client(), server.URL, 604800)).To(Succeed())
+	g.Expect(retention).To(Equal(int64(604800)))
+	g.Expect(posts).To(Equal(1))
+
+	// the config is not updated if the retention is unchanged
+	g.Expect(setProfilingRetention(server.Client(), server.URL, 604800)).To(Succeed())
+	g.Expect(posts).To(Equal(1))
+}
+
+func TestParseVolumeUsage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	used, capacity, err := parseVolumeUsage([]byte("/dev/sdb 103080888 87618752 15445752 85% /var/lib/ng-monitoring\n"))
+	g.Expect(err).To(Succeed())
+	g.Expect(used).To(Equal(uint64(87618752 * 1024)))
+	g.Expect(capacity).To(Equal(uint64(103080888 * 1024)))
+
+	_, _, err = parseVolumeUsage([]byte(""))
+	g.Expect(err).To(HaveOccurred())
+	_, _, err = parseVolumeUsage([]byte("/dev/sdb total used 15445752 85% /var/lib/ng-monitoring"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestAutoscaleStorage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cases := []struct {
+		name        string
+		used        uint64
+		size        string
+		capacity    string
+		lastScaled  *time.Time
+		maxReached  string
+		expectSize  string
+		expectEvent string
+		// expectMaxReached is the expected annotation of the max size reached
+		expectMaxReached string
+	}{
+		{
+			name:       "disk usage is below the threshold",
+			used:       70,
+			size:       "10Gi",
+			capacity:   "10Gi",
+			expectSize: "10Gi",
+		},
+		{
+			name:        "disk usage crosses the threshold",
+			used:        85,
+			size:        "10Gi",
+			capacity:    "10Gi",
+			expectSize:  "15Gi",
+			expectEvent: volumes.EventReasonStorageAutoscaled,
+		},
+		{
+			name:        "expanded size is capped by the max size",
+			used:        85,
+			size:        "18Gi",
+			capacity:    "18Gi",
+			expectSize:  "20Gi",
+			expectEvent: volumes.EventReasonStorageAutoscaled,
+		},
+		{
+			name:        "max size is reached",
+			used:        85,
+			size:        "20Gi",
+			capacity:    "20Gi",
+			expectSize:  "20Gi",
+			expectEvent: volumes.EventReasonStorageAutoscalingMaxSizeReached,
+
+			expectMaxReached: "20Gi",
+		},
+		{
+			name:       "max size is reached and warned",
+			used:       85,
+			size:       "20Gi",
+			capacity:   "20Gi",
+			maxReached: "20Gi",
+			expectSize: "20Gi",
+
+			expectMaxReached: "20Gi",
+		},
+		{
+			name:       "disk usage drops below the threshold after the max size is reached",
+			used:       70,
+			size:       "20Gi",
+			capacity:   "20Gi",
+			maxReached: "20Gi",
+			expectSize: "20Gi",
+		},
+		{
+			name:       "volume was expanded recently",
+			used:       85,
+			size:       "10Gi",
+			capacity:   "10Gi",
+			lastScaled: func() *time.Time { t := time.Now().Add(-time.Minute); return &t }(),
+			expectSize: "10Gi",
+		},
+		{
+			name:       "last expansion is not finished",
+			used:       85,
+			size:       "15Gi",
+			capacity:   "10Gi",
+			expectSize: "15Gi",
+		},
+	}
+
+	for _, c := range cases {
+		t.Logf("testcase: %s", c.name)
+
+		deps := controller.NewFakeDependencies()
+		manager := NewNGMonitorManager(deps)
+		manager.getVolumeUsage = func(tngm *v1alpha1.TidbNGMonitoring, pod *corev1.Pod) (uint64, uint64, error) {
+			return c.used, 100, nil
+		}
+
+		tngm := &v1alpha1.TidbNGMonitoring{}
+		tngm.Name = "ngm"
+		tngm.Namespace = "default"
+		tngm.Spec.NGMonitoring.StorageAutoscaling = &v1alpha1.StorageAutoscaling{
+			Step:    resource.MustParse("5Gi"),
+			MaxSize: resource.MustParse("20Gi"),
+		}
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      NGMonitoringName(tngm.Name) + "-0",
+				Namespace: tngm.Namespace,
+				Labels:    label.NewTiDBNGMonitoring().Instance(tngm.GetInstanceName()).NGMonitoring().Labels(),
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ng-monitoring-" + pod.Name,
+				Namespace:   tngm.Namespace,
+				Annotations: map[string]string{},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(c.size)},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(c.capacity)},
+			},
+		}
+		if c.lastScaled != nil {
+			pvc.Annotations[volumes.AnnoKeyPVCLastAutoscaleTimestamp] = c.lastScaled.Format(time.RFC3339)
+		}
+		if c.maxReached != "" {
+			pvc.Annotations[volumes.AnnoKeyPVCAutoscaleMaxSizeReached] = c.maxReached
+		}
+		deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+		deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)
+		_, err := deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+		g.Expect(err).To(Succeed())
+
+		g.Expect(manager.autoscaleStorage(tngm)).To(Succeed())
+
+		updated, err := deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(context.TODO(), pvc.Name, metav1.GetOptions{})
+		g.Expect(err).To(Succeed())
+		size := updated.Spec.Resources.Requests[corev1.ResourceStorage]
+		g.Expect(size.Cmp(resource.MustParse(c.expectSize))).To(Equal(0), "size: %s", size.String())
+		g.Expect(updated.Annotations[volumes.AnnoKeyPVCAutoscaleMaxSizeReached]).To(Equal(c.expectMaxReached))
+
+		events := deps.Recorder.(*record.FakeRecorder).Events
+		if c.expectEvent == "" {
+			g.Expect(events).To(BeEmpty())
+			continue
+		}
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(<-events).To(ContainSubstring(c.expectEvent))
+		if c.expectEvent == volumes.EventReasonStorageAutoscaled {
+			g.Expect(updated.Annotations).To(HaveKey(volumes.AnnoKeyPVCLastAutoscaleTimestamp))
+		}
+	}
+}
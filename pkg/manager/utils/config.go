@@ -15,6 +15,8 @@ package utils
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 
 	perrors "github.com/pingcap/errors"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -87,7 +89,8 @@ func UpdateConfigMapIfNeed(
 			desired.Name = inUseName
 		}
 		return nil
-	case v1alpha1.ConfigUpdateStrategyRollingUpdate:
+	// the components without the config API apply the configuration by rolling update
+	case v1alpha1.ConfigUpdateStrategyRollingUpdate, v1alpha1.ConfigUpdateStrategyDynamic:
 		existing, err := cmLister.ConfigMaps(desired.Namespace).Get(inUseName)
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -114,6 +117,134 @@ func UpdateConfigMapIfNeed(
 	}
 }
 
+// ConfigDiff is the change of the configuration in the ConfigMap
+type ConfigDiff struct {
+	// Online are the changed configuration items that can be changed online, the keys are the full names of the items
+	Online map[string]interface{}
+	// PendingRestart are the changed configuration items that take effect after restart
+	PendingRestart []string
+}
+
+// OnlineKeys returns the sorted names of the items changed online
+func (d *ConfigDiff) OnlineKeys() []string {
+	keys := make([]string, 0, len(d.Online))
+	for k := range d.Online {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UpdateConfigMapDynamically decides the name of the desired ConfigMap with the Dynamic strategy, and returns
+// the changed configuration items. The in-use ConfigMap is updated in place if all the changed items can be
+// changed online, otherwise a new ConfigMap is generated like RollingUpdate to rolling-update the pods.
+// isOnline returns whether the item in "config-file" can be changed online by its full name.
+func UpdateConfigMapDynamically(
+	cmLister corelisters.ConfigMapLister,
+	inUseName string,
+	desired *corev1.ConfigMap,
+	isOnline func(key string) bool,
+) (*ConfigDiff, error) {
+	diff := &ConfigDiff{Online: map[string]interface{}{}}
+	existing, err := cmLister.ConfigMaps(desired.Namespace).Get(inUseName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			AddConfigMapDigestSuffix(desired)
+			return diff, nil
+		}
+		return nil, perrors.AddStack(err)
+	}
+
+	dataEqual, err := updateConfigMap(existing, desired)
+	if err != nil {
+		return nil, err
+	}
+	if !dataEqual {
+		if err := diffConfigMap(existing, desired, isOnline, diff); err != nil {
+			return nil, err
+		}
+	}
+
+	if !dataEqual && len(diff.PendingRestart) == 0 {
+		desired.Name = existing.Name
+		return diff, nil
+	}
+	AddConfigMapDigestSuffix(desired)
+	confirmNameByData(existing, desired, dataEqual)
+	return diff, nil
+}
+
+func diffConfigMap(existing, desired *corev1.ConfigMap, isOnline func(key string) bool, diff *ConfigDiff) error {
+	keys := map[string]struct{}{}
+	for k := range existing.Data {
+		keys[k] = struct{}{}
+	}
+	for k := range desired.Data {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		oldData, newData := existing.Data[k], desired.Data[k]
+		if oldData == newData {
+			continue
+		}
+		if k != "config-file" {
+			diff.PendingRestart = append(diff.PendingRestart, k)
+			continue
+		}
+
+		oldItems, newItems := map[string]interface{}{}, map[string]interface{}{}
+		if err := ParseConfigItems(oldData, oldItems); err != nil {
+			return perrors.Annotatef(err, "parse %s/%s %s failed", existing.Namespace, existing.Name, k)
+		}
+		if err := ParseConfigItems(newData, newItems); err != nil {
+			return perrors.Annotatef(err, "parse %s/%s %s failed", desired.Namespace, desired.Name, k)
+		}
+		for key, value := range newItems {
+			if old, ok := oldItems[key]; ok && reflect.DeepEqual(old, value) {
+				continue
+			}
+			if isOnline(key) {
+				diff.Online[key] = value
+			} else {
+				diff.PendingRestart = append(diff.PendingRestart, key)
+			}
+		}
+		// the default value of the removed items can only be loaded at startup
+		for key := range oldItems {
+			if _, ok := newItems[key]; !ok {
+				diff.PendingRestart = append(diff.PendingRestart, key)
+			}
+		}
+	}
+	sort.Strings(diff.PendingRestart)
+	return nil
+}
+
+// ParseConfigItems flattens the TOML data to the items keyed by the full names, such as "raftstore.capacity"
+func ParseConfigItems(data string, items map[string]interface{}) error {
+	m := map[string]interface{}{}
+	if err := toml.Unmarshal([]byte(data), &m); err != nil {
+		return err
+	}
+	FlattenConfigItems("", m, items)
+	return nil
+}
+
+// FlattenConfigItems flattens the nested tables to the items keyed by the full names
+func FlattenConfigItems(prefix string, m map[string]interface{}, items map[string]interface{}) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if table, ok := v.(map[string]interface{}); ok {
+			FlattenConfigItems(key, table, items)
+			continue
+		}
+		items[key] = v
+	}
+}
+
 // confirmNameByData is used to fix the problem that
 // when configUpdateStrategy is changed from InPlace to RollingUpdate for the first time,
 // the name of desired configmap maybe different from the existing one while
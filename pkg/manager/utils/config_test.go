@@ -14,11 +14,14 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestUpdateConfigMap(t *testing.T) {
@@ -299,3 +302,62 @@ func TestConfirmNameByData(t *testing.T) {
 		testFn(&tests[i], t)
 	}
 }
+
+func TestUpdateConfigMapDynamically(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	cmLister := corelisters.NewConfigMapLister(indexer)
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-tikv-1234"},
+		Data: map[string]string{
+			"config-file":    "[raftstore]\nraft-log-gc-threshold = 50\n[storage]\nreserve-space = \"1GB\"\n",
+			"startup-script": "start",
+		},
+	}
+	g.Expect(indexer.Add(existing)).To(Succeed())
+	isOnline := func(key string) bool { return strings.HasPrefix(key, "raftstore.") }
+	newDesired := func(config, script string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-tikv"},
+			Data:       map[string]string{"config-file": config, "startup-script": script},
+		}
+	}
+
+	// the ConfigMap is generated if it's not found
+	desired := newDesired(existing.Data["config-file"], "start")
+	diff, err := UpdateConfigMapDynamically(cmLister, "", desired, isOnline)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(diff.Online).To(BeEmpty())
+	g.Expect(diff.PendingRestart).To(BeEmpty())
+	g.Expect(desired.Name).To(HavePrefix("test-tikv-"))
+
+	// nothing is changed if the data is logically equal
+	desired = newDesired("[storage]\nreserve-space = \"1GB\"\n[raftstore]\nraft-log-gc-threshold = 50\n", "start")
+	diff, err = UpdateConfigMapDynamically(cmLister, existing.Name, desired, isOnline)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(diff.Online).To(BeEmpty())
+	g.Expect(diff.PendingRestart).To(BeEmpty())
+	g.Expect(desired.Name).To(Equal(existing.Name))
+
+	// the ConfigMap is updated in place if all the items can be changed online
+	desired = newDesired("[raftstore]\nraft-log-gc-threshold = 100\nraft-log-gc-count-limit = 1000\n[storage]\nreserve-space = \"1GB\"\n", "start")
+	diff, err = UpdateConfigMapDynamically(cmLister, existing.Name, desired, isOnline)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(diff.Online).To(Equal(map[string]interface{}{
+		"raftstore.raft-log-gc-threshold":   int64(100),
+		"raftstore.raft-log-gc-count-limit": int64(1000),
+	}))
+	g.Expect(diff.OnlineKeys()).To(Equal([]string{"raftstore.raft-log-gc-count-limit", "raftstore.raft-log-gc-threshold"}))
+	g.Expect(diff.PendingRestart).To(BeEmpty())
+	g.Expect(desired.Name).To(Equal(existing.Name))
+
+	// a new ConfigMap is generated if some items need a restart, the removed items need a restart too
+	desired = newDesired("[raftstore]\nraft-log-gc-threshold = 100\n", "new-start")
+	diff, err = UpdateConfigMapDynamically(cmLister, existing.Name, desired, isOnline)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(diff.OnlineKeys()).To(Equal([]string{"raftstore.raft-log-gc-threshold"}))
+	g.Expect(diff.PendingRestart).To(Equal([]string{"startup-script", "storage.reserve-space"}))
+	g.Expect(desired.Name).NotTo(Equal(existing.Name))
+	g.Expect(desired.Name).To(HavePrefix("test-tikv-"))
+}
@@ -0,0 +1,387 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/compute/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	klog "k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/pingcap/tidb-operator/pkg/manager/volumes/delegation"
+)
+
+// provisioned performance of Hyperdisk and Extreme PD can only be changed once every 4 hours,
+// see https://cloud.google.com/compute/docs/disks/modify-hyperdisks
+var defaultWaitDuration = time.Hour * 6
+
+const (
+	// See https://github.com/kubernetes-sigs/gcp-compute-persistent-disk-csi-driver#createvolume-parameters
+	paramKeyIOPS       = "provisioned-iops-on-create"
+	paramKeyThroughput = "provisioned-throughput-on-create"
+	paramKeyType       = "type"
+
+	operationStatusDone = "DONE"
+
+	fieldProvisionedIOPS       = "provisionedIops"
+	fieldProvisionedThroughput = "provisionedThroughput"
+)
+
+// DiskLocation is parsed from the volume handle of pv,
+// e.g. projects/{project}/zones/{zone}/disks/{name} or projects/{project}/regions/{region}/disks/{name}.
+type DiskLocation struct {
+	Project string
+	Zone    string
+	Region  string
+	Name    string
+}
+
+type DiskClient interface {
+	GetDisk(ctx context.Context, loc *DiskLocation) (*compute.Disk, error)
+	// UpdateDisk only updates the fields specified by paths
+	UpdateDisk(ctx context.Context, loc *DiskLocation, disk *compute.Disk, paths ...string) (*compute.Operation, error)
+	GetOperation(ctx context.Context, loc *DiskLocation, name string) (*compute.Operation, error)
+}
+
+type GCPDiskModifier struct {
+	// for unit test, add switch for fake client
+	// because the client needs a context to be created, so we cannot initialize it in constructor
+	DiskClient DiskClient
+
+	mu sync.Mutex
+	// pending operations of disk updates, the key is the volume id
+	operations map[string]string
+}
+
+type Volume struct {
+	VolumeId   string
+	IOPS       *int64
+	Throughput *int64
+	Type       string
+}
+
+func NewGCPDiskModifier() delegation.VolumeModifier {
+	return &GCPDiskModifier{
+		operations: map[string]string{},
+	}
+}
+
+func (m *GCPDiskModifier) Name() string {
+	return "pd.csi.storage.gke.io"
+}
+
+func (m *GCPDiskModifier) Validate(spvc, dpvc *corev1.PersistentVolumeClaim, ssc, dsc *storagev1.StorageClass) error {
+	if ssc.Provisioner != dsc.Provisioner {
+		return fmt.Errorf("provisioner should not be changed, now from %s to %s", ssc.Provisioner, dsc.Provisioner)
+	}
+	styp, dtyp := ssc.Parameters[paramKeyType], dsc.Parameters[paramKeyType]
+	if styp != "" && dtyp != "" && styp != dtyp {
+		return fmt.Errorf("disk type cannot be changed in place, now from %s to %s, replace the volume instead", styp, dtyp)
+	}
+	return nil
+}
+
+// ModifyVolume only modifies the provisioned iops and throughput of the disk.
+// The size is expanded by the csi driver after the pvc is resized.
+func (m *GCPDiskModifier) ModifyVolume(ctx context.Context, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume, sc *storagev1.StorageClass) ( /*wait*/ bool, error) {
+	if pv == nil {
+		klog.V(4).Infof("Persistent volume is nil, skip modifying PV for %s. This may be caused by no relevant permissions", pvc.Spec.VolumeName)
+		return false, nil
+	}
+
+	desired, err := m.getExpectedVolume(pv, sc)
+	if err != nil {
+		return false, fmt.Errorf("error getting expected volume: %w", err)
+	}
+
+	loc, err := getDiskLocationFromVolumeID(desired.VolumeId)
+	if err != nil {
+		return false, fmt.Errorf("failed to get GCP disk info from PV: %w", err)
+	}
+
+	if err := m.setDiskClient(ctx); err != nil {
+		return false, fmt.Errorf("failed to create disk client: %w", err)
+	}
+
+	wait, err := m.waitForPendingOperation(ctx, loc, desired.VolumeId)
+	if err != nil || wait {
+		return wait, err
+	}
+
+	actual, err := m.getCurrentVolumeStatus(ctx, loc)
+	if err != nil {
+		return false, fmt.Errorf("getting current volume status: %w", err)
+	}
+
+	paths := m.diffVolume(actual, desired)
+	if len(paths) == 0 {
+		klog.V(4).Infof("Volume modification is already completed for PVC %s/%s", pvc.Namespace, pvc.Name)
+		return false, nil
+	}
+
+	klog.V(2).Infof("call gcp api to modify %v of disk for pvc %s/%s", paths, pvc.Namespace, pvc.Name)
+
+	disk := &compute.Disk{
+		Name:                  loc.Name,
+		ProvisionedIops:       ptr.Deref(desired.IOPS, 0),
+		ProvisionedThroughput: ptr.Deref(desired.Throughput, 0),
+	}
+	op, err := m.DiskClient.UpdateDisk(ctx, loc, disk, paths...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update disk: %w", err)
+	}
+	if op != nil && op.Status != operationStatusDone {
+		m.setPendingOperation(desired.VolumeId, op.Name)
+	}
+
+	return true, nil
+}
+
+// waitForPendingOperation returns true if the last update of the disk is not finished.
+func (m *GCPDiskModifier) waitForPendingOperation(ctx context.Context, loc *DiskLocation, id string) (bool, error) {
+	name := m.getPendingOperation(id)
+	if name == "" {
+		return false, nil
+	}
+
+	op, err := m.DiskClient.GetOperation(ctx, loc, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to get operation %s: %w", name, err)
+	}
+	if op.Status != operationStatusDone {
+		klog.V(4).Infof("operation %s of disk %s is %s, wait", name, id, op.Status)
+		return true, nil
+	}
+
+	m.setPendingOperation(id, "")
+	if op.Error != nil && len(op.Error.Errors) != 0 {
+		// the disk will be modified again in next reconciliation
+		return false, fmt.Errorf("operation %s of disk %s is failed: %s", name, id, op.Error.Errors[0].Message)
+	}
+
+	return false, nil
+}
+
+func (m *GCPDiskModifier) getPendingOperation(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.operations[id]
+}
+
+func (m *GCPDiskModifier) setPendingOperation(id, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.operations == nil {
+		m.operations = map[string]string{}
+	}
+	if name == "" {
+		delete(m.operations, id)
+		return
+	}
+	m.operations[id] = name
+}
+
+// diffVolume returns fields which should be updated.
+// If some params are not set in storage class, assume they are equal.
+func (m *GCPDiskModifier) diffVolume(actual, desired *Volume) []string {
+	var paths []string
+	if diffInt64(actual.IOPS, desired.IOPS) {
+		paths = append(paths, fieldProvisionedIOPS)
+	}
+	if diffInt64(actual.Throughput, desired.Throughput) {
+		paths = append(paths, fieldProvisionedThroughput)
+	}
+
+	return paths
+}
+
+func diffInt64(a, b *int64) bool {
+	if a == nil || b == nil {
+		return b != nil
+	}
+
+	return *a != *b
+}
+
+func (m *GCPDiskModifier) getCurrentVolumeStatus(ctx context.Context, loc *DiskLocation) (*Volume, error) {
+	disk, err := m.DiskClient.GetDisk(ctx, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk: %w", err)
+	}
+
+	v := &Volume{
+		VolumeId: disk.SelfLink,
+		Type:     lastSegment(disk.Type),
+	}
+	if disk.ProvisionedIops != 0 {
+		v.IOPS = ptr.To(disk.ProvisionedIops)
+	}
+	if disk.ProvisionedThroughput != 0 {
+		v.Throughput = ptr.To(disk.ProvisionedThroughput)
+	}
+
+	return v, nil
+}
+
+func (m *GCPDiskModifier) getExpectedVolume(pv *corev1.PersistentVolume, sc *storagev1.StorageClass) (*Volume, error) {
+	v := Volume{}
+	if err := utilerrors.NewAggregate([]error{
+		m.setArgsFromPV(&v, pv),
+		m.setArgsFromStorageClass(&v, sc),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (m *GCPDiskModifier) MinWaitDuration() time.Duration {
+	return defaultWaitDuration
+}
+
+func (m *GCPDiskModifier) setArgsFromPV(v *Volume, pv *corev1.PersistentVolume) error {
+	if pv.Spec.CSI == nil {
+		return fmt.Errorf("pv %s is not provisioned by csi driver", pv.Name)
+	}
+	v.VolumeId = pv.Spec.CSI.VolumeHandle
+	return nil
+}
+
+func (m *GCPDiskModifier) setArgsFromStorageClass(v *Volume, sc *storagev1.StorageClass) error {
+	if sc == nil {
+		return nil
+	}
+	iops, err := getParamInt64(sc.Parameters, paramKeyIOPS)
+	if err != nil {
+		return err
+	}
+	v.IOPS = iops
+
+	throughput, err := getParamThroughput(sc.Parameters, paramKeyThroughput)
+	if err != nil {
+		return err
+	}
+	v.Throughput = throughput
+
+	v.Type = sc.Parameters[paramKeyType]
+	return nil
+}
+
+func getParamInt64(params map[string]string, key string) (*int64, error) {
+	str, ok := params[key]
+	if !ok || str == "" {
+		return nil, nil
+	}
+	param, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %v param in storage class: %v", key, err)
+	}
+
+	return ptr.To(param), nil
+}
+
+// getParamThroughput returns throughput in MiB/s.
+// The param may be a quantity such as "250Mi" or a number in MiB/s.
+func getParamThroughput(params map[string]string, key string) (*int64, error) {
+	str, ok := params[key]
+	if !ok || str == "" {
+		return nil, nil
+	}
+	if param, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return ptr.To(param), nil
+	}
+	q, err := resource.ParseQuantity(str)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %v param in storage class: %v", key, err)
+	}
+
+	return ptr.To(q.Value() / 1024 / 1024), nil
+}
+
+func getDiskLocationFromVolumeID(volumeID string) (*DiskLocation, error) {
+	// example: projects/xxxx/zones/xxxx/disks/xxxx
+	parts := strings.Split(strings.TrimPrefix(volumeID, "/"), "/")
+	if len(parts) != 6 || parts[0] != "projects" || parts[4] != "disks" {
+		return nil, fmt.Errorf("invalid volumeHandle format %q", volumeID)
+	}
+	loc := &DiskLocation{
+		Project: parts[1],
+		Name:    parts[5],
+	}
+	switch parts[2] {
+	case "zones":
+		loc.Zone = parts[3]
+	case "regions":
+		loc.Region = parts[3]
+	default:
+		return nil, fmt.Errorf("invalid volumeHandle format %q", volumeID)
+	}
+
+	return loc, nil
+}
+
+func lastSegment(url string) string {
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+func (m *GCPDiskModifier) setDiskClient(ctx context.Context) error {
+	if m.DiskClient != nil {
+		return nil
+	}
+
+	// use the application default credentials, e.g. workload identity
+	svc, err := compute.NewService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create compute service: %v", err)
+	}
+
+	m.DiskClient = &computeDiskClient{svc: svc}
+
+	return nil
+}
+
+type computeDiskClient struct {
+	svc *compute.Service
+}
+
+func (c *computeDiskClient) GetDisk(ctx context.Context, loc *DiskLocation) (*compute.Disk, error) {
+	if loc.Region != "" {
+		return c.svc.RegionDisks.Get(loc.Project, loc.Region, loc.Name).Context(ctx).Do()
+	}
+	return c.svc.Disks.Get(loc.Project, loc.Zone, loc.Name).Context(ctx).Do()
+}
+
+func (c *computeDiskClient) UpdateDisk(ctx context.Context, loc *DiskLocation, disk *compute.Disk, paths ...string) (*compute.Operation, error) {
+	if loc.Region != "" {
+		return c.svc.RegionDisks.Update(loc.Project, loc.Region, loc.Name, disk).Paths(paths...).Context(ctx).Do()
+	}
+	return c.svc.Disks.Update(loc.Project, loc.Zone, loc.Name, disk).Paths(paths...).Context(ctx).Do()
+}
+
+func (c *computeDiskClient) GetOperation(ctx context.Context, loc *DiskLocation, name string) (*compute.Operation, error) {
+	if loc.Region != "" {
+		return c.svc.RegionOperations.Get(loc.Project, loc.Region, name).Context(ctx).Do()
+	}
+	return c.svc.ZoneOperations.Get(loc.Project, loc.Zone, name).Context(ctx).Do()
+}
@@ -0,0 +1,192 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func newTestPVC(size string) *corev1.PersistentVolumeClaim {
+	q := resource.MustParse(size)
+
+	return &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: q,
+				},
+			},
+		},
+	}
+}
+
+func newTestPV(volId string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					VolumeHandle: volId,
+				},
+			},
+		},
+	}
+}
+
+func newTestStorageClass(typ string, iops string, throughput string) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		Provisioner: "pd.csi.storage.gke.io",
+		Parameters: map[string]string{
+			paramKeyIOPS:       iops,
+			paramKeyType:       typ,
+			paramKeyThroughput: throughput,
+		},
+	}
+}
+
+func TestModifyVolume(t *testing.T) {
+	initialPVC := newTestPVC("10Gi")
+	initialPV := newTestPV("projects/p/zones/us-central1-a/disks/aaa")
+	initialSC := newTestStorageClass("hyperdisk-balanced", "3000", "140Mi")
+
+	done := func(name string) string { return operationStatusDone }
+	running := func(name string) string { return "RUNNING" }
+
+	cases := []struct {
+		desc string
+
+		pvc *corev1.PersistentVolumeClaim
+		pv  *corev1.PersistentVolume
+		sc  *storagev1.StorageClass
+
+		getStatus GetOperationStatusFunc
+
+		wait   bool
+		hasErr bool
+	}{
+		{
+			desc:      "volume modification is done, no need to wait",
+			pvc:       initialPVC,
+			pv:        initialPV,
+			sc:        initialSC,
+			getStatus: done,
+			wait:      false,
+			hasErr:    false,
+		},
+		{
+			desc:      "volume modification is running, wait",
+			pvc:       initialPVC,
+			pv:        initialPV,
+			sc:        initialSC,
+			getStatus: running,
+			wait:      true,
+			hasErr:    false,
+		},
+		{
+			desc:      "volume has been modified, but size is changed and it will be expanded by csi driver",
+			pvc:       newTestPVC("20Gi"),
+			pv:        initialPV,
+			sc:        initialSC,
+			getStatus: done,
+			wait:      false,
+			hasErr:    false,
+		},
+		{
+			desc:      "volume has been modified, but iops is changed",
+			pvc:       initialPVC,
+			pv:        initialPV,
+			sc:        newTestStorageClass("hyperdisk-balanced", "6000", "140Mi"),
+			getStatus: done,
+			wait:      true,
+			hasErr:    false,
+		},
+		{
+			desc:      "volume has been modified, but throughput is changed",
+			pvc:       initialPVC,
+			pv:        initialPV,
+			sc:        newTestStorageClass("hyperdisk-balanced", "3000", "280"),
+			getStatus: done,
+			wait:      true,
+			hasErr:    false,
+		},
+		{
+			desc:      "volume has not been modified, try to modify",
+			pvc:       initialPVC,
+			pv:        newTestPV("projects/p/regions/us-central1/disks/bbb"),
+			sc:        initialSC,
+			getStatus: done,
+			wait:      true,
+			hasErr:    false,
+		},
+		{
+			desc:      "volume handle is invalid",
+			pvc:       initialPVC,
+			pv:        newTestPV("invalid"),
+			sc:        initialSC,
+			getStatus: done,
+			wait:      false,
+			hasErr:    true,
+		},
+	}
+
+	g := NewGomegaWithT(t)
+	for _, c := range cases {
+		m := NewFakeGCPDiskModifier(c.getStatus)
+
+		wait1, err := m.ModifyVolume(context.TODO(), initialPVC, initialPV, initialSC)
+		g.Expect(err).Should(Succeed(), c.desc)
+		g.Expect(wait1).Should(BeTrue(), c.desc)
+
+		wait2, err := m.ModifyVolume(context.TODO(), c.pvc, c.pv, c.sc)
+		if c.hasErr {
+			g.Expect(err).Should(HaveOccurred(), c.desc)
+		} else {
+			g.Expect(err).Should(Succeed(), c.desc)
+		}
+		g.Expect(wait2).Should(Equal(c.wait), c.desc)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	m := NewGCPDiskModifier()
+
+	ssc := newTestStorageClass("hyperdisk-balanced", "3000", "140Mi")
+	g.Expect(m.Validate(nil, nil, ssc, newTestStorageClass("hyperdisk-balanced", "6000", "280Mi"))).Should(Succeed())
+	g.Expect(m.Validate(nil, nil, ssc, newTestStorageClass("pd-ssd", "", ""))).Should(HaveOccurred())
+
+	other := newTestStorageClass("hyperdisk-balanced", "3000", "140Mi")
+	other.Provisioner = "ebs.csi.aws.com"
+	g.Expect(m.Validate(nil, nil, ssc, other)).Should(HaveOccurred())
+}
+
+func TestGetDiskLocationFromVolumeID(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	loc, err := getDiskLocationFromVolumeID("projects/p/zones/us-central1-a/disks/d")
+	g.Expect(err).Should(Succeed())
+	g.Expect(*loc).Should(Equal(DiskLocation{Project: "p", Zone: "us-central1-a", Name: "d"}))
+
+	loc, err = getDiskLocationFromVolumeID("projects/p/regions/us-central1/disks/d")
+	g.Expect(err).Should(Succeed())
+	g.Expect(*loc).Should(Equal(DiskLocation{Project: "p", Region: "us-central1", Name: "d"}))
+
+	_, err = getDiskLocationFromVolumeID("projects/p/global/us-central1/disks/d")
+	g.Expect(err).Should(HaveOccurred())
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/compute/v1"
+
+	"github.com/pingcap/tidb-operator/pkg/manager/volumes/delegation"
+)
+
+func NewFakeGCPDiskModifier(f GetOperationStatusFunc) delegation.VolumeModifier {
+	return &GCPDiskModifier{
+		DiskClient: NewFakeDiskClient(f),
+		operations: map[string]string{},
+	}
+}
+
+// GetOperationStatusFunc returns the status of operation, e.g. PENDING, RUNNING or DONE.
+type GetOperationStatusFunc func(name string) string
+
+type FakeDiskClient struct {
+	disks map[string]*compute.Disk
+	// pending updates which will be applied when the operation is done
+	pending map[string]*compute.Disk
+	f       GetOperationStatusFunc
+}
+
+func NewFakeDiskClient(f GetOperationStatusFunc) *FakeDiskClient {
+	return &FakeDiskClient{
+		disks:   map[string]*compute.Disk{},
+		pending: map[string]*compute.Disk{},
+		f:       f,
+	}
+}
+
+func (c *FakeDiskClient) GetDisk(ctx context.Context, loc *DiskLocation) (*compute.Disk, error) {
+	d, ok := c.disks[loc.Name]
+	if !ok {
+		d = &compute.Disk{
+			Name:   loc.Name,
+			Status: "READY",
+		}
+		c.disks[loc.Name] = d
+	}
+
+	return d, nil
+}
+
+func (c *FakeDiskClient) UpdateDisk(ctx context.Context, loc *DiskLocation, disk *compute.Disk, paths ...string) (*compute.Operation, error) {
+	if _, ok := c.pending[loc.Name]; ok {
+		return nil, fmt.Errorf("disk %s is being modified", loc.Name)
+	}
+	d, err := c.GetDisk(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	updated := *d
+	for _, path := range paths {
+		switch path {
+		case fieldProvisionedIOPS:
+			updated.ProvisionedIops = disk.ProvisionedIops
+		case fieldProvisionedThroughput:
+			updated.ProvisionedThroughput = disk.ProvisionedThroughput
+		default:
+			return nil, fmt.Errorf("unsupported path %s", path)
+		}
+	}
+
+	name := operationName(loc)
+	c.pending[loc.Name] = &updated
+	op := &compute.Operation{
+		Name:   name,
+		Status: "PENDING",
+	}
+	if c.f != nil {
+		op.Status = c.f(name)
+	}
+	c.applyIfDone(loc, op)
+
+	return op, nil
+}
+
+func (c *FakeDiskClient) GetOperation(ctx context.Context, loc *DiskLocation, name string) (*compute.Operation, error) {
+	if name != operationName(loc) {
+		return nil, fmt.Errorf("operation %s is not found", name)
+	}
+	op := &compute.Operation{
+		Name:   name,
+		Status: operationStatusDone,
+	}
+	if c.f != nil {
+		op.Status = c.f(name)
+	}
+	c.applyIfDone(loc, op)
+
+	return op, nil
+}
+
+func (c *FakeDiskClient) applyIfDone(loc *DiskLocation, op *compute.Operation) {
+	if op.Status != operationStatusDone {
+		return
+	}
+	if d, ok := c.pending[loc.Name]; ok {
+		c.disks[loc.Name] = d
+		delete(c.pending, loc.Name)
+	}
+}
+
+func operationName(loc *DiskLocation) string {
+	return "operation-update-" + loc.Name
+}
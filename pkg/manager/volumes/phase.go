@@ -121,6 +121,10 @@ func (p *podVolModifier) validate(vol *ActualVolume) error {
 		}
 	}
 
+	if vol.Desired.GetVolumeAttributesClassName() != "" && p.vac == nil {
+		return fmt.Errorf("cannot change volume attributes class to %s, because the client is not initialized", vol.Desired.GetVolumeAttributesClassName())
+	}
+
 	m := p.getVolumeModifier(vol.StorageClass, vol.Desired.StorageClass)
 	if m == nil {
 		return nil
@@ -174,7 +178,20 @@ func needModify(pvc *corev1.PersistentVolumeClaim, desired *DesiredVolume) bool
 	size := desired.Size
 	scName := desired.GetStorageClassName()
 
-	return isPVCStatusMatched(pvc, scName, size)
+	return isPVCStatusMatched(pvc, scName, size) || isVolumeAttributesClassChanged(pvc, desired.GetVolumeAttributesClassName())
+}
+
+func isVolumeAttributesClassChanged(pvc *corev1.PersistentVolumeClaim, vacName string) bool {
+	if vacName == "" {
+		// vac is unset, keep the current one
+		return false
+	}
+	oldVac := pvc.Annotations[annoKeyPVCStatusVolumeAttributesClass]
+	if oldVac != vacName {
+		klog.Infof("volume %s/%s is changed, vac (%s => %s)", pvc.Namespace, pvc.Name, oldVac, vacName)
+		return true
+	}
+	return false
 }
 
 func isPVCStatusMatched(pvc *corev1.PersistentVolumeClaim, scName string, size resource.Quantity) bool {
@@ -15,6 +15,7 @@ package volumes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
@@ -23,7 +24,9 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	klog "k8s.io/klog/v2"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -32,6 +35,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/manager/volumes/delegation"
 	"github.com/pingcap/tidb-operator/pkg/manager/volumes/delegation/aws"
 	"github.com/pingcap/tidb-operator/pkg/manager/volumes/delegation/azure"
+	"github.com/pingcap/tidb-operator/pkg/manager/volumes/delegation/gcp"
 )
 
 type PodVolumeModifier interface {
@@ -65,6 +69,8 @@ type podVolModifier struct {
 	deps      *controller.Dependencies
 	utils     *volCompareUtils
 	modifiers map[string]delegation.VolumeModifier
+	// it may be nil and then volume attributes class is ignored
+	vac VolumeAttributesClassClient
 }
 
 func NewPodVolumeModifier(deps *controller.Dependencies) PodVolumeModifier {
@@ -73,10 +79,14 @@ func NewPodVolumeModifier(deps *controller.Dependencies) PodVolumeModifier {
 		utils:     newVolCompareUtils(deps),
 		modifiers: map[string]delegation.VolumeModifier{},
 	}
+	if deps.GenericClient != nil {
+		m.vac = NewVolumeAttributesClassClient(deps.GenericClient)
+	}
 	if features.DefaultFeatureGate.Enabled(features.VolumeModifying) {
 		// select modifier by provisioner
 		m.modifiers["ebs.csi.aws.com"] = aws.NewEBSModifier(deps.AWSConfig) // register AWS modifier
 		m.modifiers["disk.csi.azure.com"] = azure.NewAzureDiskModifier()    // register Azure modifier
+		m.modifiers["pd.csi.storage.gke.io"] = gcp.NewGCPDiskModifier()     // register GCP modifier
 	}
 
 	return m
@@ -121,6 +131,15 @@ func (p *podVolModifier) Modify(actual []ActualVolume) error {
 				errs = append(errs, fmt.Errorf("wait for volume modification completed"))
 				continue
 			}
+			wait, err = p.modifyVolumeAttributesClass(ctx, vol)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if wait {
+				errs = append(errs, fmt.Errorf("wait for volume attributes class modification completed"))
+				continue
+			}
 			// try to resize fs
 			synced, err := p.syncPVCSize(ctx, vol)
 			if err != nil {
@@ -216,6 +235,12 @@ func (p *podVolModifier) NewActualVolumeOfPod(vs []DesiredVolume, ns string, vol
 	if desired == nil {
 		return nil, nil
 	}
+	if size := getDesiredStorageSize(pvc, desired); size.Cmp(desired.Size) != 0 {
+		// the volume has been expanded by storage autoscaling
+		d := *desired
+		d.Size = size
+		desired = &d
+	}
 
 	actual := ActualVolume{
 		Desired:      desired,
@@ -299,6 +324,9 @@ func (p *podVolModifier) modifyPVCAnnoSpec(ctx context.Context, vol *ActualVolum
 	scName := vol.Desired.GetStorageClassName()
 
 	isChanged := snapshotStorageClassAndSize(pvc, scName, size)
+	if snapshotVolumeAttributesClass(pvc, vol.Desired.GetVolumeAttributesClassName()) {
+		isChanged = true
+	}
 	if isChanged {
 		upgradeRevision(pvc)
 	}
@@ -307,7 +335,7 @@ func (p *podVolModifier) modifyPVCAnnoSpec(ctx context.Context, vol *ActualVolum
 		setLastTransitionTimestamp(pvc)
 	}
 
-	updated, err := p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, pvc, metav1.UpdateOptions{})
+	updated, err := p.patchPVC(ctx, vol.PVC, pvc)
 	if err != nil {
 		return err
 	}
@@ -331,7 +359,7 @@ func (p *podVolModifier) syncPVCSize(ctx context.Context, vol *ActualVolume) (bo
 
 	pvc := vol.PVC.DeepCopy()
 	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = vol.Desired.Size
-	updated, err := p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, pvc, metav1.UpdateOptions{})
+	updated, err := p.patchPVC(ctx, vol.PVC, pvc)
 	if err != nil {
 		return false, err
 	}
@@ -353,8 +381,11 @@ func (p *podVolModifier) modifyPVCAnnoStatus(ctx context.Context, vol *ActualVol
 		pvc.Annotations[annoKeyPVCStatusStorageClass] = scName
 	}
 	pvc.Annotations[annoKeyPVCStatusStorageSize] = pvc.Annotations[annoKeyPVCSpecStorageSize]
+	if vacName := pvc.Annotations[annoKeyPVCSpecVolumeAttributesClass]; vacName != "" {
+		pvc.Annotations[annoKeyPVCStatusVolumeAttributesClass] = vacName
+	}
 
-	updated, err := p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, pvc, metav1.UpdateOptions{})
+	updated, err := p.patchPVC(ctx, vol.PVC, pvc)
 	if err != nil {
 		return err
 	}
@@ -377,6 +408,90 @@ func (p *podVolModifier) modifyVolume(ctx context.Context, vol *ActualVolume) (b
 	return m.ModifyVolume(ctx, pvc, vol.PV, vol.Desired.StorageClass)
 }
 
+// modifyVolumeAttributesClass sets the vac of pvc and waits until the modification is finished by the csi driver.
+func (p *podVolModifier) modifyVolumeAttributesClass(ctx context.Context, vol *ActualVolume) (bool, error) {
+	name := vol.Desired.GetVolumeAttributesClassName()
+	if name == "" || p.vac == nil {
+		return false, nil
+	}
+
+	attrs, err := p.vac.Get(ctx, vol.PVC)
+	if err != nil {
+		return false, err
+	}
+
+	if attrs.SpecName != name {
+		klog.Infof("modify volume attributes class of pvc %s/%s from %q to %q", vol.PVC.Namespace, vol.PVC.Name, attrs.SpecName, name)
+		if err := p.vac.Set(ctx, vol.PVC, name); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	switch attrs.ModifyStatus {
+	case modifyVolumeStatusInfeasible:
+		return false, fmt.Errorf("modifying volume attributes class of pvc %s/%s to %q is infeasible", vol.PVC.Namespace, vol.PVC.Name, name)
+	case modifyVolumeStatusPending, modifyVolumeStatusInProgress:
+		klog.V(4).Infof("volume attributes class of pvc %s/%s is being modified to %q, status: %s", vol.PVC.Namespace, vol.PVC.Name, attrs.TargetName, attrs.ModifyStatus)
+		return true, nil
+	}
+
+	return attrs.CurrentName != name, nil
+}
+
+// patchPVC updates pvc by a strategic merge patch instead of an update,
+// so that fields unknown to the typed pvc, e.g. `spec.volumeAttributesClassName`, will not be cleared.
+// The resource version of the old pvc is kept in the patch, so the patch fails with a conflict like
+// an update if the pvc has been changed after it's read.
+func (p *podVolModifier) patchPVC(ctx context.Context, old, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	oldData, err := json.Marshal(old)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := json.Marshal(pvc)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, &corev1.PersistentVolumeClaim{})
+	if err != nil {
+		return nil, fmt.Errorf("create patch for pvc %s/%s failed: %w", pvc.Namespace, pvc.Name, err)
+	}
+	patch, err = setPatchResourceVersion(patch, old.ResourceVersion)
+	if err != nil {
+		return nil, fmt.Errorf("create patch for pvc %s/%s failed: %w", pvc.Namespace, pvc.Name, err)
+	}
+
+	return p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+}
+
+// setPatchResourceVersion sets `metadata.resourceVersion` of the patch as the precondition of it
+func setPatchResourceVersion(patch []byte, resourceVersion string) ([]byte, error) {
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &obj); err != nil {
+		return nil, err
+	}
+	meta, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		meta = map[string]interface{}{}
+	}
+	meta["resourceVersion"] = resourceVersion
+	obj["metadata"] = meta
+	return json.Marshal(obj)
+}
+
+func snapshotVolumeAttributesClass(pvc *corev1.PersistentVolumeClaim, vacName string) bool {
+	if vacName == "" {
+		return false
+	}
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	isChanged := pvc.Annotations[annoKeyPVCSpecVolumeAttributesClass] != vacName
+	pvc.Annotations[annoKeyPVCSpecVolumeAttributesClass] = vacName
+
+	return isChanged
+}
+
 func (p *podVolModifier) getVolumeModifier(actualSc, desiredSc *storagev1.StorageClass) delegation.VolumeModifier {
 	if actualSc == nil || desiredSc == nil {
 		return nil
@@ -24,8 +24,10 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 
 	"github.com/pingcap/tidb-operator/pkg/controller"
@@ -256,3 +258,100 @@ func TestModify(t *testing.T) {
 		g.Expect(resultPVC).Should(Equal(c.expectedPVC), c.desc)
 	}
 }
+
+func TestModifyVolumeAttributesClass(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	size := "10Gi"
+	scName := "sc"
+	vacName := "fast"
+
+	pvc := newTestPVCForModify(&scName, size, size, nil)
+	pv := newTestPVForModify()
+	sc := newTestSCForModify(scName, "test")
+
+	kc := fake.NewSimpleClientset(pvc, pv, sc)
+	vac := NewFakeVolumeAttributesClassClient()
+	pvm := &podVolModifier{
+		deps: &controller.Dependencies{
+			KubeClientset: kc,
+		},
+		modifiers: map[string]delegation.VolumeModifier{},
+		vac:       vac,
+	}
+
+	desired := &DesiredVolume{
+		Name:                      "test",
+		Size:                      resource.MustParse(size),
+		StorageClass:              sc,
+		StorageClassName:          &scName,
+		VolumeAttributesClassName: &vacName,
+	}
+
+	sync := func() error {
+		cur, err := kc.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(context.TODO(), pvc.Name, metav1.GetOptions{})
+		g.Expect(err).Should(Succeed())
+		actual := ActualVolume{
+			Desired:      desired,
+			PVC:          cur,
+			PV:           pv,
+			StorageClass: sc,
+		}
+		actual.Phase = pvm.getVolumePhase(&actual)
+		return pvm.Modify([]ActualVolume{actual})
+	}
+
+	// vac is set and wait for the csi driver
+	g.Expect(sync()).Should(HaveOccurred())
+	g.Expect(vac.Attrs["test/test-pvc"].SpecName).Should(Equal(vacName))
+
+	// the modification is infeasible
+	vac.Attrs["test/test-pvc"].ModifyStatus = modifyVolumeStatusInfeasible
+	g.Expect(sync()).Should(HaveOccurred())
+
+	// the modification is finished
+	vac.Attrs["test/test-pvc"].ModifyStatus = ""
+	vac.Attrs["test/test-pvc"].CurrentName = vacName
+	g.Expect(sync()).Should(Succeed())
+
+	result, err := kc.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(context.TODO(), pvc.Name, metav1.GetOptions{})
+	g.Expect(err).Should(Succeed())
+	g.Expect(result.Annotations[annoKeyPVCSpecVolumeAttributesClass]).Should(Equal(vacName))
+	g.Expect(result.Annotations[annoKeyPVCStatusVolumeAttributesClass]).Should(Equal(vacName))
+	g.Expect(result.Annotations[annoKeyPVCStatusRevision]).Should(Equal("1"))
+
+	// nothing to do after the modification
+	actual := ActualVolume{
+		Desired:      desired,
+		PVC:          result,
+		PV:           pv,
+		StorageClass: sc,
+	}
+	g.Expect(pvm.getVolumePhase(&actual)).Should(Equal(VolumePhaseModified))
+}
+
+func TestPatchPVC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scName := "sc"
+	pvc := newTestPVCForModify(&scName, "10Gi", "10Gi", nil)
+	pvc.ResourceVersion = "10"
+	kc := fake.NewSimpleClientset(pvc)
+	var patch []byte
+	kc.PrependReactor("patch", "persistentvolumeclaims", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch = action.(k8stesting.PatchAction).GetPatch()
+		return false, nil, nil
+	})
+	pvm := &podVolModifier{
+		deps: &controller.Dependencies{
+			KubeClientset: kc,
+		},
+	}
+
+	updated := pvc.DeepCopy()
+	updated.Annotations = map[string]string{annoKeyPVCSpecRevision: "1"}
+	_, err := pvm.patchPVC(context.TODO(), pvc, updated)
+	g.Expect(err).Should(Succeed())
+	// the resource version is kept for the optimistic concurrency
+	g.Expect(string(patch)).Should(MatchJSON(`{"metadata":{"annotations":{"` + annoKeyPVCSpecRevision + `":"1"},"resourceVersion":"10"}}`))
+}
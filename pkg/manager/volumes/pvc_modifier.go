@@ -30,13 +30,15 @@ import (
 )
 
 const (
-	annoKeyPVCSpecRevision     = "spec.tidb.pingcap.com/revision"
-	annoKeyPVCSpecStorageClass = "spec.tidb.pingcap.com/storage-class"
-	annoKeyPVCSpecStorageSize  = "spec.tidb.pingcap.com/storage-size"
+	annoKeyPVCSpecRevision              = "spec.tidb.pingcap.com/revision"
+	annoKeyPVCSpecStorageClass          = "spec.tidb.pingcap.com/storage-class"
+	annoKeyPVCSpecStorageSize           = "spec.tidb.pingcap.com/storage-size"
+	annoKeyPVCSpecVolumeAttributesClass = "spec.tidb.pingcap.com/volume-attributes-class"
 
-	annoKeyPVCStatusRevision     = "status.tidb.pingcap.com/revision"
-	annoKeyPVCStatusStorageClass = "status.tidb.pingcap.com/storage-class"
-	annoKeyPVCStatusStorageSize  = "status.tidb.pingcap.com/storage-size"
+	annoKeyPVCStatusRevision              = "status.tidb.pingcap.com/revision"
+	annoKeyPVCStatusStorageClass          = "status.tidb.pingcap.com/storage-class"
+	annoKeyPVCStatusStorageSize           = "status.tidb.pingcap.com/storage-size"
+	annoKeyPVCStatusVolumeAttributesClass = "status.tidb.pingcap.com/volume-attributes-class"
 
 	annoKeyPVCLastTransitionTimestamp = "status.tidb.pingcap.com/last-transition-timestamp"
 
@@ -91,6 +93,13 @@ func (p *pvcModifier) modifyVolumes(ctx *componentVolumeContext) error {
 		return fmt.Errorf("component phase is not Normal")
 	}
 
+	// the failure of autoscaling, such as PD is unreachable, doesn't block the modification requested in the spec
+	if err := p.autoscaleStorage(ctx); err != nil {
+		klog.Warningf("autoscale storage for %s failed: %v", ctx.ComponentID(), err)
+		p.deps.Recorder.Eventf(ctx.tc, corev1.EventTypeWarning, EventReasonStorageAutoscalingFailed,
+			"autoscale storage for %s failed: %v", ctx.ComponentID(), err)
+	}
+
 	if err := p.tryToRecreateSTS(ctx); err != nil {
 		return err
 	}
@@ -16,7 +16,9 @@ package volumes
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/manager/utils"
@@ -52,6 +54,21 @@ func (p *pvcReplacer) getVolReplaceStatusForComponent(tc *v1alpha1.TidbCluster,
 		return comp.GetVolReplaceInProgress(), nil // Do not change existing status.
 	}
 
+	p.syncStorageClassMigration(ctx, time.Now())
+
+	if isVolumeReplaceAborted(tc) {
+		for _, pod := range ctx.pods {
+			// Let the pending replacement finish before removing the spare replicas.
+			if isVolumeReplacing(pod) {
+				return true, nil
+			}
+		}
+		if comp.GetVolReplaceInProgress() {
+			klog.Infof("volume replace for %s is aborted by annotation %s", ctx.ComponentID(), label.AnnAbortVolumeReplaceKey)
+		}
+		return false, nil
+	}
+
 	// Ignore errors as they only indicate change in number of volume which replacer can handle.
 	isSynced, _ := p.utils.IsStatefulSetSynced(ctx, ctx.sts)
 
@@ -158,6 +175,106 @@ func (p *pvcReplacer) tryToRecreateSTS(ctx *componentVolumeContext) error {
 	return fmt.Errorf("waiting on recreate statefulset %s/%s for component %s", ns, name, ctx.ComponentID())
 }
 
+func isVolumeReplaceAborted(tc *v1alpha1.TidbCluster) bool {
+	_, exist := tc.Annotations[label.AnnAbortVolumeReplaceKey]
+	return exist
+}
+
+// syncStorageClassMigration records the progress of replacing volumes whose storage class is changed.
+func (p *pvcReplacer) syncStorageClassMigration(ctx *componentVolumeContext, now time.Time) {
+	if ctx.status.GetVolumes() == nil {
+		ctx.status.SetVolumes(map[v1alpha1.StorageVolumeName]*v1alpha1.StorageVolumeStatus{})
+	}
+	volumeStatus := ctx.status.GetVolumes()
+	total := len(ctx.pods)
+
+	for i := range ctx.desiredVolumes {
+		desired := &ctx.desiredVolumes[i]
+		scName := desired.GetStorageClassName()
+		if scName == "" {
+			// sc is unset, any sc is acceptable
+			continue
+		}
+		from, migrated, err := p.countMigratedVolumes(ctx, desired.Name, scName)
+		if err != nil {
+			klog.Warningf("skip to sync storage class migration of volume %s for %s: %v", desired.Name, ctx.ComponentID(), err)
+			continue
+		}
+
+		status, exist := volumeStatus[desired.Name]
+		if !exist {
+			if migrated >= total {
+				continue
+			}
+			status = &v1alpha1.StorageVolumeStatus{Name: desired.Name}
+			volumeStatus[desired.Name] = status
+		}
+
+		m := status.StorageClassMigration
+		if migrated >= total {
+			if m != nil && m.Phase != v1alpha1.StorageClassMigrationPhaseCompleted {
+				m.Phase = v1alpha1.StorageClassMigrationPhaseCompleted
+				m.TotalCount = total
+				m.MigratedCount = migrated
+				m.EstimatedCompletionTime = nil
+				m.CompletionTime = &metav1.Time{Time: now}
+				klog.Infof("storage class migration of volume %s for %s is completed", desired.Name, ctx.ComponentID())
+			}
+			continue
+		}
+
+		if m == nil || m.ToStorageClass != scName || m.Phase == v1alpha1.StorageClassMigrationPhaseCompleted {
+			m = &v1alpha1.StorageClassMigrationStatus{
+				FromStorageClass: from,
+				ToStorageClass:   scName,
+				StartTime:        &metav1.Time{Time: now},
+			}
+			status.StorageClassMigration = m
+			klog.Infof("storage class migration of volume %s for %s is started, sc (%s => %s)", desired.Name, ctx.ComponentID(), from, scName)
+		}
+		m.TotalCount = total
+		m.MigratedCount = migrated
+		m.Phase = v1alpha1.StorageClassMigrationPhaseMigrating
+		if isVolumeReplaceAborted(ctx.tc) {
+			m.Phase = v1alpha1.StorageClassMigrationPhaseAborted
+		}
+		m.EstimatedCompletionTime = nil
+		if migrated > 0 && m.StartTime != nil && m.Phase == v1alpha1.StorageClassMigrationPhaseMigrating {
+			perVolume := now.Sub(m.StartTime.Time) / time.Duration(migrated)
+			m.EstimatedCompletionTime = &metav1.Time{Time: now.Add(perVolume * time.Duration(total-migrated))}
+		}
+	}
+}
+
+// countMigratedVolumes returns the count of pods whose volume already uses the sc
+// and one of the storage classes which are still in use by other pods.
+func (p *pvcReplacer) countMigratedVolumes(ctx *componentVolumeContext, name v1alpha1.StorageVolumeName, scName string) (string, int, error) {
+	from := ""
+	migrated := 0
+	for _, pod := range ctx.pods {
+		for i := range pod.Spec.Volumes {
+			vol := &pod.Spec.Volumes[i]
+			if vol.Name != string(name) {
+				continue
+			}
+			pvc, err := p.utils.getPVC(pod.Namespace, vol)
+			if err != nil {
+				return "", 0, err
+			}
+			if pvc == nil {
+				continue
+			}
+			actual := ignoreNil(pvc.Spec.StorageClassName)
+			if actual == scName {
+				migrated++
+			} else if from == "" {
+				from = actual
+			}
+		}
+	}
+	return from, migrated, nil
+}
+
 func isVolumeReplacing(pod *corev1.Pod) bool {
 	_, exist := pod.Annotations[v1alpha1.ReplaceVolumeAnnKey]
 	return exist
@@ -193,6 +310,10 @@ func (p *pvcReplacer) tryToReplacePVC(ctx *componentVolumeContext) error {
 		if podSynced {
 			continue
 		}
+		if isVolumeReplaceAborted(ctx.tc) {
+			klog.Infof("volume replace for %s is aborted, skip pod %s", ctx.ComponentID(), pod.Name)
+			return nil
+		}
 		if err := p.startVolumeReplace(pod); err != nil {
 			return err
 		}
@@ -390,3 +390,124 @@ func TestPvcReplacerSync(t *testing.T) {
 		})
 	}
 }
+
+func TestPvcReplacerStorageClassMigration(t *testing.T) {
+	g := NewGomegaWithT(t)
+	type testcase struct {
+		name           string
+		pods           []testPod
+		abort          bool
+		expectStatus   bool
+		expectPhase    v1alpha1.StorageClassMigrationPhase
+		expectMigrated int
+		expectNoRecord bool
+	}
+	testFn := func(tt testcase, t *testing.T) {
+		deps := controller.NewFakeDependencies()
+		stop := make(chan struct{})
+		deps.KubeInformerFactory.Start(stop)
+		deps.KubeInformerFactory.WaitForCacheSync(stop)
+		defer close(stop)
+		replacer := NewPVCReplacer(deps)
+		tc := makeTcAndK8Objects(deps, g, testSts{replicas: 3, vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}}, tt.pods)
+		if tt.abort {
+			tc.Annotations = map[string]string{label.AnnAbortVolumeReplaceKey: "true"}
+		}
+		replacer.UpdateStatus(tc)
+		g.Expect(tc.Status.TiKV.VolReplaceInProgress).To(Equal(tt.expectStatus))
+
+		vs := tc.Status.TiKV.Volumes["tikv"]
+		if tt.expectNoRecord {
+			g.Expect(vs).To(BeNil())
+			return
+		}
+		g.Expect(vs).NotTo(BeNil())
+		m := vs.StorageClassMigration
+		g.Expect(m).NotTo(BeNil())
+		g.Expect(m.Phase).To(Equal(tt.expectPhase))
+		g.Expect(m.FromStorageClass).To(Equal("storageclass-2"))
+		g.Expect(m.ToStorageClass).To(Equal("storageclass-1"))
+		g.Expect(m.TotalCount).To(Equal(len(tt.pods)))
+		g.Expect(m.MigratedCount).To(Equal(tt.expectMigrated))
+		g.Expect(m.StartTime).NotTo(BeNil())
+		if tt.expectPhase == v1alpha1.StorageClassMigrationPhaseMigrating && tt.expectMigrated > 0 {
+			g.Expect(m.EstimatedCompletionTime).NotTo(BeNil())
+		} else {
+			g.Expect(m.EstimatedCompletionTime).To(BeNil())
+		}
+	}
+	tests := []testcase{
+		{
+			name: "No migration",
+			pods: []testPod{
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+			},
+			expectStatus:   false,
+			expectNoRecord: true,
+		},
+		{
+			name: "Migrating",
+			pods: []testPod{
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-2"}}},
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-2"}}},
+			},
+			expectStatus:   true,
+			expectPhase:    v1alpha1.StorageClassMigrationPhaseMigrating,
+			expectMigrated: 1,
+		},
+		{
+			name: "Aborted",
+			pods: []testPod{
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-2"}}},
+				{vols: []testVolDef{{"tikv", "1Gi", "storageclass-2"}}},
+			},
+			abort:          true,
+			expectStatus:   false,
+			expectPhase:    v1alpha1.StorageClassMigrationPhaseAborted,
+			expectMigrated: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFn(tt, t)
+		})
+	}
+}
+
+func TestPvcReplacerStorageClassMigrationCompleted(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	stop := make(chan struct{})
+	deps.KubeInformerFactory.Start(stop)
+	deps.KubeInformerFactory.WaitForCacheSync(stop)
+	defer close(stop)
+	replacer := NewPVCReplacer(deps)
+	tc := makeTcAndK8Objects(deps, g, testSts{replicas: 3, vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}}, []testPod{
+		{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+		{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+	})
+	start := metav1.NewTime(time.Now().Add(-time.Hour))
+	tc.Status.TiKV.Volumes = map[v1alpha1.StorageVolumeName]*v1alpha1.StorageVolumeStatus{
+		"tikv": {
+			Name: "tikv",
+			StorageClassMigration: &v1alpha1.StorageClassMigrationStatus{
+				Phase:            v1alpha1.StorageClassMigrationPhaseMigrating,
+				FromStorageClass: "storageclass-2",
+				ToStorageClass:   "storageclass-1",
+				TotalCount:       2,
+				MigratedCount:    1,
+				StartTime:        &start,
+			},
+		},
+	}
+	replacer.UpdateStatus(tc)
+	m := tc.Status.TiKV.Volumes["tikv"].StorageClassMigration
+	g.Expect(m.Phase).To(Equal(v1alpha1.StorageClassMigrationPhaseCompleted))
+	g.Expect(m.MigratedCount).To(Equal(2))
+	g.Expect(m.CompletionTime).NotTo(BeNil())
+	g.Expect(m.EstimatedCompletionTime).To(BeNil())
+}
@@ -0,0 +1,294 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volumes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+)
+
+const (
+	// annoKeyPVCAutoscaledStorageSize is the storage size expanded by storage autoscaling.
+	// It overrides the desired storage size in the spec if it is larger.
+	annoKeyPVCAutoscaledStorageSize = "spec.tidb.pingcap.com/autoscaled-storage-size"
+	// AnnoKeyPVCLastAutoscaleTimestamp is the time of the last expansion by storage autoscaling.
+	AnnoKeyPVCLastAutoscaleTimestamp = "status.tidb.pingcap.com/last-autoscale-timestamp"
+	// AnnoKeyPVCAutoscaleMaxSizeReached is the max size the volume has reached while its disk usage is above
+	// the threshold. It's used to warn only once when the volume reaches the max size.
+	AnnoKeyPVCAutoscaleMaxSizeReached = "status.tidb.pingcap.com/autoscale-max-size-reached"
+
+	defaultStorageAutoscalingThresholdPercent = 80
+	// storageAutoscalingCoolDown is the min interval between two expansions of a volume.
+	// It gives the store time to report the expanded capacity.
+	storageAutoscalingCoolDown = time.Minute * 10
+
+	EventReasonStorageAutoscaled                = "StorageAutoscaled"
+	EventReasonStorageAutoscalingMaxSizeReached = "StorageAutoscalingMaxSizeReached"
+	EventReasonStorageAutoscalingFailed         = "StorageAutoscalingFailed"
+)
+
+// GetStorageAutoscalingThreshold returns the disk usage percent above which the volume is expanded.
+func GetStorageAutoscalingThreshold(as *v1alpha1.StorageAutoscaling) int {
+	if as.ThresholdPercent != nil {
+		return int(*as.ThresholdPercent)
+	}
+	return defaultStorageAutoscalingThresholdPercent
+}
+
+// getStorageAutoscaling returns the autoscaling config, the data volume name and the stores of the component.
+// The autoscaling config is nil if the component does not support or enable storage autoscaling.
+func getStorageAutoscaling(tc *v1alpha1.TidbCluster, mt v1alpha1.MemberType) (*v1alpha1.StorageAutoscaling, v1alpha1.StorageVolumeName, map[string]v1alpha1.TiKVStore) {
+	switch mt {
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV == nil {
+			return nil, "", nil
+		}
+		return tc.Spec.TiKV.StorageAutoscaling, v1alpha1.GetStorageVolumeName("", mt), tc.Status.TiKV.Stores
+	case v1alpha1.TiFlashMemberType:
+		if tc.Spec.TiFlash == nil {
+			return nil, "", nil
+		}
+		return tc.Spec.TiFlash.StorageAutoscaling, v1alpha1.GetStorageVolumeNameForTiFlash(0), tc.Status.TiFlash.Stores
+	}
+	return nil, "", nil
+}
+
+// getDesiredStorageSize returns the autoscaled size of the pvc if it is larger than the desired size.
+func getDesiredStorageSize(pvc *corev1.PersistentVolumeClaim, desired *DesiredVolume) resource.Quantity {
+	size := desired.GetStorageSize()
+	s, ok := pvc.Annotations[annoKeyPVCAutoscaledStorageSize]
+	if !ok {
+		return size
+	}
+	autoscaled, err := resource.ParseQuantity(s)
+	if err != nil {
+		klog.Warningf("autoscaled storage size %q of pvc %s/%s is invalid: %v", s, pvc.Namespace, pvc.Name, err)
+		return size
+	}
+	if autoscaled.Cmp(size) > 0 {
+		return autoscaled
+	}
+	return size
+}
+
+// autoscaleStorage expands the data volumes of stores whose disk usage crosses the threshold.
+// The expanded size is recorded in the annotation of the pvc and then the volume is
+// modified by the pod volume modifier as if the storage request is changed.
+func (p *pvcModifier) autoscaleStorage(ctx *componentVolumeContext) error {
+	as, volName, stores := getStorageAutoscaling(ctx.tc, ctx.status.MemberType())
+	if as == nil {
+		return nil
+	}
+	desired := getDesiredVolumeByName(ctx.desiredVolumes, volName)
+	if desired == nil {
+		return nil
+	}
+
+	threshold := GetStorageAutoscalingThreshold(as)
+
+	usage, err := p.getStoreUsage(ctx.tc, stores)
+	if err != nil {
+		return fmt.Errorf("get disk usage of stores for %s failed: %w", ctx.ComponentID(), err)
+	}
+
+	now := time.Now()
+	status := &v1alpha1.StorageAutoscalingStatus{}
+	volStatus := ctx.status.GetVolumes()[volName]
+	if volStatus != nil && volStatus.StorageAutoscaling != nil {
+		status.LastExpansionTime = volStatus.StorageAutoscaling.LastExpansionTime
+	}
+
+	errs := []error{}
+	for _, pod := range ctx.pods {
+		pvc, err := p.getPVCOfPod(pod, volName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if pvc == nil {
+			continue
+		}
+
+		size := getDesiredStorageSize(pvc, desired)
+		isExpanded := size.Cmp(desired.Size) > 0
+		if isExpanded {
+			status.ExpandedCount++
+		}
+
+		percent, ok := usage[pod.Name]
+		if ok && percent >= threshold && size.Cmp(as.MaxSize) >= 0 {
+			status.MaxSizeReachedCount++
+			// only warn when the volume reaches the max size instead of in every reconciliation
+			if pvc.Annotations[AnnoKeyPVCAutoscaleMaxSizeReached] == as.MaxSize.String() {
+				continue
+			}
+			klog.Warningf("volume %s/%s of %s can't be expanded any more, disk usage: %d%%, max size: %s",
+				pvc.Namespace, pvc.Name, ctx.ComponentID(), percent, as.MaxSize.String())
+			p.deps.Recorder.Eventf(ctx.tc, corev1.EventTypeWarning, EventReasonStorageAutoscalingMaxSizeReached,
+				"volume %s of pod %s reaches the max size %s, disk usage: %d%%", volName, pod.Name, as.MaxSize.String(), percent)
+			if err := p.patchPVCAnnotations(ctx, pvc, map[string]interface{}{
+				AnnoKeyPVCAutoscaleMaxSizeReached: as.MaxSize.String(),
+			}); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if _, reached := pvc.Annotations[AnnoKeyPVCAutoscaleMaxSizeReached]; reached {
+			// the volume can be expanded again or its disk usage drops below the threshold
+			if err := p.patchPVCAnnotations(ctx, pvc, map[string]interface{}{
+				AnnoKeyPVCAutoscaleMaxSizeReached: nil,
+			}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		if !ok || percent < threshold {
+			continue
+		}
+
+		if !IsStorageAutoscaleCooledDown(pvc, now) {
+			klog.V(4).Infof("volume %s/%s of %s was expanded recently, skip it", pvc.Namespace, pvc.Name, ctx.ComponentID())
+			continue
+		}
+		// wait until the last expansion is finished
+		capacity := getStorageSize(pvc.Status.Capacity)
+		if capacity.Cmp(size) < 0 {
+			klog.V(4).Infof("volume %s/%s of %s is still expanding to %s", pvc.Namespace, pvc.Name, ctx.ComponentID(), size.String())
+			continue
+		}
+
+		newSize := size.DeepCopy()
+		newSize.Add(as.Step)
+		if newSize.Cmp(as.MaxSize) > 0 {
+			newSize = as.MaxSize.DeepCopy()
+		}
+
+		if err := p.setAutoscaledStorageSize(ctx, pvc, newSize, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		klog.Infof("expand volume %s/%s of %s from %s to %s, disk usage: %d%%",
+			pvc.Namespace, pvc.Name, ctx.ComponentID(), size.String(), newSize.String(), percent)
+		p.deps.Recorder.Eventf(ctx.tc, corev1.EventTypeNormal, EventReasonStorageAutoscaled,
+			"expand volume %s of pod %s from %s to %s, disk usage: %d%%", volName, pod.Name, size.String(), newSize.String(), percent)
+
+		if !isExpanded {
+			status.ExpandedCount++
+		}
+		status.LastExpansionTime = &metav1.Time{Time: now}
+	}
+
+	if volStatus != nil {
+		volStatus.StorageAutoscaling = status
+	}
+
+	return errutil.NewAggregate(errs)
+}
+
+// getStoreUsage returns the disk usage percent of stores, keyed by the pod name.
+func (p *pvcModifier) getStoreUsage(tc *v1alpha1.TidbCluster, stores map[string]v1alpha1.TiKVStore) (map[string]int, error) {
+	info, err := controller.GetPDClient(p.deps.PDControl, tc).GetStores()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := map[string]int{}
+	for _, s := range info.Stores {
+		if s.Store == nil || s.Status == nil || s.Status.Capacity == 0 {
+			continue
+		}
+		store, ok := stores[strconv.FormatUint(s.Store.Id, 10)]
+		if !ok {
+			continue
+		}
+		capacity := uint64(s.Status.Capacity)
+		available := uint64(s.Status.Available)
+		if available > capacity {
+			available = capacity
+		}
+		usage[store.PodName] = int((capacity - available) * 100 / capacity)
+	}
+
+	return usage, nil
+}
+
+func (p *pvcModifier) getPVCOfPod(pod *corev1.Pod, volName v1alpha1.StorageVolumeName) (*corev1.PersistentVolumeClaim, error) {
+	for i := range pod.Spec.Volumes {
+		vol := &pod.Spec.Volumes[i]
+		if vol.Name != string(volName) {
+			continue
+		}
+		return p.utils.getPVC(pod.Namespace, vol)
+	}
+	return nil, nil
+}
+
+func (p *pvcModifier) setAutoscaledStorageSize(ctx context.Context, pvc *corev1.PersistentVolumeClaim, size resource.Quantity, now time.Time) error {
+	return p.patchPVCAnnotations(ctx, pvc, map[string]interface{}{
+		annoKeyPVCAutoscaledStorageSize:  size.String(),
+		AnnoKeyPVCLastAutoscaleTimestamp: now.Format(time.RFC3339),
+	})
+}
+
+func (p *pvcModifier) patchPVCAnnotations(ctx context.Context, pvc *corev1.PersistentVolumeClaim, annotations map[string]interface{}) error {
+	return PatchPVCAnnotations(ctx, p.deps.KubeClientset, pvc, annotations)
+}
+
+// PatchPVCAnnotations sets the annotations of the pvc, the annotation whose value is nil is removed.
+func PatchPVCAnnotations(ctx context.Context, cli kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, annotations map[string]interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := cli.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("patch annotations of pvc %s/%s failed: %w", pvc.Namespace, pvc.Name, err)
+	}
+
+	return nil
+}
+
+// IsStorageAutoscaleCooledDown returns whether the volume can be expanded again since the last expansion.
+func IsStorageAutoscaleCooledDown(pvc *corev1.PersistentVolumeClaim, now time.Time) bool {
+	s, ok := pvc.Annotations[AnnoKeyPVCLastAutoscaleTimestamp]
+	if !ok {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		klog.Warningf("last autoscale timestamp %q of pvc %s/%s is invalid: %v", s, pvc.Namespace, pvc.Name, err)
+		return true
+	}
+	return now.Sub(t) >= storageAutoscalingCoolDown
+}
@@ -0,0 +1,330 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volumes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/tikv/pd/pkg/typeutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+)
+
+func TestStorageAutoscaling(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recent := time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+	cases := []struct {
+		desc      string
+		step      string
+		maxSize   string
+		usedBytes uint64
+		anno      map[string]string
+		capacity  string
+
+		expectedSize          string
+		expectedExpandedCount int
+		expectedMaxSizeCount  int
+	}{
+		{
+			desc:      "disk usage is below the threshold",
+			step:      "1Gi",
+			maxSize:   "10Gi",
+			usedBytes: 50,
+			capacity:  "1Gi",
+
+			expectedSize: "",
+		},
+		{
+			desc:      "disk usage crosses the threshold",
+			step:      "1Gi",
+			maxSize:   "10Gi",
+			usedBytes: 90,
+			capacity:  "1Gi",
+
+			expectedSize:          "2Gi",
+			expectedExpandedCount: 1,
+		},
+		{
+			desc:      "expanded size is limited by the max size",
+			step:      "5Gi",
+			maxSize:   "3Gi",
+			usedBytes: 90,
+			capacity:  "1Gi",
+
+			expectedSize:          "3Gi",
+			expectedExpandedCount: 1,
+		},
+		{
+			desc:      "volume reaches the max size",
+			step:      "1Gi",
+			maxSize:   "2Gi",
+			usedBytes: 90,
+			anno: map[string]string{
+				annoKeyPVCAutoscaledStorageSize: "2Gi",
+			},
+			capacity: "2Gi",
+
+			expectedSize:          "2Gi",
+			expectedExpandedCount: 1,
+			expectedMaxSizeCount:  1,
+		},
+		{
+			desc:      "volume was expanded recently",
+			step:      "1Gi",
+			maxSize:   "10Gi",
+			usedBytes: 90,
+			anno: map[string]string{
+				annoKeyPVCAutoscaledStorageSize:  "2Gi",
+				AnnoKeyPVCLastAutoscaleTimestamp: recent,
+			},
+			capacity: "2Gi",
+
+			expectedSize:          "2Gi",
+			expectedExpandedCount: 1,
+		},
+		{
+			desc:      "last expansion is not finished",
+			step:      "1Gi",
+			maxSize:   "10Gi",
+			usedBytes: 90,
+			anno: map[string]string{
+				annoKeyPVCAutoscaledStorageSize: "2Gi",
+			},
+			capacity: "1Gi",
+
+			expectedSize:          "2Gi",
+			expectedExpandedCount: 1,
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+		deps := controller.NewFakeDependencies()
+		stop := make(chan struct{})
+		deps.KubeInformerFactory.Start(stop)
+		deps.KubeInformerFactory.WaitForCacheSync(stop)
+
+		as := &v1alpha1.StorageAutoscaling{
+			Step:    resource.MustParse(c.step),
+			MaxSize: resource.MustParse(c.maxSize),
+		}
+		tc := prepareStorageAutoscaling(g, deps, as, c.anno, c.capacity, &c.usedBytes)
+
+		pm := NewPVCModifier(deps).(*pvcModifier)
+		ctx, err := pm.utils.BuildContextForTC(tc, &tc.Status.TiKV)
+		g.Expect(err).Should(Succeed(), c.desc)
+		g.Expect(pm.autoscaleStorage(ctx)).Should(Succeed(), c.desc)
+
+		result, err := deps.KubeClientset.CoreV1().PersistentVolumeClaims(tc.Namespace).Get(context.TODO(), "tikv-test-cluster-tikv-0", metav1.GetOptions{})
+		g.Expect(err).Should(Succeed(), c.desc)
+		g.Expect(result.Annotations[annoKeyPVCAutoscaledStorageSize]).Should(Equal(c.expectedSize), c.desc)
+
+		status := tc.Status.TiKV.Volumes["tikv"].StorageAutoscaling
+		g.Expect(status).ShouldNot(BeNil(), c.desc)
+		g.Expect(status.ExpandedCount).Should(Equal(c.expectedExpandedCount), c.desc)
+		g.Expect(status.MaxSizeReachedCount).Should(Equal(c.expectedMaxSizeCount), c.desc)
+
+		close(stop)
+	}
+}
+
+func TestStorageAutoscalingMaxSizeReached(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	stop := make(chan struct{})
+	defer close(stop)
+	deps.KubeInformerFactory.Start(stop)
+	deps.KubeInformerFactory.WaitForCacheSync(stop)
+	events := deps.Recorder.(*record.FakeRecorder).Events
+
+	as := &v1alpha1.StorageAutoscaling{
+		Step:    resource.MustParse("1Gi"),
+		MaxSize: resource.MustParse("2Gi"),
+	}
+	usedBytes := uint64(90)
+	tc := prepareStorageAutoscaling(g, deps, as, map[string]string{annoKeyPVCAutoscaledStorageSize: "2Gi"}, "2Gi", &usedBytes)
+	pm := NewPVCModifier(deps).(*pvcModifier)
+	sync := func() {
+		ctx, err := pm.utils.BuildContextForTC(tc, &tc.Status.TiKV)
+		g.Expect(err).Should(Succeed())
+		g.Expect(pm.autoscaleStorage(ctx)).Should(Succeed())
+	}
+	waitForAnnotation := func(expected string) {
+		g.Eventually(func() string {
+			pvc, err := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("tikv-test-cluster-tikv-0")
+			g.Expect(err).Should(Succeed())
+			return pvc.Annotations[AnnoKeyPVCAutoscaleMaxSizeReached]
+		}, testMatchTimeout, testMatchInterval).Should(Equal(expected))
+	}
+
+	// the warning is only recorded when the volume reaches the max size
+	sync()
+	g.Expect(events).Should(HaveLen(1))
+	g.Expect(<-events).Should(ContainSubstring(EventReasonStorageAutoscalingMaxSizeReached))
+	waitForAnnotation("2Gi")
+	sync()
+	g.Expect(events).Should(BeEmpty())
+	g.Expect(tc.Status.TiKV.Volumes["tikv"].StorageAutoscaling.MaxSizeReachedCount).Should(Equal(1))
+
+	// the state is reset after the disk usage drops below the threshold
+	usedBytes = 50
+	sync()
+	waitForAnnotation("")
+	g.Expect(tc.Status.TiKV.Volumes["tikv"].StorageAutoscaling.MaxSizeReachedCount).Should(Equal(0))
+
+	usedBytes = 90
+	sync()
+	g.Expect(events).Should(HaveLen(1))
+}
+
+// prepareStorageAutoscaling returns a tidb cluster with one tikv store whose disk usage is `usedBytes` percent
+func prepareStorageAutoscaling(g *GomegaWithT, deps *controller.Dependencies, as *v1alpha1.StorageAutoscaling,
+	anno map[string]string, capacity string, usedBytes *uint64) *v1alpha1.TidbCluster {
+	tc := makeTcAndK8Objects(deps, g, testSts{replicas: 1, vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}}, []testPod{
+		{vols: []testVolDef{{"tikv", "1Gi", "storageclass-1"}}},
+	})
+	tc.Spec.TiKV.StorageAutoscaling = as
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-cluster-tikv-0"},
+	}
+	tc.Status.TiKV.Volumes = map[v1alpha1.StorageVolumeName]*v1alpha1.StorageVolumeStatus{
+		"tikv": {Name: "tikv"},
+	}
+
+	pvc, err := deps.KubeClientset.CoreV1().PersistentVolumeClaims(tc.Namespace).Get(context.TODO(), "tikv-test-cluster-tikv-0", metav1.GetOptions{})
+	g.Expect(err).Should(Succeed())
+	pvc.Annotations = anno
+	pvc.Status.Capacity = corev1.ResourceList{
+		corev1.ResourceStorage: resource.MustParse(capacity),
+	}
+	_, err = deps.KubeClientset.CoreV1().PersistentVolumeClaims(tc.Namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
+	g.Expect(err).Should(Succeed())
+	g.Eventually(func() string {
+		pvc, err := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get(pvc.Name)
+		g.Expect(err).Should(Succeed())
+		capacity := getStorageSize(pvc.Status.Capacity)
+		return capacity.String()
+	}, testMatchTimeout, testMatchInterval).Should(Equal(capacity))
+
+	pdClient := pdapi.NewFakePDClient()
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{
+			Count: 1,
+			Stores: []*pdapi.StoreInfo{
+				{
+					Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 1}},
+					Status: &pdapi.StoreStatus{
+						Capacity:  typeutil.ByteSize(100),
+						Available: typeutil.ByteSize(100 - *usedBytes),
+					},
+				},
+			},
+		}, nil
+	})
+	deps.PDControl.(*pdapi.FakePDControl).SetPDClient(pdapi.Namespace(tc.Namespace), tc.Name, pdClient)
+
+	return tc
+}
+
+func TestGetDesiredStorageSize(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	desired := &DesiredVolume{
+		Name:             "tikv",
+		Size:             resource.MustParse("2Gi"),
+		StorageClassName: pointer.StringPtr("sc"),
+	}
+
+	cases := []struct {
+		anno     map[string]string
+		expected string
+	}{
+		{
+			anno:     nil,
+			expected: "2Gi",
+		},
+		{
+			anno:     map[string]string{annoKeyPVCAutoscaledStorageSize: "3Gi"},
+			expected: "3Gi",
+		},
+		{
+			// spec is changed to a larger size than the autoscaled size
+			anno:     map[string]string{annoKeyPVCAutoscaledStorageSize: "1Gi"},
+			expected: "2Gi",
+		},
+		{
+			anno:     map[string]string{annoKeyPVCAutoscaledStorageSize: "invalid"},
+			expected: "2Gi",
+		},
+	}
+
+	for _, c := range cases {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: c.anno,
+			},
+		}
+		size := getDesiredStorageSize(pvc, desired)
+		g.Expect(size.String()).Should(Equal(c.expected))
+	}
+}
+
+func TestStorageAutoscalingFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	stop := make(chan struct{})
+	defer close(stop)
+	deps.KubeInformerFactory.Start(stop)
+	deps.KubeInformerFactory.WaitForCacheSync(stop)
+	events := deps.Recorder.(*record.FakeRecorder).Events
+
+	as := &v1alpha1.StorageAutoscaling{
+		Step:    resource.MustParse("1Gi"),
+		MaxSize: resource.MustParse("10Gi"),
+	}
+	usedBytes := uint64(90)
+	tc := prepareStorageAutoscaling(g, deps, as, nil, "1Gi", &usedBytes)
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	pdClient := pdapi.NewFakePDClient()
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return nil, fmt.Errorf("pd is unreachable")
+	})
+	deps.PDControl.(*pdapi.FakePDControl).SetPDClient(pdapi.Namespace(tc.Namespace), tc.Name, pdClient)
+
+	// the volumes are still modified as the spec if the autoscaling fails, which reaches the lookup of the pv
+	pm := NewPVCModifier(deps).(*pvcModifier)
+	ctx, err := pm.utils.BuildContextForTC(tc, &tc.Status.TiKV)
+	g.Expect(err).Should(Succeed())
+	err = pm.modifyVolumes(ctx)
+	g.Expect(err).Should(MatchError(ContainSubstring("persistentvolume")))
+	g.Expect(err).ShouldNot(MatchError(ContainSubstring("pd is unreachable")))
+	g.Expect(events).Should(HaveLen(1))
+	g.Expect(<-events).Should(ContainSubstring(EventReasonStorageAutoscalingFailed + " autoscale storage for"))
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volumes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// See https://kubernetes.io/docs/reference/kubernetes-api/config-and-storage-resources/persistent-volume-claim-v1/#PersistentVolumeClaimStatus
+	modifyVolumeStatusPending    = "Pending"
+	modifyVolumeStatusInProgress = "InProgress"
+	modifyVolumeStatusInfeasible = "Infeasible"
+)
+
+// VolumeAttributes is the volume attributes class state of a pvc.
+type VolumeAttributes struct {
+	// SpecName is `spec.volumeAttributesClassName`
+	SpecName string
+	// CurrentName is `status.currentVolumeAttributesClassName`
+	CurrentName string
+	// TargetName is `status.modifyVolumeStatus.targetVolumeAttributesClassName`
+	TargetName string
+	// ModifyStatus is `status.modifyVolumeStatus.status`, it is empty if no modification is in progress
+	ModifyStatus string
+}
+
+// VolumeAttributesClassClient reads and writes fields of pvc which are introduced by VolumeAttributesClass.
+// These fields are unknown to the typed pvc, so they are accessed by unstructured objects.
+type VolumeAttributesClassClient interface {
+	Get(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (*VolumeAttributes, error)
+	Set(ctx context.Context, pvc *corev1.PersistentVolumeClaim, name string) error
+}
+
+type volumeAttributesClassClient struct {
+	cli client.Client
+}
+
+func NewVolumeAttributesClassClient(cli client.Client) VolumeAttributesClassClient {
+	return &volumeAttributesClassClient{
+		cli: cli,
+	}
+}
+
+func newUnstructuredPVC() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+	return u
+}
+
+func (c *volumeAttributesClassClient) Get(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (*VolumeAttributes, error) {
+	u := newUnstructuredPVC()
+	if err := c.cli.Get(ctx, client.ObjectKey{Namespace: pvc.Namespace, Name: pvc.Name}, u); err != nil {
+		return nil, fmt.Errorf("get pvc %s/%s failed: %w", pvc.Namespace, pvc.Name, err)
+	}
+
+	attrs := &VolumeAttributes{}
+	attrs.SpecName, _, _ = unstructured.NestedString(u.Object, "spec", "volumeAttributesClassName")
+	attrs.CurrentName, _, _ = unstructured.NestedString(u.Object, "status", "currentVolumeAttributesClassName")
+	attrs.TargetName, _, _ = unstructured.NestedString(u.Object, "status", "modifyVolumeStatus", "targetVolumeAttributesClassName")
+	attrs.ModifyStatus, _, _ = unstructured.NestedString(u.Object, "status", "modifyVolumeStatus", "status")
+
+	return attrs, nil
+}
+
+func (c *volumeAttributesClassClient) Set(ctx context.Context, pvc *corev1.PersistentVolumeClaim, name string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"volumeAttributesClassName": name,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	u := newUnstructuredPVC()
+	u.SetNamespace(pvc.Namespace)
+	u.SetName(pvc.Name)
+	if err := c.cli.Patch(ctx, u, client.RawPatch(types.MergePatchType, data)); err != nil {
+		return fmt.Errorf("set volume attributes class of pvc %s/%s to %s failed: %w", pvc.Namespace, pvc.Name, name, err)
+	}
+
+	return nil
+}
+
+type FakeVolumeAttributesClassClient struct {
+	Attrs map[string]*VolumeAttributes
+}
+
+func NewFakeVolumeAttributesClassClient() *FakeVolumeAttributesClassClient {
+	return &FakeVolumeAttributesClassClient{
+		Attrs: map[string]*VolumeAttributes{},
+	}
+}
+
+func (c *FakeVolumeAttributesClassClient) Get(_ context.Context, pvc *corev1.PersistentVolumeClaim) (*VolumeAttributes, error) {
+	attrs, ok := c.Attrs[pvc.Namespace+"/"+pvc.Name]
+	if !ok {
+		return &VolumeAttributes{}, nil
+	}
+	return attrs, nil
+}
+
+func (c *FakeVolumeAttributesClassClient) Set(_ context.Context, pvc *corev1.PersistentVolumeClaim, name string) error {
+	key := pvc.Namespace + "/" + pvc.Name
+	attrs, ok := c.Attrs[key]
+	if !ok {
+		attrs = &VolumeAttributes{}
+		c.Attrs[key] = attrs
+	}
+	attrs.SpecName = name
+	attrs.TargetName = name
+	attrs.ModifyStatus = modifyVolumeStatusPending
+	return nil
+}
@@ -38,6 +38,8 @@ type DesiredVolume struct {
 	// it is sc name specified by user
 	// the sc may not exist
 	StorageClassName *string
+	// it is vac name specified by user
+	VolumeAttributesClassName *string
 }
 
 // get storage class name from tc
@@ -53,6 +55,15 @@ func (v *DesiredVolume) GetStorageSize() resource.Quantity {
 	return v.Size
 }
 
+// get volume attributes class name from tc
+// it may return empty because vac is unset
+func (v *DesiredVolume) GetVolumeAttributesClassName() string {
+	if v.VolumeAttributesClassName == nil {
+		return ""
+	}
+	return *v.VolumeAttributesClassName
+}
+
 type volCompareUtils struct {
 	deps *controller.Dependencies
 	sf   *selectorFactory
@@ -203,7 +214,8 @@ func (u *volCompareUtils) IsPodSyncedForReplacement(ctx *componentVolumeContext,
 			return false, nil
 		}
 		pvcQuantity := getStorageSize(pvc.Spec.Resources.Requests)
-		if pvcQuantity.Cmp(desired.Size) != 0 {
+		desiredQuantity := getDesiredStorageSize(pvc, desired)
+		if pvcQuantity.Cmp(desiredQuantity) != 0 {
 			// Sizes don't match.
 			return false, nil
 		}
@@ -222,16 +234,20 @@ func (u *volCompareUtils) GetDesiredVolumes(tc *v1alpha1.TidbCluster, mt v1alpha
 
 	storageVolumes := []v1alpha1.StorageVolume{}
 	var defaultScName *string
+	var defaultVacName *string
 	switch mt {
 	case v1alpha1.TiProxyMemberType:
 		defaultScName = tc.Spec.TiProxy.StorageClassName
+		defaultVacName = tc.Spec.TiProxy.VolumeAttributesClassName
 		storageVolumes = tc.Spec.TiProxy.StorageVolumes
 	case v1alpha1.PDMemberType:
 		defaultScName = tc.Spec.PD.StorageClassName
+		defaultVacName = tc.Spec.PD.VolumeAttributesClassName
 		d := DesiredVolume{
-			Name:             v1alpha1.GetStorageVolumeName("", mt),
-			Size:             getStorageSize(tc.Spec.PD.Requests),
-			StorageClassName: defaultScName,
+			Name:                      v1alpha1.GetStorageVolumeName("", mt),
+			Size:                      getStorageSize(tc.Spec.PD.Requests),
+			StorageClassName:          defaultScName,
+			VolumeAttributesClassName: defaultVacName,
 		}
 		desiredVolumes = append(desiredVolumes, d)
 
@@ -246,14 +262,17 @@ func (u *volCompareUtils) GetDesiredVolumes(tc *v1alpha1.TidbCluster, mt v1alpha
 		}
 	case v1alpha1.TiDBMemberType:
 		defaultScName = tc.Spec.TiDB.StorageClassName
+		defaultVacName = tc.Spec.TiDB.VolumeAttributesClassName
 		storageVolumes = tc.Spec.TiDB.StorageVolumes
 
 	case v1alpha1.TiKVMemberType:
 		defaultScName = tc.Spec.TiKV.StorageClassName
+		defaultVacName = tc.Spec.TiKV.VolumeAttributesClassName
 		d := DesiredVolume{
-			Name:             v1alpha1.GetStorageVolumeName("", mt),
-			Size:             getStorageSize(tc.Spec.TiKV.Requests),
-			StorageClassName: defaultScName,
+			Name:                      v1alpha1.GetStorageVolumeName("", mt),
+			Size:                      getStorageSize(tc.Spec.TiKV.Requests),
+			StorageClassName:          defaultScName,
+			VolumeAttributesClassName: defaultVacName,
 		}
 		desiredVolumes = append(desiredVolumes, d)
 
@@ -262,23 +281,27 @@ func (u *volCompareUtils) GetDesiredVolumes(tc *v1alpha1.TidbCluster, mt v1alpha
 	case v1alpha1.TiFlashMemberType:
 		for i, claim := range tc.Spec.TiFlash.StorageClaims {
 			d := DesiredVolume{
-				Name:             v1alpha1.GetStorageVolumeNameForTiFlash(i),
-				Size:             getStorageSize(claim.Resources.Requests),
-				StorageClassName: claim.StorageClassName,
+				Name:                      v1alpha1.GetStorageVolumeNameForTiFlash(i),
+				Size:                      getStorageSize(claim.Resources.Requests),
+				StorageClassName:          claim.StorageClassName,
+				VolumeAttributesClassName: claim.VolumeAttributesClassName,
 			}
 			desiredVolumes = append(desiredVolumes, d)
 		}
 
 	case v1alpha1.TiCDCMemberType:
 		defaultScName = tc.Spec.TiCDC.StorageClassName
+		defaultVacName = tc.Spec.TiCDC.VolumeAttributesClassName
 		storageVolumes = tc.Spec.TiCDC.StorageVolumes
 
 	case v1alpha1.PumpMemberType:
 		defaultScName = tc.Spec.Pump.StorageClassName
+		defaultVacName = tc.Spec.Pump.VolumeAttributesClassName
 		d := DesiredVolume{
-			Name:             v1alpha1.GetStorageVolumeName("", mt),
-			Size:             getStorageSize(tc.Spec.Pump.Requests),
-			StorageClassName: defaultScName,
+			Name:                      v1alpha1.GetStorageVolumeName("", mt),
+			Size:                      getStorageSize(tc.Spec.Pump.Requests),
+			StorageClassName:          defaultScName,
+			VolumeAttributesClassName: defaultVacName,
 		}
 		desiredVolumes = append(desiredVolumes, d)
 	default:
@@ -288,13 +311,17 @@ func (u *volCompareUtils) GetDesiredVolumes(tc *v1alpha1.TidbCluster, mt v1alpha
 	for _, sv := range storageVolumes {
 		if quantity, err := resource.ParseQuantity(sv.StorageSize); err == nil {
 			d := DesiredVolume{
-				Name:             v1alpha1.GetStorageVolumeName(sv.Name, mt),
-				Size:             quantity,
-				StorageClassName: sv.StorageClassName,
+				Name:                      v1alpha1.GetStorageVolumeName(sv.Name, mt),
+				Size:                      quantity,
+				StorageClassName:          sv.StorageClassName,
+				VolumeAttributesClassName: sv.VolumeAttributesClassName,
 			}
 			if d.StorageClassName == nil {
 				d.StorageClassName = defaultScName
 			}
+			if d.VolumeAttributesClassName == nil {
+				d.VolumeAttributesClassName = defaultVacName
+			}
 
 			desiredVolumes = append(desiredVolumes, d)
 
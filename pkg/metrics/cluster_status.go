@@ -0,0 +1,339 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	LabelState  = "state"
+	LabelPhase  = "phase"
+	LabelPod    = "pod"
+	LabelVolume = "volume"
+	LabelSecret = "secret"
+)
+
+var (
+	clusterComponentReadyReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "component_ready_replicas"),
+		"Ready replicas of each component observed in TidbCluster status",
+		[]string{LabelNamespace, LabelName, LabelComponent}, nil)
+	clusterComponentUpgradingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "component_upgrading"),
+		"Whether each component of TidbCluster is being upgraded, 1 for upgrading",
+		[]string{LabelNamespace, LabelName, LabelComponent}, nil)
+	clusterComponentPhaseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "component_phase"),
+		"Current phase of each component of TidbCluster, the value of the current phase is 1",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelPhase}, nil)
+	clusterStoresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "stores"),
+		"Number of TiKV and TiFlash stores in each state observed in TidbCluster status",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelState}, nil)
+	clusterFailureMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "failure_members"),
+		"Number of failure members or stores of each component recorded for failover",
+		[]string{LabelNamespace, LabelName, LabelComponent}, nil)
+	clusterVolumeReplacingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "volume_replacing"),
+		"Whether volumes of each component of TidbCluster are being replaced, 1 for replacing",
+		[]string{LabelNamespace, LabelName, LabelComponent}, nil)
+	clusterVolumePendingModifyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "volume_pending_modify"),
+		"Number of volumes which are not modified to the desired spec yet",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelVolume}, nil)
+	clusterEvictLeaderDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "evict_leader_duration_seconds"),
+		"Duration since the operator began to evict leaders from each TiKV pod",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelPod}, nil)
+	clusterCertificateExpirationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "cluster", "certificate_expiration_timestamp_seconds"),
+		"Expiration time of the CA and the certificates issued by the internal CA of TidbCluster",
+		[]string{LabelNamespace, LabelName, LabelSecret}, nil)
+
+	backupPhaseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "backup", "phase"),
+		"Current phase of each Backup, the value of the current phase is 1",
+		[]string{LabelNamespace, LabelName, LabelPhase}, nil)
+	backupDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "backup", "duration_seconds"),
+		"Duration of each Backup, it keeps increasing until the Backup is completed",
+		[]string{LabelNamespace, LabelName}, nil)
+	backupLogCheckpointDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "backup", "log_checkpoint_timestamp_seconds"),
+		"Checkpoint time of each log Backup",
+		[]string{LabelNamespace, LabelName}, nil)
+	backupScheduleLastBackupDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "backup_schedule", "last_backup_timestamp_seconds"),
+		"Time of the last backup of each BackupSchedule, or its creation time if no backup is created yet",
+		[]string{LabelNamespace, LabelName}, nil)
+	backupScheduleIntervalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "backup_schedule", "interval_seconds"),
+		"Interval between the last backup and the next scheduled run of each BackupSchedule",
+		[]string{LabelNamespace, LabelName}, nil)
+	restorePhaseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "restore", "phase"),
+		"Current phase of each Restore, the value of the current phase is 1",
+		[]string{LabelNamespace, LabelName, LabelPhase}, nil)
+	restoreDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("tidb_operator", "restore", "duration_seconds"),
+		"Duration of each Restore, it keeps increasing until the Restore is completed",
+		[]string{LabelNamespace, LabelName}, nil)
+)
+
+// StatusCollector exports the status of TidbCluster, Backup, BackupSchedule
+// and Restore observed by the controller-manager. Metrics are built from the
+// informer cache on every scrape, so series of deleted objects disappear with them.
+type StatusCollector struct {
+	tcLister             listers.TidbClusterLister
+	backupLister         listers.BackupLister
+	backupScheduleLister listers.BackupScheduleLister
+	restoreLister        listers.RestoreLister
+
+	now func() time.Time
+}
+
+// NewStatusCollector returns a StatusCollector reading from the given listers.
+func NewStatusCollector(
+	tcLister listers.TidbClusterLister,
+	backupLister listers.BackupLister,
+	backupScheduleLister listers.BackupScheduleLister,
+	restoreLister listers.RestoreLister,
+) *StatusCollector {
+	return &StatusCollector{
+		tcLister:             tcLister,
+		backupLister:         backupLister,
+		backupScheduleLister: backupScheduleLister,
+		restoreLister:        restoreLister,
+		now:                  time.Now,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *StatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterComponentReadyReplicasDesc
+	ch <- clusterComponentUpgradingDesc
+	ch <- clusterComponentPhaseDesc
+	ch <- clusterStoresDesc
+	ch <- clusterFailureMembersDesc
+	ch <- clusterVolumeReplacingDesc
+	ch <- clusterVolumePendingModifyDesc
+	ch <- clusterEvictLeaderDurationDesc
+	ch <- clusterCertificateExpirationDesc
+	ch <- backupPhaseDesc
+	ch <- backupDurationDesc
+	ch <- backupLogCheckpointDesc
+	ch <- backupScheduleLastBackupDesc
+	ch <- backupScheduleIntervalDesc
+	ch <- restorePhaseDesc
+	ch <- restoreDurationDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *StatusCollector) Collect(ch chan<- prometheus.Metric) {
+	now := c.now()
+
+	tcs, err := c.tcLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list TidbClusters for metrics: %v", err)
+	}
+	for _, tc := range tcs {
+		c.collectTidbCluster(ch, tc, now)
+	}
+
+	backups, err := c.backupLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list Backups for metrics: %v", err)
+	}
+	for _, backup := range backups {
+		labelValues := []string{backup.Namespace, backup.Name}
+		if backup.Status.Phase != "" {
+			ch <- prometheus.MustNewConstMetric(backupPhaseDesc, prometheus.GaugeValue, 1,
+				append(labelValues, string(backup.Status.Phase))...)
+		}
+		if d, ok := getDuration(backup.Status.TimeStarted, backup.Status.TimeCompleted, now); ok {
+			ch <- prometheus.MustNewConstMetric(backupDurationDesc, prometheus.GaugeValue, d, labelValues...)
+		}
+		if backup.Spec.Mode == v1alpha1.BackupModeLog && backup.Status.LogCheckpointTs != "" {
+			ts, err := strconv.ParseUint(backup.Status.LogCheckpointTs, 10, 64)
+			if err != nil {
+				klog.Warningf("invalid checkpoint ts %s of log backup %s/%s: %v", backup.Status.LogCheckpointTs, backup.Namespace, backup.Name, err)
+				continue
+			}
+			// the physical part of the TSO is in milliseconds
+			ch <- prometheus.MustNewConstMetric(backupLogCheckpointDesc, prometheus.GaugeValue,
+				float64(ts>>18)/1000, labelValues...)
+		}
+	}
+
+	schedules, err := c.backupScheduleLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list BackupSchedules for metrics: %v", err)
+	}
+	for _, bs := range schedules {
+		if bs.Spec.Pause || bs.Spec.Schedule == "" {
+			continue
+		}
+		sched, err := cron.ParseStandard(bs.Spec.Schedule)
+		if err != nil {
+			klog.Warningf("invalid schedule %s of backup schedule %s/%s: %v", bs.Spec.Schedule, bs.Namespace, bs.Name, err)
+			continue
+		}
+		last := bs.CreationTimestamp.Time
+		if bs.Status.LastBackupTime != nil {
+			last = bs.Status.LastBackupTime.Time
+		}
+		labelValues := []string{bs.Namespace, bs.Name}
+		ch <- prometheus.MustNewConstMetric(backupScheduleLastBackupDesc, prometheus.GaugeValue,
+			float64(last.Unix()), labelValues...)
+		ch <- prometheus.MustNewConstMetric(backupScheduleIntervalDesc, prometheus.GaugeValue,
+			sched.Next(last).Sub(last).Seconds(), labelValues...)
+	}
+
+	restores, err := c.restoreLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list Restores for metrics: %v", err)
+	}
+	for _, restore := range restores {
+		labelValues := []string{restore.Namespace, restore.Name}
+		if restore.Status.Phase != "" {
+			ch <- prometheus.MustNewConstMetric(restorePhaseDesc, prometheus.GaugeValue, 1,
+				append(labelValues, string(restore.Status.Phase))...)
+		}
+		if d, ok := getDuration(restore.Status.TimeStarted, restore.Status.TimeCompleted, now); ok {
+			ch <- prometheus.MustNewConstMetric(restoreDurationDesc, prometheus.GaugeValue, d, labelValues...)
+		}
+	}
+}
+
+func (c *StatusCollector) collectTidbCluster(ch chan<- prometheus.Metric, tc *v1alpha1.TidbCluster, now time.Time) {
+	for _, status := range tc.AllComponentStatus() {
+		component := status.MemberType().String()
+		labelValues := []string{tc.Namespace, tc.Name, component}
+
+		if sts := status.GetStatefulSet(); sts != nil {
+			ch <- prometheus.MustNewConstMetric(clusterComponentReadyReplicasDesc, prometheus.GaugeValue,
+				float64(sts.ReadyReplicas), labelValues...)
+		}
+		if phase := status.GetPhase(); phase != "" {
+			ch <- prometheus.MustNewConstMetric(clusterComponentPhaseDesc, prometheus.GaugeValue, 1,
+				append(labelValues, string(phase))...)
+		}
+		ch <- prometheus.MustNewConstMetric(clusterComponentUpgradingDesc, prometheus.GaugeValue,
+			boolToFloat64(status.GetPhase() == v1alpha1.UpgradePhase), labelValues...)
+		ch <- prometheus.MustNewConstMetric(clusterVolumeReplacingDesc, prometheus.GaugeValue,
+			boolToFloat64(status.GetVolReplaceInProgress()), labelValues...)
+		for name, vol := range status.GetVolumes() {
+			pending := vol.BoundCount - vol.ModifiedCount
+			if pending < 0 {
+				pending = 0
+			}
+			ch <- prometheus.MustNewConstMetric(clusterVolumePendingModifyDesc, prometheus.GaugeValue,
+				float64(pending), append(labelValues, string(name))...)
+		}
+	}
+
+	failureMembers := map[v1alpha1.MemberType]int{}
+	if tc.Spec.PD != nil {
+		failureMembers[v1alpha1.PDMemberType] = len(tc.Status.PD.FailureMembers)
+	}
+	if tc.Spec.TiDB != nil {
+		failureMembers[v1alpha1.TiDBMemberType] = len(tc.Status.TiDB.FailureMembers)
+	}
+	if tc.Spec.TiKV != nil {
+		failureMembers[v1alpha1.TiKVMemberType] = len(tc.Status.TiKV.FailureStores)
+		collectStores(ch, tc, v1alpha1.TiKVMemberType,
+			tc.Status.TiKV.Stores, tc.Status.TiKV.PeerStores, tc.Status.TiKV.TombstoneStores)
+		for podName, evict := range tc.Status.TiKV.EvictLeader {
+			if evict == nil || evict.BeginTime.IsZero() {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(clusterEvictLeaderDurationDesc, prometheus.GaugeValue,
+				now.Sub(evict.BeginTime.Time).Seconds(),
+				tc.Namespace, tc.Name, v1alpha1.TiKVMemberType.String(), podName)
+		}
+	}
+	if tc.Spec.TiFlash != nil {
+		failureMembers[v1alpha1.TiFlashMemberType] = len(tc.Status.TiFlash.FailureStores)
+		collectStores(ch, tc, v1alpha1.TiFlashMemberType,
+			tc.Status.TiFlash.Stores, tc.Status.TiFlash.PeerStores, tc.Status.TiFlash.TombstoneStores)
+	}
+	for memberType, count := range failureMembers {
+		ch <- prometheus.MustNewConstMetric(clusterFailureMembersDesc, prometheus.GaugeValue, float64(count),
+			tc.Namespace, tc.Name, memberType.String())
+	}
+
+	if ca := tc.Status.InternalCA; ca != nil {
+		if ca.CANotAfter != nil {
+			ch <- prometheus.MustNewConstMetric(clusterCertificateExpirationDesc, prometheus.GaugeValue,
+				float64(ca.CANotAfter.Unix()), tc.Namespace, tc.Name, tc.GetInternalCASecretName())
+		}
+		for secret, cert := range ca.Certificates {
+			ch <- prometheus.MustNewConstMetric(clusterCertificateExpirationDesc, prometheus.GaugeValue,
+				float64(cert.NotAfter.Unix()), tc.Namespace, tc.Name, secret)
+		}
+	}
+}
+
+// collectStores counts stores by their state. Stores of the TidbCluster and
+// its peer clusters are counted together, tombstone stores are recorded in
+// their own map so they are counted as Tombstone.
+func collectStores(ch chan<- prometheus.Metric, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType,
+	stores, peerStores, tombstoneStores map[string]v1alpha1.TiKVStore) {
+	counts := map[string]int{
+		v1alpha1.TiKVStateUp:        0,
+		v1alpha1.TiKVStateDown:      0,
+		v1alpha1.TiKVStateOffline:   0,
+		v1alpha1.TiKVStateTombstone: len(tombstoneStores),
+	}
+	for _, m := range []map[string]v1alpha1.TiKVStore{stores, peerStores} {
+		for _, store := range m {
+			counts[store.State]++
+		}
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(clusterStoresDesc, prometheus.GaugeValue, float64(count),
+			tc.Namespace, tc.Name, memberType.String(), state)
+	}
+}
+
+// getDuration returns the duration between started and completed, or between
+// started and now if it's not completed yet.
+func getDuration(started, completed metav1.Time, now time.Time) (float64, bool) {
+	if started.IsZero() {
+		return 0, false
+	}
+	end := now
+	if !completed.IsZero() {
+		end = completed.Time
+	}
+	return end.Sub(started.Time).Seconds(), true
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestStatusCollector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+	started := metav1.NewTime(now.Add(-10 * time.Minute))
+	completed := metav1.NewTime(now.Add(-5 * time.Minute))
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "basic"},
+		Spec: v1alpha1.TidbClusterSpec{
+			TiKV: &v1alpha1.TiKVSpec{},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Phase:       v1alpha1.UpgradePhase,
+				StatefulSet: &apps.StatefulSetStatus{ReadyReplicas: 2},
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {State: v1alpha1.TiKVStateUp},
+					"2": {State: v1alpha1.TiKVStateUp},
+					"3": {State: v1alpha1.TiKVStateOffline},
+				},
+				TombstoneStores: map[string]v1alpha1.TiKVStore{
+					"4": {State: v1alpha1.TiKVStateTombstone},
+				},
+				FailureStores: map[string]v1alpha1.TiKVFailureStore{
+					"3": {},
+				},
+				EvictLeader: map[string]*v1alpha1.EvictLeaderStatus{
+					"basic-tikv-0": {BeginTime: started},
+				},
+				Volumes: map[v1alpha1.StorageVolumeName]*v1alpha1.StorageVolumeStatus{
+					"tikv": {
+						ObservedStorageVolumeStatus: v1alpha1.ObservedStorageVolumeStatus{BoundCount: 3, CurrentCount: 1, ModifiedCount: 1},
+					},
+				},
+				VolReplaceInProgress: true,
+			},
+			InternalCA: &v1alpha1.InternalCAStatus{
+				CANotAfter: &metav1.Time{Time: now.Add(24 * time.Hour)},
+				Certificates: map[string]v1alpha1.IssuedCertificate{
+					"basic-tikv-cluster-secret": {SerialNumber: "1", NotAfter: metav1.NewTime(now.Add(time.Hour))},
+				},
+			},
+		},
+	}
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "backup"},
+		Status: v1alpha1.BackupStatus{
+			Phase:         v1alpha1.BackupComplete,
+			TimeStarted:   started,
+			TimeCompleted: completed,
+		},
+	}
+	logBackup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "log"},
+		Spec:       v1alpha1.BackupSpec{Mode: v1alpha1.BackupModeLog},
+		Status: v1alpha1.BackupStatus{
+			// 2024-01-01 00:05:00 in TSO
+			LogCheckpointTs: strconv.FormatUint(uint64(now.Add(-5*time.Minute).UnixMilli())<<18, 10),
+		},
+	}
+	schedule := &v1alpha1.BackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "hourly"},
+		Spec:       v1alpha1.BackupScheduleSpec{Schedule: "0 * * * *"},
+		Status:     v1alpha1.BackupScheduleStatus{LastBackupTime: &started},
+	}
+	paused := &v1alpha1.BackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "paused"},
+		Spec:       v1alpha1.BackupScheduleSpec{Schedule: "0 * * * *", Pause: true},
+	}
+	restore := &v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "restore"},
+		Status: v1alpha1.RestoreStatus{
+			Phase:       v1alpha1.RestoreRunning,
+			TimeStarted: started,
+		},
+	}
+
+	tcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	g.Expect(tcIndexer.Add(tc)).To(Succeed())
+	backupIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	g.Expect(backupIndexer.Add(backup)).To(Succeed())
+	g.Expect(backupIndexer.Add(logBackup)).To(Succeed())
+	scheduleIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	g.Expect(scheduleIndexer.Add(schedule)).To(Succeed())
+	g.Expect(scheduleIndexer.Add(paused)).To(Succeed())
+	restoreIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	g.Expect(restoreIndexer.Add(restore)).To(Succeed())
+
+	c := NewStatusCollector(
+		listers.NewTidbClusterLister(tcIndexer),
+		listers.NewBackupLister(backupIndexer),
+		listers.NewBackupScheduleLister(scheduleIndexer),
+		listers.NewRestoreLister(restoreIndexer),
+	)
+	c.now = func() time.Time { return now }
+
+	expected := `
+# HELP tidb_operator_backup_duration_seconds Duration of each Backup, it keeps increasing until the Backup is completed
+# TYPE tidb_operator_backup_duration_seconds gauge
+tidb_operator_backup_duration_seconds{name="backup",namespace="ns"} 300
+# HELP tidb_operator_backup_log_checkpoint_timestamp_seconds Checkpoint time of each log Backup
+# TYPE tidb_operator_backup_log_checkpoint_timestamp_seconds gauge
+tidb_operator_backup_log_checkpoint_timestamp_seconds{name="log",namespace="ns"} 1.7040675e+09
+# HELP tidb_operator_backup_phase Current phase of each Backup, the value of the current phase is 1
+# TYPE tidb_operator_backup_phase gauge
+tidb_operator_backup_phase{name="backup",namespace="ns",phase="Complete"} 1
+# HELP tidb_operator_backup_schedule_interval_seconds Interval between the last backup and the next scheduled run of each BackupSchedule
+# TYPE tidb_operator_backup_schedule_interval_seconds gauge
+tidb_operator_backup_schedule_interval_seconds{name="hourly",namespace="ns"} 3600
+# HELP tidb_operator_backup_schedule_last_backup_timestamp_seconds Time of the last backup of each BackupSchedule, or its creation time if no backup is created yet
+# TYPE tidb_operator_backup_schedule_last_backup_timestamp_seconds gauge
+tidb_operator_backup_schedule_last_backup_timestamp_seconds{name="hourly",namespace="ns"} 1.7040672e+09
+# HELP tidb_operator_cluster_certificate_expiration_timestamp_seconds Expiration time of the CA and the certificates issued by the internal CA of TidbCluster
+# TYPE tidb_operator_cluster_certificate_expiration_timestamp_seconds gauge
+tidb_operator_cluster_certificate_expiration_timestamp_seconds{name="basic",namespace="ns",secret="basic-internal-ca"} 1.7041542e+09
+tidb_operator_cluster_certificate_expiration_timestamp_seconds{name="basic",namespace="ns",secret="basic-tikv-cluster-secret"} 1.7040714e+09
+# HELP tidb_operator_cluster_component_ready_replicas Ready replicas of each component observed in TidbCluster status
+# TYPE tidb_operator_cluster_component_ready_replicas gauge
+tidb_operator_cluster_component_ready_replicas{component="tikv",name="basic",namespace="ns"} 2
+# HELP tidb_operator_cluster_component_upgrading Whether each component of TidbCluster is being upgraded, 1 for upgrading
+# TYPE tidb_operator_cluster_component_upgrading gauge
+tidb_operator_cluster_component_upgrading{component="tikv",name="basic",namespace="ns"} 1
+# HELP tidb_operator_cluster_evict_leader_duration_seconds Duration since the operator began to evict leaders from each TiKV pod
+# TYPE tidb_operator_cluster_evict_leader_duration_seconds gauge
+tidb_operator_cluster_evict_leader_duration_seconds{component="tikv",name="basic",namespace="ns",pod="basic-tikv-0"} 600
+# HELP tidb_operator_cluster_failure_members Number of failure members or stores of each component recorded for failover
+# TYPE tidb_operator_cluster_failure_members gauge
+tidb_operator_cluster_failure_members{component="tikv",name="basic",namespace="ns"} 1
+# HELP tidb_operator_cluster_stores Number of TiKV and TiFlash stores in each state observed in TidbCluster status
+# TYPE tidb_operator_cluster_stores gauge
+tidb_operator_cluster_stores{component="tikv",name="basic",namespace="ns",state="Down"} 0
+tidb_operator_cluster_stores{component="tikv",name="basic",namespace="ns",state="Offline"} 1
+tidb_operator_cluster_stores{component="tikv",name="basic",namespace="ns",state="Tombstone"} 1
+tidb_operator_cluster_stores{component="tikv",name="basic",namespace="ns",state="Up"} 2
+# HELP tidb_operator_cluster_volume_pending_modify Number of volumes which are not modified to the desired spec yet
+# TYPE tidb_operator_cluster_volume_pending_modify gauge
+tidb_operator_cluster_volume_pending_modify{component="tikv",name="basic",namespace="ns",volume="tikv"} 2
+# HELP tidb_operator_cluster_volume_replacing Whether volumes of each component of TidbCluster are being replaced, 1 for replacing
+# TYPE tidb_operator_cluster_volume_replacing gauge
+tidb_operator_cluster_volume_replacing{component="tikv",name="basic",namespace="ns"} 1
+# HELP tidb_operator_restore_duration_seconds Duration of each Restore, it keeps increasing until the Restore is completed
+# TYPE tidb_operator_restore_duration_seconds gauge
+tidb_operator_restore_duration_seconds{name="restore",namespace="ns"} 600
+# HELP tidb_operator_restore_phase Current phase of each Restore, the value of the current phase is 1
+# TYPE tidb_operator_restore_phase gauge
+tidb_operator_restore_phase{name="restore",namespace="ns",phase="Running"} 1
+`
+	g.Expect(testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"tidb_operator_backup_duration_seconds",
+		"tidb_operator_backup_log_checkpoint_timestamp_seconds",
+		"tidb_operator_backup_phase",
+		"tidb_operator_backup_schedule_interval_seconds",
+		"tidb_operator_backup_schedule_last_backup_timestamp_seconds",
+		"tidb_operator_cluster_certificate_expiration_timestamp_seconds",
+		"tidb_operator_cluster_component_ready_replicas",
+		"tidb_operator_cluster_component_upgrading",
+		"tidb_operator_cluster_evict_leader_duration_seconds",
+		"tidb_operator_cluster_failure_members",
+		"tidb_operator_cluster_stores",
+		"tidb_operator_cluster_volume_pending_modify",
+		"tidb_operator_cluster_volume_replacing",
+		"tidb_operator_restore_duration_seconds",
+		"tidb_operator_restore_phase",
+	)).To(Succeed())
+}
@@ -0,0 +1,322 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// generatedRuleFile is the key of the generated alert rules in the Prometheus ConfigMap
+	generatedRuleFile = "generated" + ruleFileSuffix
+
+	defaultGeneratedAlertFor               = "5m"
+	defaultGeneratedBackupScheduleDelay    = "1h"
+	defaultGeneratedLogBackupCheckpointLag = "10m"
+
+	alertPDMembersBelowDesired      = "PDMembersBelowDesired"
+	alertTiKVStoresBelowDesired     = "TiKVStoresBelowDesired"
+	alertTiFlashStoresBelowDesired  = "TiFlashStoresBelowDesired"
+	alertTiDBInstancesBelowDesired  = "TiDBInstancesBelowDesired"
+	alertBackupScheduleMissed       = "BackupScheduleMissed"
+	alertLogBackupCheckpointLagging = "LogBackupCheckpointLagging"
+
+	// metrics of backups exported by the controller-manager, see pkg/metrics
+	metricBackupScheduleLastBackup = "tidb_operator_backup_schedule_last_backup_timestamp_seconds"
+	metricBackupScheduleInterval   = "tidb_operator_backup_schedule_interval_seconds"
+	metricBackupLogCheckpoint      = "tidb_operator_backup_log_checkpoint_timestamp_seconds"
+)
+
+var defaultGeneratedAlertLabels = map[string]string{"level": "critical"}
+
+type alertRuleFile struct {
+	Groups []alertRuleGroup `yaml:"groups"`
+}
+
+type alertRuleGroup struct {
+	Name  string      `yaml:"name"`
+	Rules []alertRule `yaml:"rules"`
+}
+
+type alertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// generatedAlertRulesConfig is the GeneratedAlertRulesSpec with defaults applied
+type generatedAlertRulesConfig struct {
+	disabled               sets.String
+	alertFor               string
+	labels                 map[string]string
+	backupScheduleDelay    time.Duration
+	logBackupCheckpointLag time.Duration
+}
+
+func newGeneratedAlertRulesConfig(spec *v1alpha1.GeneratedAlertRulesSpec) (*generatedAlertRulesConfig, error) {
+	cfg := &generatedAlertRulesConfig{
+		disabled: sets.NewString(spec.DisabledAlerts...),
+		alertFor: defaultGeneratedAlertFor,
+		labels:   defaultGeneratedAlertLabels,
+	}
+	if spec.For != "" {
+		cfg.alertFor = spec.For
+	}
+	if spec.Labels != nil {
+		cfg.labels = spec.Labels
+	}
+
+	parse := func(value, defaultValue string) (time.Duration, error) {
+		if value == "" {
+			value = defaultValue
+		}
+		d, err := model.ParseDuration(value)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(d), nil
+	}
+	var err error
+	if cfg.backupScheduleDelay, err = parse(spec.BackupScheduleDelay, defaultGeneratedBackupScheduleDelay); err != nil {
+		return nil, fmt.Errorf("invalid backupScheduleDelay %q: %v", spec.BackupScheduleDelay, err)
+	}
+	if cfg.logBackupCheckpointLag, err = parse(spec.LogBackupCheckpointLag, defaultGeneratedLogBackupCheckpointLag); err != nil {
+		return nil, fmt.Errorf("invalid logBackupCheckpointLag %q: %v", spec.LogBackupCheckpointLag, err)
+	}
+	return cfg, nil
+}
+
+func (c *generatedAlertRulesConfig) newRule(alert, expr, alertFor string, ruleLabels map[string]string, summary, description string) alertRule {
+	merged := map[string]string{}
+	for k, v := range c.labels {
+		merged[k] = v
+	}
+	for k, v := range ruleLabels {
+		merged[k] = v
+	}
+	return alertRule{
+		Alert:  alert,
+		Expr:   expr,
+		For:    alertFor,
+		Labels: merged,
+		Annotations: map[string]string{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+}
+
+// getGeneratedAlertRules generates the alert rules of the clusters from what the operator manages:
+// the desired replicas of components, the schedules of BackupSchedules and the checkpoints of
+// running log backups. Prometheus can't see the spec and status of the custom resources, so the
+// thresholds are rendered into the rules, and the rules must be regenerated when they change.
+// An empty string is returned if there is no rule.
+func getGeneratedAlertRules(monitor *v1alpha1.TidbMonitor, tcs []*v1alpha1.TidbCluster, schedules []*v1alpha1.BackupSchedule, backups []*v1alpha1.Backup) (string, error) {
+	if monitor.Spec.GeneratedAlertRules == nil {
+		return "", nil
+	}
+	cfg, err := newGeneratedAlertRulesConfig(monitor.Spec.GeneratedAlertRules)
+	if err != nil {
+		return "", fmt.Errorf("tm[%s/%s] %v", monitor.Namespace, monitor.Name, err)
+	}
+
+	file := alertRuleFile{}
+	for _, tc := range tcs {
+		var rules []alertRule
+		rules = append(rules, cfg.componentRules(tc)...)
+
+		rules = append(rules, cfg.backupScheduleRules(tc, schedules)...)
+		rules = append(rules, cfg.logBackupRules(tc, backups)...)
+
+		if len(rules) == 0 {
+			continue
+		}
+		file.Groups = append(file.Groups, alertRuleGroup{
+			Name:  fmt.Sprintf("%s-%s-operator.rules", tc.Namespace, tc.Name),
+			Rules: rules,
+		})
+	}
+	if len(file.Groups) == 0 {
+		return "", nil
+	}
+
+	content, err := yaml.Marshal(file)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// componentRules fires if less instances of a component are up than the replicas in the spec
+func (c *generatedAlertRulesConfig) componentRules(tc *v1alpha1.TidbCluster) []alertRule {
+	type component struct {
+		alert    string
+		name     string
+		replicas int32
+	}
+	var components []component
+	if tc.Spec.PD != nil {
+		components = append(components, component{alertPDMembersBelowDesired, v1alpha1.PDMemberType.String(), tc.Spec.PD.Replicas})
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, component{alertTiKVStoresBelowDesired, v1alpha1.TiKVMemberType.String(), tc.Spec.TiKV.Replicas})
+	}
+	if tc.Spec.TiFlash != nil {
+		components = append(components, component{alertTiFlashStoresBelowDesired, v1alpha1.TiFlashMemberType.String(), tc.Spec.TiFlash.Replicas})
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, component{alertTiDBInstancesBelowDesired, v1alpha1.TiDBMemberType.String(), tc.Spec.TiDB.Replicas})
+	}
+
+	var rules []alertRule
+	for _, comp := range components {
+		if c.disabled.Has(comp.alert) || comp.replicas <= 0 {
+			continue
+		}
+		// the jobs are named in the same way by both the bundled Prometheus and the PodMonitors
+		job := fmt.Sprintf("%s-%s-%s", tc.Namespace, tc.Name, comp.name)
+		rules = append(rules, c.newRule(comp.alert,
+			fmt.Sprintf(`(count(up{job="%s"} == 1) or vector(0)) < %d`, job, comp.replicas),
+			c.alertFor,
+			map[string]string{
+				"tidb_cluster": fmt.Sprintf("%s-%s", tc.Namespace, tc.Name),
+				"component":    comp.name,
+			},
+			fmt.Sprintf("%s of tc[%s/%s] has less instances up than desired", comp.name, tc.Namespace, tc.Name),
+			fmt.Sprintf("{{ $value }} %s instances of tc[%s/%s] are up, %d are desired", comp.name, tc.Namespace, tc.Name, comp.replicas),
+		))
+	}
+	return rules
+}
+
+// backupScheduleRules fires if the next backup of a BackupSchedule of the cluster
+// is not created after the delay. The deadline is computed from the time of the last
+// backup and the interval to the next run exported by the controller-manager, so the
+// rules are not changed by every backup.
+func (c *generatedAlertRulesConfig) backupScheduleRules(tc *v1alpha1.TidbCluster, schedules []*v1alpha1.BackupSchedule) []alertRule {
+	if c.disabled.Has(alertBackupScheduleMissed) {
+		return nil
+	}
+	var rules []alertRule
+	for _, bs := range schedules {
+		if bs.Spec.Pause || bs.Spec.Schedule == "" || !isBRTargetCluster(bs.Spec.BackupTemplate.BR, bs.Namespace, tc) {
+			continue
+		}
+		selector := fmt.Sprintf(`{namespace="%s",name="%s"}`, bs.Namespace, bs.Name)
+		rules = append(rules, c.newRule(alertBackupScheduleMissed,
+			fmt.Sprintf("time() - %s%s - %s%s > %d", metricBackupScheduleLastBackup, selector,
+				metricBackupScheduleInterval, selector, int64(c.backupScheduleDelay.Seconds())),
+			"",
+			map[string]string{
+				"tidb_cluster":    fmt.Sprintf("%s-%s", tc.Namespace, tc.Name),
+				"backup_schedule": fmt.Sprintf("%s/%s", bs.Namespace, bs.Name),
+			},
+			fmt.Sprintf("backup schedule %s/%s of tc[%s/%s] missed its run", bs.Namespace, bs.Name, tc.Namespace, tc.Name),
+			fmt.Sprintf("no backup is created by backup schedule %s/%s {{ $value | humanizeDuration }} after its next run",
+				bs.Namespace, bs.Name),
+		))
+	}
+	return rules
+}
+
+// logBackupRules fires if the checkpoint of a running log backup of the cluster
+// falls behind more than the lag. The checkpoint is exported by the controller-manager.
+func (c *generatedAlertRulesConfig) logBackupRules(tc *v1alpha1.TidbCluster, backups []*v1alpha1.Backup) []alertRule {
+	if c.disabled.Has(alertLogBackupCheckpointLagging) {
+		return nil
+	}
+	var rules []alertRule
+	for _, backup := range backups {
+		if backup.Spec.Mode != v1alpha1.BackupModeLog || backup.Status.Phase != v1alpha1.BackupRunning ||
+			!isBRTargetCluster(backup.Spec.BR, backup.Namespace, tc) {
+			continue
+		}
+		rules = append(rules, c.newRule(alertLogBackupCheckpointLagging,
+			fmt.Sprintf(`time() - %s{namespace="%s",name="%s"} > %d`, metricBackupLogCheckpoint,
+				backup.Namespace, backup.Name, int64(c.logBackupCheckpointLag.Seconds())),
+			"",
+			map[string]string{
+				"tidb_cluster": fmt.Sprintf("%s-%s", tc.Namespace, tc.Name),
+				"backup":       fmt.Sprintf("%s/%s", backup.Namespace, backup.Name),
+			},
+			fmt.Sprintf("checkpoint of log backup %s/%s of tc[%s/%s] is lagging", backup.Namespace, backup.Name, tc.Namespace, tc.Name),
+			fmt.Sprintf("checkpoint of log backup %s/%s lags {{ $value | humanizeDuration }}, more than %s",
+				backup.Namespace, backup.Name, c.logBackupCheckpointLag),
+		))
+	}
+	return rules
+}
+
+func isBRTargetCluster(br *v1alpha1.BRConfig, namespace string, tc *v1alpha1.TidbCluster) bool {
+	if br == nil || br.Cluster != tc.Name {
+		return false
+	}
+	if br.ClusterNamespace != "" {
+		namespace = br.ClusterNamespace
+	}
+	return namespace == tc.Namespace
+}
+
+// getGeneratedAlertRuleFile gets the monitored clusters and the BackupSchedules and Backups
+// in the namespaces of the clusters and the TidbMonitor to generate the alert rules.
+func (m *MonitorManager) getGeneratedAlertRuleFile(monitor *v1alpha1.TidbMonitor) (string, error) {
+	if monitor.Spec.GeneratedAlertRules == nil {
+		return "", nil
+	}
+
+	namespaces := sets.NewString(monitor.Namespace)
+	var tcs []*v1alpha1.TidbCluster
+	for _, tcRef := range monitor.Spec.Clusters {
+		tc, err := m.deps.TiDBClusterLister.TidbClusters(tcRef.Namespace).Get(tcRef.Name)
+		if err != nil {
+			return "", fmt.Errorf("get tm[%s/%s]'s target tc[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, tcRef.Namespace, tcRef.Name, err)
+		}
+		tcs = append(tcs, tc)
+		namespaces.Insert(tc.Namespace)
+	}
+
+	var schedules []*v1alpha1.BackupSchedule
+	var backups []*v1alpha1.Backup
+	for _, ns := range namespaces.List() {
+		bss, err := m.deps.BackupScheduleLister.BackupSchedules(ns).List(labels.Everything())
+		if err != nil {
+			return "", fmt.Errorf("list backup schedules in namespace %s for tm[%s/%s] failed, err: %v", ns, monitor.Namespace, monitor.Name, err)
+		}
+		schedules = append(schedules, bss...)
+		bks, err := m.deps.BackupLister.Backups(ns).List(labels.Everything())
+		if err != nil {
+			return "", fmt.Errorf("list backups in namespace %s for tm[%s/%s] failed, err: %v", ns, monitor.Namespace, monitor.Name, err)
+		}
+		backups = append(backups, bks...)
+	}
+	// listers return objects in random order, keep the rules stable
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Namespace+"/"+schedules[i].Name < schedules[j].Namespace+"/"+schedules[j].Name
+	})
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Namespace+"/"+backups[i].Name < backups[j].Namespace+"/"+backups[j].Name
+	})
+
+	return getGeneratedAlertRules(monitor, tcs, schedules, backups)
+}
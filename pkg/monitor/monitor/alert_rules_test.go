@@ -0,0 +1,212 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetGeneratedAlertRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tm := &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+	}
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "basic"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 3},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 3},
+			TiDB: &v1alpha1.TiDBSpec{Replicas: 0},
+		},
+	}
+	lastBackup := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	schedules := []*v1alpha1.BackupSchedule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "daily"},
+			Spec: v1alpha1.BackupScheduleSpec{
+				Schedule:       "0 0 * * *",
+				BackupTemplate: v1alpha1.BackupSpec{BR: &v1alpha1.BRConfig{Cluster: "basic"}},
+			},
+			Status: v1alpha1.BackupScheduleStatus{LastBackupTime: &lastBackup},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "paused"},
+			Spec: v1alpha1.BackupScheduleSpec{
+				Schedule:       "0 0 * * *",
+				Pause:          true,
+				BackupTemplate: v1alpha1.BackupSpec{BR: &v1alpha1.BRConfig{Cluster: "basic"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"},
+			Spec: v1alpha1.BackupScheduleSpec{
+				Schedule:       "0 0 * * *",
+				BackupTemplate: v1alpha1.BackupSpec{BR: &v1alpha1.BRConfig{Cluster: "basic", ClusterNamespace: "other"}},
+			},
+		},
+	}
+	checkpoint := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	backups := []*v1alpha1.Backup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "log"},
+			Spec: v1alpha1.BackupSpec{
+				Mode: v1alpha1.BackupModeLog,
+				BR:   &v1alpha1.BRConfig{Cluster: "basic"},
+			},
+			Status: v1alpha1.BackupStatus{
+				Phase:           v1alpha1.BackupRunning,
+				LogCheckpointTs: strconv.FormatUint(config.GoTimeToTS(checkpoint), 10),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "snapshot"},
+			Spec: v1alpha1.BackupSpec{
+				BR: &v1alpha1.BRConfig{Cluster: "basic"},
+			},
+			Status: v1alpha1.BackupStatus{Phase: v1alpha1.BackupRunning},
+		},
+	}
+
+	// not enabled
+	content, err := getGeneratedAlertRules(tm, []*v1alpha1.TidbCluster{tc}, schedules, backups)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(BeEmpty())
+
+	tm.Spec.GeneratedAlertRules = &v1alpha1.GeneratedAlertRulesSpec{}
+	content, err = getGeneratedAlertRules(tm, []*v1alpha1.TidbCluster{tc}, schedules, backups)
+	g.Expect(err).NotTo(HaveOccurred())
+	file := alertRuleFile{}
+	g.Expect(yaml.Unmarshal([]byte(content), &file)).To(Succeed())
+	g.Expect(file.Groups).To(HaveLen(1))
+	g.Expect(file.Groups[0].Name).To(Equal("ns-basic-operator.rules"))
+	rules := map[string]alertRule{}
+	for _, rule := range file.Groups[0].Rules {
+		rules[rule.Alert] = rule
+	}
+	g.Expect(rules).To(HaveLen(4))
+
+	g.Expect(rules[alertPDMembersBelowDesired].Expr).To(Equal(`(count(up{job="ns-basic-pd"} == 1) or vector(0)) < 3`))
+	g.Expect(rules[alertPDMembersBelowDesired].For).To(Equal("5m"))
+	g.Expect(rules[alertPDMembersBelowDesired].Labels).To(Equal(map[string]string{
+		"level":        "critical",
+		"tidb_cluster": "ns-basic",
+		"component":    "pd",
+	}))
+	g.Expect(rules[alertTiKVStoresBelowDesired].Expr).To(Equal(`(count(up{job="ns-basic-tikv"} == 1) or vector(0)) < 3`))
+
+	// the deadline is computed from the metrics, so the rule is not changed by backups
+	g.Expect(rules[alertBackupScheduleMissed].Expr).To(Equal(`time() - tidb_operator_backup_schedule_last_backup_timestamp_seconds{namespace="ns",name="daily"}` +
+		` - tidb_operator_backup_schedule_interval_seconds{namespace="ns",name="daily"} > 3600`))
+	g.Expect(rules[alertBackupScheduleMissed].Labels).To(HaveKeyWithValue("backup_schedule", "ns/daily"))
+	lastBackup = metav1.NewTime(lastBackup.Add(24 * time.Hour))
+	updated, err := getGeneratedAlertRules(tm, []*v1alpha1.TidbCluster{tc}, schedules, backups)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated).To(Equal(content))
+
+	g.Expect(rules[alertLogBackupCheckpointLagging].Expr).To(Equal(`time() - tidb_operator_backup_log_checkpoint_timestamp_seconds{namespace="ns",name="log"} > 600`))
+	g.Expect(rules[alertLogBackupCheckpointLagging].Labels).To(HaveKeyWithValue("backup", "ns/log"))
+
+	// overrides
+	tm.Spec.GeneratedAlertRules = &v1alpha1.GeneratedAlertRulesSpec{
+		DisabledAlerts:         []string{alertPDMembersBelowDesired, alertTiKVStoresBelowDesired},
+		Labels:                 map[string]string{"severity": "page"},
+		BackupScheduleDelay:    "30m",
+		LogBackupCheckpointLag: "1h",
+	}
+	content, err = getGeneratedAlertRules(tm, []*v1alpha1.TidbCluster{tc}, schedules, backups)
+	g.Expect(err).NotTo(HaveOccurred())
+	file = alertRuleFile{}
+	g.Expect(yaml.Unmarshal([]byte(content), &file)).To(Succeed())
+	g.Expect(file.Groups[0].Rules).To(HaveLen(2))
+	schedule, logBackup := file.Groups[0].Rules[0], file.Groups[0].Rules[1]
+	g.Expect(schedule.Expr).To(HaveSuffix(" > 1800"))
+	g.Expect(schedule.Labels).To(HaveKeyWithValue("severity", "page"))
+	g.Expect(schedule.Labels).NotTo(HaveKey("level"))
+	g.Expect(logBackup.Expr).To(HaveSuffix(" > 3600"))
+
+	// all disabled
+	tm.Spec.GeneratedAlertRules.DisabledAlerts = append(tm.Spec.GeneratedAlertRules.DisabledAlerts, alertBackupScheduleMissed, alertLogBackupCheckpointLagging)
+	content, err = getGeneratedAlertRules(tm, []*v1alpha1.TidbCluster{tc}, schedules, backups)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(content).To(BeEmpty())
+}
+
+func TestRenderPrometheusConfigWithGeneratedAlertRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg, err := RenderPrometheusConfig(&MonitorConfigModel{
+		EnableAlertRules:          true,
+		EnableGeneratedAlertRules: true,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	var rulesPath interface{}
+	for _, item := range cfg {
+		if item.Key == "rule_files" {
+			rulesPath = item.Value
+		}
+	}
+	g.Expect(rulesPath).To(Equal([]string{
+		"/prometheus-rules/rules/*.rules.yml",
+		"/etc/prometheus/config/generated.rules.yml",
+	}))
+}
+
+func TestRenderPrometheusConfigWithControllerManager(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	getJob := func(model *MonitorConfigModel) yaml.MapSlice {
+		cfg, err := RenderPrometheusConfig(model)
+		g.Expect(err).NotTo(HaveOccurred())
+		content, err := yaml.Marshal(cfg)
+		g.Expect(err).NotTo(HaveOccurred())
+		parsed := struct {
+			ScrapeConfigs []yaml.MapSlice `yaml:"scrape_configs"`
+		}{}
+		g.Expect(yaml.Unmarshal(content, &parsed)).To(Succeed())
+		for _, job := range parsed.ScrapeConfigs {
+			if job[0].Key == "job_name" && job[0].Value == controllerManagerJob {
+				return job
+			}
+		}
+		return nil
+	}
+
+	// the controller-manager is not scraped without the operator namespace
+	g.Expect(getJob(&MonitorConfigModel{EnableGeneratedAlertRules: true})).To(BeNil())
+
+	job := getJob(&MonitorConfigModel{
+		EnableGeneratedAlertRules: true,
+		OperatorNamespace:         "tidb-admin",
+	})
+	g.Expect(job).NotTo(BeNil())
+	values := map[interface{}]interface{}{}
+	for _, item := range job {
+		values[item.Key] = item.Value
+	}
+	g.Expect(values["honor_labels"]).To(BeTrue())
+	content, err := yaml.Marshal(job)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(ContainSubstring("- tidb-admin\n"))
+	g.Expect(string(content)).To(ContainSubstring("replacement: $1:6060\n"))
+	g.Expect(string(content)).To(ContainSubstring("regex: controller-manager\n"))
+}
@@ -0,0 +1,329 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+)
+
+const grafanaAPITimeout = 10 * time.Second
+
+// Permission levels of the Grafana folder and datasource permission API
+const (
+	grafanaPermissionView = 1
+	grafanaPermissionEdit = 2
+)
+
+// grafanaAPIError is returned when Grafana responds with an error status
+type grafanaAPIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       string
+}
+
+func (e *grafanaAPIError) Error() string {
+	return fmt.Sprintf("grafana API %s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+func isGrafanaStatus(err error, code int) bool {
+	var apiErr *grafanaAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+type grafanaFolder struct {
+	ID    int64  `json:"id"`
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+type grafanaSearchHit struct {
+	UID   string   `json:"uid"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+type grafanaTeam struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type grafanaPermissionItem struct {
+	TeamID     int64 `json:"teamId"`
+	Permission int   `json:"permission"`
+}
+
+// grafanaClient calls the HTTP API of the Grafana deployed by TidbMonitor as the admin user
+type grafanaClient struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func newGrafanaClient(url, username, password string) *grafanaClient {
+	return &grafanaClient{
+		url:        url,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: grafanaAPITimeout},
+	}
+}
+
+// do sends the request with in as the JSON body, and decodes the response into out if it's not nil
+func (c *grafanaClient) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return &grafanaAPIError{StatusCode: res.StatusCode, Method: method, Path: path, Body: string(data)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *grafanaClient) getFolder(uid string) (*grafanaFolder, error) {
+	folder := &grafanaFolder{}
+	if err := c.do(http.MethodGet, "/api/folders/"+url.PathEscape(uid), nil, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// ensureFolder returns the folder, it's created if not exist
+func (c *grafanaClient) ensureFolder(uid, title string) (*grafanaFolder, error) {
+	folder, err := c.getFolder(uid)
+	if err == nil || !isGrafanaStatus(err, http.StatusNotFound) {
+		return folder, err
+	}
+	folder = &grafanaFolder{}
+	if err := c.do(http.MethodPost, "/api/folders", map[string]string{"uid": uid, "title": title}, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (c *grafanaClient) listFolders() ([]grafanaFolder, error) {
+	var folders []grafanaFolder
+	if err := c.do(http.MethodGet, "/api/folders?limit=1000", nil, &folders); err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+// deleteFolder deletes the folder and the dashboards in it
+func (c *grafanaClient) deleteFolder(uid string) error {
+	err := c.do(http.MethodDelete, "/api/folders/"+url.PathEscape(uid), nil, nil)
+	if isGrafanaStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+// setFolderPermissions replaces all the permissions of the folder, only admins can access
+// the folder if items is empty.
+func (c *grafanaClient) setFolderPermissions(uid string, items []grafanaPermissionItem) error {
+	if items == nil {
+		items = []grafanaPermissionItem{}
+	}
+	return c.do(http.MethodPost, "/api/folders/"+url.PathEscape(uid)+"/permissions", map[string]interface{}{"items": items}, nil)
+}
+
+func (c *grafanaClient) searchDashboards(folderID int64) ([]grafanaSearchHit, error) {
+	var hits []grafanaSearchHit
+	path := "/api/search?type=dash-db&limit=5000&folderIds=" + strconv.FormatInt(folderID, 10)
+	if err := c.do(http.MethodGet, path, nil, &hits); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+func (c *grafanaClient) getDashboard(uid string) (map[string]interface{}, error) {
+	var res struct {
+		Dashboard map[string]interface{} `json:"dashboard"`
+	}
+	if err := c.do(http.MethodGet, "/api/dashboards/uid/"+url.PathEscape(uid), nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Dashboard, nil
+}
+
+// saveDashboard creates or overwrites the dashboard with the same uid in the folder
+func (c *grafanaClient) saveDashboard(dashboard map[string]interface{}, folderID int64) error {
+	return c.do(http.MethodPost, "/api/dashboards/db", map[string]interface{}{
+		"dashboard": dashboard,
+		"folderId":  folderID,
+		"overwrite": true,
+	}, nil)
+}
+
+func (c *grafanaClient) deleteDashboard(uid string) error {
+	err := c.do(http.MethodDelete, "/api/dashboards/uid/"+url.PathEscape(uid), nil, nil)
+	if isGrafanaStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (c *grafanaClient) getDatasource(path string) (map[string]interface{}, error) {
+	ds := map[string]interface{}{}
+	if err := c.do(http.MethodGet, path, nil, &ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func (c *grafanaClient) getDatasourceByName(name string) (map[string]interface{}, error) {
+	return c.getDatasource("/api/datasources/name/" + url.PathEscape(name))
+}
+
+func (c *grafanaClient) getDatasourceByUID(uid string) (map[string]interface{}, error) {
+	return c.getDatasource("/api/datasources/uid/" + url.PathEscape(uid))
+}
+
+func (c *grafanaClient) listDatasources() ([]map[string]interface{}, error) {
+	var list []map[string]interface{}
+	if err := c.do(http.MethodGet, "/api/datasources", nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (c *grafanaClient) createDatasource(ds map[string]interface{}) error {
+	return c.do(http.MethodPost, "/api/datasources", ds, nil)
+}
+
+func (c *grafanaClient) updateDatasource(id int64, ds map[string]interface{}) error {
+	return c.do(http.MethodPut, fmt.Sprintf("/api/datasources/%d", id), ds, nil)
+}
+
+func (c *grafanaClient) deleteDatasource(uid string) error {
+	err := c.do(http.MethodDelete, "/api/datasources/uid/"+url.PathEscape(uid), nil, nil)
+	if isGrafanaStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+// setDatasourcePermissions restricts the query of the datasource to the teams. The API is only
+// provided by Grafana Enterprise, it returns false if the API is not found.
+func (c *grafanaClient) setDatasourcePermissions(id int64, items []grafanaPermissionItem) (bool, error) {
+	path := fmt.Sprintf("/api/datasources/%d/permissions", id)
+	var current struct {
+		Enabled     bool `json:"enabled"`
+		Permissions []struct {
+			ID         int64 `json:"id"`
+			TeamID     int64 `json:"teamId"`
+			Permission int   `json:"permission"`
+		} `json:"permissions"`
+	}
+	if err := c.do(http.MethodGet, path, nil, &current); err != nil {
+		if isGrafanaStatus(err, http.StatusNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !current.Enabled {
+		if err := c.do(http.MethodPost, fmt.Sprintf("/api/datasources/%d/enable-permissions", id), nil, nil); err != nil {
+			return true, err
+		}
+	}
+	granted := map[grafanaPermissionItem]bool{}
+	for _, p := range current.Permissions {
+		item := grafanaPermissionItem{TeamID: p.TeamID, Permission: p.Permission}
+		if containsPermissionItem(items, item) {
+			granted[item] = true
+			continue
+		}
+		// revoke the permissions of the teams no longer owning the cluster
+		if err := c.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, p.ID), nil, nil); err != nil {
+			return true, err
+		}
+	}
+	for _, item := range items {
+		if granted[item] {
+			continue
+		}
+		if err := c.do(http.MethodPost, path, item, nil); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+func containsPermissionItem(items []grafanaPermissionItem, item grafanaPermissionItem) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureTeam returns the id of the team, it's created if not exist
+func (c *grafanaClient) ensureTeam(name string) (int64, error) {
+	var res struct {
+		Teams []grafanaTeam `json:"teams"`
+	}
+	if err := c.do(http.MethodGet, "/api/teams/search?name="+url.QueryEscape(name), nil, &res); err != nil {
+		return 0, err
+	}
+	for _, t := range res.Teams {
+		if t.Name == name {
+			return t.ID, nil
+		}
+	}
+	var created struct {
+		TeamID int64 `json:"teamId"`
+	}
+	if err := c.do(http.MethodPost, "/api/teams", map[string]string{"name": name}, &created); err != nil {
+		return 0, err
+	}
+	return created.TeamID, nil
+}
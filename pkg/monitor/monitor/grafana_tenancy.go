@@ -0,0 +1,507 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// With tenancy enabled, the dashboards provisioned from the initializer are put into the shared
+// folder which is only visible to Grafana admins. For each monitored TidbCluster, the operator
+// creates a folder with copies of the shared dashboards and a datasource. The copies query the
+// datasource of the cluster and the cluster dropdown is pinned to the cluster. The folder and
+// the datasource are granted to the team named by the label of the TidbCluster.
+const (
+	grafanaSharedFolderUID   = "tidb-shared"
+	grafanaSharedFolderTitle = "TiDB (all clusters)"
+	// grafanaSharedDatasource is the datasource of the Prometheus provisioned by the initializer
+	grafanaSharedDatasource = "tidb-cluster"
+	// grafanaTenantUIDPrefix is the prefix of the uids of the objects created for tenants,
+	// the objects with the prefix and not desired are deleted.
+	grafanaTenantUIDPrefix = "tidb-"
+	// grafanaClusterVariable is the template variable of dashboards to select the cluster
+	grafanaClusterVariable = "tidb_cluster"
+	// grafanaSyncTagPrefix tags the copied dashboards with the hash of the source to skip unchanged ones
+	grafanaSyncTagPrefix = "tidb-operator-sync:"
+	grafanaPort          = 3000
+
+	// The datasources of tenants query Prometheus through prom-label-proxy, which enforces the
+	// tidb_cluster label with the value of the header set by the datasource.
+	grafanaLabelProxyPort         = 9096
+	grafanaLabelProxyHeader       = "X-TiDB-Cluster"
+	defaultGrafanaLabelProxyImage = "quay.io/prometheuscommunity/prom-label-proxy"
+	defaultGrafanaLabelProxyTag   = "v0.11.0"
+
+	eventReasonGrafanaDatasourcePermissionsUnsupported = "GrafanaDatasourcePermissionsUnsupported"
+)
+
+// grafanaTenant is the Grafana objects of a monitored TidbCluster
+type grafanaTenant struct {
+	// Cluster is the value of the tidb_cluster label of the metrics of the cluster
+	Cluster    string
+	FolderUID  string
+	Title      string
+	Datasource string
+	Team       string
+}
+
+// grafanaTenantUID returns the uid of the Grafana objects derived from key,
+// the uid of Grafana is limited to 40 characters.
+func grafanaTenantUID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return grafanaTenantUIDPrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+func isGrafanaTenantUID(uid string) bool {
+	return strings.HasPrefix(uid, grafanaTenantUIDPrefix) && len(uid) == len(grafanaTenantUIDPrefix)+16
+}
+
+func getGrafanaTenants(tenancy *v1alpha1.GrafanaTenancySpec, tcs []*v1alpha1.TidbCluster) []grafanaTenant {
+	var tenants []grafanaTenant
+	for _, tc := range tcs {
+		key := fmt.Sprintf("%s/%s", tc.Namespace, tc.Name)
+		tenants = append(tenants, grafanaTenant{
+			Cluster:    fmt.Sprintf("%s-%s", tc.Namespace, tc.Name),
+			FolderUID:  grafanaTenantUID(key),
+			Title:      key,
+			Datasource: key,
+			Team:       tc.Labels[tenancy.GetTeamLabelKey()],
+		})
+	}
+	return tenants
+}
+
+func grafanaPermissionLevel(p v1alpha1.GrafanaPermission) int {
+	if p == v1alpha1.GrafanaPermissionEdit {
+		return grafanaPermissionEdit
+	}
+	return grafanaPermissionView
+}
+
+// getGrafanaCredentials returns the admin user and password of the Grafana
+func (m *MonitorManager) getGrafanaCredentials(monitor *v1alpha1.TidbMonitor) (string, string, error) {
+	spec := monitor.Spec.Grafana
+	read := func(name, key string) (string, error) {
+		secret, err := m.deps.SecretLister.Secrets(monitor.Namespace).Get(name)
+		if err != nil {
+			return "", fmt.Errorf("get secret %s/%s failed: %v", monitor.Namespace, name, err)
+		}
+		return string(secret.Data[key]), nil
+	}
+	username, password := spec.Username, spec.Password
+	var err error
+	if spec.UsernameSecret != nil {
+		if username, err = read(spec.UsernameSecret.Name, spec.UsernameSecret.Key); err != nil {
+			return "", "", err
+		}
+	}
+	if spec.PasswordSecret != nil {
+		if password, err = read(spec.PasswordSecret.Name, spec.PasswordSecret.Key); err != nil {
+			return "", "", err
+		}
+	}
+	return username, password, nil
+}
+
+// syncGrafanaTenancy provisions the folder, dashboards and datasource of each TidbCluster in the
+// Grafana of every shard, and deletes the ones of the clusters no longer monitored. The Grafana whose
+// tenants and pod are not changed since the last sync is skipped, see getGrafanaTenancyHash.
+func (m *MonitorManager) syncGrafanaTenancy(monitor *v1alpha1.TidbMonitor, tcs []*v1alpha1.TidbCluster) error {
+	username, password, err := m.getGrafanaCredentials(monitor)
+	if err != nil {
+		return err
+	}
+	tenancy := monitor.Spec.Grafana.Tenancy
+	tenants := getGrafanaTenants(tenancy, tcs)
+	permission := grafanaPermissionLevel(tenancy.GetPermission())
+	hashes := map[string]string{}
+	synced, enforced := false, true
+	defer func() {
+		// the hashes of the synced Grafanas are kept even if others failed
+		monitor.Status.GrafanaTenancyHashes = hashes
+		if synced {
+			setGrafanaTenancyCondition(monitor, enforced || len(tenants) == 0)
+		}
+	}()
+	for shard := int32(0); shard < monitor.GetShards(); shard++ {
+		name := GrafanaName(monitor.Name, shard)
+		hash, err := m.getGrafanaTenancyHash(monitor, shard, tenants, permission)
+		if err != nil {
+			return err
+		}
+		if hash != "" && monitor.Status.GrafanaTenancyHashes[name] == hash {
+			hashes[name] = hash
+			continue
+		}
+
+		url := fmt.Sprintf("http://%s.%s:%d", name, monitor.Namespace, grafanaPort)
+		cli := newGrafanaClient(url, username, password)
+		supported, err := syncGrafanaTenants(cli, tenants, permission)
+		if err != nil {
+			return controller.RequeueErrorf("tm[%s/%s] sync tenants of Grafana %s failed: %v", monitor.Namespace, monitor.Name, url, err)
+		}
+		hashes[name] = hash
+		synced, enforced = true, enforced && supported
+		if !supported && len(tenants) > 0 {
+			klog.Warningf("tm[%s/%s] Grafana %s doesn't support datasource permissions", monitor.Namespace, monitor.Name, url)
+			m.deps.Recorder.Eventf(monitor, corev1.EventTypeWarning, eventReasonGrafanaDatasourcePermissionsUnsupported,
+				"Grafana %s doesn't support datasource permissions, the datasources of all clusters can be queried by all users", url)
+		}
+	}
+	klog.V(4).Infof("tm[%s/%s]'s Grafana tenants synced", monitor.Namespace, monitor.Name)
+	return nil
+}
+
+// getGrafanaTenancyHash returns the hash of the tenants to be synced to the Grafana of the shard. The uid of
+// the pod is a part of the hash, because the shared dashboards are provisioned by the initializer of the pod
+// and the objects in Grafana may be lost when the pod is recreated. An empty string is returned if the pod is
+// not found, the Grafana is always synced then.
+func (m *MonitorManager) getGrafanaTenancyHash(monitor *v1alpha1.TidbMonitor, shard int32, tenants []grafanaTenant, permission int) (string, error) {
+	podName := fmt.Sprintf("%s-0", GetMonitorShardName(monitor.Name, shard))
+	pod, err := m.deps.PodLister.Pods(monitor.Namespace).Get(podName)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("tm[%s/%s] get pod %s failed: %v", monitor.Namespace, monitor.Name, podName, err)
+	}
+	data, err := json.Marshal(struct {
+		PodUID     string
+		Tenants    []grafanaTenant
+		Permission int
+	}{string(pod.UID), tenants, permission})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// setGrafanaTenancyCondition sets the GrafanaTenancyEnforced condition of the TidbMonitor
+func setGrafanaTenancyCondition(monitor *v1alpha1.TidbMonitor, enforced bool) {
+	cond := metav1.Condition{
+		Type:    v1alpha1.TidbMonitorGrafanaTenancyEnforced,
+		Status:  metav1.ConditionTrue,
+		Reason:  "DatasourcePermissionsEnforced",
+		Message: "Only the team owning a cluster can query its datasource",
+	}
+	if !enforced {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = eventReasonGrafanaDatasourcePermissionsUnsupported
+		cond.Message = "Grafana doesn't support datasource permissions, the datasources of all clusters can be queried by all users"
+	}
+	meta.SetStatusCondition(&monitor.Status.Conditions, cond)
+}
+
+func clearGrafanaTenancyStatus(monitor *v1alpha1.TidbMonitor) {
+	monitor.Status.GrafanaTenancyHashes = nil
+	meta.RemoveStatusCondition(&monitor.Status.Conditions, v1alpha1.TidbMonitorGrafanaTenancyEnforced)
+}
+
+// syncGrafanaTenants syncs the Grafana objects of tenants, it returns whether the Grafana
+// enforces the permissions of the datasources.
+func syncGrafanaTenants(cli *grafanaClient, tenants []grafanaTenant, permission int) (bool, error) {
+	// the shared folder is created by the provisioning of Grafana
+	shared, err := cli.getFolder(grafanaSharedFolderUID)
+	if err != nil {
+		return false, err
+	}
+	if err := cli.setFolderPermissions(shared.UID, nil); err != nil {
+		return false, err
+	}
+	hits, err := cli.searchDashboards(shared.ID)
+	if err != nil {
+		return false, err
+	}
+	var sources []map[string]interface{}
+	for _, hit := range hits {
+		dashboard, err := cli.getDashboard(hit.UID)
+		if err != nil {
+			return false, err
+		}
+		sources = append(sources, dashboard)
+	}
+	sharedDS, err := cli.getDatasourceByName(grafanaSharedDatasource)
+	if err != nil {
+		return false, err
+	}
+	sharedDSUID, _ := sharedDS["uid"].(string)
+
+	enforced := true
+	desired := map[string]bool{}
+	for _, tenant := range tenants {
+		desired[tenant.FolderUID] = true
+		supported, err := syncGrafanaTenant(cli, tenant, permission, sources, sharedDS, sharedDSUID)
+		if err != nil {
+			return false, fmt.Errorf("sync tenant %s failed: %v", tenant.Title, err)
+		}
+		enforced = enforced && supported
+	}
+
+	folders, err := cli.listFolders()
+	if err != nil {
+		return false, err
+	}
+	for _, folder := range folders {
+		if isGrafanaTenantUID(folder.UID) && !desired[folder.UID] {
+			klog.Infof("delete Grafana folder %s of the cluster no longer monitored", folder.Title)
+			if err := cli.deleteFolder(folder.UID); err != nil {
+				return false, err
+			}
+		}
+	}
+	datasources, err := cli.listDatasources()
+	if err != nil {
+		return false, err
+	}
+	for _, ds := range datasources {
+		uid, _ := ds["uid"].(string)
+		if isGrafanaTenantUID(uid) && !desired[uid] {
+			klog.Infof("delete Grafana datasource %v of the cluster no longer monitored", ds["name"])
+			if err := cli.deleteDatasource(uid); err != nil {
+				return false, err
+			}
+		}
+	}
+	return enforced, nil
+}
+
+// syncGrafanaTenant syncs the folder, datasource and dashboards of the tenant, it returns
+// whether the Grafana supports the permissions of the datasource.
+func syncGrafanaTenant(cli *grafanaClient, tenant grafanaTenant, permission int, sources []map[string]interface{},
+	sharedDS map[string]interface{}, sharedDSUID string) (bool, error) {
+	ds, err := syncGrafanaTenantDatasource(cli, tenant, sharedDS)
+	if err != nil {
+		return false, err
+	}
+
+	folder, err := cli.ensureFolder(tenant.FolderUID, tenant.Title)
+	if err != nil {
+		return false, err
+	}
+	var items []grafanaPermissionItem
+	if tenant.Team != "" {
+		teamID, err := cli.ensureTeam(tenant.Team)
+		if err != nil {
+			return false, err
+		}
+		items = append(items, grafanaPermissionItem{TeamID: teamID, Permission: permission})
+	}
+	if err := cli.setFolderPermissions(folder.UID, items); err != nil {
+		return false, err
+	}
+	dsID, _ := ds["id"].(float64)
+	// only the team can query the datasource, it's always View for datasources
+	var dsItems []grafanaPermissionItem
+	for _, item := range items {
+		dsItems = append(dsItems, grafanaPermissionItem{TeamID: item.TeamID, Permission: grafanaPermissionView})
+	}
+	supported, err := cli.setDatasourcePermissions(int64(dsID), dsItems)
+	if err != nil {
+		return false, err
+	}
+
+	hits, err := cli.searchDashboards(folder.ID)
+	if err != nil {
+		return false, err
+	}
+	existing := map[string][]string{}
+	for _, hit := range hits {
+		existing[hit.UID] = hit.Tags
+	}
+	copied := map[string]bool{}
+	for _, source := range sources {
+		dashboard, hash, err := pinDashboardToTenant(source, tenant, sharedDSUID)
+		if err != nil {
+			return false, err
+		}
+		uid := dashboard["uid"].(string)
+		copied[uid] = true
+		if tags, ok := existing[uid]; ok && containsString(tags, grafanaSyncTagPrefix+hash) {
+			continue
+		}
+		if err := cli.saveDashboard(dashboard, folder.ID); err != nil {
+			return false, err
+		}
+	}
+	for uid := range existing {
+		if !copied[uid] {
+			if err := cli.deleteDashboard(uid); err != nil {
+				return false, err
+			}
+		}
+	}
+	return supported, nil
+}
+
+// syncGrafanaTenantDatasource ensures the datasource of the tenant, which queries Prometheus through
+// the label proxy with the cluster of the tenant in the header, so only the metrics of the cluster
+// can be queried by the datasource.
+func syncGrafanaTenantDatasource(cli *grafanaClient, tenant grafanaTenant, sharedDS map[string]interface{}) (map[string]interface{}, error) {
+	url := fmt.Sprintf("http://127.0.0.1:%d", grafanaLabelProxyPort)
+	// the datasource shares the uid with the folder, they are in different namespaces of Grafana
+	ds, err := cli.getDatasourceByUID(tenant.FolderUID)
+	if isGrafanaStatus(err, http.StatusNotFound) {
+		ds = map[string]interface{}{}
+		for _, k := range []string{"type", "access", "basicAuth", "basicAuthUser", "withCredentials", "jsonData"} {
+			if v, ok := sharedDS[k]; ok {
+				ds[k] = v
+			}
+		}
+		ds["uid"] = tenant.FolderUID
+		ds["name"] = tenant.Datasource
+		ds["isDefault"] = false
+		setGrafanaTenantDatasource(ds, tenant, url)
+		if err = cli.createDatasource(ds); err == nil {
+			ds, err = cli.getDatasourceByUID(tenant.FolderUID)
+		}
+		return ds, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, _ := ds["jsonData"].(map[string]interface{})
+	if ds["url"] == url && jsonData["httpHeaderName1"] == grafanaLabelProxyHeader {
+		return ds, nil
+	}
+	// the datasource is created by an old version or modified, the value of the header can't be
+	// read back from Grafana, so it's set again with the url
+	klog.Infof("update Grafana datasource %s to query through the label proxy", tenant.Datasource)
+	setGrafanaTenantDatasource(ds, tenant, url)
+	dsID, _ := ds["id"].(float64)
+	if err := cli.updateDatasource(int64(dsID), ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func setGrafanaTenantDatasource(ds map[string]interface{}, tenant grafanaTenant, url string) {
+	jsonData := map[string]interface{}{}
+	if data, ok := ds["jsonData"].(map[string]interface{}); ok {
+		for k, v := range data {
+			jsonData[k] = v
+		}
+	}
+	jsonData["httpHeaderName1"] = grafanaLabelProxyHeader
+	ds["jsonData"] = jsonData
+	ds["secureJsonData"] = map[string]interface{}{"httpHeaderValue1": tenant.Cluster}
+	ds["url"] = url
+}
+
+// pinDashboardToTenant returns a copy of the shared dashboard querying the datasource of the tenant
+// with the cluster variable fixed, and the hash of the copy to detect changes of the source.
+func pinDashboardToTenant(source map[string]interface{}, tenant grafanaTenant, sharedDSUID string) (map[string]interface{}, string, error) {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil, "", err
+	}
+	var dashboard map[string]interface{}
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		return nil, "", err
+	}
+	srcUID, _ := source["uid"].(string)
+	delete(dashboard, "id")
+	delete(dashboard, "version")
+	dashboard["uid"] = grafanaTenantUID(tenant.FolderUID + "/" + srcUID)
+
+	replaceDatasource(dashboard, tenant, sharedDSUID)
+	if templating, ok := dashboard["templating"].(map[string]interface{}); ok {
+		list, _ := templating["list"].([]interface{})
+		for _, v := range list {
+			variable, ok := v.(map[string]interface{})
+			if !ok || variable["name"] != grafanaClusterVariable {
+				continue
+			}
+			variable["type"] = "constant"
+			variable["query"] = tenant.Cluster
+			variable["hide"] = 2
+			variable["current"] = map[string]interface{}{"text": tenant.Cluster, "value": tenant.Cluster}
+			variable["options"] = []interface{}{map[string]interface{}{"text": tenant.Cluster, "value": tenant.Cluster, "selected": true}}
+			delete(variable, "datasource")
+			delete(variable, "refresh")
+		}
+	}
+
+	data, err = json.Marshal(dashboard)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:16]
+	var tags []interface{}
+	if t, ok := dashboard["tags"].([]interface{}); ok {
+		for _, tag := range t {
+			if s, ok := tag.(string); ok && strings.HasPrefix(s, grafanaSyncTagPrefix) {
+				continue
+			}
+			tags = append(tags, tag)
+		}
+	}
+	dashboard["tags"] = append(tags, grafanaSyncTagPrefix+hash)
+	return dashboard, hash, nil
+}
+
+// replaceDatasource points the references to the shared datasource to the one of the tenant.
+// A reference is a name before Grafana 8.3, and an object with the uid since then. A null
+// reference is the default datasource, which is the shared one.
+func replaceDatasource(obj interface{}, tenant grafanaTenant, sharedDSUID string) {
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range o {
+			if k != "datasource" {
+				replaceDatasource(v, tenant, sharedDSUID)
+				continue
+			}
+			switch ds := v.(type) {
+			case nil:
+				o[k] = tenant.Datasource
+			case string:
+				if ds == grafanaSharedDatasource || ds == "${DS_TIDB-CLUSTER}" {
+					o[k] = tenant.Datasource
+				}
+			case map[string]interface{}:
+				if uid, _ := ds["uid"].(string); uid != "" && uid == sharedDSUID {
+					ds["uid"] = tenant.FolderUID
+				}
+			}
+		}
+	case []interface{}:
+		for _, v := range o {
+			replaceDatasource(v, tenant, sharedDSUID)
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newSharedDashboard() map[string]interface{} {
+	var dashboard map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"id": 3,
+		"uid": "overview",
+		"title": "Overview",
+		"version": 2,
+		"panels": [
+			{"title": "QPS", "datasource": "tidb-cluster"},
+			{"title": "Duration", "datasource": {"type": "prometheus", "uid": "shared-uid"}},
+			{"title": "Up", "datasource": null},
+			{"title": "Nodes", "datasource": "kubernetes"}
+		],
+		"templating": {"list": [
+			{"name": "tidb_cluster", "type": "query", "datasource": "tidb-cluster", "query": "label_values(pd_cluster_status, tidb_cluster)", "refresh": 2}
+		]}
+	}`), &dashboard)
+	return dashboard
+}
+
+func TestPinDashboardToTenant(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tenant := getGrafanaTenants(&v1alpha1.GrafanaTenancySpec{}, []*v1alpha1.TidbCluster{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "basic", Labels: map[string]string{v1alpha1.DefaultGrafanaTeamLabelKey: "team-a"}},
+	}})[0]
+	g.Expect(tenant.Cluster).To(Equal("ns-basic"))
+	g.Expect(tenant.Team).To(Equal("team-a"))
+	g.Expect(isGrafanaTenantUID(tenant.FolderUID)).To(BeTrue())
+
+	source := newSharedDashboard()
+	dashboard, hash, err := pinDashboardToTenant(source, tenant, "shared-uid")
+	g.Expect(err).To(Succeed())
+	g.Expect(source["uid"]).To(Equal("overview"), "the source should not be changed")
+	g.Expect(dashboard).NotTo(HaveKey("id"))
+	g.Expect(dashboard).NotTo(HaveKey("version"))
+	g.Expect(dashboard["uid"]).NotTo(Equal("overview"))
+	g.Expect(dashboard["tags"]).To(Equal([]interface{}{grafanaSyncTagPrefix + hash}))
+
+	panels := dashboard["panels"].([]interface{})
+	g.Expect(panels[0].(map[string]interface{})["datasource"]).To(Equal("ns/basic"))
+	g.Expect(panels[1].(map[string]interface{})["datasource"]).To(Equal(map[string]interface{}{"type": "prometheus", "uid": tenant.FolderUID}))
+	g.Expect(panels[2].(map[string]interface{})["datasource"]).To(Equal("ns/basic"))
+	g.Expect(panels[3].(map[string]interface{})["datasource"]).To(Equal("kubernetes"))
+
+	variable := dashboard["templating"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})
+	g.Expect(variable["type"]).To(Equal("constant"))
+	g.Expect(variable["query"]).To(Equal("ns-basic"))
+	g.Expect(variable).NotTo(HaveKey("datasource"))
+
+	// the hash is stable
+	_, hash2, err := pinDashboardToTenant(newSharedDashboard(), tenant, "shared-uid")
+	g.Expect(err).To(Succeed())
+	g.Expect(hash2).To(Equal(hash))
+}
+
+// fakeGrafana implements the part of the Grafana API used by the tenancy
+type fakeGrafana struct {
+	sync.Mutex
+	nextID      int64
+	folders     map[string]*grafanaFolder
+	permissions map[string][]grafanaPermissionItem
+	dashboards  map[string]map[string]interface{}
+	dashFolder  map[string]int64
+	datasources map[string]map[string]interface{}
+	teams       map[string]int64
+	saves       int
+	dsUpdates   int
+}
+
+func newFakeGrafana() *fakeGrafana {
+	f := &fakeGrafana{
+		nextID:      100,
+		folders:     map[string]*grafanaFolder{grafanaSharedFolderUID: {ID: 1, UID: grafanaSharedFolderUID, Title: grafanaSharedFolderTitle}},
+		permissions: map[string][]grafanaPermissionItem{},
+		dashboards:  map[string]map[string]interface{}{"overview": newSharedDashboard()},
+		dashFolder:  map[string]int64{"overview": 1},
+		datasources: map[string]map[string]interface{}{"shared-uid": {"id": float64(1), "uid": "shared-uid", "name": grafanaSharedDatasource, "type": "prometheus", "url": "http://127.0.0.1:9090"}},
+		teams:       map[string]int64{},
+	}
+	return f
+}
+
+func (f *fakeGrafana) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	reply := func(v interface{}) { json.NewEncoder(w).Encode(v) }
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	f.nextID++
+	switch {
+	case parts[0] == "folders" && len(parts) == 1 && r.Method == http.MethodGet:
+		var list []grafanaFolder
+		for _, folder := range f.folders {
+			list = append(list, *folder)
+		}
+		reply(list)
+	case parts[0] == "folders" && len(parts) == 1 && r.Method == http.MethodPost:
+		folder := &grafanaFolder{ID: f.nextID, UID: body["uid"].(string), Title: body["title"].(string)}
+		f.folders[folder.UID] = folder
+		reply(folder)
+	case parts[0] == "folders" && len(parts) == 3:
+		data, _ := json.Marshal(body["items"])
+		var items []grafanaPermissionItem
+		json.Unmarshal(data, &items)
+		f.permissions[parts[1]] = items
+	case parts[0] == "folders" && r.Method == http.MethodDelete:
+		for uid, id := range f.dashFolder {
+			if id == f.folders[parts[1]].ID {
+				delete(f.dashboards, uid)
+			}
+		}
+		delete(f.folders, parts[1])
+	case parts[0] == "folders":
+		folder, ok := f.folders[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reply(folder)
+	case parts[0] == "search":
+		folderID, _ := strconv.ParseInt(r.URL.Query().Get("folderIds"), 10, 64)
+		var hits []grafanaSearchHit
+		for uid, d := range f.dashboards {
+			if f.dashFolder[uid] == folderID {
+				var tags []string
+				list, _ := d["tags"].([]interface{})
+				for _, tag := range list {
+					tags = append(tags, tag.(string))
+				}
+				hits = append(hits, grafanaSearchHit{UID: uid, Tags: tags})
+			}
+		}
+		reply(hits)
+	case parts[0] == "dashboards" && parts[1] == "db":
+		f.saves++
+		d := body["dashboard"].(map[string]interface{})
+		f.dashboards[d["uid"].(string)] = d
+		f.dashFolder[d["uid"].(string)] = int64(body["folderId"].(float64))
+	case parts[0] == "dashboards" && r.Method == http.MethodDelete:
+		delete(f.dashboards, parts[2])
+	case parts[0] == "dashboards":
+		d, ok := f.dashboards[parts[2]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reply(map[string]interface{}{"dashboard": d})
+	case parts[0] == "datasources" && len(parts) == 1 && r.Method == http.MethodGet:
+		var list []map[string]interface{}
+		for _, ds := range f.datasources {
+			list = append(list, ds)
+		}
+		reply(list)
+	case parts[0] == "datasources" && len(parts) == 1:
+		body["id"] = float64(f.nextID)
+		f.datasources[body["uid"].(string)] = body
+	case parts[0] == "datasources" && parts[1] == "name":
+		for _, ds := range f.datasources {
+			if ds["name"] == parts[2] {
+				reply(ds)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case parts[0] == "datasources" && parts[1] == "uid" && r.Method == http.MethodDelete:
+		delete(f.datasources, parts[2])
+	case parts[0] == "datasources" && parts[1] == "uid":
+		ds, ok := f.datasources[parts[2]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reply(ds)
+	case parts[0] == "datasources" && len(parts) == 2 && r.Method == http.MethodPut:
+		for uid, ds := range f.datasources {
+			if fmt.Sprint(ds["id"]) == parts[1] {
+				f.dsUpdates++
+				body["id"] = ds["id"]
+				f.datasources[uid] = body
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case parts[0] == "datasources":
+		// the datasource permissions are only supported by Grafana Enterprise
+		w.WriteHeader(http.StatusNotFound)
+	case parts[0] == "teams" && len(parts) == 2:
+		var teams []grafanaTeam
+		if id, ok := f.teams[r.URL.Query().Get("name")]; ok {
+			teams = append(teams, grafanaTeam{ID: id, Name: r.URL.Query().Get("name")})
+		}
+		reply(map[string]interface{}{"teams": teams})
+	case parts[0] == "teams":
+		f.teams[body["name"].(string)] = f.nextID
+		reply(map[string]interface{}{"teamId": f.nextID})
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestSyncGrafanaTenants(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fake := newFakeGrafana()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	cli := newGrafanaClient(server.URL, "admin", "admin")
+
+	tcs := []*v1alpha1.TidbCluster{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a", Labels: map[string]string{"team": "team-a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "b"}},
+	}
+	tenants := getGrafanaTenants(&v1alpha1.GrafanaTenancySpec{TeamLabelKey: "team"}, tcs)
+	enforced, err := syncGrafanaTenants(cli, tenants, grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	// the fake Grafana doesn't support datasource permissions
+	g.Expect(enforced).To(BeFalse())
+
+	g.Expect(fake.folders).To(HaveLen(3))
+	g.Expect(fake.datasources).To(HaveLen(3))
+	g.Expect(fake.dashboards).To(HaveLen(3))
+	g.Expect(fake.saves).To(Equal(2))
+	g.Expect(fake.teams).To(HaveKey("team-a"))
+	g.Expect(fake.permissions[grafanaSharedFolderUID]).To(BeEmpty())
+	g.Expect(fake.permissions[tenants[0].FolderUID]).To(Equal([]grafanaPermissionItem{{TeamID: fake.teams["team-a"], Permission: grafanaPermissionView}}))
+	g.Expect(fake.permissions[tenants[1].FolderUID]).To(BeEmpty())
+	// the datasources of tenants query through the label proxy with their own clusters
+	for _, tenant := range tenants {
+		ds := fake.datasources[tenant.FolderUID]
+		g.Expect(ds["url"]).To(Equal("http://127.0.0.1:9096"))
+		g.Expect(ds["jsonData"]).To(HaveKeyWithValue("httpHeaderName1", grafanaLabelProxyHeader))
+		g.Expect(ds["secureJsonData"]).To(Equal(map[string]interface{}{"httpHeaderValue1": tenant.Cluster}))
+	}
+
+	// unchanged dashboards and datasources are not saved again
+	_, err = syncGrafanaTenants(cli, tenants, grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	g.Expect(fake.saves).To(Equal(2))
+	g.Expect(fake.dsUpdates).To(Equal(0))
+
+	// the datasource querying Prometheus directly is updated
+	fake.datasources[tenants[0].FolderUID]["url"] = "http://127.0.0.1:9090"
+	delete(fake.datasources[tenants[0].FolderUID], "secureJsonData")
+	_, err = syncGrafanaTenants(cli, tenants, grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	g.Expect(fake.dsUpdates).To(Equal(1))
+	g.Expect(fake.datasources[tenants[0].FolderUID]["url"]).To(Equal("http://127.0.0.1:9096"))
+	g.Expect(fake.datasources[tenants[0].FolderUID]["secureJsonData"]).To(Equal(map[string]interface{}{"httpHeaderValue1": tenants[0].Cluster}))
+
+	// the objects of the clusters no longer monitored are deleted
+	_, err = syncGrafanaTenants(cli, tenants[:1], grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	g.Expect(fake.folders).To(HaveLen(2))
+	g.Expect(fake.folders).NotTo(HaveKey(tenants[1].FolderUID))
+	g.Expect(fake.datasources).To(HaveLen(2))
+	g.Expect(fake.dashboards).To(HaveLen(2))
+}
+
+func TestSyncGrafanaTenancySkipsUnchanged(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	m := newFakeTidbMonitorManager()
+	tm := &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+		Spec: v1alpha1.TidbMonitorSpec{
+			Grafana: &v1alpha1.GrafanaSpec{Tenancy: &v1alpha1.GrafanaTenancySpec{}},
+		},
+	}
+	tcs := []*v1alpha1.TidbCluster{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a", Labels: map[string]string{v1alpha1.DefaultGrafanaTeamLabelKey: "team-a"}}},
+	}
+	tenants := getGrafanaTenants(tm.Spec.Grafana.Tenancy, tcs)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo-monitor-0", UID: "uid-1"}}
+	podIndexer := m.deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	g.Expect(podIndexer.Add(pod)).To(Succeed())
+
+	hash, err := m.getGrafanaTenancyHash(tm, 0, tenants, grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	g.Expect(hash).NotTo(BeEmpty())
+
+	// the hash is changed with the tenants, the permission and the pod
+	other, err := m.getGrafanaTenancyHash(tm, 0, nil, grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	g.Expect(other).NotTo(Equal(hash))
+	other, err = m.getGrafanaTenancyHash(tm, 0, tenants, grafanaPermissionEdit)
+	g.Expect(err).To(Succeed())
+	g.Expect(other).NotTo(Equal(hash))
+	recreated := pod.DeepCopy()
+	recreated.UID = "uid-2"
+	g.Expect(podIndexer.Update(recreated)).To(Succeed())
+	other, err = m.getGrafanaTenancyHash(tm, 0, tenants, grafanaPermissionView)
+	g.Expect(err).To(Succeed())
+	g.Expect(other).NotTo(Equal(hash))
+	g.Expect(podIndexer.Update(pod)).To(Succeed())
+
+	// the unchanged Grafana is not requested, and the condition is kept
+	tm.Status.GrafanaTenancyHashes = map[string]string{"foo-grafana": hash, "foo-grafana-shard-1": "stale"}
+	setGrafanaTenancyCondition(tm, false)
+	g.Expect(m.syncGrafanaTenancy(tm, tcs)).To(Succeed())
+	g.Expect(tm.Status.GrafanaTenancyHashes).To(Equal(map[string]string{"foo-grafana": hash}))
+	g.Expect(tm.Status.Conditions).To(HaveLen(1))
+	g.Expect(tm.Status.Conditions[0].Type).To(Equal(v1alpha1.TidbMonitorGrafanaTenancyEnforced))
+	g.Expect(tm.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+
+	setGrafanaTenancyCondition(tm, true)
+	g.Expect(tm.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+	clearGrafanaTenancyStatus(tm)
+	g.Expect(tm.Status.Conditions).To(BeEmpty())
+	g.Expect(tm.Status.GrafanaTenancyHashes).To(BeNil())
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -51,6 +52,8 @@ type MonitorManager struct {
 	deps               *controller.Dependencies
 	pvManager          monitor.MonitorManager
 	discoveryInterface discovery.CachedDiscoveryInterface
+	// operatorNamespace is the namespace of the controller-manager
+	operatorNamespace string
 }
 
 const (
@@ -66,6 +69,7 @@ func NewMonitorManager(deps *controller.Dependencies) *MonitorManager {
 		deps:               deps,
 		pvManager:          meta.NewReclaimPolicyManager(deps),
 		discoveryInterface: discoverycachedmemory.NewMemCacheClient(deps.KubeClientset.Discovery()),
+		operatorNamespace:  os.Getenv("NAMESPACE"),
 	}
 }
 
@@ -88,6 +92,7 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	}
 
 	var firstTc *v1alpha1.TidbCluster
+	var tcs []*v1alpha1.TidbCluster
 	assetStore := NewStore(m.deps.SecretLister)
 
 	for _, tcRef := range monitor.Spec.Clusters {
@@ -109,6 +114,15 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 		if firstTc == nil && !tc.WithoutLocalPD() {
 			firstTc = tc
 		}
+		tcs = append(tcs, tc)
+		if monitor.Spec.PrometheusOperator != nil {
+			// the bundled Prometheus and Grafana are not deployed
+			continue
+		}
+		if monitor.IsAgentMode() {
+			// TiDB Dashboard can't query Prometheus in agent mode
+			continue
+		}
 		err = m.syncDashboardMetricStorage(tc, monitor)
 		if err != nil {
 			klog.Errorf("Fail to sync TiDB Dashboard metrics config for TiDB cluster [%s/%s], error: %v", tc.Namespace, tc.Name, err)
@@ -143,6 +157,28 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 	if err != nil {
 		return err
 	}
+
+	if monitor.Spec.PrometheusOperator != nil {
+		if err := m.syncPrometheusOperatorObjects(monitor); err != nil {
+			message := fmt.Sprintf("Sync TidbMonitor[%s/%s] Prometheus Operator objects failed, err: %v", monitor.Namespace, monitor.Name, err)
+			m.deps.Recorder.Event(monitor, corev1.EventTypeWarning, FailedSync, message)
+			return err
+		}
+		klog.V(4).Infof("tm[%s/%s]'s Prometheus Operator objects synced", monitor.Namespace, monitor.Name)
+
+		if err := m.removeBundledPrometheus(monitor); err != nil {
+			message := fmt.Sprintf("Remove TidbMonitor[%s/%s] bundled Prometheus failed, err: %v", monitor.Namespace, monitor.Name, err)
+			m.deps.Recorder.Event(monitor, corev1.EventTypeWarning, FailedSync, message)
+			return err
+		}
+
+		if err := m.syncTidbMonitorStatus(monitor); err != nil {
+			klog.Errorf("Fail to sync tm[%s/%s]'s status, err: %v", monitor.Namespace, monitor.Name, err)
+			return err
+		}
+		return nil
+	}
+
 	// sync basicAuth
 	err = m.syncBasicAuth(monitor, assetStore)
 	if err != nil {
@@ -191,6 +227,15 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 		return err
 	}
 
+	// Sync Grafana tenants at last, it's requeued until Grafana is ready
+	if monitor.IsGrafanaTenancyEnabled() {
+		if err := m.syncGrafanaTenancy(monitor, tcs); err != nil {
+			return err
+		}
+	} else {
+		clearGrafanaTenancyStatus(monitor)
+	}
+
 	return nil
 }
 
@@ -256,6 +301,7 @@ func (m *MonitorManager) syncTidbMonitorStatus(monitor *v1alpha1.TidbMonitor) er
 	if err != nil {
 		if errors.IsNotFound(err) {
 			klog.V(4).Infof("tm[%s/%s]'s sts not found", monitor.Namespace, monitor.Name)
+			monitor.Status.StatefulSet = nil
 			return nil
 		}
 		return err
@@ -394,49 +440,23 @@ func (m *MonitorManager) syncTidbMonitorConfig(monitor *v1alpha1.TidbMonitor, st
 		monitor = cloned
 	}
 
-	var monitorClusterInfos []ClusterRegexInfo
-	for _, tcRef := range monitor.Spec.Clusters {
-		tc, err := m.deps.TiDBClusterLister.TidbClusters(tcRef.Namespace).Get(tcRef.Name)
-		if err != nil {
-			rerr := fmt.Errorf("get tm[%s/%s]'s target tc[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, tcRef.Namespace, tcRef.Name, err)
-			return rerr
-		}
-		clusterRegex := ClusterRegexInfo{
-			Name:      tcRef.Name,
-			Namespace: tcRef.Namespace,
-		}
-		// If cluster enable tls
-		if tc.IsTLSClusterEnabled() {
-			clusterRegex.enableTLS = true
-		}
-		monitorClusterInfos = append(monitorClusterInfos, clusterRegex)
-	}
-
-	var dmClusterInfos []ClusterRegexInfo
-	if monitor.Spec.DM != nil {
-		for _, dmRef := range monitor.Spec.DM.Clusters {
-			dm, err := m.deps.DMClusterLister.DMClusters(dmRef.Namespace).Get(dmRef.Name)
-			if err != nil {
-				rerr := fmt.Errorf("get tm[%s/%s]'s target dm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, dmRef.Namespace, dmRef.Name, err)
-				return rerr
-			}
-			clusterRegex := ClusterRegexInfo{
-				Name:      dmRef.Name,
-				Namespace: dmRef.Namespace,
-			}
-			// If cluster enable tls
-			if dm.IsTLSClusterEnabled() {
-				clusterRegex.enableTLS = true
-			}
-			dmClusterInfos = append(dmClusterInfos, clusterRegex)
-		}
+	monitorClusterInfos, dmClusterInfos, err := m.getClusterRegexInfos(monitor)
+	if err != nil {
+		return err
 	}
 
 	shards := monitor.GetShards()
-	promCM, err := getPromConfigMap(monitor, monitorClusterInfos, dmClusterInfos, shards, store)
+	promCM, err := getPromConfigMap(monitor, monitorClusterInfos, dmClusterInfos, shards, store, m.operatorNamespace)
+	if err != nil {
+		return err
+	}
+	generatedRules, err := m.getGeneratedAlertRuleFile(monitor)
 	if err != nil {
 		return err
 	}
+	if generatedRules != "" {
+		promCM.Data[generatedRuleFile] = generatedRules
+	}
 	config := monitor.Spec.Prometheus.Config
 	if config != nil && config.ConfigMapRef != nil && len(config.ConfigMapRef.Name) > 0 {
 		namespace := monitor.Namespace
@@ -473,6 +493,48 @@ func (m *MonitorManager) syncTidbMonitorConfig(monitor *v1alpha1.TidbMonitor, st
 	return err
 }
 
+// getClusterRegexInfos returns the infos of the TiDB clusters and DM clusters monitored by TidbMonitor
+func (m *MonitorManager) getClusterRegexInfos(monitor *v1alpha1.TidbMonitor) ([]ClusterRegexInfo, []ClusterRegexInfo, error) {
+	var monitorClusterInfos []ClusterRegexInfo
+	for _, tcRef := range monitor.Spec.Clusters {
+		tc, err := m.deps.TiDBClusterLister.TidbClusters(tcRef.Namespace).Get(tcRef.Name)
+		if err != nil {
+			rerr := fmt.Errorf("get tm[%s/%s]'s target tc[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, tcRef.Namespace, tcRef.Name, err)
+			return nil, nil, rerr
+		}
+		clusterRegex := ClusterRegexInfo{
+			Name:      tcRef.Name,
+			Namespace: tcRef.Namespace,
+		}
+		// If cluster enable tls
+		if tc.IsTLSClusterEnabled() {
+			clusterRegex.enableTLS = true
+		}
+		monitorClusterInfos = append(monitorClusterInfos, clusterRegex)
+	}
+
+	var dmClusterInfos []ClusterRegexInfo
+	if monitor.Spec.DM != nil {
+		for _, dmRef := range monitor.Spec.DM.Clusters {
+			dm, err := m.deps.DMClusterLister.DMClusters(dmRef.Namespace).Get(dmRef.Name)
+			if err != nil {
+				rerr := fmt.Errorf("get tm[%s/%s]'s target dm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, dmRef.Namespace, dmRef.Name, err)
+				return nil, nil, rerr
+			}
+			clusterRegex := ClusterRegexInfo{
+				Name:      dmRef.Name,
+				Namespace: dmRef.Namespace,
+			}
+			// If cluster enable tls
+			if dm.IsTLSClusterEnabled() {
+				clusterRegex.enableTLS = true
+			}
+			dmClusterInfos = append(dmClusterInfos, clusterRegex)
+		}
+	}
+	return monitorClusterInfos, dmClusterInfos, nil
+}
+
 func (m *MonitorManager) syncTidbMonitorRbac(monitor *v1alpha1.TidbMonitor) (*corev1.ServiceAccount, error) {
 	sa := getMonitorServiceAccount(monitor)
 	sa, err := m.deps.TypedControl.CreateOrUpdateServiceAccount(monitor, sa)
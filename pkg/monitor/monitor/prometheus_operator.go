@@ -0,0 +1,675 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/label"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	defaultPodMonitorScrapeInterval = "15s"
+	grafanaDashboardLabelKey        = "grafana_dashboard"
+	ruleFileSuffix                  = ".rules.yml"
+	dashboardFileSuffix             = ".json"
+
+	eventReasonBundledPVCRetained = "BundledPVCRetained"
+	eventReasonBundledPVCDeleted  = "BundledPVCDeleted"
+)
+
+var (
+	podMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+	prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// podMonitorJob is a scrape job of the bundled Prometheus, it is generated as a PodMonitor
+// for every monitored cluster.
+type podMonitorJob struct {
+	name      string
+	component string
+}
+
+var podMonitorJobs = []podMonitorJob{
+	{"pd", pdPattern},
+	{"tso", pdmsTSOPattern},
+	{"scheduling", pdmsSchedulingPattern},
+	{"tidb", tidbPattern},
+	{"tikv", tikvPattern},
+	{"tiproxy", tiproxyPattern},
+	{"tiflash", tiflashPattern},
+	{"tiflash-proxy", tiflashPattern},
+	{"pump", pumpPattern},
+	{"drainer", drainerPattern},
+	{"ticdc", cdcPattern},
+	{"lightning", lightningPattern},
+	{dmWorker, dmWorkerPattern},
+	{dmMaster, dmMasterPattern},
+}
+
+// The following types are the subset of the Prometheus Operator API used by TidbMonitor.
+// See https://prometheus-operator.dev/docs/api-reference/api/
+
+type podMonitorSpec struct {
+	NamespaceSelector   namespaceSelector    `json:"namespaceSelector"`
+	Selector            metav1.LabelSelector `json:"selector"`
+	PodMetricsEndpoints []podMetricsEndpoint `json:"podMetricsEndpoints"`
+}
+
+type namespaceSelector struct {
+	MatchNames []string `json:"matchNames,omitempty"`
+}
+
+type podMetricsEndpoint struct {
+	Interval    string          `json:"interval,omitempty"`
+	Scheme      string          `json:"scheme,omitempty"`
+	HonorLabels bool            `json:"honorLabels,omitempty"`
+	TLSConfig   *safeTLSConfig  `json:"tlsConfig,omitempty"`
+	Relabelings []relabelConfig `json:"relabelings,omitempty"`
+}
+
+type safeTLSConfig struct {
+	CA                 *secretOrConfigMap        `json:"ca,omitempty"`
+	Cert               *secretOrConfigMap        `json:"cert,omitempty"`
+	KeySecret          *corev1.SecretKeySelector `json:"keySecret,omitempty"`
+	InsecureSkipVerify bool                      `json:"insecureSkipVerify,omitempty"`
+}
+
+type secretOrConfigMap struct {
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
+}
+
+type relabelConfig struct {
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	TargetLabel  string   `json:"targetLabel,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Action       string   `json:"action,omitempty"`
+}
+
+type prometheusRuleSpec struct {
+	Groups []interface{} `json:"groups"`
+}
+
+// syncPrometheusOperatorObjects generates PodMonitor, PrometheusRule and Grafana dashboard objects
+// for the monitored clusters instead of deploying the bundled Prometheus and Grafana.
+func (m *MonitorManager) syncPrometheusOperatorObjects(monitor *v1alpha1.TidbMonitor) error {
+	clusterInfos, dmClusterInfos, err := m.getClusterRegexInfos(monitor)
+	if err != nil {
+		return err
+	}
+
+	podMonitors, err := getPodMonitors(monitor, clusterInfos, dmClusterInfos, m.operatorNamespace)
+	if err != nil {
+		return err
+	}
+	names := sets.NewString()
+	for _, pm := range podMonitors {
+		if err := m.createOrUpdateUnstructured(pm); err != nil {
+			return fmt.Errorf("sync PodMonitor %s/%s for tm[%s/%s] failed, err: %v", pm.GetNamespace(), pm.GetName(), monitor.Namespace, monitor.Name, err)
+		}
+		names.Insert(pm.GetName())
+	}
+	if err := m.removeStalePodMonitors(monitor, names); err != nil {
+		return err
+	}
+	klog.V(4).Infof("tm[%s/%s]'s PodMonitors synced", monitor.Namespace, monitor.Name)
+
+	if err := m.syncPrometheusRule(monitor); err != nil {
+		return err
+	}
+	klog.V(4).Infof("tm[%s/%s]'s PrometheusRule synced", monitor.Namespace, monitor.Name)
+
+	if err := m.syncGrafanaDashboards(monitor); err != nil {
+		return err
+	}
+	klog.V(4).Infof("tm[%s/%s]'s Grafana dashboards synced", monitor.Namespace, monitor.Name)
+
+	return nil
+}
+
+// removeBundledPrometheus removes the StatefulSets and Services of the bundled Prometheus and Grafana
+// deployed before switching to the Prometheus Operator. The PVCs are only deleted if `deleteBundledPVCs`
+// is set, otherwise they are left with the data and can be deleted manually.
+func (m *MonitorManager) removeBundledPrometheus(monitor *v1alpha1.TidbMonitor) error {
+	ns := monitor.Namespace
+	for _, svc := range getMonitorService(monitor) {
+		existing, err := m.deps.ServiceLister.Services(ns).Get(svc.Name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get service %s/%s of tm[%s/%s] failed, err: %v", ns, svc.Name, ns, monitor.Name, err)
+		}
+		if !metav1.IsControlledBy(existing, monitor) {
+			continue
+		}
+		if err := m.deps.ServiceControl.DeleteService(monitor, existing); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete service %s/%s of tm[%s/%s] failed, err: %v", ns, svc.Name, ns, monitor.Name, err)
+		}
+		klog.Infof("tm[%s/%s]'s bundled service %s is deleted", ns, monitor.Name, svc.Name)
+	}
+
+	deletePVC := monitor.Spec.PrometheusOperator.DeleteBundledPVCs
+	for shard := int32(0); shard < monitor.GetShards(); shard++ {
+		name := GetMonitorShardName(monitor.Name, shard)
+		sts, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(name)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("get sts %s/%s of tm[%s/%s] failed, err: %v", ns, name, ns, monitor.Name, err)
+		}
+		stsDeleted := false
+		if err == nil && metav1.IsControlledBy(sts, monitor) {
+			if err := m.deps.StatefulSetControl.DeleteStatefulSet(monitor, sts, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("delete sts %s/%s of tm[%s/%s] failed, err: %v", ns, name, ns, monitor.Name, err)
+			}
+			klog.Infof("tm[%s/%s]'s bundled sts %s is deleted", ns, monitor.Name, name)
+			stsDeleted = true
+		}
+
+		// the pvcs created by the sts have the labels of its selector
+		selector := k8slabels.SelectorFromSet(buildTidbMonitorLabel(GetMonitorInstanceName(monitor, shard)))
+		pvcs, err := m.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+		if err != nil {
+			return fmt.Errorf("list pvcs of tm[%s/%s] failed, err: %v", ns, monitor.Name, err)
+		}
+		for _, pvc := range pvcs {
+			if !deletePVC {
+				klog.V(4).Infof("tm[%s/%s]'s bundled pvc %s is retained", ns, monitor.Name, pvc.Name)
+				// the event is only recorded when the bundled Prometheus is removed
+				if stsDeleted {
+					m.deps.Recorder.Eventf(monitor, corev1.EventTypeNormal, eventReasonBundledPVCRetained,
+						"pvc %s of the bundled Prometheus is retained, it can be deleted manually", pvc.Name)
+				}
+				continue
+			}
+			if pvc.DeletionTimestamp != nil {
+				continue
+			}
+			if err := m.deps.PVCControl.DeletePVC(monitor, pvc); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("delete pvc %s/%s of tm[%s/%s] failed, err: %v", ns, pvc.Name, ns, monitor.Name, err)
+			}
+			klog.Infof("tm[%s/%s]'s bundled pvc %s is deleted", ns, monitor.Name, pvc.Name)
+			m.deps.Recorder.Eventf(monitor, corev1.EventTypeWarning, eventReasonBundledPVCDeleted,
+				"pvc %s of the bundled Prometheus is deleted as deleteBundledPVCs is set", pvc.Name)
+		}
+	}
+	return nil
+}
+
+func (m *MonitorManager) removeStalePodMonitors(monitor *v1alpha1.TidbMonitor, names sets.String) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(podMonitorGVK.GroupVersion().WithKind(podMonitorGVK.Kind + "List"))
+	if err := m.deps.GenericClient.List(context.TODO(), list, client.InNamespace(monitor.Namespace), client.MatchingLabels(buildTidbMonitorLabel(monitor.Name))); err != nil {
+		return fmt.Errorf("list PodMonitors of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	for i := range list.Items {
+		pm := &list.Items[i]
+		if names.Has(pm.GetName()) || !metav1.IsControlledBy(pm, monitor) {
+			continue
+		}
+		if err := m.deps.GenericClient.Delete(context.TODO(), pm); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete PodMonitor %s/%s of tm[%s/%s] failed, err: %v", pm.GetNamespace(), pm.GetName(), monitor.Namespace, monitor.Name, err)
+		}
+		klog.Infof("tm[%s/%s]'s stale PodMonitor %s is deleted", monitor.Namespace, monitor.Name, pm.GetName())
+	}
+	return nil
+}
+
+func (m *MonitorManager) syncPrometheusRule(monitor *v1alpha1.TidbMonitor) error {
+	ruleFiles := map[string]string{}
+	config := monitor.Spec.Prometheus.Config
+	if config != nil && config.RuleConfigRef != nil {
+		cm, err := m.getReferencedConfigMap(monitor, config.RuleConfigRef)
+		if err != nil {
+			return err
+		}
+		for key, content := range cm.Data {
+			ruleFiles[key] = content
+		}
+	}
+	generatedRules, err := m.getGeneratedAlertRuleFile(monitor)
+	if err != nil {
+		return err
+	}
+	if generatedRules != "" {
+		ruleFiles[generatedRuleFile] = generatedRules
+	}
+
+	rule, err := getPrometheusRule(monitor, ruleFiles)
+	if err != nil {
+		return err
+	}
+	if rule != nil {
+		if err := m.createOrUpdateUnstructured(rule); err != nil {
+			return fmt.Errorf("sync PrometheusRule %s/%s for tm[%s/%s] failed, err: %v", rule.GetNamespace(), rule.GetName(), monitor.Namespace, monitor.Name, err)
+		}
+		return nil
+	}
+
+	// no rules, remove the PrometheusRule generated before
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(prometheusRuleGVK)
+	err = m.deps.GenericClient.Get(context.TODO(), client.ObjectKey{Namespace: monitor.Namespace, Name: GetMonitorObjectName(monitor)}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get PrometheusRule of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	if !metav1.IsControlledBy(existing, monitor) {
+		return nil
+	}
+	if err := m.deps.GenericClient.Delete(context.TODO(), existing); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("delete PrometheusRule of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	return nil
+}
+
+func (m *MonitorManager) syncGrafanaDashboards(monitor *v1alpha1.TidbMonitor) error {
+	dashboards := map[string]string{}
+	if spec := monitor.Spec.PrometheusOperator.GrafanaDashboards; spec != nil {
+		for i := range spec.ConfigMapRefs {
+			cm, err := m.getReferencedConfigMap(monitor, &spec.ConfigMapRefs[i])
+			if err != nil {
+				return err
+			}
+			for key, content := range cm.Data {
+				if !strings.HasSuffix(key, dashboardFileSuffix) {
+					continue
+				}
+				if _, ok := dashboards[key]; ok {
+					klog.Warningf("tm[%s/%s]'s dashboard %s is duplicated, use the one in configmap %s/%s", monitor.Namespace, monitor.Name, key, cm.Namespace, cm.Name)
+				}
+				dashboards[key] = content
+			}
+		}
+	}
+
+	names := sets.NewString()
+	for _, cm := range getGrafanaDashboardConfigMaps(monitor, dashboards) {
+		if _, err := m.deps.TypedControl.CreateOrUpdateConfigMap(monitor, cm); err != nil {
+			return fmt.Errorf("sync dashboard configmap %s/%s for tm[%s/%s] failed, err: %v", cm.Namespace, cm.Name, monitor.Namespace, monitor.Name, err)
+		}
+		names.Insert(cm.Name)
+	}
+
+	// remove the dashboards which do not exist any more
+	existing, err := m.deps.ConfigMapLister.ConfigMaps(monitor.Namespace).List(k8slabels.SelectorFromSet(buildTidbMonitorGrafanaLabel(monitor.Name)))
+	if err != nil {
+		return fmt.Errorf("list dashboard configmaps of tm[%s/%s] failed, err: %v", monitor.Namespace, monitor.Name, err)
+	}
+	prefix := getGrafanaDashboardConfigMapPrefix(monitor)
+	for _, cm := range existing {
+		if names.Has(cm.Name) || !strings.HasPrefix(cm.Name, prefix) || !metav1.IsControlledBy(cm, monitor) {
+			continue
+		}
+		if err := m.deps.TypedControl.Delete(monitor, cm); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete dashboard configmap %s/%s of tm[%s/%s] failed, err: %v", cm.Namespace, cm.Name, monitor.Namespace, monitor.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *MonitorManager) getReferencedConfigMap(monitor *v1alpha1.TidbMonitor, ref *v1alpha1.ConfigMapRef) (*corev1.ConfigMap, error) {
+	namespace := monitor.Namespace
+	if ref.Namespace != nil {
+		namespace = *ref.Namespace
+	}
+	cm, err := m.deps.ConfigMapControl.GetConfigMap(monitor, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: namespace,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get configmap %s/%s of tm[%s/%s] failed, err: %v", namespace, ref.Name, monitor.Namespace, monitor.Name, err)
+	}
+	return cm, nil
+}
+
+// createOrUpdateUnstructured creates the object or updates the spec and labels of the existing one.
+// The Prometheus Operator types are not registered in the scheme, so GenericControl can't be used.
+func (m *MonitorManager) createOrUpdateUnstructured(obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := m.deps.GenericClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), existing)
+	if errors.IsNotFound(err) {
+		return m.deps.GenericClient.Create(context.TODO(), obj.DeepCopy())
+	}
+	if err != nil {
+		return err
+	}
+
+	if apiequality.Semantic.DeepEqual(existing.Object["spec"], obj.Object["spec"]) &&
+		apiequality.Semantic.DeepEqual(existing.GetLabels(), obj.GetLabels()) {
+		return nil
+	}
+	existing.Object["spec"] = obj.Object["spec"]
+	existing.SetLabels(obj.GetLabels())
+	existing.SetOwnerReferences(obj.GetOwnerReferences())
+	return m.deps.GenericClient.Update(context.TODO(), existing)
+}
+
+func newPrometheusOperatorObject(monitor *v1alpha1.TidbMonitor, gvk schema.GroupVersionKind, name string, spec interface{}) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(monitor.Namespace)
+	l := buildTidbMonitorLabel(monitor.Name)
+	for k, v := range monitor.Spec.PrometheusOperator.Labels {
+		if _, ok := l[k]; !ok {
+			l[k] = v
+		}
+	}
+	obj.SetLabels(l)
+	obj.SetOwnerReferences([]metav1.OwnerReference{controller.GetTiDBMonitorOwnerRef(monitor)})
+	obj.Object["spec"] = content
+	return obj, nil
+}
+
+// getPodMonitors generates a PodMonitor for every component of the monitored clusters,
+// the relabel configs are the same as the scrape jobs of the bundled Prometheus.
+// The controller-manager is also scraped if the alert rules are generated.
+func getPodMonitors(monitor *v1alpha1.TidbMonitor, clusterInfos, dmClusterInfos []ClusterRegexInfo, operatorNamespace string) ([]*unstructured.Unstructured, error) {
+	interval := monitor.Spec.PrometheusOperator.ScrapeInterval
+	if interval == "" {
+		interval = defaultPodMonitorScrapeInterval
+	}
+
+	var podMonitors []*unstructured.Unstructured
+	for _, job := range podMonitorJobs {
+		clusters := clusterInfos
+		if isDMJob(job.name) {
+			clusters = dmClusterInfos
+		}
+		for _, cluster := range clusters {
+			scheme, tlsConfig := getPodMonitorTLSConfig(monitor, job.name, cluster)
+			endpoint := podMetricsEndpoint{
+				Interval:    interval,
+				Scheme:      scheme,
+				HonorLabels: true,
+				TLSConfig:   tlsConfig,
+				Relabelings: getPodMonitorRelabelConfigs(job.name, cluster),
+			}
+			spec := &podMonitorSpec{
+				NamespaceSelector: namespaceSelector{
+					MatchNames: []string{cluster.Namespace},
+				},
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						label.InstanceLabelKey:  cluster.Name,
+						label.ComponentLabelKey: job.component,
+					},
+				},
+				PodMetricsEndpoints: []podMetricsEndpoint{endpoint},
+			}
+			name := fmt.Sprintf("%s-%s-%s-%s", monitor.Name, cluster.Namespace, cluster.Name, job.name)
+			pm, err := newPrometheusOperatorObject(monitor, podMonitorGVK, name, spec)
+			if err != nil {
+				return nil, err
+			}
+			podMonitors = append(podMonitors, pm)
+		}
+	}
+
+	if monitor.Spec.GeneratedAlertRules != nil && operatorNamespace != "" {
+		pm, err := getControllerManagerPodMonitor(monitor, operatorNamespace, interval)
+		if err != nil {
+			return nil, err
+		}
+		podMonitors = append(podMonitors, pm)
+	}
+	return podMonitors, nil
+}
+
+func getControllerManagerPodMonitor(monitor *v1alpha1.TidbMonitor, operatorNamespace, interval string) (*unstructured.Unstructured, error) {
+	relabelings := []relabelConfig{
+		{
+			// keep the job name of the bundled Prometheus
+			TargetLabel: "job",
+			Replacement: controllerManagerJob,
+			Action:      "replace",
+		},
+	}
+	for _, item := range controllerManagerRelabelConfigs() {
+		relabelings = append(relabelings, toRelabelConfig(item))
+	}
+	spec := &podMonitorSpec{
+		NamespaceSelector: namespaceSelector{
+			MatchNames: []string{operatorNamespace},
+		},
+		Selector: metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				label.NameLabelKey:      controllerManagerNamePattern,
+				label.ComponentLabelKey: controllerManagerPattern,
+			},
+		},
+		PodMetricsEndpoints: []podMetricsEndpoint{
+			{
+				Interval:    interval,
+				Scheme:      "http",
+				HonorLabels: true,
+				Relabelings: relabelings,
+			},
+		},
+	}
+	return newPrometheusOperatorObject(monitor, podMonitorGVK, fmt.Sprintf("%s-%s", monitor.Name, controllerManagerJob), spec)
+}
+
+func getPodMonitorTLSConfig(monitor *v1alpha1.TidbMonitor, jobName string, cluster ClusterRegexInfo) (string, *safeTLSConfig) {
+	if !cluster.enableTLS {
+		return "http", nil
+	}
+
+	var secretName string
+	switch {
+	case jobName == "tiproxy", jobName == "lightning":
+		// the same as the bundled Prometheus, see scrapeJob
+		return "https", &safeTLSConfig{InsecureSkipVerify: true}
+	case isDMJob(jobName):
+		secretName = util.DMClientTLSSecretName(cluster.Name)
+	default:
+		secretName = util.ClusterClientTLSSecretName(cluster.Name)
+	}
+
+	// the client certs are copied into the tls assets secret in the namespace of TidbMonitor,
+	// because PodMonitor can only refer to secrets in its own namespace.
+	selector := func(key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: GetTLSAssetsSecretName(monitor.Name)},
+			Key:                  TLSAssetKey{"secret", cluster.Namespace, secretName, key}.String(),
+		}
+	}
+	return "https", &safeTLSConfig{
+		CA:        &secretOrConfigMap{Secret: selector(corev1.ServiceAccountRootCAKey)},
+		Cert:      &secretOrConfigMap{Secret: selector(corev1.TLSCertKey)},
+		KeySecret: selector(corev1.TLSPrivateKeyKey),
+	}
+}
+
+func getPodMonitorRelabelConfigs(jobName string, cluster ClusterRegexInfo) []relabelConfig {
+	return []relabelConfig{
+		{
+			SourceLabels: []string{scrapeLabel},
+			Action:       "keep",
+			Regex:        truePattern,
+		},
+		toRelabelConfig(buildAddressRelabelConfigByComponent(jobName)),
+		{
+			// keep the job name of the bundled Prometheus which is used by the dashboards
+			TargetLabel: "job",
+			Replacement: fmt.Sprintf("%s-%s-%s", cluster.Namespace, cluster.Name, jobName),
+			Action:      "replace",
+		},
+		{
+			SourceLabels: []string{namespaceLabel},
+			Action:       "replace",
+			TargetLabel:  "kubernetes_namespace",
+		},
+		{
+			SourceLabels: []string{instanceLabel},
+			Action:       "replace",
+			TargetLabel:  "cluster",
+		},
+		{
+			SourceLabels: []string{podNameLabel},
+			Action:       "replace",
+			TargetLabel:  "instance",
+		},
+		{
+			SourceLabels: []string{componentLabel},
+			Action:       "replace",
+			TargetLabel:  "component",
+		},
+		{
+			SourceLabels: []string{namespaceLabel, instanceLabel},
+			Separator:    "-",
+			TargetLabel:  "tidb_cluster",
+		},
+		{
+			SourceLabels: []string{metricsPathLabel},
+			Action:       "replace",
+			TargetLabel:  "__metrics_path__",
+			Regex:        allMatchPattern,
+		},
+	}
+}
+
+// toRelabelConfig converts a relabel config of the Prometheus config file to the one of PodMonitor
+func toRelabelConfig(item yaml.MapSlice) relabelConfig {
+	c := relabelConfig{}
+	for _, kv := range item {
+		switch kv.Key {
+		case "source_labels":
+			c.SourceLabels = kv.Value.([]string)
+		case "separator":
+			c.Separator = kv.Value.(string)
+		case "target_label":
+			c.TargetLabel = kv.Value.(string)
+		case "regex":
+			c.Regex = kv.Value.(string)
+		case "replacement":
+			c.Replacement = kv.Value.(string)
+		case "action":
+			c.Action = kv.Value.(string)
+		}
+	}
+	return c
+}
+
+// getPrometheusRule generates a PrometheusRule from the rule files,
+// it returns nil if there is no rule group.
+func getPrometheusRule(monitor *v1alpha1.TidbMonitor, ruleFiles map[string]string) (*unstructured.Unstructured, error) {
+	keys := make([]string, 0, len(ruleFiles))
+	for key := range ruleFiles {
+		if strings.HasSuffix(key, ruleFileSuffix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	spec := &prometheusRuleSpec{}
+	for _, key := range keys {
+		file := &prometheusRuleSpec{}
+		if err := k8syaml.Unmarshal([]byte(ruleFiles[key]), file); err != nil {
+			return nil, fmt.Errorf("parse rule file %s of tm[%s/%s] failed, err: %v", key, monitor.Namespace, monitor.Name, err)
+		}
+		spec.Groups = append(spec.Groups, file.Groups...)
+	}
+	if len(spec.Groups) == 0 {
+		return nil, nil
+	}
+
+	return newPrometheusOperatorObject(monitor, prometheusRuleGVK, GetMonitorObjectName(monitor), spec)
+}
+
+func getGrafanaDashboardConfigMapPrefix(monitor *v1alpha1.TidbMonitor) string {
+	return fmt.Sprintf("%s-dashboard-", GetMonitorObjectName(monitor))
+}
+
+// getGrafanaDashboardConfigMaps generates a ConfigMap for every dashboard which is loaded by the Grafana sidecar
+func getGrafanaDashboardConfigMaps(monitor *v1alpha1.TidbMonitor, dashboards map[string]string) []*corev1.ConfigMap {
+	spec := monitor.Spec.PrometheusOperator.GrafanaDashboards
+	if spec == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(dashboards))
+	for key := range dashboards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var cms []*corev1.ConfigMap
+	for _, key := range keys {
+		l := buildTidbMonitorGrafanaLabel(monitor.Name)
+		if len(spec.Labels) == 0 {
+			l[grafanaDashboardLabelKey] = "1"
+		}
+		for k, v := range spec.Labels {
+			if _, ok := l[k]; !ok {
+				l[k] = v
+			}
+		}
+		var annotations map[string]string
+		if len(spec.Annotations) > 0 {
+			annotations = make(map[string]string, len(spec.Annotations))
+			for k, v := range spec.Annotations {
+				annotations[k] = v
+			}
+		}
+
+		name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(strings.TrimSuffix(key, dashboardFileSuffix)), "-"), "-")
+		cms = append(cms, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            getGrafanaDashboardConfigMapPrefix(monitor) + name,
+				Namespace:       monitor.Namespace,
+				Labels:          l,
+				Annotations:     annotations,
+				OwnerReferences: []metav1.OwnerReference{controller.GetTiDBMonitorOwnerRef(monitor)},
+			},
+			Data: map[string]string{
+				key: dashboards[key],
+			},
+		})
+	}
+	return cms
+}
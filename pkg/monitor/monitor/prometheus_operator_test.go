@@ -0,0 +1,273 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func newTidbMonitorForPrometheusOperator() *v1alpha1.TidbMonitor {
+	return &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "ns",
+		},
+		Spec: v1alpha1.TidbMonitorSpec{
+			PrometheusOperator: &v1alpha1.PrometheusOperatorSpec{
+				Labels: map[string]string{
+					"release": "kube-prometheus-stack",
+				},
+			},
+		},
+	}
+}
+
+func TestGetPodMonitors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tm := newTidbMonitorForPrometheusOperator()
+	clusters := []ClusterRegexInfo{
+		{Name: "basic", Namespace: "ns1"},
+		{Name: "tls", Namespace: "ns2", enableTLS: true},
+	}
+	dmClusters := []ClusterRegexInfo{
+		{Name: "dm", Namespace: "ns1"},
+	}
+
+	pms, err := getPodMonitors(tm, clusters, dmClusters, "tidb-admin")
+	g.Expect(err).NotTo(HaveOccurred())
+	// 12 jobs for every tidb cluster and 2 jobs for every dm cluster
+	g.Expect(pms).To(HaveLen(26))
+
+	byName := map[string]*unstructured.Unstructured{}
+	for _, pm := range pms {
+		g.Expect(pm.GetKind()).To(Equal("PodMonitor"))
+		g.Expect(pm.GetNamespace()).To(Equal("ns"))
+		g.Expect(pm.GetLabels()).To(HaveKeyWithValue("release", "kube-prometheus-stack"))
+		g.Expect(pm.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/instance", "foo"))
+		g.Expect(pm.GetOwnerReferences()).To(HaveLen(1))
+		byName[pm.GetName()] = pm
+	}
+
+	pm := byName["foo-ns1-basic-tikv"]
+	g.Expect(pm).NotTo(BeNil())
+	matchLabels, _, _ := unstructured.NestedStringMap(pm.Object, "spec", "selector", "matchLabels")
+	g.Expect(matchLabels).To(Equal(map[string]string{
+		"app.kubernetes.io/instance":  "basic",
+		"app.kubernetes.io/component": "tikv",
+	}))
+	namespaces, _, _ := unstructured.NestedStringSlice(pm.Object, "spec", "namespaceSelector", "matchNames")
+	g.Expect(namespaces).To(Equal([]string{"ns1"}))
+	endpoints, _, _ := unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+	g.Expect(endpoints).To(HaveLen(1))
+	endpoint := endpoints[0].(map[string]interface{})
+	g.Expect(endpoint["scheme"]).To(Equal("http"))
+	g.Expect(endpoint["interval"]).To(Equal("15s"))
+	g.Expect(endpoint).NotTo(HaveKey("tlsConfig"))
+	g.Expect(endpoint["relabelings"]).To(ContainElement(map[string]interface{}{
+		"action":       "replace",
+		"regex":        addressPattern,
+		"replacement":  "$1.$2-tikv-peer.$3:$4",
+		"targetLabel":  "__address__",
+		"sourceLabels": []interface{}{podNameLabel, instanceLabel, namespaceLabel, portLabel},
+	}))
+	g.Expect(endpoint["relabelings"]).To(ContainElement(map[string]interface{}{
+		"action":      "replace",
+		"replacement": "ns1-basic-tikv",
+		"targetLabel": "job",
+	}))
+
+	pm = byName["foo-ns2-tls-tikv"]
+	g.Expect(pm).NotTo(BeNil())
+	endpoints, _, _ = unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+	endpoint = endpoints[0].(map[string]interface{})
+	g.Expect(endpoint["scheme"]).To(Equal("https"))
+	g.Expect(endpoint["tlsConfig"]).To(Equal(map[string]interface{}{
+		"ca": map[string]interface{}{
+			"secret": map[string]interface{}{
+				"name": "tidbmonitor-foo-tls-assets",
+				"key":  "secret_ns2_tls-cluster-client-secret_ca.crt",
+			},
+		},
+		"cert": map[string]interface{}{
+			"secret": map[string]interface{}{
+				"name": "tidbmonitor-foo-tls-assets",
+				"key":  "secret_ns2_tls-cluster-client-secret_tls.crt",
+			},
+		},
+		"keySecret": map[string]interface{}{
+			"name": "tidbmonitor-foo-tls-assets",
+			"key":  "secret_ns2_tls-cluster-client-secret_tls.key",
+		},
+	}))
+
+	pm = byName["foo-ns2-tls-tiproxy"]
+	g.Expect(pm).NotTo(BeNil())
+	endpoints, _, _ = unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+	endpoint = endpoints[0].(map[string]interface{})
+	g.Expect(endpoint["tlsConfig"]).To(Equal(map[string]interface{}{
+		"insecureSkipVerify": true,
+	}))
+
+	g.Expect(byName).To(HaveKey("foo-ns1-dm-dm-master"))
+	g.Expect(byName).NotTo(HaveKey("foo-ns1-basic-dm-master"))
+	g.Expect(byName).NotTo(HaveKey("foo-tidb-controller-manager"))
+
+	// the controller-manager is scraped for the generated alert rules
+	tm.Spec.GeneratedAlertRules = &v1alpha1.GeneratedAlertRulesSpec{}
+	pms, err = getPodMonitors(tm, clusters, dmClusters, "tidb-admin")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pms).To(HaveLen(27))
+	pm = pms[len(pms)-1]
+	g.Expect(pm.GetName()).To(Equal("foo-tidb-controller-manager"))
+	namespaces, _, _ = unstructured.NestedStringSlice(pm.Object, "spec", "namespaceSelector", "matchNames")
+	g.Expect(namespaces).To(Equal([]string{"tidb-admin"}))
+	endpoints, _, _ = unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+	endpoint = endpoints[0].(map[string]interface{})
+	g.Expect(endpoint["honorLabels"]).To(BeTrue())
+	g.Expect(endpoint["relabelings"]).To(ContainElement(map[string]interface{}{
+		"action":       "replace",
+		"regex":        podAddressPattern,
+		"replacement":  "$1:6060",
+		"targetLabel":  "__address__",
+		"sourceLabels": []interface{}{"__address__"},
+	}))
+}
+
+func TestGetPrometheusRule(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tm := newTidbMonitorForPrometheusOperator()
+
+	rule, err := getPrometheusRule(tm, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rule).To(BeNil())
+
+	rule, err = getPrometheusRule(tm, map[string]string{
+		"tidb.rules.yml": `groups:
+- name: alert.rules
+  rules:
+  - alert: TiDB_schema_error
+    expr: increase(tidb_session_schema_lease_error_total{type="outdated"}[15m]) > 0
+    for: 1m
+`,
+		"tikv.rules.yml": `groups:
+- name: tikv.rules
+  rules: []
+`,
+		"README.md": "ignored",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rule).NotTo(BeNil())
+	g.Expect(rule.GetKind()).To(Equal("PrometheusRule"))
+	g.Expect(rule.GetName()).To(Equal("foo-monitor"))
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	g.Expect(groups).To(HaveLen(2))
+	g.Expect(groups[0].(map[string]interface{})["name"]).To(Equal("alert.rules"))
+	g.Expect(groups[1].(map[string]interface{})["name"]).To(Equal("tikv.rules"))
+
+	_, err = getPrometheusRule(tm, map[string]string{
+		"invalid.rules.yml": "groups: {",
+	})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestGetGrafanaDashboardConfigMaps(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tm := newTidbMonitorForPrometheusOperator()
+	dashboards := map[string]string{
+		"TiKV_Details.json": "{}",
+		"pd.json":           "{}",
+	}
+	g.Expect(getGrafanaDashboardConfigMaps(tm, dashboards)).To(BeEmpty())
+
+	tm.Spec.PrometheusOperator.GrafanaDashboards = &v1alpha1.GrafanaDashboardsSpec{}
+	cms := getGrafanaDashboardConfigMaps(tm, dashboards)
+	g.Expect(cms).To(HaveLen(2))
+	g.Expect(cms[0].Name).To(Equal("foo-monitor-dashboard-tikv-details"))
+	g.Expect(cms[0].Data).To(Equal(map[string]string{"TiKV_Details.json": "{}"}))
+	g.Expect(cms[0].Labels).To(HaveKeyWithValue("grafana_dashboard", "1"))
+	g.Expect(cms[1].Name).To(Equal("foo-monitor-dashboard-pd"))
+
+	tm.Spec.PrometheusOperator.GrafanaDashboards = &v1alpha1.GrafanaDashboardsSpec{
+		Labels:      map[string]string{"custom_dashboard": "tidb"},
+		Annotations: map[string]string{"grafana_folder": "TiDB"},
+	}
+	cms = getGrafanaDashboardConfigMaps(tm, dashboards)
+	g.Expect(cms).To(HaveLen(2))
+	g.Expect(cms[0].Labels).To(HaveKeyWithValue("custom_dashboard", "tidb"))
+	g.Expect(cms[0].Labels).NotTo(HaveKey("grafana_dashboard"))
+	g.Expect(cms[0].Annotations).To(Equal(map[string]string{"grafana_folder": "TiDB"}))
+}
+
+func TestRemoveBundledPrometheus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, deletePVCs := range []bool{false, true} {
+		tmm := newFakeTidbMonitorManager()
+		events := tmm.deps.Recorder.(*record.FakeRecorder).Events
+		tm := newTidbMonitorForPrometheusOperator()
+		tm.UID = "foo"
+		// the PVCs are not deleted by the reclaim policy
+		policy := corev1.PersistentVolumeReclaimDelete
+		tm.Spec.PVReclaimPolicy = &policy
+		tm.Spec.PrometheusOperator.DeleteBundledPVCs = deletePVCs
+		tm.Status.StatefulSet = &appsv1.StatefulSetStatus{Replicas: 1}
+
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            GetMonitorShardName(tm.Name, 0),
+				Namespace:       tm.Namespace,
+				OwnerReferences: []metav1.OwnerReference{{UID: tm.UID, Controller: pointer.BoolPtr(true)}},
+			},
+		}
+		stsIndexer := tmm.deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer()
+		g.Expect(stsIndexer.Add(sts)).To(Succeed())
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "monitor-data-foo-monitor-0",
+				Namespace: tm.Namespace,
+				Labels:    buildTidbMonitorLabel(tm.Name),
+			},
+		}
+		pvcIndexer := tmm.deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+		g.Expect(pvcIndexer.Add(pvc)).To(Succeed())
+
+		g.Expect(tmm.removeBundledPrometheus(tm)).To(Succeed())
+		_, exist, err := pvcIndexer.Get(pvc)
+		g.Expect(err).To(Succeed())
+		g.Expect(exist).To(Equal(!deletePVCs))
+		g.Expect(events).To(HaveLen(1))
+		if deletePVCs {
+			g.Expect(<-events).To(ContainSubstring(eventReasonBundledPVCDeleted))
+		} else {
+			g.Expect(<-events).To(ContainSubstring(eventReasonBundledPVCRetained))
+		}
+
+		// the status of the removed statefulset is cleared
+		g.Expect(stsIndexer.Delete(sts)).To(Succeed())
+		g.Expect(tmm.syncTidbMonitorStatus(tm)).To(Succeed())
+		g.Expect(tm.Status.StatefulSet).To(BeNil())
+	}
+}
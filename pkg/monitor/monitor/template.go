@@ -36,6 +36,13 @@ const (
 	additionalPortLabelPattern = "__meta_kubernetes_pod_annotation_%s_prometheus_io_port"
 	dmWorker                   = "dm-worker"
 	dmMaster                   = "dm-master"
+
+	// controllerManagerJob scrapes the metrics of backups used by the generated alert rules
+	controllerManagerJob         = "tidb-controller-manager"
+	controllerManagerPattern     = "controller-manager"
+	controllerManagerNamePattern = "tidb-operator"
+	controllerManagerAddress     = "$1:6060"
+	podAddressPattern            = "([^:]+)(?::\\d+)?"
 )
 
 var (
@@ -70,6 +77,23 @@ var (
             "type": "file"
         }
     ]
+}`
+	// tenantDashBoardConfig puts the provisioned dashboards into the shared folder which
+	// is only visible to admins, the tenants see the copies in the folders of their clusters.
+	tenantDashBoardConfig = `{
+    "apiVersion": 1,
+    "providers": [
+        {
+            "folder": "` + grafanaSharedFolderTitle + `",
+            "folderUid": "` + grafanaSharedFolderUID + `",
+            "name": "0",
+            "options": {
+                "path": "/grafana-dashboard-definitions/tidb"
+            },
+            "orgId": 1,
+            "type": "file"
+        }
+    ]
 }`
 )
 
@@ -81,7 +105,11 @@ type MonitorConfigModel struct {
 	RemoteWriteCfg            *yaml.MapItem
 	EnableAlertRules          bool
 	EnableExternalRuleConfigs bool
-	shards                    int32
+	EnableGeneratedAlertRules bool
+	AgentMode                 bool
+	// OperatorNamespace is the namespace of the controller-manager, it is scraped if not empty
+	OperatorNamespace string
+	shards            int32
 }
 
 // ClusterRegexInfo is the monitor cluster info
@@ -107,6 +135,9 @@ func newPrometheusConfig(cmodel *MonitorConfigModel) yaml.MapSlice {
 	scrapeJobs = append(scrapeJobs, scrapeJob("lightning", lightningPattern, cmodel, buildAddressRelabelConfigByComponent("lightning"))...)
 	scrapeJobs = append(scrapeJobs, scrapeJob(dmWorker, dmWorkerPattern, cmodel, buildAddressRelabelConfigByComponent(dmWorker))...)
 	scrapeJobs = append(scrapeJobs, scrapeJob(dmMaster, dmMasterPattern, cmodel, buildAddressRelabelConfigByComponent(dmMaster))...)
+	if cmodel.OperatorNamespace != "" {
+		scrapeJobs = append(scrapeJobs, controllerManagerScrapeJob(cmodel))
+	}
 	cfg := yaml.MapSlice{}
 	globalItems := yaml.MapSlice{
 		{Key: "evaluation_interval", Value: "15s"},
@@ -439,6 +470,57 @@ func scrapeJob(jobName string, componentPattern string, cmodel *MonitorConfigMod
 
 }
 
+// controllerManagerScrapeJob scrapes the controller-manager which exports the metrics of backups.
+// The labels of the metrics are honored, because `namespace` and `name` of them are the backup objects.
+func controllerManagerScrapeJob(cmodel *MonitorConfigModel) yaml.MapSlice {
+	relabelConfigs := controllerManagerRelabelConfigs()
+	relabelConfigs = appendShardingRelabelConfigRules(relabelConfigs, uint64(cmodel.shards))
+	return yaml.MapSlice{
+		{Key: "job_name", Value: controllerManagerJob},
+		{Key: "honor_labels", Value: true},
+		{Key: "scrape_interval", Value: "15s"},
+		{Key: "scheme", Value: "http"},
+		{Key: "kubernetes_sd_configs", Value: []yaml.MapSlice{
+			{
+				{Key: "api_server", Value: nil},
+				{Key: "role", Value: "pod"},
+				{Key: "namespaces", Value: yaml.MapSlice{
+					{Key: "names", Value: []string{cmodel.OperatorNamespace}},
+				}},
+			},
+		}},
+		{Key: "relabel_configs", Value: relabelConfigs},
+	}
+}
+
+func controllerManagerRelabelConfigs() []yaml.MapSlice {
+	return []yaml.MapSlice{
+		{
+			{Key: "source_labels", Value: []string{nameLabel}},
+			{Key: "action", Value: "keep"},
+			{Key: "regex", Value: controllerManagerNamePattern},
+		},
+		{
+			{Key: "source_labels", Value: []string{componentLabel}},
+			{Key: "action", Value: "keep"},
+			{Key: "regex", Value: controllerManagerPattern},
+		},
+		{
+			// the metrics are served on the same port as pprof, which is not declared in the pod
+			{Key: "source_labels", Value: []string{"__address__"}},
+			{Key: "action", Value: "replace"},
+			{Key: "regex", Value: podAddressPattern},
+			{Key: "replacement", Value: controllerManagerAddress},
+			{Key: "target_label", Value: "__address__"},
+		},
+		{
+			{Key: "source_labels", Value: []string{podNameLabel}},
+			{Key: "action", Value: "replace"},
+			{Key: "target_label", Value: "instance"},
+		},
+	}
+}
+
 func isDMJob(jobName string) bool {
 	if jobName == dmMaster || jobName == dmWorker {
 		return true
@@ -474,6 +556,10 @@ func addAlertManagerUrl(cfg yaml.MapSlice, cmodel *MonitorConfigModel) yaml.MapS
 
 func RenderPrometheusConfig(model *MonitorConfigModel) (yaml.MapSlice, error) {
 	cfg := newPrometheusConfig(model)
+	if model.AgentMode {
+		// Alerting and rules are not allowed in the config of agent mode
+		return cfg, nil
+	}
 	var rulesPath []string
 	if len(model.AlertmanagerURL) > 0 {
 		cfg = addAlertManagerUrl(cfg, model)
@@ -492,6 +578,10 @@ func RenderPrometheusConfig(model *MonitorConfigModel) (yaml.MapSlice, error) {
 			"/prometheus-external-rules/*.rules.yml",
 		}
 	}
+	if model.EnableGeneratedAlertRules {
+		// The generated rules are in the same ConfigMap as prometheus.yml.
+		rulesPath = append(rulesPath, path.Join("/etc/prometheus/config", generatedRuleFile))
+	}
 	if rulesPath != nil {
 		cfg = append(cfg, yaml.MapItem{
 			Key:   "rule_files",
@@ -42,6 +42,8 @@ import (
 
 const (
 	defaultReplicaExternalLabelName = "prometheus_replica"
+	// prometheusConfigRenderCommand renders the env of the pod into the Prometheus config before starting Prometheus
+	prometheusConfigRenderCommand = "sed -e '5s/[()]//g' -e 's/SHARD//g'  -e 's/$NAMESPACE/'\"$NAMESPACE\"'/g;s/$POD_NAME/'\"$POD_NAME\"'/g;s/$()/'$(SHARD)'/g' /etc/prometheus/config/prometheus.yml > /etc/prometheus/config_out/prometheus.yml && "
 )
 
 func GetTLSAssetsSecretName(name string) string {
@@ -150,7 +152,7 @@ func getAlertManagerRulesVersion(monitor *v1alpha1.TidbMonitor) string {
 
 // getPromConfigMap generate the Prometheus config for TidbMonitor,
 // If the namespace in ClusterRef is empty, we would set the TidbMonitor's namespace in the default
-func getPromConfigMap(monitor *v1alpha1.TidbMonitor, monitorClusterInfos []ClusterRegexInfo, dmClusterInfos []ClusterRegexInfo, shard int32, store *Store) (*core.ConfigMap, error) {
+func getPromConfigMap(monitor *v1alpha1.TidbMonitor, monitorClusterInfos []ClusterRegexInfo, dmClusterInfos []ClusterRegexInfo, shard int32, store *Store, operatorNamespace string) (*core.ConfigMap, error) {
 	model := &MonitorConfigModel{
 		AlertmanagerURL:  "",
 		ClusterInfos:     monitorClusterInfos,
@@ -159,6 +161,14 @@ func getPromConfigMap(monitor *v1alpha1.TidbMonitor, monitorClusterInfos []Clust
 		EnableAlertRules: monitor.Spec.EnableAlertRules,
 		shards:           shard,
 	}
+	if monitor.Spec.GeneratedAlertRules != nil {
+		model.EnableGeneratedAlertRules = true
+		// the generated alert rules of backups query the metrics of the controller-manager
+		model.OperatorNamespace = operatorNamespace
+	}
+	if monitor.IsAgentMode() {
+		model.AgentMode = true
+	}
 
 	if monitor.Spec.AlertmanagerURL != nil {
 		model.AlertmanagerURL = *monitor.Spec.AlertmanagerURL
@@ -196,6 +206,10 @@ func getPromConfigMap(monitor *v1alpha1.TidbMonitor, monitorClusterInfos []Clust
 
 // getGrafanaConfigMap generates the Grafana config for TidbMonitor,
 func getGrafanaConfigMap(monitor *v1alpha1.TidbMonitor) *core.ConfigMap {
+	dashboards := dashBoardConfig
+	if monitor.IsGrafanaTenancyEnabled() {
+		dashboards = tenantDashBoardConfig
+	}
 	cm := &core.ConfigMap{
 		ObjectMeta: meta.ObjectMeta{
 			Name:            GetGrafanaConfigMapName(monitor),
@@ -204,7 +218,7 @@ func getGrafanaConfigMap(monitor *v1alpha1.TidbMonitor) *core.ConfigMap {
 			OwnerReferences: []meta.OwnerReference{controller.GetTiDBMonitorOwnerRef(monitor)},
 		},
 		Data: map[string]string{
-			"dashboards.yaml": dashBoardConfig,
+			"dashboards.yaml": dashboards,
 		},
 	}
 	return cm
@@ -477,7 +491,11 @@ func getMonitorPrometheusContainer(monitor *v1alpha1.TidbMonitor, shard int32) c
 	} else {
 		retention = fmt.Sprintf("%dd", monitor.Spec.Prometheus.ReserveDays)
 	}
-	commands := []string{"sed -e '5s/[()]//g' -e 's/SHARD//g'  -e 's/$NAMESPACE/'\"$NAMESPACE\"'/g;s/$POD_NAME/'\"$POD_NAME\"'/g;s/$()/'$(SHARD)'/g' /etc/prometheus/config/prometheus.yml > /etc/prometheus/config_out/prometheus.yml && /bin/prometheus --web.enable-admin-api --web.enable-lifecycle --config.file=/etc/prometheus/config_out/prometheus.yml --storage.tsdb.path=/data/prometheus --storage.tsdb.retention.time=" + retention}
+	commands := []string{prometheusConfigRenderCommand + "/bin/prometheus --web.enable-admin-api --web.enable-lifecycle --config.file=/etc/prometheus/config_out/prometheus.yml --storage.tsdb.path=/data/prometheus --storage.tsdb.retention.time=" + retention}
+	if monitor.IsAgentMode() {
+		// Only the WAL is kept in agent mode, the flags of TSDB are not allowed.
+		commands = []string{prometheusConfigRenderCommand + "/bin/prometheus --enable-feature=agent --web.enable-lifecycle --config.file=/etc/prometheus/config_out/prometheus.yml --storage.agent.path=/data/prometheus"}
+	}
 	c := core.Container{
 		Name:      "prometheus",
 		Image:     fmt.Sprintf("%s:%s", monitor.Spec.Prometheus.BaseImage, monitor.Spec.Prometheus.Version),
@@ -550,7 +568,7 @@ func getMonitorPrometheusContainer(monitor *v1alpha1.TidbMonitor, shard int32) c
 	if monitor.Spec.Prometheus.Config != nil && len(monitor.Spec.Prometheus.Config.CommandOptions) > 0 {
 		commands = append(commands, monitor.Spec.Prometheus.Config.CommandOptions...)
 	}
-	if monitor.Spec.Prometheus.DisableCompaction || monitor.Spec.Thanos != nil {
+	if !monitor.IsAgentMode() && (monitor.Spec.Prometheus.DisableCompaction || monitor.Spec.Thanos != nil) {
 		commands = append(commands, "--storage.tsdb.max-block-duration=2h")
 		commands = append(commands, "--storage.tsdb.min-block-duration=2h")
 	}
@@ -726,6 +744,38 @@ func getMonitorGrafanaContainer(secret *core.Secret, monitor *v1alpha1.TidbMonit
 	return c
 }
 
+// getMonitorGrafanaLabelProxyContainer returns the prom-label-proxy queried by the datasources of
+// Grafana tenants, it only listens on localhost as the label is taken from the header of requests.
+func getMonitorGrafanaLabelProxyContainer(monitor *v1alpha1.TidbMonitor) core.Container {
+	image := fmt.Sprintf("%s:%s", defaultGrafanaLabelProxyImage, defaultGrafanaLabelProxyTag)
+	spec := monitor.Spec.Grafana.Tenancy.LabelProxy
+	c := core.Container{
+		Name: "label-proxy",
+		Args: []string{
+			fmt.Sprintf("--insecure-listen-address=127.0.0.1:%d", grafanaLabelProxyPort),
+			"--upstream=http://127.0.0.1:9090",
+			"--label=tidb_cluster",
+			fmt.Sprintf("--header-name=%s", grafanaLabelProxyHeader),
+			"--enable-label-apis",
+		},
+	}
+	if spec != nil {
+		if spec.BaseImage != "" {
+			tag := defaultGrafanaLabelProxyTag
+			if spec.Version != "" {
+				tag = spec.Version
+			}
+			image = fmt.Sprintf("%s:%s", spec.BaseImage, tag)
+		}
+		c.Resources = controller.ContainerResource(spec.ResourceRequirements)
+		if spec.ImagePullPolicy != nil {
+			c.ImagePullPolicy = *spec.ImagePullPolicy
+		}
+	}
+	c.Image = image
+	return c
+}
+
 func getMonitorPrometheusReloaderContainer(monitor *v1alpha1.TidbMonitor, shard int32) core.Container {
 	c := core.Container{
 		Name:  "prometheus-config-reloader",
@@ -787,6 +837,10 @@ func getMonitorPrometheusReloaderContainer(monitor *v1alpha1.TidbMonitor, shard
 		})
 		c.Command = append(c.Command, "--watched-dir=/prometheus-external-rules")
 	}
+	if monitor.Spec.GeneratedAlertRules != nil {
+		// reload Prometheus when the generated rules change without changes of prometheus.yml
+		c.Command = append(c.Command, "--watched-dir=/etc/prometheus/config")
+	}
 	return c
 }
 
@@ -1252,6 +1306,10 @@ func getMonitorStatefulSet(sa *core.ServiceAccount, secret *core.Secret, monitor
 		grafanaContainer := getMonitorGrafanaContainer(secret, monitor)
 		statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, grafanaContainer)
 	}
+	if monitor.IsGrafanaTenancyEnabled() {
+		labelProxyContainer := getMonitorGrafanaLabelProxyContainer(monitor)
+		statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, labelProxyContainer)
+	}
 	volumes := getMonitorVolumes(monitor)
 	statefulSet.Spec.Template.Spec.Volumes = volumes
 
@@ -299,7 +299,7 @@ func TestGetMonitorConfigMap(t *testing.T) {
 	for i := range testCases {
 		tt := testCases[i]
 		t.Run(tt.name, func(t *testing.T) {
-			cm, err := getPromConfigMap(&tt.monitor, tt.monitorClusterInfos, nil, 0, nil)
+			cm, err := getPromConfigMap(&tt.monitor, tt.monitorClusterInfos, nil, 0, nil, "")
 			g.Expect(err).NotTo(HaveOccurred())
 			if tt.expected == nil {
 				g.Expect(cm).To(BeNil())
@@ -1461,6 +1461,23 @@ func TestGetMonitorGrafanaContainer(t *testing.T) {
 	}
 }
 
+func TestGetMonitorGrafanaLabelProxyContainer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	monitor := &v1alpha1.TidbMonitor{
+		Spec: v1alpha1.TidbMonitorSpec{
+			Grafana: &v1alpha1.GrafanaSpec{Tenancy: &v1alpha1.GrafanaTenancySpec{}},
+		},
+	}
+	c := getMonitorGrafanaLabelProxyContainer(monitor)
+	g.Expect(c.Image).To(Equal("quay.io/prometheuscommunity/prom-label-proxy:v0.11.0"))
+	g.Expect(c.Args).To(ContainElements("--upstream=http://127.0.0.1:9090", "--label=tidb_cluster", "--header-name=X-TiDB-Cluster"))
+
+	monitor.Spec.Grafana.Tenancy.LabelProxy = &v1alpha1.MonitorContainer{BaseImage: "registry/prom-label-proxy"}
+	c = getMonitorGrafanaLabelProxyContainer(monitor)
+	g.Expect(c.Image).To(Equal("registry/prom-label-proxy:v0.11.0"))
+}
+
 func TestGetMonitorThanosSidecarContainer(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -1636,3 +1653,30 @@ func TestBuildExternalLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMonitorPrometheusContainerAgentMode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tm := &v1alpha1.TidbMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"},
+		Spec: v1alpha1.TidbMonitorSpec{
+			Prometheus: v1alpha1.PrometheusSpec{
+				MonitorContainer:  v1alpha1.MonitorContainer{Version: "v2.40.0"},
+				ReserveDays:       8,
+				DisableCompaction: true,
+				AgentMode:         true,
+			},
+		},
+	}
+	c := getMonitorPrometheusContainer(tm, 0)
+	g.Expect(c.Command).To(HaveLen(3))
+	g.Expect(c.Command[2]).To(HaveSuffix("/bin/prometheus --enable-feature=agent --web.enable-lifecycle --config.file=/etc/prometheus/config_out/prometheus.yml --storage.agent.path=/data/prometheus"))
+
+	tm.Spec.AlertmanagerURL = pointer.StringPtr("alertmanager:9093")
+	tm.Spec.EnableAlertRules = true
+	cm, err := getPromConfigMap(tm, []ClusterRegexInfo{{Name: "basic", Namespace: "ns"}}, nil, 1, nil, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data["prometheus.yml"]).To(ContainSubstring("scrape_configs:"))
+	g.Expect(cm.Data["prometheus.yml"]).NotTo(ContainSubstring("rule_files:"))
+	g.Expect(cm.Data["prometheus.yml"]).NotTo(ContainSubstring("alerting:"))
+}
@@ -38,6 +38,7 @@ const (
 	DeleteMemberActionType                      ActionType = "DeleteMember "
 	SetStoreLabelsActionType                    ActionType = "SetStoreLabels"
 	UpdateReplicationActionType                 ActionType = "UpdateReplicationConfig"
+	UpdateConfigActionType                      ActionType = "UpdateConfig"
 	BeginEvictLeaderActionType                  ActionType = "BeginEvictLeader"
 	EndEvictLeaderActionType                    ActionType = "EndEvictLeader"
 	GetEvictLeaderSchedulersActionType          ActionType = "GetEvictLeaderSchedulers"
@@ -62,6 +63,7 @@ type Action struct {
 	Name        string
 	Labels      map[string]string
 	Replication PDReplicationConfig
+	Config      map[string]interface{}
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -230,6 +232,15 @@ func (c *FakePDClient) UpdateReplicationConfig(config PDReplicationConfig) error
 	return nil
 }
 
+func (c *FakePDClient) UpdateConfig(config map[string]interface{}) error {
+	if reaction, ok := c.reactions[UpdateConfigActionType]; ok {
+		action := &Action{Config: config}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
 func (c *FakePDClient) BeginEvictLeader(storeID uint64) error {
 	if reaction, ok := c.reactions[BeginEvictLeaderActionType]; ok {
 		action := &Action{ID: storeID}
@@ -100,6 +100,10 @@ type clientConfig struct {
 	tlsEnable          bool
 	tlsSecretNamespace Namespace
 	tlsSecretName      string
+	// tlsServerNamespace and tlsClusterName are the target cluster, whose component client certs are used if the
+	// secret is not specified, and whose URI SAN of the servers is verified if the policy requires it
+	tlsServerNamespace Namespace
+	tlsClusterName     string
 }
 
 func (c *clientConfig) applyOptions(opts ...Option) {
@@ -116,8 +120,9 @@ func (c *clientConfig) completeForPDClient(namespace Namespace, tcName, serviceN
 		scheme = "https"
 		if c.tlsSecretName == "" {
 			c.tlsSecretNamespace = namespace
-			c.tlsSecretName = util.ClusterClientTLSSecretName(tcName)
 		}
+		c.tlsServerNamespace = namespace
+		c.tlsClusterName = tcName
 	}
 
 	if c.clientURL == "" {
@@ -133,8 +138,9 @@ func (c *clientConfig) completeForEtcdClient(namespace Namespace, tcName string)
 	if c.tlsEnable {
 		if c.tlsSecretName == "" {
 			c.tlsSecretNamespace = namespace
-			c.tlsSecretName = util.ClusterClientTLSSecretName(tcName)
 		}
+		c.tlsServerNamespace = namespace
+		c.tlsClusterName = tcName
 	}
 
 	if c.clientURL == "" {
@@ -145,6 +151,21 @@ func (c *clientConfig) completeForEtcdClient(namespace Namespace, tcName string)
 	}
 }
 
+// getTLSConfig returns the tls.Config of the clients to the component, the certs of the specified secret
+// are used if it's set, otherwise the client certs of the component of the target cluster are used.
+// The URI SAN of the servers of the target cluster is verified in both cases.
+func (c *clientConfig) getTLSConfig(secretLister corelisterv1.SecretLister, component v1alpha1.MemberType) (*tls.Config, error) {
+	if c.tlsSecretName != "" {
+		return GetTLSConfig(secretLister, c.tlsSecretNamespace, c.tlsSecretName, TLSServer{
+			Namespace:     c.tlsServerNamespace,
+			ClusterName:   c.tlsClusterName,
+			ClusterDomain: c.clusterDomain,
+			Component:     component,
+		})
+	}
+	return GetComponentTLSConfig(secretLister, c.tlsSecretNamespace, c.tlsClusterName, c.clusterDomain, component)
+}
+
 // defaultPDControl is the default implementation of PDControlInterface.
 type defaultPDControl struct {
 	secretLister corelisterv1.SecretLister
@@ -185,7 +206,7 @@ func (pdc *defaultPDControl) GetEndpoints(namespace Namespace, tcName string, tl
 	config.completeForEtcdClient(namespace, tcName)
 
 	if config.tlsEnable {
-		tlsConfig, err = GetTLSConfig(pdc.secretLister, config.tlsSecretNamespace, config.tlsSecretName)
+		tlsConfig, err = config.getTLSConfig(pdc.secretLister, v1alpha1.PDMemberType)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -208,7 +229,7 @@ func (pdc *defaultPDControl) GetPDEtcdClient(namespace Namespace, tcName string,
 	defer pdc.etcdmutex.Unlock()
 
 	if config.tlsEnable {
-		tlsConfig, err := GetTLSConfig(pdc.secretLister, config.tlsSecretNamespace, config.tlsSecretName)
+		tlsConfig, err := config.getTLSConfig(pdc.secretLister, v1alpha1.PDMemberType)
 		if err != nil {
 			klog.Errorf("Unable to get tls config for tidb cluster %q in %s, pd client may not work: %v", tcName, namespace, err)
 			return nil, err
@@ -239,16 +260,16 @@ func (pdc *defaultPDControl) GetPDClient(namespace Namespace, tcName string, tls
 	defer pdc.mutex.Unlock()
 
 	if config.tlsEnable {
-		tlsConfig, err := GetTLSConfig(pdc.secretLister, config.tlsSecretNamespace, config.tlsSecretName)
+		tlsConfig, err := config.getTLSConfig(pdc.secretLister, v1alpha1.PDMemberType)
 		if err != nil {
 			klog.Errorf("Unable to get tls config for tidb cluster %q in %s, pd client may not work: %v", tcName, namespace, err)
 			return &pdClient{url: config.clientURL, httpClient: &http.Client{Timeout: DefaultTimeout}}
 		}
 
-		return NewPDClient(config.clientURL, DefaultTimeout, tlsConfig)
+		return withClusterTracing(NewPDClient(config.clientURL, DefaultTimeout, tlsConfig), namespace, tcName)
 	}
 	if _, ok := pdc.pdClients[config.clientKey]; !ok {
-		pdc.pdClients[config.clientKey] = NewPDClient(config.clientURL, DefaultTimeout, nil)
+		pdc.pdClients[config.clientKey] = withClusterTracing(NewPDClient(config.clientURL, DefaultTimeout, nil), namespace, tcName)
 	}
 	return pdc.pdClients[config.clientKey]
 }
@@ -269,7 +290,7 @@ func (pdc *defaultPDControl) GetPDMSClient(namespace Namespace, tcName, serviceN
 	defer pdc.mutex.Unlock()
 
 	if config.tlsEnable {
-		tlsConfig, err := GetTLSConfig(pdc.secretLister, config.tlsSecretNamespace, config.tlsSecretName)
+		tlsConfig, err := config.getTLSConfig(pdc.secretLister, v1alpha1.MemberType(serviceName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for tidb cluster %q in %s, pdms client may not work: %v", tcName, namespace, err)
 			return &pdMSClient{url: config.clientURL, httpClient: &http.Client{Timeout: DefaultTimeout}}
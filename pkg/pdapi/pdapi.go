@@ -25,10 +25,9 @@ import (
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
-	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"github.com/tikv/pd/pkg/typeutil"
-	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -38,16 +37,6 @@ const (
 	tiKVNotBootstrapped  = `TiKV cluster not bootstrapped, please start TiKV first"`
 )
 
-// GetTLSConfig returns *tls.Config for given TiDB cluster.
-func GetTLSConfig(secretLister corelisterv1.SecretLister, namespace Namespace, secretName string) (*tls.Config, error) {
-	secret, err := secretLister.Secrets(string(namespace)).Get(secretName)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load certificates from secret %s/%s: %v", namespace, secretName, err)
-	}
-
-	return crypto.LoadTlsConfigFromSecret(secret)
-}
-
 // PDClient provides pd server's api
 type PDClient interface {
 	// GetHealth returns the PD's health info
@@ -69,6 +58,9 @@ type PDClient interface {
 	SetStoreLabels(storeID uint64, labels map[string]string) (bool, error)
 	// UpdateReplicationConfig updates the replication config
 	UpdateReplicationConfig(config PDReplicationConfig) error
+	// UpdateConfig changes the configuration items online, the keys are the full names
+	// of the items, such as "schedule.max-snapshot-count"
+	UpdateConfig(config map[string]interface{}) error
 	// DeleteStore deletes a TiKV store from cluster
 	DeleteStore(storeID uint64) error
 	// SetStoreState sets store to specified state.
@@ -126,6 +118,14 @@ type pdClient struct {
 	httpClient *http.Client
 }
 
+// withClusterTracing traces the requests sent by the client during the reconcile of the cluster
+func withClusterTracing(c PDClient, namespace Namespace, tcName string) PDClient {
+	if client, ok := c.(*pdClient); ok {
+		client.httpClient.Transport = tracing.NewClusterTransport(client.httpClient.Transport, string(namespace), tcName)
+	}
+	return c
+}
+
 // NewPDClient returns a new PDClient
 func NewPDClient(url string, timeout time.Duration, tlsConfig *tls.Config) PDClient {
 	var disableKeepalive bool
@@ -562,6 +562,27 @@ func (c *pdClient) UpdateReplicationConfig(config PDReplicationConfig) error {
 	return fmt.Errorf("failed %v to update replication: %v", res.StatusCode, err)
 }
 
+func (c *pdClient) UpdateConfig(config map[string]interface{}) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return &httputil.StatusError{
+		StatusCode: res.StatusCode,
+		Message:    fmt.Sprintf("failed %v to update config: %v", res.StatusCode, err),
+	}
+}
+
 func (c *pdClient) BeginEvictLeader(storeID uint64) error {
 	leaderEvictInfo := getLeaderEvictSchedulerInfo(storeID)
 	apiURL := fmt.Sprintf("%s/%s", c.url, schedulersPrefix)
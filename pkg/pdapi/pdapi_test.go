@@ -353,6 +353,46 @@ func TestSetStoreLabels(t *testing.T) {
 	}
 }
 
+func TestUpdateConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	config := map[string]interface{}{"schedule.max-snapshot-count": float64(8), "log.level": "warn"}
+	tcs := []struct {
+		caseName string
+		status   int
+		wantErr  bool
+	}{{
+		caseName: "success_UpdateConfig",
+		status:   http.StatusOK,
+	}, {
+		caseName: "failed_UpdateConfig",
+		status:   http.StatusBadRequest,
+		wantErr:  true,
+	},
+	}
+
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("POST"), "check method")
+			g.Expect(request.URL.Path).To(Equal("/"+configPrefix), "check url")
+
+			data := map[string]interface{}{}
+			g.Expect(readJSON(request.Body, &data)).To(Succeed())
+			g.Expect(data).To(Equal(config), "check config")
+
+			w.WriteHeader(tc.status)
+		})
+		defer svc.Close()
+
+		pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+		err := pdClient.UpdateConfig(config)
+		if tc.wantErr {
+			g.Expect(err).To(HaveOccurred(), tc.caseName)
+		} else {
+			g.Expect(err).NotTo(HaveOccurred(), tc.caseName)
+		}
+	}
+}
+
 func TestDeleteMember(t *testing.T) {
 	g := NewGomegaWithT(t)
 	name := "testMember"
@@ -0,0 +1,209 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/fips"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+)
+
+// TLSPolicy is the policy of the TLS connections from the operator to the components
+type TLSPolicy struct {
+	// MinVersion is the minimum TLS version, the default of Go is used if it's 0
+	MinVersion uint16
+	// CipherSuites are the cipher suites of TLS 1.2, the defaults of Go are used if it's empty
+	CipherSuites []uint16
+	// ServerURISAN is the template of the URI SAN that the certificates of the servers must contain besides
+	// being signed by the CA, such as "spiffe://{clusterDomain}/ns/{namespace}/tc/{cluster}/{component}".
+	// Only the CA is verified if it's empty.
+	ServerURISAN string
+}
+
+// DefaultServerURISAN is the template of the URI SAN of the certificates issued by the internal CA
+// when the policy doesn't pin one
+const DefaultServerURISAN = "spiffe://{clusterDomain}/ns/{namespace}/tc/{cluster}/{component}"
+
+// defaultTrustDomain is the trust domain of the URI SAN of the clusters without the cluster domain
+const defaultTrustDomain = "cluster.local"
+
+// tlsPolicy is the policy of the process, it's set at startup before the clients are created
+var tlsPolicy = TLSPolicy{}
+
+// SetTLSPolicy sets the policy of the TLS connections from the operator to the components
+func SetTLSPolicy(policy TLSPolicy) {
+	tlsPolicy = policy
+}
+
+// the cipher suites approved by FIPS 140-2, which are the only ones allowed by crypto/tls/fipsonly
+var fipsCipherSuites = map[uint16]bool{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: true,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: true,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   true,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   true,
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewTLSPolicy parses the policy from the minimum TLS version, such as "1.2", the comma separated names of
+// the cipher suites, such as "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", and the template of the URI SAN of the
+// servers. Only the FIPS-approved versions and cipher suites are allowed by the FIPS build.
+func NewTLSPolicy(minVersion, cipherSuites, serverURISAN string) (TLSPolicy, error) {
+	policy := TLSPolicy{ServerURISAN: serverURISAN}
+
+	if minVersion != "" {
+		version, ok := tlsVersions[minVersion]
+		if !ok {
+			return policy, fmt.Errorf("unsupported TLS version %q, supported versions are 1.0, 1.1, 1.2 and 1.3", minVersion)
+		}
+		if fips.Enabled && version < tls.VersionTLS12 {
+			return policy, fmt.Errorf("TLS version %s is not allowed by FIPS", minVersion)
+		}
+		policy.MinVersion = version
+	}
+
+	if cipherSuites != "" {
+		if policy.MinVersion == tls.VersionTLS13 {
+			return policy, fmt.Errorf("cipher suites are not configurable for TLS 1.3")
+		}
+		suites := map[string]uint16{}
+		for _, suite := range tls.CipherSuites() {
+			suites[suite.Name] = suite.ID
+		}
+		for _, name := range strings.Split(cipherSuites, ",") {
+			name = strings.TrimSpace(name)
+			id, ok := suites[name]
+			if !ok {
+				return policy, fmt.Errorf("unsupported or insecure cipher suite %q", name)
+			}
+			if fips.Enabled && !fipsCipherSuites[id] {
+				return policy, fmt.Errorf("cipher suite %s is not allowed by FIPS", name)
+			}
+			policy.CipherSuites = append(policy.CipherSuites, id)
+		}
+	}
+
+	if serverURISAN != "" && !strings.Contains(serverURISAN, "://") {
+		return policy, fmt.Errorf("the URI SAN %q of the servers should contain a scheme, such as spiffe://", serverURISAN)
+	}
+	return policy, nil
+}
+
+// apply applies the versions and the cipher suites of the policy to the config
+func (p TLSPolicy) apply(config *tls.Config) {
+	config.MinVersion = p.MinVersion
+	config.CipherSuites = p.CipherSuites
+}
+
+// serverURISAN returns the URI SAN that the servers of the component must contain, the trust domain
+// is the cluster domain of the cluster, or cluster.local if it's not set
+func (p TLSPolicy) serverURISAN(namespace Namespace, tcName, clusterDomain string, component v1alpha1.MemberType) string {
+	if clusterDomain == "" {
+		clusterDomain = defaultTrustDomain
+	}
+	return strings.NewReplacer(
+		"{clusterDomain}", clusterDomain,
+		"{namespace}", string(namespace),
+		"{cluster}", tcName,
+		"{component}", string(component),
+	).Replace(p.ServerURISAN)
+}
+
+// GetServerURISAN returns the URI SAN that the certificates of the servers of the component should contain,
+// the certificates issued by the internal CA contain it so that they pass the verification of the policy.
+func GetServerURISAN(namespace Namespace, tcName, clusterDomain string, component v1alpha1.MemberType) string {
+	p := tlsPolicy
+	if p.ServerURISAN == "" {
+		p.ServerURISAN = DefaultServerURISAN
+	}
+	return p.serverURISAN(namespace, tcName, clusterDomain, component)
+}
+
+// verifyServerURISAN returns the function that verifies that the certificate of the server contains the URI SAN,
+// it's called after the certificate chain is verified against the CA.
+func verifyServerURISAN(expected string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return fmt.Errorf("no certificate of the server %s", cs.ServerName)
+		}
+		for _, uri := range cs.PeerCertificates[0].URIs {
+			if uri.String() == expected {
+				return nil
+			}
+		}
+		return fmt.Errorf("the certificate of the server %s doesn't contain the URI SAN %s", cs.ServerName, expected)
+	}
+}
+
+// TLSServer is the component of the cluster that the client connects to. The URI SAN of its certificate is
+// verified if the policy requires it, only the CA is verified for the empty one, such as the servers of DM
+// whose certificates are not issued for the components of TidbCluster.
+type TLSServer struct {
+	Namespace     Namespace
+	ClusterName   string
+	ClusterDomain string
+	Component     v1alpha1.MemberType
+}
+
+// GetTLSConfig returns *tls.Config for given TiDB cluster, the client certs are loaded from the secret and the
+// certificate of the server is verified by the policy.
+func GetTLSConfig(secretLister corelisterv1.SecretLister, namespace Namespace, secretName string, server TLSServer) (*tls.Config, error) {
+	secret, err := secretLister.Secrets(string(namespace)).Get(secretName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load certificates from secret %s/%s: %v", namespace, secretName, err)
+	}
+
+	config, err := crypto.LoadTlsConfigFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	tlsPolicy.apply(config)
+	if tlsPolicy.ServerURISAN != "" && server.ClusterName != "" {
+		config.VerifyConnection = verifyServerURISAN(tlsPolicy.serverURISAN(server.Namespace, server.ClusterName, server.ClusterDomain, server.Component))
+	}
+	return config, nil
+}
+
+// GetComponentTLSConfig returns *tls.Config for the operator to connect to the component of given TiDB cluster.
+// The client certificate of the component in `<clusterName>-<component>-cluster-client-secret` takes precedence
+// over the one of the cluster in `<clusterName>-cluster-client-secret`, so that the components can authorize the
+// operator by different identities. The URI SAN of the servers is verified if the policy requires it.
+func GetComponentTLSConfig(secretLister corelisterv1.SecretLister, namespace Namespace, tcName, clusterDomain string, component v1alpha1.MemberType) (*tls.Config, error) {
+	secretName := util.ComponentClientTLSSecretName(tcName, string(component))
+	if _, err := secretLister.Secrets(string(namespace)).Get(secretName); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to load certificates from secret %s/%s: %v", namespace, secretName, err)
+		}
+		secretName = util.ClusterClientTLSSecretName(tcName)
+	}
+
+	return GetTLSConfig(secretLister, namespace, secretName, TLSServer{
+		Namespace:     namespace,
+		ClusterName:   tcName,
+		ClusterDomain: clusterDomain,
+		Component:     component,
+	})
+}
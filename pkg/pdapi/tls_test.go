@@ -0,0 +1,139 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewTLSPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy, err := NewTLSPolicy("", "", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy).To(Equal(TLSPolicy{}))
+
+	policy, err = NewTLSPolicy("1.2", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		"spiffe://cluster.local/ns/{namespace}/tc/{cluster}/{component}")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+	g.Expect(policy.CipherSuites).To(Equal([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}))
+	g.Expect(policy.serverURISAN("ns", "basic", "", v1alpha1.TiKVMemberType)).To(Equal("spiffe://cluster.local/ns/ns/tc/basic/tikv"))
+	// the trust domain is the cluster domain of the cluster
+	policy.ServerURISAN = DefaultServerURISAN
+	g.Expect(policy.serverURISAN("ns", "basic", "", v1alpha1.TiKVMemberType)).To(Equal("spiffe://cluster.local/ns/ns/tc/basic/tikv"))
+	g.Expect(policy.serverURISAN("ns", "basic", "cluster-1.com", v1alpha1.TiKVMemberType)).To(Equal("spiffe://cluster-1.com/ns/ns/tc/basic/tikv"))
+
+	_, err = NewTLSPolicy("1.4", "", "")
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported TLS version")))
+	_, err = NewTLSPolicy("1.3", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "")
+	g.Expect(err).To(MatchError(ContainSubstring("not configurable for TLS 1.3")))
+	// the insecure cipher suites are rejected
+	_, err = NewTLSPolicy("", "TLS_RSA_WITH_RC4_128_SHA", "")
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported or insecure cipher suite")))
+	_, err = NewTLSPolicy("", "", "cluster.local/ns/{namespace}")
+	g.Expect(err).To(MatchError(ContainSubstring("should contain a scheme")))
+}
+
+func TestVerifyServerURISAN(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	uri, err := url.Parse("spiffe://cluster.local/ns/ns/tc/basic/pd")
+	g.Expect(err).NotTo(HaveOccurred())
+	verify := verifyServerURISAN("spiffe://cluster.local/ns/ns/tc/basic/pd")
+
+	g.Expect(verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{{URIs: []*url.URL{uri}}}})).To(Succeed())
+	g.Expect(verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}})).NotTo(Succeed())
+	g.Expect(verify(tls.ConnectionState{})).NotTo(Succeed())
+}
+
+func TestGetComponentTLSConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	defer SetTLSPolicy(TLSPolicy{})
+
+	caCert, caKey, err := crypto.NewCA("ca", time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+	newSecret := func(name string) *corev1.Secret {
+		cert, key, err := crypto.IssueCert(caCert, caKey, name, nil, nil, nil, time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:              cert,
+				corev1.TLSPrivateKeyKey:        key,
+				corev1.ServiceAccountRootCAKey: caCert,
+			},
+		}
+	}
+	commonName := func(config *tls.Config) string {
+		cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+		g.Expect(err).NotTo(HaveOccurred())
+		return cert.Subject.CommonName
+	}
+
+	informer := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	indexer := informer.Core().V1().Secrets().Informer().GetIndexer()
+	lister := informer.Core().V1().Secrets().Lister()
+
+	_, err = GetComponentTLSConfig(lister, "ns", "basic", "", v1alpha1.PDMemberType)
+	g.Expect(err).To(HaveOccurred())
+
+	// the cluster client certs are used if the component client certs don't exist
+	g.Expect(indexer.Add(newSecret("basic-cluster-client-secret"))).To(Succeed())
+	g.Expect(indexer.Add(newSecret("basic-tikv-cluster-client-secret"))).To(Succeed())
+	config, err := GetComponentTLSConfig(lister, "ns", "basic", "", v1alpha1.PDMemberType)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commonName(config)).To(Equal("basic-cluster-client-secret"))
+	g.Expect(config.VerifyConnection).To(BeNil())
+
+	SetTLSPolicy(TLSPolicy{
+		MinVersion:   tls.VersionTLS13,
+		ServerURISAN: "spiffe://cluster.local/ns/{namespace}/tc/{cluster}/{component}",
+	})
+	config, err = GetComponentTLSConfig(lister, "ns", "basic", "", v1alpha1.TiKVMemberType)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commonName(config)).To(Equal("basic-tikv-cluster-client-secret"))
+	g.Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS13)))
+	g.Expect(config.VerifyConnection).NotTo(BeNil())
+
+	// the URI SAN of the target cluster is verified if the clients use the certs of the specified secret
+	uri, err := url.Parse("spiffe://cluster.local/ns/target/tc/basic/pd")
+	g.Expect(err).NotTo(HaveOccurred())
+	cc := &clientConfig{tlsEnable: true}
+	cc.applyOptions(TLSCertFromSecret("ns", "basic-cluster-client-secret"))
+	cc.completeForPDClient("target", "basic", "")
+	config, err = cc.getTLSConfig(lister, v1alpha1.PDMemberType)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commonName(config)).To(Equal("basic-cluster-client-secret"))
+	g.Expect(config.VerifyConnection).NotTo(BeNil())
+	g.Expect(config.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{{URIs: []*url.URL{uri}}}})).To(Succeed())
+	g.Expect(config.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}})).NotTo(Succeed())
+
+	// only the CA is verified for the servers not issued for the components
+	config, err = GetTLSConfig(lister, "ns", "basic-cluster-client-secret", TLSServer{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.VerifyConnection).To(BeNil())
+}
@@ -74,4 +74,8 @@ type CreateUpdateStrategy interface {
 	Validate(ctx context.Context, obj runtime.Object) field.ErrorList
 	// ValidateUpdate validates an update request for existing resource
 	ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList
+	// WarningsOnCreate returns warnings to the client performing a create, they don't reject the request
+	WarningsOnCreate(ctx context.Context, obj runtime.Object) []string
+	// WarningsOnUpdate returns warnings to the client performing an update, they don't reject the request
+	WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/defaulting"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1/validation"
+	"github.com/pingcap/tidb-operator/pkg/webhook/configschema"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
@@ -43,7 +44,9 @@ func (TidbClusterStrategy) PrepareForUpdate(ctx context.Context, obj, old runtim
 
 func (TidbClusterStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
 	if tc, ok := castTidbCluster(obj); ok {
-		return validation.ValidateCreateTidbCluster(tc)
+		allErrs := validation.ValidateCreateTidbCluster(tc)
+		configErrs, _ := configschema.ValidateTidbCluster(tc)
+		return append(allErrs, configErrs...)
 	}
 	return field.ErrorList{}
 }
@@ -52,11 +55,31 @@ func (TidbClusterStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.
 	oldTc, oldOk := castTidbCluster(old)
 	tc, ok := castTidbCluster(obj)
 	if ok && oldOk {
-		return validation.ValidateUpdateTidbCluster(oldTc, tc)
+		allErrs := validation.ValidateUpdateTidbCluster(oldTc, tc)
+		configErrs, _ := configschema.ValidateTidbClusterUpdate(oldTc, tc)
+		return append(allErrs, configErrs...)
 	}
 	return field.ErrorList{}
 }
 
+func (TidbClusterStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	if tc, ok := castTidbCluster(obj); ok {
+		_, warnings := configschema.ValidateTidbCluster(tc)
+		return warnings
+	}
+	return nil
+}
+
+func (TidbClusterStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	oldTc, oldOk := castTidbCluster(old)
+	tc, ok := castTidbCluster(obj)
+	if ok && oldOk {
+		_, warnings := configschema.ValidateTidbClusterUpdate(oldTc, tc)
+		return warnings
+	}
+	return nil
+}
+
 func castTidbCluster(obj runtime.Object) (*v1alpha1.TidbCluster, bool) {
 	tc, ok := obj.(*v1alpha1.TidbCluster)
 	if !ok {
@@ -0,0 +1,176 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog parses the slow query log of TiDB into structured records
+// and ships them to sinks.
+package slowlog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The TiDB slow log consists of multi-line entries. Each entry starts with
+// "# Time: ...", followed by lines of "# Key: value" fields, optionally a
+// "use db;" line, and ends with the SQL statement terminated by ";", e.g.
+//
+//	# Time: 2024-01-01T00:00:00.000000000+08:00
+//	# Txn_start_ts: 446683366093488129
+//	# User@Host: root[root] @ 10.0.0.1 [10.0.0.1]
+//	# Conn_ID: 3
+//	# Query_time: 1.527627037
+//	# Process_time: 0.07 Wait_time: 0.002
+//	# DB: test
+//	# Digest: 42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772
+//	# Plan_digest: e5f9d9746c756438a13c75ba3eedf601eecf555cdb7ad327d7092bdd041a83e7
+//	use test;
+//	select * from t where a > 1;
+const (
+	linePrefix   = "# "
+	fieldSep     = ": "
+	sqlSuffix    = ";"
+	timeField    = "Time"
+	userHostKey  = "User@Host"
+	queryTimeKey = "Query_time"
+	connIDKey    = "Conn_ID"
+	dbKey        = "DB"
+	digestKey    = "Digest"
+	planDigest   = "Plan_digest"
+)
+
+// fields whose values may contain spaces, the rest of the line is the value
+var singleValueFields = map[string]bool{
+	timeField:     true,
+	userHostKey:   true,
+	"Plan":        true,
+	"Binary_plan": true,
+	"Prev_stmt":   true,
+	"Stats":       true,
+	"Index_names": true,
+}
+
+// Record is a parsed entry of the slow log
+type Record struct {
+	Time       string  `json:"time"`
+	Instance   string  `json:"instance,omitempty"`
+	QueryTime  float64 `json:"query_time"`
+	Digest     string  `json:"digest,omitempty"`
+	PlanDigest string  `json:"plan_digest,omitempty"`
+	User       string  `json:"user,omitempty"`
+	Host       string  `json:"host,omitempty"`
+	DB         string  `json:"db,omitempty"`
+	ConnID     string  `json:"conn_id,omitempty"`
+	Query      string  `json:"query"`
+	// Fields are all the other fields of the entry keyed by their names in the slow log
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Parser assembles lines of the slow log into records. It's not safe for concurrent use.
+type Parser struct {
+	current *Record
+	query   []string
+}
+
+// Feed adds a line of the slow log and returns the record if the line ends an entry.
+func (p *Parser) Feed(line string) *Record {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, linePrefix) {
+		p.parseFields(line[len(linePrefix):])
+		return nil
+	}
+	if p.current == nil || line == "" {
+		// the tail of an entry whose head is not seen, e.g. the tailer starts in the middle of an entry
+		return nil
+	}
+	if len(p.query) == 0 && strings.HasPrefix(line, "use ") && strings.HasSuffix(line, sqlSuffix) {
+		if p.current.DB == "" {
+			p.current.DB = strings.TrimSuffix(strings.TrimPrefix(line, "use "), sqlSuffix)
+		}
+		return nil
+	}
+	p.query = append(p.query, line)
+	if !strings.HasSuffix(line, sqlSuffix) {
+		return nil
+	}
+	r := p.current
+	r.Query = strings.Join(p.query, "\n")
+	p.current, p.query = nil, nil
+	return r
+}
+
+func (p *Parser) parseFields(line string) {
+	if strings.HasPrefix(line, timeField+fieldSep) {
+		// a new entry begins, the unfinished one is dropped
+		p.current = &Record{Time: strings.TrimSpace(line[len(timeField+fieldSep):])}
+		p.query = nil
+		return
+	}
+	if p.current == nil {
+		return
+	}
+	for line != "" {
+		i := strings.Index(line, fieldSep)
+		if i <= 0 {
+			return
+		}
+		key := line[:i]
+		rest := line[i+len(fieldSep):]
+		var value string
+		if singleValueFields[key] {
+			value, line = rest, ""
+		} else if j := strings.IndexByte(rest, ' '); j >= 0 {
+			value, line = rest[:j], rest[j+1:]
+		} else {
+			value, line = rest, ""
+		}
+		p.current.set(key, strings.TrimSpace(value))
+	}
+}
+
+func (r *Record) set(key, value string) {
+	switch key {
+	case queryTimeKey:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			r.QueryTime = v
+			return
+		}
+	case digestKey:
+		r.Digest = value
+		return
+	case planDigest:
+		r.PlanDigest = value
+		return
+	case dbKey:
+		r.DB = value
+		return
+	case connIDKey:
+		r.ConnID = value
+		return
+	case userHostKey:
+		// root[root] @ 10.0.0.1 [10.0.0.1]
+		user, host, _ := strings.Cut(value, "@")
+		if i := strings.IndexByte(user, '['); i >= 0 {
+			user = user[:i]
+		}
+		host = strings.TrimSpace(host)
+		if i := strings.IndexByte(host, ' '); i >= 0 {
+			host = host[:i]
+		}
+		r.User, r.Host = strings.TrimSpace(user), host
+		return
+	}
+	if r.Fields == nil {
+		r.Fields = map[string]string{}
+	}
+	r.Fields[key] = value
+}
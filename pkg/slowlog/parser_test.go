@@ -0,0 +1,80 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const testSlowLog = `select * from t_incomplete;
+# Time: 2024-01-01T00:00:00.000000000+08:00
+# Txn_start_ts: 446683366093488129
+# User@Host: root[root] @ 10.0.0.1 [10.0.0.1]
+# Conn_ID: 3
+# Query_time: 1.527627037
+# Process_time: 0.07 Wait_time: 0.002
+# Digest: 42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772
+# Stats: t:pseudo
+# Plan_digest: e5f9d9746c756438a13c75ba3eedf601eecf555cdb7ad327d7092bdd041a83e7
+use test;
+select *
+from t where a > 1;
+# Time: 2024-01-01T00:00:01.000000000+08:00
+# User@Host: app[app] @ 10.0.0.2 [10.0.0.2]
+# Query_time: 0.5
+# DB: db1
+insert into t values (1);
+`
+
+func TestParser(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	p := &Parser{}
+	var records []*Record
+	for _, line := range strings.SplitAfter(testSlowLog, "\n") {
+		if r := p.Feed(line); r != nil {
+			records = append(records, r)
+		}
+	}
+
+	g.Expect(records).To(HaveLen(2))
+	g.Expect(*records[0]).To(Equal(Record{
+		Time:       "2024-01-01T00:00:00.000000000+08:00",
+		QueryTime:  1.527627037,
+		Digest:     "42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772",
+		PlanDigest: "e5f9d9746c756438a13c75ba3eedf601eecf555cdb7ad327d7092bdd041a83e7",
+		User:       "root",
+		Host:       "10.0.0.1",
+		DB:         "test",
+		ConnID:     "3",
+		Query:      "select *\nfrom t where a > 1;",
+		Fields: map[string]string{
+			"Txn_start_ts": "446683366093488129",
+			"Process_time": "0.07",
+			"Wait_time":    "0.002",
+			"Stats":        "t:pseudo",
+		},
+	}))
+	g.Expect(*records[1]).To(Equal(Record{
+		Time:      "2024-01-01T00:00:01.000000000+08:00",
+		QueryTime: 0.5,
+		User:      "app",
+		Host:      "10.0.0.2",
+		DB:        "db1",
+		Query:     "insert into t values (1);",
+	}))
+}
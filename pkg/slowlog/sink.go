@@ -0,0 +1,151 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkHTTP   = "http"
+
+	defaultHTTPBatchSize = 100
+	httpTimeout          = 10 * time.Second
+)
+
+// Sink receives the parsed records
+type Sink interface {
+	Write(r *Record) error
+	// Flush sends the buffered records if any
+	Flush() error
+	Close() error
+}
+
+// SinkConfig is the configuration to create a Sink
+type SinkConfig struct {
+	Type string
+	// Path of the file sink
+	Path string
+	// URL of the HTTP sink
+	URL string
+	// BatchSize is the max number of records in a request of the HTTP sink
+	BatchSize int
+}
+
+// NewSink creates the sink of the type
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "", SinkStdout:
+		return &writerSink{enc: json.NewEncoder(os.Stdout)}, nil
+	case SinkFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("path is required for the %s sink", SinkFile)
+		}
+		f, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return &writerSink{enc: json.NewEncoder(f), closer: f}, nil
+	case SinkHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("url is required for the %s sink", SinkHTTP)
+		}
+		batchSize := cfg.BatchSize
+		if batchSize <= 0 {
+			batchSize = defaultHTTPBatchSize
+		}
+		return &httpSink{url: cfg.URL, batchSize: batchSize, client: &http.Client{Timeout: httpTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+}
+
+// writerSink writes a record as a line of JSON
+type writerSink struct {
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+func (s *writerSink) Write(r *Record) error {
+	return s.enc.Encode(r)
+}
+
+func (s *writerSink) Flush() error {
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// httpSink POSTs the records in batches as newline delimited JSON
+type httpSink struct {
+	url       string
+	batchSize int
+	client    *http.Client
+
+	buf   bytes.Buffer
+	count int
+}
+
+func (s *httpSink) Write(r *Record) error {
+	if err := json.NewEncoder(&s.buf).Encode(r); err != nil {
+		return err
+	}
+	s.count++
+	if s.count >= s.batchSize {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered records, they are dropped if the request fails
+// so that a broken endpoint doesn't make the sidecar run out of memory.
+func (s *httpSink) Flush() error {
+	if s.count == 0 {
+		return nil
+	}
+	count := s.count
+	body := bytes.NewReader(s.buf.Bytes())
+	defer func() {
+		s.buf.Reset()
+		s.count = 0
+	}()
+
+	resp, err := s.client.Post(s.url, "application/x-ndjson", body)
+	if err != nil {
+		return fmt.Errorf("failed to send %d records to %s: %v", count, s.url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to send %d records to %s: %s", count, s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	return s.Flush()
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFileSink(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	path := filepath.Join(t.TempDir(), "slowlog.json")
+	s, err := NewSink(SinkConfig{Type: SinkFile, Path: path})
+	g.Expect(err).To(Succeed())
+	g.Expect(s.Write(&Record{Time: "t1", QueryTime: 1, Query: "select 1;"})).To(Succeed())
+	g.Expect(s.Write(&Record{Time: "t2", QueryTime: 2, Query: "select 2;"})).To(Succeed())
+	g.Expect(s.Close()).To(Succeed())
+
+	data, err := os.ReadFile(path)
+	g.Expect(err).To(Succeed())
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	g.Expect(lines).To(HaveLen(2))
+	g.Expect(lines[0]).To(Equal(`{"time":"t1","query_time":1,"query":"select 1;"}`))
+
+	_, err = NewSink(SinkConfig{Type: SinkFile})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestHTTPSink(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var bodies []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s, err := NewSink(SinkConfig{Type: SinkHTTP, URL: server.URL, BatchSize: 2})
+	g.Expect(err).To(Succeed())
+
+	// a request is sent when the batch is full
+	g.Expect(s.Write(&Record{Time: "t1"})).To(Succeed())
+	g.Expect(bodies).To(BeEmpty())
+	g.Expect(s.Write(&Record{Time: "t2"})).To(Succeed())
+	g.Expect(bodies).To(HaveLen(1))
+	lines := strings.Split(strings.TrimSpace(bodies[0]), "\n")
+	g.Expect(lines).To(HaveLen(2))
+	var r Record
+	g.Expect(json.Unmarshal([]byte(lines[1]), &r)).To(Succeed())
+	g.Expect(r.Time).To(Equal("t2"))
+
+	// the records are dropped if the request fails
+	status = http.StatusInternalServerError
+	g.Expect(s.Write(&Record{Time: "t3"})).To(Succeed())
+	g.Expect(s.Flush()).To(HaveOccurred())
+	g.Expect(bodies).To(HaveLen(2))
+	g.Expect(s.Flush()).To(Succeed())
+	g.Expect(bodies).To(HaveLen(2))
+
+	_, err = NewSink(SinkConfig{Type: SinkHTTP})
+	g.Expect(err).To(HaveOccurred())
+	_, err = NewSink(SinkConfig{Type: "kafka"})
+	g.Expect(err).To(HaveOccurred())
+}
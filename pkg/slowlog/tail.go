@@ -0,0 +1,145 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Tail follows the file like `tail -n0 -F`, the lines appended to the file are passed to fn.
+// The file is reopened if it's rotated or truncated. idle is called when there is no new
+// line for pollInterval. It returns when ctx is done.
+func Tail(ctx context.Context, path string, pollInterval time.Duration, fn func(line string), idle func()) error {
+	var (
+		f      *os.File
+		reader *bufio.Reader
+		// a line without the line break is kept until the rest is written
+		partial string
+	)
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	open := func(seekEnd bool) error {
+		if f != nil {
+			f.Close()
+			f, reader = nil, nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		if seekEnd {
+			if _, err := file.Seek(0, io.SeekEnd); err != nil {
+				file.Close()
+				return err
+			}
+		}
+		f, reader, partial = file, bufio.NewReader(file), ""
+		return nil
+	}
+	if err := open(true); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// drain passes the lines to fn until the end of the opened file
+	drain := func() error {
+		if reader == nil {
+			return nil
+		}
+		for {
+			line, err := reader.ReadString('\n')
+			if err == nil {
+				fn(partial + line)
+				partial = ""
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				partial += line
+				return nil
+			}
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := drain(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		idle()
+
+		reopen, err := shouldReopen(f, path)
+		if err != nil {
+			klog.Warningf("failed to check slow log file %s: %v", path, err)
+			continue
+		}
+		if reopen {
+			// the lines written to the rotated file after the last read are not lost
+			if err := drain(); err != nil {
+				return err
+			}
+			if partial != "" {
+				fn(partial)
+			}
+			// read the new file from the beginning, all the lines are new
+			if err := open(false); err != nil && !os.IsNotExist(err) {
+				klog.Warningf("failed to open slow log file %s: %v", path, err)
+			}
+		}
+	}
+}
+
+// shouldReopen returns true if the file at path is not the opened one, or it's truncated
+func shouldReopen(f *os.File, path string) (bool, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// rotated and the new one is not created yet
+			return false, nil
+		}
+		return false, err
+	}
+	if f == nil {
+		return true, nil
+	}
+	opened, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(st, opened) {
+		return true, nil
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	return st.Size() < offset, nil
+}
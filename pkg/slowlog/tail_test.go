@@ -0,0 +1,74 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTailRotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	path := filepath.Join(t.TempDir(), "slowlog")
+	f, err := os.Create(path)
+	g.Expect(err).To(Succeed())
+	defer f.Close()
+
+	var (
+		mu    sync.Mutex
+		lines []string
+		idles int
+	)
+	getLines := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+	idle := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		idles++
+		switch idles {
+		case 1:
+			// the file is opened before the first idle
+			g.Expect(f.WriteString("line1\n")).Error().To(Succeed())
+		case 3:
+			// rotated after line1 is read, and line2 is written to the rotated file before the reopen
+			g.Expect(os.Rename(path, path+".1")).To(Succeed())
+			g.Expect(f.WriteString("line2\n")).Error().To(Succeed())
+			g.Expect(os.WriteFile(path, []byte("line3\n"), 0644)).To(Succeed())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Tail(ctx, path, 10*time.Millisecond, func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			lines = append(lines, line)
+		}, idle)
+	}()
+
+	g.Eventually(getLines, 5*time.Second).Should(Equal([]string{"line1\n", "line2\n", "line3\n"}))
+	cancel()
+	g.Expect(<-done).To(Succeed())
+}
@@ -21,7 +21,6 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
-	"github.com/pingcap/tidb-operator/pkg/util"
 	corelisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 )
@@ -33,7 +32,7 @@ const (
 // TiFlashControlInterface is an interface that knows how to manage and get client for TiFlash
 type TiFlashControlInterface interface {
 	// GetTiFlashPodClient provides TiFlashClient of the TiFlash cluster.
-	GetTiFlashPodClient(namespace string, tcName string, podName string, tlsEnabled bool) TiFlashClient
+	GetTiFlashPodClient(namespace string, tcName string, podName, clusterDomain string, tlsEnabled bool) TiFlashClient
 }
 
 // defaultTiFlashControl is the default implementation of TiFlashControlInterface.
@@ -47,7 +46,7 @@ func NewDefaultTiFlashControl(secretLister corelisterv1.SecretLister) TiFlashCon
 	return &defaultTiFlashControl{secretLister: secretLister}
 }
 
-func (tc *defaultTiFlashControl) GetTiFlashPodClient(namespace string, tcName string, podName string, tlsEnabled bool) TiFlashClient {
+func (tc *defaultTiFlashControl) GetTiFlashPodClient(namespace string, tcName string, podName, clusterDomain string, tlsEnabled bool) TiFlashClient {
 	tc.mutex.Lock()
 	defer tc.mutex.Unlock()
 
@@ -57,16 +56,16 @@ func (tc *defaultTiFlashControl) GetTiFlashPodClient(namespace string, tcName st
 
 	if tlsEnabled {
 		scheme = "https"
-		tlsConfig, err = pdapi.GetTLSConfig(tc.secretLister, pdapi.Namespace(namespace), util.ClusterClientTLSSecretName(tcName))
+		tlsConfig, err = pdapi.GetComponentTLSConfig(tc.secretLister, pdapi.Namespace(namespace), tcName, clusterDomain, v1alpha1.TiFlashMemberType)
 		if err != nil {
 			klog.Errorf("Unable to get tls config for TiFlash cluster %q, tiflash client may not work: %v", tcName, err)
-			return NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+			return withClusterTracing(NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true), namespace, tcName)
 		}
 
-		return NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+		return withClusterTracing(NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true), namespace, tcName)
 	}
 
-	return NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
+	return withClusterTracing(NewTiFlashClient(TiFlashPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true), namespace, tcName)
 }
 
 func tiflashPodClientKey(schema, namespace, clusterName, podName string) string {
@@ -95,6 +94,6 @@ func (ftc *FakeTiFlashControl) SetTiFlashPodClient(namespace, tcName, podName st
 	ftc.tiflashPodClients[tiflashPodClientKey("http", namespace, tcName, podName)] = tiflashPodClient
 }
 
-func (ftc *FakeTiFlashControl) GetTiFlashPodClient(namespace, tcName, podName string, tlsEnabled bool) TiFlashClient {
+func (ftc *FakeTiFlashControl) GetTiFlashPodClient(namespace, tcName, podName, clusterDomain string, tlsEnabled bool) TiFlashClient {
 	return ftc.tiflashPodClients[tiflashPodClientKey("http", namespace, tcName, podName)]
 }
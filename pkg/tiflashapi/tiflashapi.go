@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/tracing"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 )
 
@@ -46,6 +47,14 @@ type tiflashClient struct {
 	httpClient *http.Client
 }
 
+// withClusterTracing traces the requests sent by the client during the reconcile of the cluster
+func withClusterTracing(c TiFlashClient, namespace, tcName string) TiFlashClient {
+	if client, ok := c.(*tiflashClient); ok {
+		client.httpClient.Transport = tracing.NewClusterTransport(client.httpClient.Transport, namespace, tcName)
+	}
+	return c
+}
+
 // NewTiFlashClient returns a new TiFlashClient
 func NewTiFlashClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) TiFlashClient {
 	return &tiflashClient{
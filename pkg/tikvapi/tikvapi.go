@@ -14,13 +14,17 @@
 package tikvapi
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/tracing"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prom2json"
 	"k8s.io/klog/v2"
@@ -31,11 +35,17 @@ const (
 	metricNameRegionCount = "tikv_raftstore_region_count"
 	labelNameLeaderCount  = "leader"
 	metricsPrefix         = "metrics"
+	configPrefix          = "config"
 )
 
 // TiKVClient provides tikv server's api
 type TiKVClient interface {
 	GetLeaderCount() (int, error)
+	// UpdateConfig changes the configuration items online, the keys are the full names
+	// of the items, such as "raftstore.raft-log-gc-threshold"
+	UpdateConfig(config map[string]interface{}) error
+	// GetConfig returns the live configuration of TiKV
+	GetConfig() (map[string]interface{}, error)
 }
 
 // tikvClient is default implementation of TiKVClient
@@ -74,6 +84,50 @@ func (c *tikvClient) GetLeaderCount() (int, error) {
 	return 0, fmt.Errorf("metric %s{type=\"%s\"} not found for %s", metricNameRegionCount, labelNameLeaderCount, apiURL)
 }
 
+// UpdateConfig changes the configuration items online
+func (c *tikvClient) UpdateConfig(config map[string]interface{}) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Post(apiURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	err = httputil.ReadErrorBody(res.Body)
+	return &httputil.StatusError{
+		StatusCode: res.StatusCode,
+		Message:    fmt.Sprintf("failed %v to update config: %v", res.StatusCode, err),
+	}
+}
+
+// GetConfig returns the live configuration of TiKV
+func (c *tikvClient) GetConfig() (map[string]interface{}, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, configPrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// withClusterTracing traces the requests sent by the client during the reconcile of the cluster
+func withClusterTracing(c TiKVClient, namespace, tcName string) TiKVClient {
+	if client, ok := c.(*tikvClient); ok {
+		client.httpClient.Transport = tracing.NewClusterTransport(client.httpClient.Transport, namespace, tcName)
+	}
+	return c
+}
+
 // NewTiKVClient returns a new TiKVClient
 func NewTiKVClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) TiKVClient {
 	return &tikvClient{
@@ -0,0 +1,233 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing exports OpenTelemetry traces of reconciling clusters.
+//
+// Most of the code paths in the operator do not accept a context, so the
+// context of the span started for a reconcile is recorded by the namespace
+// and name of the cluster. Any code that knows which cluster it is working
+// for can start a child span of the reconcile with StartClusterSpan. The
+// sync of each component is started with StartClusterSyncSpan, which makes
+// its span the parent of the spans started by the component until it ends.
+// A cluster is only reconciled by one worker at a time, so there is at most
+// one current context for each cluster.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/pingcap/tidb-operator"
+
+	// AttrNamespace is the span attribute of the namespace of the cluster
+	AttrNamespace = attribute.Key("tidbcluster.namespace")
+	// AttrName is the span attribute of the name of the cluster
+	AttrName = attribute.Key("tidbcluster.name")
+)
+
+var (
+	enabled atomic.Bool
+	// clusterContexts records the context of the current span of the
+	// reconcile of each cluster, key: namespace/name
+	clusterContexts sync.Map
+)
+
+// Config is the configuration of tracing
+type Config struct {
+	// Endpoint is the address of the OTLP gRPC receiver, tracing is
+	// disabled if it's empty
+	Endpoint string
+	// Insecure disables the TLS of the connection to the receiver
+	Insecure bool
+	// SampleRatio is the ratio of reconciles to be traced
+	SampleRatio float64
+	// ServiceName is reported as the service.name of the resource
+	ServiceName string
+}
+
+// Init sets up the global tracer provider exporting spans to the OTLP
+// endpoint. The returned function flushes and stops the exporter.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter for %s: %v", cfg.Endpoint, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	)
+	SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// SetTracerProvider sets the global tracer provider and enables tracing.
+func SetTracerProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	enabled.Store(true)
+}
+
+// Enabled returns whether tracing is enabled.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func clusterKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// ClusterAttributes returns the span attributes of a cluster.
+func ClusterAttributes(namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{AttrNamespace.String(namespace), AttrName.String(name)}
+}
+
+// StartClusterReconcile starts the root span of reconciling the cluster and
+// records its context for child spans. The returned function must be called
+// with the result of the reconcile to end the span.
+func StartClusterReconcile(namespace, name, spanName string) func(error) {
+	if !Enabled() {
+		return func(error) {}
+	}
+
+	key := clusterKey(namespace, name)
+	ctx, span := tracer().Start(context.Background(), spanName,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(ClusterAttributes(namespace, name)...))
+	clusterContexts.Store(key, ctx)
+
+	return func(err error) {
+		clusterContexts.Delete(key)
+		End(span, err)
+	}
+}
+
+// ClusterContext returns the context of the current span of the reconcile of
+// the cluster, which is the span of the component being synced if any.
+// The second return value is false if the cluster is not being reconciled.
+func ClusterContext(namespace, name string) (context.Context, bool) {
+	if !Enabled() {
+		return context.Background(), false
+	}
+	v, ok := clusterContexts.Load(clusterKey(namespace, name))
+	if !ok {
+		return context.Background(), false
+	}
+	return v.(context.Context), true
+}
+
+// StartClusterSyncSpan starts a child span of the current span of the cluster
+// and makes it the parent of the spans started for the cluster, e.g. the
+// writes to the Kubernetes API and the requests to PD, until the returned
+// function is called with the result of the sync.
+func StartClusterSyncSpan(namespace, name, spanName string) func(error) {
+	parent, ok := ClusterContext(namespace, name)
+	if !ok {
+		return func(error) {}
+	}
+
+	key := clusterKey(namespace, name)
+	ctx, span := tracer().Start(parent, spanName,
+		trace.WithAttributes(ClusterAttributes(namespace, name)...))
+	clusterContexts.Store(key, ctx)
+
+	return func(err error) {
+		clusterContexts.Store(key, parent)
+		End(span, err)
+	}
+}
+
+// StartClusterSpan starts a child span of the current span of the cluster.
+// A non-recording span is returned if the cluster is not being reconciled so
+// work done out of reconciles doesn't create orphan traces.
+func StartClusterSpan(namespace, name, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, ok := ClusterContext(namespace, name)
+	if !ok {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer().Start(ctx, spanName,
+		trace.WithAttributes(append(ClusterAttributes(namespace, name), attrs...)...))
+}
+
+// End records the error if any and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// clusterTransport traces the HTTP requests sent to components of a cluster.
+type clusterTransport struct {
+	namespace string
+	name      string
+	rt        http.RoundTripper
+}
+
+// NewClusterTransport wraps rt so that requests sent during the reconcile of
+// the cluster are traced as child spans of the reconcile.
+func NewClusterTransport(rt http.RoundTripper, namespace, name string) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &clusterTransport{namespace: namespace, name: name, rt: rt}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *clusterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := StartClusterSpan(t.namespace, t.name, fmt.Sprintf("HTTP %s %s", req.Method, req.URL.Path),
+		attribute.String("http.method", req.Method),
+		attribute.String("http.url", req.URL.String()))
+	if !span.IsRecording() {
+		return t.rt.RoundTrip(req)
+	}
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := t.rt.RoundTrip(req)
+	if err == nil {
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	End(span, err)
+	return resp, err
+}
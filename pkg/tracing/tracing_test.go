@@ -0,0 +1,79 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClusterSpans(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	cli := &http.Client{Transport: NewClusterTransport(nil, "ns", "basic")}
+
+	// not reconciling
+	_, span := StartClusterSpan("ns", "basic", "PDMemberManager.Sync")
+	g.Expect(span.IsRecording()).To(BeFalse())
+	_, err := cli.Get(srv.URL + "/pd/api/v1/health")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(traceparent).To(BeEmpty())
+	g.Expect(recorder.Ended()).To(BeEmpty())
+
+	endReconcile := StartClusterReconcile("ns", "basic", "UpdateTidbCluster")
+	endSync := StartClusterSyncSpan("ns", "basic", "PDMemberManager.Sync")
+	_, err = cli.Get(srv.URL + "/pd/api/v1/health")
+	g.Expect(err).NotTo(HaveOccurred())
+	endSync(nil)
+	// the reconcile span is the parent again after the sync
+	_, span = StartClusterSpan("ns", "basic", "Update TidbCluster")
+	End(span, nil)
+	// another cluster
+	_, other := StartClusterSpan("ns", "other", "PDMemberManager.Sync")
+	g.Expect(other.IsRecording()).To(BeFalse())
+	endReconcile(fmt.Errorf("failed"))
+
+	spans := recorder.Ended()
+	g.Expect(spans).To(HaveLen(4))
+	httpSpan, syncSpan, updateSpan, rootSpan := spans[0], spans[1], spans[2], spans[3]
+	g.Expect(rootSpan.Name()).To(Equal("UpdateTidbCluster"))
+	g.Expect(rootSpan.Attributes()).To(ContainElements(AttrNamespace.String("ns"), AttrName.String("basic")))
+	g.Expect(rootSpan.Status().Description).To(Equal("failed"))
+	g.Expect(syncSpan.Name()).To(Equal("PDMemberManager.Sync"))
+	g.Expect(syncSpan.Parent().SpanID()).To(Equal(rootSpan.SpanContext().SpanID()))
+	g.Expect(httpSpan.Name()).To(Equal("HTTP GET /pd/api/v1/health"))
+	g.Expect(httpSpan.Parent().SpanID()).To(Equal(syncSpan.SpanContext().SpanID()))
+	g.Expect(updateSpan.Parent().SpanID()).To(Equal(rootSpan.SpanContext().SpanID()))
+	g.Expect(httpSpan.Status().Description).To(Equal("500 Internal Server Error"))
+	g.Expect(traceparent).To(ContainSubstring(rootSpan.SpanContext().TraceID().String()))
+
+	// the reconcile context is removed after the reconcile
+	_, ok := ClusterContext("ns", "basic")
+	g.Expect(ok).To(BeFalse())
+}
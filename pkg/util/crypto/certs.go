@@ -22,7 +22,10 @@ import (
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net"
+	"net/url"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
@@ -69,11 +72,7 @@ func NewCSR(commonName string, hostList []string, IPList []string) ([]byte, []by
 
 	// set CSR attributes
 	csrTemplate := &x509.CertificateRequest{
-		Subject: pkix.Name{
-			Organization:       []string{"PingCAP"},
-			OrganizationalUnit: []string{"TiDB Operator"},
-			CommonName:         commonName,
-		},
+		Subject:     newSubject(commonName),
 		DNSNames:    hostList,
 		IPAddresses: ipAddrList,
 	}
@@ -85,6 +84,146 @@ func NewCSR(commonName string, hostList []string, IPList []string) ([]byte, []by
 	return csr, convertKeyToPEM("RSA PRIVATE KEY", privKey), nil
 }
 
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func newSubject(commonName string) pkix.Name {
+	return pkix.Name{
+		Organization:       []string{"PingCAP"},
+		OrganizationalUnit: []string{"TiDB Operator"},
+		CommonName:         commonName,
+	}
+}
+
+// NewCA generates a self-signed CA, and returns the certificate and the private key in PEM format
+func NewCA(commonName string, validity time.Duration) ([]byte, []byte, error) {
+	privKey, err := newPrivateKey(rsaKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               newSubject(commonName),
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privKey.PublicKey, privKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), convertKeyToPEM("RSA PRIVATE KEY", privKey), nil
+}
+
+// IssueCert issues a certificate signed by the CA for both the server and the client authentication,
+// and returns the certificate and the private key in PEM format
+func IssueCert(caCertPEM, caKeyPEM []byte, commonName string, hostList []string, IPList []string, URIList []string, validity time.Duration) ([]byte, []byte, error) {
+	ca, err := tls.X509KeyPair(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load the CA: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privKey, err := newPrivateKey(rsaKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ipAddrList []net.IP
+	for _, ip := range IPList {
+		ipAddrList = append(ipAddrList, net.ParseIP(ip))
+	}
+	var uris []*url.URL
+	for _, u := range URIList {
+		uri, err := url.Parse(u)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid URI SAN %q: %v", u, err)
+		}
+		uris = append(uris, uri)
+	}
+
+	now := time.Now()
+	notAfter := now.Add(validity)
+	// the certificate can not outlive the CA
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      newSubject(commonName),
+		DNSNames:     hostList,
+		IPAddresses:  ipAddrList,
+		URIs:         uris,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &privKey.PublicKey, ca.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), convertKeyToPEM("RSA PRIVATE KEY", privKey), nil
+}
+
+// ParseCertPEM parses the first certificate in PEM format
+func ParseCertPEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate is found in PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// ParseCertsPEM parses all the certificates in PEM format, such as a bundle of CAs
+func ParseCertsPEM(certsPEM []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, certsPEM = pem.Decode(certsPEM)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate is found in PEM")
+	}
+	return certs, nil
+}
+
+// EncodeCertsPEM encodes the certificates in PEM format
+func EncodeCertsPEM(certs ...*x509.Certificate) []byte {
+	var buf []byte
+	for _, cert := range certs {
+		buf = append(buf, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return buf
+}
+
 func readCACerts(tryAppendCAFile string) (*x509.CertPool, error) {
 	// try to load system CA certs
 	rootCAs, err := x509.SystemCertPool()
@@ -18,6 +18,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -52,6 +53,58 @@ func TestNewCSR(t *testing.T) {
 	g.Expect(csrObj.IPAddresses[1].String()).Should(Equal("fe80:2333::dead:beef"))
 }
 
+func TestIssueCert(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	caCert, caKey, err := NewCA("test-ca", 24*time.Hour)
+	g.Expect(err).Should(BeNil())
+	ca, err := ParseCertPEM(caCert)
+	g.Expect(err).Should(BeNil())
+	g.Expect(ca.IsCA).Should(BeTrue())
+	g.Expect(ca.Subject.CommonName).Should(Equal("test-ca"))
+
+	certPEM, keyPEM, err := IssueCert(caCert, caKey, "tidb", []string{"tidb", "*.tidb-peer"}, []string{"127.0.0.1"},
+		[]string{"spiffe://cluster.local/ns/ns/tc/basic/tidb"}, 48*time.Hour)
+	g.Expect(err).Should(BeNil())
+	cert, err := ParseCertPEM(certPEM)
+	g.Expect(err).Should(BeNil())
+	g.Expect(cert.DNSNames).Should(Equal([]string{"tidb", "*.tidb-peer"}))
+	g.Expect(cert.IPAddresses[0].String()).Should(Equal("127.0.0.1"))
+	g.Expect(cert.URIs).Should(HaveLen(1))
+	g.Expect(cert.URIs[0].String()).Should(Equal("spiffe://cluster.local/ns/ns/tc/basic/tidb"))
+	g.Expect(cert.ExtKeyUsage).Should(ConsistOf(x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth))
+	// the certificate can not outlive the CA
+	g.Expect(cert.NotAfter).Should(Equal(ca.NotAfter))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "tidb-0.tidb-peer"})
+	g.Expect(err).Should(BeNil())
+
+	secret := &corev1.Secret{Data: map[string][]byte{
+		corev1.ServiceAccountRootCAKey: caCert,
+		corev1.TLSCertKey:              certPEM,
+		corev1.TLSPrivateKeyKey:        keyPEM,
+	}}
+	_, err = LoadTlsConfigFromSecret(secret)
+	g.Expect(err).Should(BeNil())
+
+	_, _, err = IssueCert(certData, caKey, "tidb", nil, nil, nil, time.Hour)
+	g.Expect(err).ShouldNot(BeNil())
+
+	// the bundle of CAs round-trips
+	otherCA, _, err := NewCA("other-ca", 24*time.Hour)
+	g.Expect(err).Should(BeNil())
+	other, err := ParseCertPEM(otherCA)
+	g.Expect(err).Should(BeNil())
+	bundle, err := ParseCertsPEM(append(caCert, otherCA...))
+	g.Expect(err).Should(BeNil())
+	g.Expect(bundle).Should(HaveLen(2))
+	g.Expect(EncodeCertsPEM(ca, other)).Should(Equal(append(caCert, otherCA...)))
+	_, err = ParseCertsPEM(keyPEM)
+	g.Expect(err).ShouldNot(BeNil())
+}
+
 var certData = []byte(`-----BEGIN CERTIFICATE-----
 MIIEMDCCAxigAwIBAgIQUJRs7Bjq1ZxN1ZfvdY+grTANBgkqhkiG9w0BAQUFADCB
 gjELMAkGA1UEBhMCVVMxHjAcBgNVBAsTFXd3dy54cmFtcHNlY3VyaXR5LmNvbTEk
@@ -40,6 +40,16 @@ func ReadErrorBody(body io.Reader) (err error) {
 	return errors.New(string(bodyBytes))
 }
 
+// StatusError is the error responded by the server, which means the request is received but rejected
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
 // GetBodyOK returns the body or an error if the response is not okay
 func GetBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
 	return DoBodyOK(httpClient, apiURL, "GET", nil)
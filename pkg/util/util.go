@@ -212,6 +212,12 @@ func ClusterClientTLSSecretName(tcName string) string {
 	return fmt.Sprintf("%s-cluster-client-secret", tcName)
 }
 
+// ComponentClientTLSSecretName returns the name of the secret of the client certificate that the operator
+// uses to connect to the component, it takes precedence over the cluster client certificate if it exists.
+func ComponentClientTLSSecretName(tcName, component string) string {
+	return fmt.Sprintf("%s-%s-cluster-client-secret", tcName, component)
+}
+
 func ClusterTLSSecretName(tcName, component string) string {
 	return fmt.Sprintf("%s-%s-cluster-secret", tcName, component)
 }
@@ -0,0 +1,111 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+// The changes of the config items after the versions the typed configs are ported from.
+// Add the changes here when the components add, deprecate or remove config items.
+var (
+	pdChanges = []change{
+		{typ: changeDeprecated, key: "log-file", version: "v3.0.0", replacement: "log.file.filename"},
+		{typ: changeDeprecated, key: "log-level", version: "v3.0.0", replacement: "log.level"},
+
+		{typ: changeAdded, key: "replication-mode", version: "v4.0.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "schedule.enable-remove-down-replica", version: "v4.0.0", kind: kindBool},
+		{typ: changeAdded, key: "schedule.enable-replace-offline-replica", version: "v4.0.0", kind: kindBool},
+		{typ: changeAdded, key: "schedule.enable-make-up-replica", version: "v4.0.0", kind: kindBool},
+		{typ: changeAdded, key: "schedule.enable-remove-extra-replica", version: "v4.0.0", kind: kindBool},
+		{typ: changeAdded, key: "schedule.enable-location-replacement", version: "v4.0.0", kind: kindBool},
+		{typ: changeDeprecated, key: "schedule.disable-remove-down-replica", version: "v4.0.0", replacement: "schedule.enable-remove-down-replica"},
+		{typ: changeDeprecated, key: "schedule.disable-replace-offline-replica", version: "v4.0.0", replacement: "schedule.enable-replace-offline-replica"},
+		{typ: changeDeprecated, key: "schedule.disable-make-up-replica", version: "v4.0.0", replacement: "schedule.enable-make-up-replica"},
+		{typ: changeDeprecated, key: "schedule.disable-remove-extra-replica", version: "v4.0.0", replacement: "schedule.enable-remove-extra-replica"},
+		{typ: changeDeprecated, key: "schedule.disable-location-replacement", version: "v4.0.0", replacement: "schedule.enable-location-replacement"},
+		{typ: changeDeprecated, key: "schedule.store-balance-rate", version: "v4.0.0", replacement: "the store limit of pd-ctl"},
+		{typ: changeRemoved, key: "namespace", version: "v4.0.0", replacement: "placement rules"},
+		{typ: changeRemoved, key: "namespace-classifier", version: "v4.0.0", replacement: "placement rules"},
+		{typ: changeRemoved, key: "schedule.disable-namespace-relocation", version: "v4.0.0", replacement: "placement rules"},
+
+		{typ: changeAdded, key: "schedule.enable-joint-consensus", version: "v5.0.0", kind: kindBool},
+		{typ: changeAdded, key: "schedule.region-score-formula-version", version: "v5.0.0", kind: kindString},
+		{typ: changeAdded, key: "security.redact-info-log", version: "v5.0.0", kind: kindBool},
+
+		{typ: changeAdded, key: "controller", version: "v7.1.0", kind: kindOpenTable},
+	}
+
+	tikvChanges = []change{
+		{typ: changeAdded, key: "cdc", version: "v4.0.0", kind: kindOpenTable},
+
+		{typ: changeAdded, key: "raft-engine", version: "v5.0.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "resolved-ts", version: "v5.0.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "storage.io-rate-limit", version: "v5.0.0", kind: kindOpenTable},
+		{typ: changeDeprecated, key: "raftstore.sync-log", version: "v5.0.0"},
+		{typ: changeAdded, key: "storage.flow-control", version: "v5.2.0", kind: kindOpenTable},
+
+		{typ: changeAdded, key: "log.level", version: "v5.4.0", kind: kindString},
+		{typ: changeAdded, key: "log.format", version: "v5.4.0", kind: kindString},
+		{typ: changeAdded, key: "log.enable-timestamp", version: "v5.4.0", kind: kindBool},
+		{typ: changeAdded, key: "log.file.filename", version: "v5.4.0", kind: kindString},
+		{typ: changeAdded, key: "log.file.max-size", version: "v5.4.0", kind: kindInt},
+		{typ: changeAdded, key: "log.file.max-days", version: "v5.4.0", kind: kindInt},
+		{typ: changeAdded, key: "log.file.max-backups", version: "v5.4.0", kind: kindInt},
+		{typ: changeDeprecated, key: "log-level", version: "v5.4.0", replacement: "log.level"},
+		{typ: changeDeprecated, key: "log-file", version: "v5.4.0", replacement: "log.file.filename"},
+		{typ: changeDeprecated, key: "log-format", version: "v5.4.0", replacement: "log.format"},
+		{typ: changeDeprecated, key: "log-rotation-timespan", version: "v5.4.0", replacement: "log.file.max-days"},
+		{typ: changeDeprecated, key: "log-rotation-size", version: "v5.4.0", replacement: "log.file.max-size"},
+
+		{typ: changeAdded, key: "quota", version: "v6.0.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "storage.api-version", version: "v6.1.0", kind: kindInt},
+		{typ: changeAdded, key: "causal-ts", version: "v6.2.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "log-backup", version: "v6.2.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "storage.engine", version: "v6.6.0", kind: kindString},
+		{typ: changeDeprecated, key: "storage.block-cache.shared", version: "v6.6.0"},
+	}
+
+	tidbChanges = []change{
+		{typ: changeDeprecated, key: "alter-primary-key", version: "v5.0.0", replacement: "the CLUSTERED and NONCLUSTERED keywords"},
+		{typ: changeAdded, key: "graceful-wait-before-shutdown", version: "v5.0.0", kind: kindInt},
+		{typ: changeAdded, key: "security.auto-tls", version: "v5.2.0", kind: kindBool},
+		{typ: changeDeprecated, key: "performance.feedback-probability", version: "v5.4.0"},
+
+		{typ: changeAdded, key: "instance", version: "v6.1.0", kind: kindOpenTable},
+		{typ: changeAdded, key: "enable-global-kill", version: "v6.1.0", kind: kindBool},
+		{typ: changeDeprecated, key: "log.enable-slow-log", version: "v6.1.0", replacement: "instance.tidb_enable_slow_log"},
+		{typ: changeDeprecated, key: "log.slow-threshold", version: "v6.1.0", replacement: "instance.tidb_slow_log_threshold"},
+		{typ: changeDeprecated, key: "log.record-plan-in-slow-log", version: "v6.1.0", replacement: "instance.tidb_record_plan_in_slow_log"},
+		{typ: changeDeprecated, key: "check-mb4-value-in-utf8", version: "v6.1.0", replacement: "instance.tidb_check_mb4_value_in_utf8"},
+		{typ: changeDeprecated, key: "performance.force-priority", version: "v6.1.0", replacement: "instance.tidb_force_priority"},
+		{typ: changeDeprecated, key: "mem-quota-query", version: "v6.1.0", replacement: "the system variable tidb_mem_quota_query"},
+		{typ: changeDeprecated, key: "oom-action", version: "v6.1.0", replacement: "the system variable tidb_mem_oom_action"},
+		{typ: changeDeprecated, key: "prepared-plan-cache.enabled", version: "v6.1.0", replacement: "the system variable tidb_enable_prepared_plan_cache"},
+		{typ: changeDeprecated, key: "prepared-plan-cache.capacity", version: "v6.1.0", replacement: "the system variable tidb_prepared_plan_cache_size"},
+		{typ: changeDeprecated, key: "oom-use-tmp-storage", version: "v6.3.0", replacement: "the system variable tidb_enable_tmp_storage_on_oom"},
+
+		{typ: changeAdded, key: "security.session-token-signing-cert", version: "v6.4.0", kind: kindString},
+		{typ: changeAdded, key: "security.session-token-signing-key", version: "v6.4.0", kind: kindString},
+		{typ: changeAdded, key: "initialize-sql-file", version: "v6.6.0", kind: kindString},
+
+		{typ: changeRemoved, key: "binlog", version: "v8.4.0", replacement: "TiCDC"},
+	}
+)
+
+var (
+	PDSchema   = newSchema("pd", v1alpha1.PDConfig{}, pdChanges)
+	TiKVSchema = newSchema("tikv", v1alpha1.TiKVConfig{}, tikvChanges)
+	TiDBSchema = newSchema("tidb", v1alpha1.TiDBConfig{}, tidbChanges)
+)
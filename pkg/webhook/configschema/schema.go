@@ -0,0 +1,382 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/util/cmpver"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// valueKind is the kind of the value of a config item
+type valueKind string
+
+const (
+	kindTable valueKind = "table"
+	// kindOpenTable is a table whose items are not known, such as the labels
+	kindOpenTable valueKind = "open table"
+	kindString    valueKind = "string"
+	kindBool      valueKind = "bool"
+	kindInt       valueKind = "integer"
+	kindFloat     valueKind = "number"
+	kindArray     valueKind = "array"
+	kindAny       valueKind = "any"
+)
+
+// maxTypoDistance is the max edit distance between an unknown item and a known item to regard it as a typo
+const maxTypoDistance = 2
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// node is an item of the config schema
+type node struct {
+	kind     valueKind
+	children map[string]*node
+}
+
+// changeType is the type of the change of a config item in a version
+type changeType string
+
+const (
+	// changeAdded means the item is supported since the version
+	changeAdded changeType = "Added"
+	// changeDeprecated means the item still works but is deprecated since the version
+	changeDeprecated changeType = "Deprecated"
+	// changeRemoved means the item is not supported since the version
+	changeRemoved changeType = "Removed"
+)
+
+// change is the change of a config item in a version of the component
+type change struct {
+	typ     changeType
+	key     string
+	version string
+	// kind is the kind of the added item
+	kind valueKind
+	// replacement is the item or the way that replaces the deprecated or removed item
+	replacement string
+}
+
+// Schema is the schema of the config of a component, it's built from the typed config in
+// `pkg/apis/pingcap/v1alpha1` with the changes of the items in the later versions.
+type Schema struct {
+	component string
+	root      *node
+	changes   []change
+}
+
+// issue is an invalid config item
+type issue struct {
+	key string
+	err *field.Error
+}
+
+// result is the result of the validation
+type result struct {
+	errs     []issue
+	warnings []string
+}
+
+func newSchema(component string, typed interface{}, changes []change) *Schema {
+	s := &Schema{
+		component: component,
+		root:      buildNode(reflect.TypeOf(typed)),
+		changes:   changes,
+	}
+	for _, c := range changes {
+		switch {
+		case c.typ == changeAdded:
+			s.add(c.key, c.kind)
+		case s.lookup(c.key) == nil:
+			// the deprecated and removed items may be not in the typed config
+			s.add(c.key, kindAny)
+		}
+	}
+	return s
+}
+
+func (s *Schema) lookup(key string) *node {
+	n := s.root
+	for _, part := range strings.Split(key, ".") {
+		if n.children == nil {
+			return nil
+		}
+		if n = n.children[part]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// buildNode builds the schema of the typed config by the toml tags
+func buildNode(t reflect.Type) *node {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return &node{kind: kindString}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		n := &node{kind: kindTable, children: map[string]*node{}}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("toml"), ",")[0]
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			n.children[name] = buildNode(f.Type)
+		}
+		return n
+	case reflect.Map:
+		return &node{kind: kindOpenTable}
+	case reflect.Slice, reflect.Array:
+		return &node{kind: kindArray}
+	case reflect.String:
+		return &node{kind: kindString}
+	case reflect.Bool:
+		return &node{kind: kindBool}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &node{kind: kindInt}
+	case reflect.Float32, reflect.Float64:
+		return &node{kind: kindFloat}
+	}
+	return &node{kind: kindAny}
+}
+
+// add adds the item that is not in the typed config
+func (s *Schema) add(key string, kind valueKind) {
+	parts := strings.Split(key, ".")
+	n := s.root
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := n.children[part]
+		if !ok || child.children == nil {
+			child = &node{kind: kindTable, children: map[string]*node{}}
+			n.children[part] = child
+		}
+		n = child
+	}
+	n.children[parts[len(parts)-1]] = &node{kind: kind}
+	if kind == kindTable {
+		n.children[parts[len(parts)-1]].children = map[string]*node{}
+	}
+}
+
+// Validate validates the config of the version of the component. The unknown items that are probably typos of
+// the known items, the items of wrong types and the removed items are errors, while the deprecated items, the
+// items not supported by the version and the other unknown items are warnings, since the schema may lag behind
+// the new versions of the component.
+func (s *Schema) Validate(config map[string]interface{}, version string, fldPath *field.Path) (field.ErrorList, []string) {
+	r := s.validate(config, version, fldPath)
+	var allErrs field.ErrorList
+	for _, i := range r.errs {
+		allErrs = append(allErrs, i.err)
+	}
+	return allErrs, r.warnings
+}
+
+func (s *Schema) validate(config map[string]interface{}, version string, fldPath *field.Path) *result {
+	r := &result{}
+	changes := s.changesOf(version)
+	s.walk(r, s.root, config, "", changes, fldPath)
+	sort.Strings(r.warnings)
+	return r
+}
+
+// changesOf returns the changes of the items by key. The unknown versions, such as the image digests,
+// are regarded as the latest version.
+func (s *Schema) changesOf(version string) map[string]change {
+	changes := map[string]change{}
+	for _, c := range s.changes {
+		since, err := cmpver.Compare(version, cmpver.GreaterOrEqual, c.version)
+		if err != nil {
+			since = true
+		}
+		switch {
+		case c.typ == changeAdded && !since:
+			changes[c.key] = c
+		case c.typ != changeAdded && since:
+			changes[c.key] = c
+		}
+	}
+	return changes
+}
+
+func (s *Schema) walk(r *result, n *node, config map[string]interface{}, prefix string, changes map[string]change, fldPath *field.Path) {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		value := config[k]
+		path := fldPath.Child(k)
+
+		if c, ok := changes[key]; ok {
+			switch c.typ {
+			case changeRemoved:
+				r.errs = append(r.errs, issue{key, field.Forbidden(path,
+					fmt.Sprintf("%s is removed since %s %s%s", key, s.component, c.version, replacementHint(c)))})
+				continue
+			case changeDeprecated:
+				r.warnings = append(r.warnings, fmt.Sprintf("%s: %s is deprecated since %s %s%s",
+					path, key, s.component, c.version, replacementHint(c)))
+			case changeAdded:
+				r.warnings = append(r.warnings, fmt.Sprintf("%s: %s is not supported until %s %s",
+					path, key, s.component, c.version))
+			}
+		}
+
+		child, ok := n.children[k]
+		if !ok {
+			// the item is probably a typo if it's similar to a known item, otherwise it may be a new item
+			// of the component that is not in the schema yet
+			if similar := similarKey(k, n.children); similar != "" {
+				r.errs = append(r.errs, issue{key, field.Invalid(path, k,
+					fmt.Sprintf("unknown config item of %s, did you mean %q?", s.component, similar))})
+			} else {
+				r.warnings = append(r.warnings, fmt.Sprintf("%s: unknown config item of %s", path, s.component))
+			}
+			continue
+		}
+
+		if child.kind == kindTable {
+			table, ok := value.(map[string]interface{})
+			if !ok {
+				r.errs = append(r.errs, issue{key, field.Invalid(path, value, "should be a table")})
+				continue
+			}
+			s.walk(r, child, table, key, changes, fldPath.Child(k))
+			continue
+		}
+		if !kindMatches(child.kind, value) {
+			r.errs = append(r.errs, issue{key, field.Invalid(path, value,
+				fmt.Sprintf("should be of type %s, but is: %T", child.kind, value))})
+		}
+	}
+}
+
+func replacementHint(c change) string {
+	if c.replacement == "" {
+		return ""
+	}
+	return ", use " + c.replacement + " instead"
+}
+
+// kindMatches returns whether the value is of the kind. The strings also accept numbers, because the sizes
+// and durations are strings in the typed config, and the components also accept numbers for the sizes.
+func kindMatches(kind valueKind, value interface{}) bool {
+	switch kind {
+	case kindOpenTable:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case kindString:
+		_, ok := value.(string)
+		return ok || isNumber(value)
+	case kindBool:
+		_, ok := value.(bool)
+		return ok
+	case kindInt:
+		return isInteger(value)
+	case kindFloat:
+		return isNumber(value)
+	case kindArray:
+		switch value.(type) {
+		case []interface{}, []map[string]interface{}:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func isNumber(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isInteger returns whether the value is an integer, the numbers decoded from JSON may be floats
+func isInteger(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == math.Trunc(v.Float())
+	}
+	return isNumber(value)
+}
+
+// similarKey returns the known key that the unknown key is probably a typo of
+func similarKey(key string, known map[string]*node) string {
+	// short keys are too close to each other to guess the typo
+	if len(key) <= maxTypoDistance*2 {
+		return ""
+	}
+	best, bestDistance := "", maxTypoDistance+1
+	for k := range known {
+		d := editDistance(key, k)
+		if d < bestDistance || (d == bestDistance && k < best) {
+			best, bestDistance = k, d
+		}
+	}
+	if bestDistance > maxTypoDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the optimal string alignment distance of a and b, the transposition of
+// two adjacent characters is regarded as one edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestEditDistance(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(editDistance("reserve-space", "reserve-space")).To(Equal(0))
+	g.Expect(editDistance("reserve-spcae", "reserve-space")).To(Equal(1))
+	g.Expect(editDistance("reserve-spac", "reserve-space")).To(Equal(1))
+	g.Expect(editDistance("reserve-spaces", "reserve-space")).To(Equal(1))
+	g.Expect(editDistance("raftstore", "raftdb")).To(Equal(5))
+	g.Expect(editDistance("", "gc")).To(Equal(2))
+}
+
+func TestSchemaValidate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name     string
+		schema   *Schema
+		config   string
+		version  string
+		errs     []string
+		warnings []string
+	}
+	tests := []testcase{
+		{
+			name:    "valid",
+			schema:  TiKVSchema,
+			config:  "[storage]\nreserve-space = \"1GB\"\nscheduler-concurrency = 1024\n[server.labels]\nzone = \"z1\"\n[raft-engine]\nenable = true\n",
+			version: "v6.5.0",
+		},
+		{
+			name:    "typo",
+			schema:  TiKVSchema,
+			config:  "[storage]\nreserve-spcae = \"1GB\"\n[raftstor]\nsync-log = true\n",
+			version: "v6.5.0",
+			errs: []string{
+				`spec.tikv.config.raftstor: Invalid value: "raftstor": unknown config item of tikv, did you mean "raftstore"?`,
+				`spec.tikv.config.storage.reserve-spcae: Invalid value: "reserve-spcae": unknown config item of tikv, did you mean "reserve-space"?`,
+			},
+		},
+		{
+			name:    "wrong types",
+			schema:  TiKVSchema,
+			config:  "coprocessor = 1\n[storage]\nscheduler-concurrency = \"1k\"\nreserve-space = 0\n[server]\nenable-request-batch = 1\nlabels = \"zone\"\n",
+			version: "v6.5.0",
+			errs: []string{
+				`spec.tikv.config.coprocessor: Invalid value: 1: should be a table`,
+				`spec.tikv.config.server.enable-request-batch: Invalid value: 1: should be of type bool, but is: int64`,
+				`spec.tikv.config.server.labels: Invalid value: "zone": should be of type open table, but is: string`,
+				`spec.tikv.config.storage.scheduler-concurrency: Invalid value: "1k": should be of type integer, but is: string`,
+			},
+		},
+		{
+			name:    "unknown items",
+			schema:  TiKVSchema,
+			config:  "[server]\ngrpc-raft-conn-num = 1\nsimplify-metrics = true\n",
+			version: "v6.5.0",
+			warnings: []string{
+				"spec.tikv.config.server.simplify-metrics: unknown config item of tikv",
+			},
+		},
+		{
+			name:    "deprecated items",
+			schema:  TiKVSchema,
+			config:  "log-level = \"info\"\n[raftstore]\nsync-log = true\n",
+			version: "v6.5.0",
+			warnings: []string{
+				"spec.tikv.config.log-level: log-level is deprecated since tikv v5.4.0, use log.level instead",
+				"spec.tikv.config.raftstore.sync-log: raftstore.sync-log is deprecated since tikv v5.0.0",
+			},
+		},
+		{
+			name:    "deprecated items before the version",
+			schema:  TiKVSchema,
+			config:  "log-level = \"info\"\n",
+			version: "v5.3.0",
+		},
+		{
+			name:    "items not supported by the version",
+			schema:  TiKVSchema,
+			config:  "[log]\nlevel = \"info\"\n[log.file]\nmax-days = 1\n",
+			version: "v5.3.0",
+			warnings: []string{
+				"spec.tikv.config.log.file.max-days: log.file.max-days is not supported until tikv v5.4.0",
+				"spec.tikv.config.log.level: log.level is not supported until tikv v5.4.0",
+			},
+		},
+		{
+			name:    "latest version",
+			schema:  TiKVSchema,
+			config:  "[log]\nlevel = \"info\"\n",
+			version: "latest",
+		},
+		{
+			name:    "removed items",
+			schema:  TiDBSchema,
+			config:  "[binlog]\nenable = true\n",
+			version: "v8.5.0",
+			errs: []string{
+				"spec.tidb.config.binlog: Forbidden: binlog is removed since tidb v8.4.0, use TiCDC instead",
+			},
+		},
+		{
+			name:    "removed items before the version",
+			schema:  TiDBSchema,
+			config:  "[binlog]\nenable = true\n",
+			version: "v8.1.0",
+		},
+		{
+			name:    "pd",
+			schema:  PDSchema,
+			config:  "[schedule]\nmax-snapshot-count = 3\nenable-remove-down-replica = true\nmax-store-down-tim = \"30m\"\n[namespace.ns1]\n",
+			version: "v7.5.0",
+			errs: []string{
+				"spec.pd.config.namespace: Forbidden: namespace is removed since pd v4.0.0, use placement rules instead",
+				`spec.pd.config.schedule.max-store-down-tim: Invalid value: "max-store-down-tim": unknown config item of pd, did you mean "max-store-down-time"?`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := v1alpha1.NewTiKVConfig()
+			g.Expect(cfg.UnmarshalTOML([]byte(tt.config))).To(Succeed())
+			path := field.NewPath("spec", tt.schema.component, "config")
+			errs, warnings := tt.schema.Validate(cfg.Inner(), tt.version, path)
+			var errStrs []string
+			for _, err := range errs {
+				errStrs = append(errStrs, err.Error())
+			}
+			g.Expect(errStrs).To(Equal(tt.errs))
+			g.Expect(warnings).To(Equal(tt.warnings))
+		})
+	}
+}
+
+func TestValidateTidbCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newTidbCluster := func(tikvConfig string) *v1alpha1.TidbCluster {
+		tc := &v1alpha1.TidbCluster{}
+		tc.Spec.Version = "v7.5.0"
+		tc.Spec.TiKV = &v1alpha1.TiKVSpec{Config: v1alpha1.NewTiKVConfig()}
+		tc.Spec.TiKV.BaseImage = "pingcap/tikv"
+		g.Expect(tc.Spec.TiKV.Config.UnmarshalTOML([]byte(tikvConfig))).To(Succeed())
+		tc.Spec.TiDB = &v1alpha1.TiDBSpec{Config: v1alpha1.NewTiDBConfig()}
+		tc.Spec.TiDB.BaseImage = "pingcap/tidb"
+		tc.Spec.TiDB.Config.Set("log.slow-threshold", 300)
+		return tc
+	}
+
+	tc := newTidbCluster("[storage]\nreserve-spcae = \"1GB\"\n")
+	errs, warnings := ValidateTidbCluster(tc)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0].Field).To(Equal("spec.tikv.config.storage.reserve-spcae"))
+	g.Expect(warnings).To(ConsistOf(
+		"spec.tidb.config.log.slow-threshold: log.slow-threshold is deprecated since tidb v6.1.0, use instance.tidb_slow_log_threshold instead"))
+
+	// the invalid items that are not changed don't block the update
+	newTc := newTidbCluster("[storage]\nreserve-spcae = \"1GB\"\nscheduler-concurrency = 1024\n")
+	errs, warnings = ValidateTidbClusterUpdate(tc, newTc)
+	g.Expect(errs).To(BeEmpty())
+	g.Expect(warnings).To(ContainElement(ContainSubstring("reserve-spcae")))
+	g.Expect(warnings).To(ContainElement(ContainSubstring("(unchanged)")))
+
+	// the invalid items that are changed are rejected
+	newTc = newTidbCluster("[storage]\nreserve-spcae = \"2GB\"\n")
+	errs, _ = ValidateTidbClusterUpdate(tc, newTc)
+	g.Expect(errs).To(HaveLen(1))
+	newTc = newTidbCluster("[storage]\nreserve-space = \"2GB\"\n[gc]\nbatch-key = 512\n")
+	errs, _ = ValidateTidbClusterUpdate(tc, newTc)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0].Field).To(Equal("spec.tikv.config.gc.batch-key"))
+}
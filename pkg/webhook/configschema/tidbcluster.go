@@ -0,0 +1,92 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configschema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// componentConfig is the config of a component to validate
+type componentConfig struct {
+	schema  *Schema
+	version string
+	config  *config.GenericConfig
+	path    *field.Path
+}
+
+func componentConfigs(tc *v1alpha1.TidbCluster) []componentConfig {
+	var configs []componentConfig
+	specPath := field.NewPath("spec")
+	if tc.Spec.PD != nil && tc.Spec.PD.Config != nil && tc.Spec.PD.Config.GenericConfig != nil {
+		configs = append(configs, componentConfig{PDSchema, tc.PDVersion(), tc.Spec.PD.Config.GenericConfig, specPath.Child("pd", "config")})
+	}
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Config != nil && tc.Spec.TiKV.Config.GenericConfig != nil {
+		configs = append(configs, componentConfig{TiKVSchema, tc.TiKVVersion(), tc.Spec.TiKV.Config.GenericConfig, specPath.Child("tikv", "config")})
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.Config != nil && tc.Spec.TiDB.Config.GenericConfig != nil {
+		configs = append(configs, componentConfig{TiDBSchema, tc.TiDBVersion(), tc.Spec.TiDB.Config.GenericConfig, specPath.Child("tidb", "config")})
+	}
+	return configs
+}
+
+// ValidateTidbCluster validates the config of the components in the TidbCluster against the schemas of their
+// versions, it returns the errors and the warnings.
+func ValidateTidbCluster(tc *v1alpha1.TidbCluster) (field.ErrorList, []string) {
+	var allErrs field.ErrorList
+	var warnings []string
+	for _, c := range componentConfigs(tc) {
+		errs, w := c.schema.Validate(c.config.Inner(), c.version, c.path)
+		allErrs = append(allErrs, errs...)
+		warnings = append(warnings, w...)
+	}
+	return allErrs, warnings
+}
+
+// ValidateTidbClusterUpdate validates the config like ValidateTidbCluster, but the invalid items that are not
+// changed are warnings, so that the existing clusters can still be updated after the schemas become stricter.
+func ValidateTidbClusterUpdate(old, tc *v1alpha1.TidbCluster) (field.ErrorList, []string) {
+	oldConfigs := map[string]*config.GenericConfig{}
+	for _, c := range componentConfigs(old) {
+		oldConfigs[c.path.String()] = c.config
+	}
+
+	var allErrs field.ErrorList
+	var warnings []string
+	for _, c := range componentConfigs(tc) {
+		r := c.schema.validate(c.config.Inner(), c.version, c.path)
+		oldConfig := oldConfigs[c.path.String()]
+		for _, i := range r.errs {
+			if oldConfig != nil && itemUnchanged(oldConfig, c.config, i.key) {
+				warnings = append(warnings, fmt.Sprintf("%s (unchanged)", i.err.Error()))
+				continue
+			}
+			allErrs = append(allErrs, i.err)
+		}
+		warnings = append(warnings, r.warnings...)
+	}
+	return allErrs, warnings
+}
+
+func itemUnchanged(old, new *config.GenericConfig, key string) bool {
+	oldValue, newValue := old.Get(key), new.Get(key)
+	if oldValue == nil || newValue == nil {
+		return false
+	}
+	return reflect.DeepEqual(oldValue.Interface(), newValue.Interface())
+}
@@ -71,8 +71,10 @@ func (w *StrategyAdmissionHook) Validate(ar *admissionv1.AdmissionRequest) *admi
 		return util.ARFail(err)
 	}
 	var allErr field.ErrorList
+	var warnings []string
 	if ar.Operation == admissionv1.Create {
 		allErr = s.Validate(context.TODO(), obj)
+		warnings = s.WarningsOnCreate(context.TODO(), obj)
 	} else {
 		old := s.NewObject()
 		if err := json.Unmarshal(ar.OldObject.Raw, old); err != nil {
@@ -80,11 +82,16 @@ func (w *StrategyAdmissionHook) Validate(ar *admissionv1.AdmissionRequest) *admi
 			return util.ARFail(err)
 		}
 		allErr = s.ValidateUpdate(context.TODO(), obj, old)
+		warnings = s.WarningsOnUpdate(context.TODO(), obj, old)
 	}
+	var resp *admissionv1.AdmissionResponse
 	if len(allErr) > 0 {
-		return util.ARFail(allErr.ToAggregate())
+		resp = util.ARFail(allErr.ToAggregate())
+	} else {
+		resp = util.ARSuccess()
 	}
-	return util.ARSuccess()
+	resp.Warnings = warnings
+	return resp
 }
 
 func (w *StrategyAdmissionHook) Admit(ar *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
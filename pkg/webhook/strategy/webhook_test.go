@@ -220,6 +220,7 @@ type FakeStrategy struct {
 	prepareForUpdateTracker controller.RequestTracker
 	validateTracker         controller.RequestTracker
 	validateUpdateTracker   controller.RequestTracker
+	warnings                []string
 }
 
 func (s *FakeStrategy) NewObject() runtime.Object {
@@ -252,6 +253,44 @@ func (s *FakeStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Obje
 	return allErrs
 }
 
+func (s *FakeStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return s.warnings
+}
+
+func (s *FakeStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	return s.warnings
+}
+
+func TestStrategyAdmissionHook_ValidateWarnings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := NewRegistry()
+	s := &FakeStrategy{warnings: []string{"spec.tikv.config.log-level: log-level is deprecated"}}
+	r.Register(s)
+	w := NewStrategyAdmissionHook(&r)
+	tc := &v1alpha1.TidbCluster{}
+	gvk, err := controller.InferObjectKind(tc)
+	g.Expect(err).To(Succeed())
+	raw, err := json.Marshal(tc)
+	g.Expect(err).To(Succeed())
+	ar := admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Kind: gvk.Kind, Group: gvk.Group, Version: gvk.Version},
+		Operation: admissionv1.Update,
+		Object:    runtime.RawExtension{Raw: raw},
+		OldObject: runtime.RawExtension{Raw: raw},
+	}
+
+	resp := w.Validate(&ar)
+	g.Expect(resp.Allowed).To(BeTrue())
+	g.Expect(resp.Warnings).To(Equal(s.warnings))
+
+	// the warnings are returned with the errors
+	s.validateUpdateTracker.SetError(fmt.Errorf("invalid object"))
+	resp = w.Validate(&ar)
+	g.Expect(resp.Allowed).To(BeFalse())
+	g.Expect(resp.Warnings).To(Equal(s.warnings))
+}
+
 func TestValidatingResource(t *testing.T) {
 	r := NewRegistry()
 	w := NewStrategyAdmissionHook(&r)
@@ -1204,7 +1204,7 @@ func (oa *OperatorActions) pumpIsHealthy(tcName, ns, podName string, tlsEnabled
 	var tlsConfig *tls.Config
 	scheme := "http"
 	if tlsEnabled {
-		tlsConfig, err = pdapi.GetTLSConfig(GetSecretListerWithCacheSynced(oa.kubeCli, 1*time.Second), pdapi.Namespace(ns), util.ClusterTLSSecretName(tcName, label.PumpLabelVal))
+		tlsConfig, err = pdapi.GetTLSConfig(GetSecretListerWithCacheSynced(oa.kubeCli, 1*time.Second), pdapi.Namespace(ns), util.ClusterTLSSecretName(tcName, label.PumpLabelVal), pdapi.TLSServer{})
 		if err != nil {
 			return false
 		}
@@ -1268,7 +1268,7 @@ func (oa *OperatorActions) drainerHealth(tcName, ns, podName string, tlsEnabled
 	var tlsConfig *tls.Config
 	scheme := "http"
 	if tlsEnabled {
-		tlsConfig, err = pdapi.GetTLSConfig(GetSecretListerWithCacheSynced(oa.kubeCli, 1*time.Second), pdapi.Namespace(ns), util.ClusterTLSSecretName(tcName, "drainer"))
+		tlsConfig, err = pdapi.GetTLSConfig(GetSecretListerWithCacheSynced(oa.kubeCli, 1*time.Second), pdapi.Namespace(ns), util.ClusterTLSSecretName(tcName, "drainer"), pdapi.TLSServer{})
 		if err != nil {
 			return false
 		}
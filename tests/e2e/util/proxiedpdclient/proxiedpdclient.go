@@ -44,7 +44,7 @@ func NewProxiedPDClient(secretLister corelisterv1.SecretLister, fw utilportforwa
 	scheme := "http"
 	if tlsEnabled {
 		scheme = "https"
-		tlsConfig, err = pdapi.GetTLSConfig(secretLister, pdapi.Namespace(namespace), util.ClusterClientTLSSecretName(tcName))
+		tlsConfig, err = pdapi.GetTLSConfig(secretLister, pdapi.Namespace(namespace), util.ClusterClientTLSSecretName(tcName), pdapi.TLSServer{})
 		if err != nil {
 			return nil, nil, err
 		}
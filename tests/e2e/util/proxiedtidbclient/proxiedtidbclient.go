@@ -14,6 +14,7 @@
 package proxiedtidbclient
 
 import (
+	"crypto/x509"
 	"net/http"
 	"time"
 
@@ -42,6 +43,14 @@ func (p *proxiedTiDBClient) SetServerLabels(tc *v1alpha1.TidbCluster, ordinal in
 	panic("implement when necessary")
 }
 
+func (p *proxiedTiDBClient) GetConfig(tc *v1alpha1.TidbCluster, ordinal int32) (map[string]interface{}, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) ReloadTLS(tc *v1alpha1.TidbCluster, ordinal int32, user, password string) (*x509.Certificate, error) {
+	panic("implement when necessary")
+}
+
 func NewProxiedTiDBClient(fw portforward.PortForward, caCert []byte) controller.TiDBControlInterface {
 	return &proxiedTiDBClient{fw: fw, httpClient: &http.Client{Timeout: 5 * time.Second}, caCert: caCert}
 }